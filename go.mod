@@ -3,6 +3,8 @@ module github.com/todo
 go 1.21
 
 require (
+	github.com/golang-jwt/jwt/v5 v5.2.0
+	github.com/google/uuid v1.6.0
 	google.golang.org/grpc v1.67.0
 	google.golang.org/protobuf v1.34.2
 )
@@ -0,0 +1,13 @@
+package auth
+
+import "github.com/golang-jwt/jwt/v5"
+
+// Claims are the claims of an access token issued by auth-service.
+type Claims struct {
+	UserID    string   `json:"user_id"`
+	Username  string   `json:"username"`
+	SessionID string   `json:"sid,omitempty"`
+	Roles     []string `json:"roles,omitempty"`
+	Scopes    []string `json:"scopes,omitempty"`
+	jwt.RegisteredClaims
+}
@@ -0,0 +1,110 @@
+package auth
+
+import (
+	"context"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// UnaryServerInterceptor validates the bearer token in the authorization
+// metadata of every call and stores the caller's identity in its context.
+// Calls to the public methods, given by full method name, are let through
+// without credentials, though valid credentials they do carry still set the
+// identity.
+func UnaryServerInterceptor(validator Validator, public ...string) grpc.UnaryServerInterceptor {
+	publicMethods := methodSet(public)
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if publicMethods[info.FullMethod] {
+			return handler(authenticateOptional(ctx, validator), req)
+		}
+
+		ctx, err := authenticate(ctx, validator)
+		if err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+}
+
+// StreamServerInterceptor is the streaming counterpart of
+// UnaryServerInterceptor.
+func StreamServerInterceptor(validator Validator, public ...string) grpc.StreamServerInterceptor {
+	publicMethods := methodSet(public)
+	return func(srv interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if publicMethods[info.FullMethod] {
+			ctx := authenticateOptional(stream.Context(), validator)
+			return handler(srv, &authenticatedStream{ServerStream: stream, ctx: ctx})
+		}
+
+		ctx, err := authenticate(stream.Context(), validator)
+		if err != nil {
+			return err
+		}
+		return handler(srv, &authenticatedStream{ServerStream: stream, ctx: ctx})
+	}
+}
+
+type authenticatedStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *authenticatedStream) Context() context.Context {
+	return s.ctx
+}
+
+func authenticate(ctx context.Context, validator Validator) (context.Context, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	values := md.Get("authorization")
+	if len(values) == 0 {
+		return nil, status.Error(codes.Unauthenticated, ErrUnauthenticated.Error())
+	}
+
+	token, ok := BearerToken(values[0])
+	if !ok {
+		return nil, status.Error(codes.Unauthenticated, "invalid authorization metadata")
+	}
+
+	identity, err := validator.Validate(ctx, token)
+	if err != nil {
+		return nil, status.Error(codes.Unauthenticated, err.Error())
+	}
+
+	return NewContext(ctx, identity), nil
+}
+
+// authenticateOptional is authenticate for public methods, which go on
+// without an identity when the credentials are missing or invalid.
+func authenticateOptional(ctx context.Context, validator Validator) context.Context {
+	if authenticated, err := authenticate(ctx, validator); err == nil {
+		return authenticated
+	}
+	return ctx
+}
+
+func methodSet(methods []string) map[string]bool {
+	set := make(map[string]bool, len(methods))
+	for _, method := range methods {
+		set[method] = true
+	}
+	return set
+}
+
+// ServiceCredentials attaches the shared service token to outgoing calls.
+type ServiceCredentials string
+
+func (c ServiceCredentials) GetRequestMetadata(ctx context.Context, uri ...string) (map[string]string, error) {
+	if c == "" {
+		return nil, nil
+	}
+	return map[string]string{"authorization": "Bearer " + string(c)}, nil
+}
+
+// RequireTransportSecurity allows the token over the plaintext connections
+// used inside the cluster.
+func (c ServiceCredentials) RequireTransportSecurity() bool {
+	return false
+}
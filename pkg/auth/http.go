@@ -0,0 +1,62 @@
+package auth
+
+import (
+	"errors"
+	"net/http"
+)
+
+// Middleware validates the bearer token of every request and stores the
+// caller's identity in its context. Requests the public predicate accepts
+// are let through without credentials; it may be nil.
+func Middleware(validator Validator, public func(r *http.Request) bool) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if public != nil && public(r) {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			token, ok := BearerToken(r.Header.Get("Authorization"))
+			if !ok {
+				http.Error(w, ErrUnauthenticated.Error(), http.StatusUnauthorized)
+				return
+			}
+
+			identity, err := validator.Validate(r.Context(), token)
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusUnauthorized)
+				return
+			}
+
+			next.ServeHTTP(w, r.WithContext(NewContext(r.Context(), identity)))
+		})
+	}
+}
+
+// ServiceMiddleware is Middleware for endpoints that only other services,
+// calling with the service token, may use.
+func ServiceMiddleware(validator Validator) func(http.Handler) http.Handler {
+	authenticate := Middleware(validator, nil)
+	return func(next http.Handler) http.Handler {
+		return authenticate(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if err := RequireService(r.Context()); err != nil {
+				HTTPError(w, err, http.StatusForbidden)
+				return
+			}
+			next.ServeHTTP(w, r)
+		}))
+	}
+}
+
+// HTTPError writes err with 401 or 403 when it is an authentication or
+// authorization failure, and with the fallback status otherwise.
+func HTTPError(w http.ResponseWriter, err error, fallback int) {
+	switch {
+	case errors.Is(err, ErrUnauthenticated):
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+	case errors.Is(err, ErrForbidden):
+		http.Error(w, err.Error(), http.StatusForbidden)
+	default:
+		http.Error(w, err.Error(), fallback)
+	}
+}
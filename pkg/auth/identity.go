@@ -0,0 +1,141 @@
+package auth
+
+import (
+	"context"
+	"errors"
+)
+
+var (
+	// ErrUnauthenticated is returned when a call carries no valid credentials.
+	ErrUnauthenticated = errors.New("authentication required")
+	// ErrForbidden is returned when the caller may not touch a resource.
+	ErrForbidden = errors.New("permission denied")
+)
+
+// Identity is the authenticated caller of a request.
+type Identity struct {
+	UserID   string
+	Username string
+	Roles    []string
+	Scopes   []string
+	// Service is set for other services calling with the shared service
+	// token. They act on behalf of the system and may touch any resource.
+	Service bool
+	// PersonalAccessToken is set when the caller used a personal access
+	// token. Its owner scopes then limit what it may do with its owner's own
+	// resources.
+	PersonalAccessToken bool
+}
+
+// HasScope reports whether the caller holds the scope.
+func (i *Identity) HasScope(scope string) bool {
+	if i.Service {
+		return true
+	}
+	for _, s := range i.Scopes {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
+
+// HasOwnerScope reports whether the caller may use scope on its own
+// resources. Users always may; personal access tokens only when they hold
+// the matching owner scope, such as own:tasks:read for tasks:read.
+func (i *Identity) HasOwnerScope(scope string) bool {
+	return !i.PersonalAccessToken || i.HasScope(OwnerScope(scope))
+}
+
+type contextKey struct{}
+
+// NewContext returns a copy of ctx carrying the identity.
+func NewContext(ctx context.Context, identity *Identity) context.Context {
+	return context.WithValue(ctx, contextKey{}, identity)
+}
+
+// FromContext returns the identity stored in ctx, if any.
+func FromContext(ctx context.Context) (*Identity, bool) {
+	identity, ok := ctx.Value(contextKey{}).(*Identity)
+	return identity, ok && identity != nil
+}
+
+// Authorize checks that the caller may act on resources owned by userID
+// with the access scope names. Only personal access tokens need to hold it.
+func Authorize(ctx context.Context, userID, scope string) error {
+	identity, ok := FromContext(ctx)
+	if !ok {
+		return ErrUnauthenticated
+	}
+	if identity.Service || (userID != "" && identity.UserID == userID && identity.HasOwnerScope(scope)) {
+		return nil
+	}
+	return ErrForbidden
+}
+
+// AuthorizeScope checks that the caller may act on resources owned by
+// userID, either as their owner or by holding the scope.
+func AuthorizeScope(ctx context.Context, userID, scope string) error {
+	identity, ok := FromContext(ctx)
+	if !ok {
+		return ErrUnauthenticated
+	}
+	if identity.HasScope(scope) {
+		return nil
+	}
+	return Authorize(ctx, userID, scope)
+}
+
+// RequireScope checks that the caller holds the scope.
+func RequireScope(ctx context.Context, scope string) error {
+	identity, ok := FromContext(ctx)
+	if !ok {
+		return ErrUnauthenticated
+	}
+	if !identity.HasScope(scope) {
+		return ErrForbidden
+	}
+	return nil
+}
+
+// RequireService checks that the caller is another service.
+func RequireService(ctx context.Context) error {
+	identity, ok := FromContext(ctx)
+	if !ok {
+		return ErrUnauthenticated
+	}
+	if !identity.Service {
+		return ErrForbidden
+	}
+	return nil
+}
+
+// ScopeUser resolves the user a request acts for with the access scope
+// names. An empty userID defaults to the caller; any other must be one the
+// caller may act for. Services may leave it empty to act across users.
+func ScopeUser(ctx context.Context, userID, scope string) (string, error) {
+	return scopeUser(ctx, userID, scope, Authorize)
+}
+
+// ScopeUserWith is ScopeUser that also lets callers holding the scope name
+// any user.
+func ScopeUserWith(ctx context.Context, userID, scope string) (string, error) {
+	return scopeUser(ctx, userID, scope, AuthorizeScope)
+}
+
+func scopeUser(ctx context.Context, userID, scope string, authorize func(context.Context, string, string) error) (string, error) {
+	identity, ok := FromContext(ctx)
+	if !ok {
+		return "", ErrUnauthenticated
+	}
+	if userID == "" {
+		if identity.Service {
+			return "", nil
+		}
+		userID = identity.UserID
+	}
+	if err := authorize(ctx, userID, scope); err != nil {
+		return "", err
+	}
+	return userID, nil
+}
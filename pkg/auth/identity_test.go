@@ -0,0 +1,63 @@
+package auth
+
+import (
+	"context"
+	"testing"
+)
+
+func TestAuthorizePersonalAccessToken(t *testing.T) {
+	const owner = "owner-id"
+	const other = "other-id"
+
+	// A user without roles whose token may only read their own tasks
+	readOwn := &Identity{UserID: owner, Scopes: []string{ScopeOwnTasksRead}, PersonalAccessToken: true}
+	// An admin whose token may only read other users' tasks
+	readAny := &Identity{UserID: owner, Roles: []string{RoleAdmin}, Scopes: []string{ScopeTasksRead}, PersonalAccessToken: true}
+	// The same user signed in with an access token
+	session := &Identity{UserID: owner}
+
+	tests := []struct {
+		name      string
+		identity  *Identity
+		authorize func(context.Context, string, string) error
+		userID    string
+		scope     string
+		wantErr   error
+	}{
+		{name: "owner scope reads own tasks", identity: readOwn, authorize: Authorize, userID: owner, scope: ScopeTasksRead},
+		{name: "owner scope reads own tasks with role check", identity: readOwn, authorize: AuthorizeScope, userID: owner, scope: ScopeTasksRead},
+		{name: "owner scope doesn't write own tasks", identity: readOwn, authorize: Authorize, userID: owner, scope: ScopeTasksWrite, wantErr: ErrForbidden},
+		{name: "owner scope doesn't read own user", identity: readOwn, authorize: Authorize, userID: owner, scope: ScopeUsersRead, wantErr: ErrForbidden},
+		{name: "owner scope doesn't read other tasks", identity: readOwn, authorize: AuthorizeScope, userID: other, scope: ScopeTasksRead, wantErr: ErrForbidden},
+		{name: "role scope reads other tasks", identity: readAny, authorize: AuthorizeScope, userID: other, scope: ScopeTasksRead},
+		{name: "role scope doesn't cover owner-only access", identity: readAny, authorize: Authorize, userID: owner, scope: ScopeTasksRead, wantErr: ErrForbidden},
+		{name: "session needs no owner scope", identity: session, authorize: Authorize, userID: owner, scope: ScopeTasksDelete},
+		{name: "session doesn't read other tasks", identity: session, authorize: AuthorizeScope, userID: other, scope: ScopeTasksRead, wantErr: ErrForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := NewContext(context.Background(), tt.identity)
+			if err := tt.authorize(ctx, tt.userID, tt.scope); err != tt.wantErr {
+				t.Errorf("authorize(%s, %s) = %v, want %v", tt.userID, tt.scope, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestScopeUserPersonalAccessToken(t *testing.T) {
+	identity := &Identity{UserID: "owner-id", Scopes: []string{ScopeOwnTasksRead}, PersonalAccessToken: true}
+	ctx := NewContext(context.Background(), identity)
+
+	userID, err := ScopeUser(ctx, "", ScopeTasksRead)
+	if err != nil {
+		t.Fatalf("ScopeUser: %v", err)
+	}
+	if userID != identity.UserID {
+		t.Errorf("ScopeUser = %q, want the owner %q", userID, identity.UserID)
+	}
+
+	if _, err := ScopeUser(ctx, "", ScopeTasksWrite); err != ErrForbidden {
+		t.Errorf("ScopeUser for tasks:write = %v, want %v", err, ErrForbidden)
+	}
+}
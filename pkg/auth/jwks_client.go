@@ -0,0 +1,132 @@
+package auth
+
+import (
+	"crypto"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// minRefreshInterval limits how often the key set is refetched, so tokens
+// with made-up key IDs can't be used to hammer auth-service.
+const minRefreshInterval = 30 * time.Second
+
+type verificationKey struct {
+	alg string
+	key crypto.PublicKey
+}
+
+// JWKSClient fetches auth-service's public keys and caches them. The set is
+// refetched once it is older than the TTL, or early when a token names a key
+// ID that isn't cached yet, which is how newly rotated keys are picked up.
+type JWKSClient struct {
+	url        string
+	ttl        time.Duration
+	httpClient *http.Client
+
+	mu          sync.Mutex
+	keys        map[string]verificationKey
+	fetchedAt   time.Time
+	attemptedAt time.Time
+}
+
+func NewJWKSClient(url string, ttl time.Duration) *JWKSClient {
+	return &JWKSClient{
+		url:        url,
+		ttl:        ttl,
+		httpClient: &http.Client{Timeout: 10 * time.Second},
+		keys:       make(map[string]verificationKey),
+	}
+}
+
+// Verify checks a token's signature and expiry against the cached key set and
+// returns its claims. Revocation is not checked; callers that need it must
+// ask auth-service, as RevocationList does.
+func (c *JWKSClient) Verify(tokenString string) (*Claims, error) {
+	token, err := jwt.ParseWithClaims(tokenString, &Claims{}, func(token *jwt.Token) (interface{}, error) {
+		kid, _ := token.Header["kid"].(string)
+		if kid == "" {
+			return nil, fmt.Errorf("token has no key ID")
+		}
+
+		key, err := c.key(kid)
+		if err != nil {
+			return nil, err
+		}
+		if token.Method.Alg() != key.alg {
+			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+		}
+		return key.key, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	claims, ok := token.Claims.(*Claims)
+	if !ok || !token.Valid {
+		return nil, fmt.Errorf("invalid token")
+	}
+
+	return claims, nil
+}
+
+func (c *JWKSClient) key(kid string) (verificationKey, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	key, ok := c.keys[kid]
+	if ok && time.Since(c.fetchedAt) < c.ttl {
+		return key, nil
+	}
+
+	if time.Since(c.attemptedAt) >= minRefreshInterval {
+		c.attemptedAt = time.Now()
+		if err := c.refresh(); err != nil && !ok {
+			return verificationKey{}, err
+		}
+		// On failure keep verifying with the keys we have
+		key, ok = c.keys[kid]
+	}
+
+	if !ok {
+		return verificationKey{}, fmt.Errorf("unknown key ID: %s", kid)
+	}
+	return key, nil
+}
+
+func (c *JWKSClient) refresh() error {
+	resp, err := c.httpClient.Get(c.url)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("fetching JWKS: unexpected status %d", resp.StatusCode)
+	}
+
+	var set JWKS
+	if err := json.NewDecoder(resp.Body).Decode(&set); err != nil {
+		return err
+	}
+
+	keys := make(map[string]verificationKey, len(set.Keys))
+	for _, jwk := range set.Keys {
+		if _, err := SigningMethod(jwk.Alg); err != nil {
+			continue
+		}
+		publicKey, err := jwk.PublicKey()
+		if err != nil {
+			continue
+		}
+		keys[jwk.Kid] = verificationKey{alg: jwk.Alg, key: publicKey}
+	}
+
+	c.keys = keys
+	c.fetchedAt = time.Now()
+	return nil
+}
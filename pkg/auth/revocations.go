@@ -0,0 +1,72 @@
+package auth
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	authpb "github.com/todo/proto/auth"
+	"google.golang.org/grpc"
+)
+
+// RevocationListTTL is how long NewValidator caches the revocation list, and
+// so how long a revoked token can still be accepted by local verification.
+const RevocationListTTL = 30 * time.Second
+
+// RevocationList caches the JWT IDs of revoked access tokens that have not
+// expired yet, as listed by auth-service to callers with the service token.
+type RevocationList struct {
+	client       authpb.AuthServiceClient
+	serviceToken string
+	ttl          time.Duration
+
+	mu        sync.Mutex
+	revoked   map[string]time.Time
+	fetchedAt time.Time
+}
+
+func NewRevocationList(client authpb.AuthServiceClient, serviceToken string, ttl time.Duration) *RevocationList {
+	return &RevocationList{
+		client:       client,
+		serviceToken: serviceToken,
+		ttl:          ttl,
+		revoked:      make(map[string]time.Time),
+	}
+}
+
+// Revoked reports whether the token with the given JWT ID has been revoked.
+// The list is refetched once it is older than the TTL. While that fails an
+// error is returned, so that revoked tokens aren't accepted on a stale list.
+func (l *RevocationList) Revoked(ctx context.Context, jti string) (bool, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if time.Since(l.fetchedAt) >= l.ttl {
+		if err := l.refresh(ctx); err != nil {
+			return false, fmt.Errorf("fetching revoked tokens: %v", err)
+		}
+	}
+
+	expiresAt, ok := l.revoked[jti]
+	return ok && time.Now().Before(expiresAt), nil
+}
+
+func (l *RevocationList) refresh(ctx context.Context) error {
+	resp, err := l.client.ListRevokedTokens(ctx, &authpb.ListRevokedTokensRequest{}, grpc.PerRPCCredentials(ServiceCredentials(l.serviceToken)))
+	if err != nil {
+		return err
+	}
+	if resp.Error != "" {
+		return fmt.Errorf("%s", resp.Error)
+	}
+
+	revoked := make(map[string]time.Time, len(resp.Tokens))
+	for _, token := range resp.Tokens {
+		revoked[token.Jti] = token.ExpiresAt.AsTime()
+	}
+
+	l.revoked = revoked
+	l.fetchedAt = time.Now()
+	return nil
+}
@@ -0,0 +1,80 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	authpb "github.com/todo/proto/auth"
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+type fakeRevocationClient struct {
+	authpb.AuthServiceClient
+	tokens []*authpb.RevokedToken
+	err    error
+	calls  int
+}
+
+func (c *fakeRevocationClient) ListRevokedTokens(ctx context.Context, req *authpb.ListRevokedTokensRequest, opts ...grpc.CallOption) (*authpb.ListRevokedTokensResponse, error) {
+	c.calls++
+	if c.err != nil {
+		return nil, c.err
+	}
+	return &authpb.ListRevokedTokensResponse{Tokens: c.tokens}, nil
+}
+
+func TestRevocationList(t *testing.T) {
+	client := &fakeRevocationClient{tokens: []*authpb.RevokedToken{
+		{Jti: "revoked", ExpiresAt: timestamppb.New(time.Now().Add(time.Minute))},
+		{Jti: "expired", ExpiresAt: timestamppb.New(time.Now().Add(-time.Minute))},
+	}}
+	list := NewRevocationList(client, "service-token", time.Hour)
+	ctx := context.Background()
+
+	tests := []struct {
+		jti  string
+		want bool
+	}{
+		{jti: "revoked", want: true},
+		{jti: "expired", want: false},
+		{jti: "live", want: false},
+	}
+	for _, tt := range tests {
+		revoked, err := list.Revoked(ctx, tt.jti)
+		if err != nil {
+			t.Fatalf("Revoked(%q): %v", tt.jti, err)
+		}
+		if revoked != tt.want {
+			t.Errorf("Revoked(%q) = %v, want %v", tt.jti, revoked, tt.want)
+		}
+	}
+
+	if client.calls != 1 {
+		t.Errorf("fetched the list %d times within its TTL, want once", client.calls)
+	}
+}
+
+func TestRevocationListRefusesWhenStale(t *testing.T) {
+	client := &fakeRevocationClient{}
+	list := NewRevocationList(client, "service-token", 0)
+	ctx := context.Background()
+
+	if _, err := list.Revoked(ctx, "live"); err != nil {
+		t.Fatalf("Revoked: %v", err)
+	}
+
+	// A token revoked since is seen on the next fetch
+	client.tokens = []*authpb.RevokedToken{{Jti: "live", ExpiresAt: timestamppb.New(time.Now().Add(time.Minute))}}
+	if revoked, err := list.Revoked(ctx, "live"); err != nil || !revoked {
+		t.Errorf("Revoked after refetch = %v, %v; want true", revoked, err)
+	}
+
+	// Without a fresh list tokens are refused rather than trusted
+	client.err = errors.New("unavailable")
+	if _, err := list.Revoked(ctx, "live"); err == nil {
+		t.Error("Revoked succeeded on a stale list")
+	}
+}
@@ -0,0 +1,60 @@
+package auth
+
+// Scopes let a caller act on other users' data. Users get them through the
+// roles assigned to them in user-service; they are embedded in access
+// tokens. Services hold every scope.
+const (
+	ScopeUsersRead   = "users:read"
+	ScopeUsersWrite  = "users:write"
+	ScopeUsersDelete = "users:delete"
+	ScopeTasksRead   = "tasks:read"
+	ScopeTasksWrite  = "tasks:write"
+	ScopeTasksDelete = "tasks:delete"
+	ScopeRolesManage = "roles:manage"
+)
+
+// Owner scopes let a personal access token act on its owner's own data.
+// Any user may give them to their tokens; other callers don't need them.
+const (
+	ScopeOwnUsersRead   = "own:users:read"
+	ScopeOwnUsersWrite  = "own:users:write"
+	ScopeOwnUsersDelete = "own:users:delete"
+	ScopeOwnTasksRead   = "own:tasks:read"
+	ScopeOwnTasksWrite  = "own:tasks:write"
+	ScopeOwnTasksDelete = "own:tasks:delete"
+)
+
+// Built-in roles seeded by user-service.
+const (
+	// RoleAdmin holds every scope.
+	RoleAdmin = "admin"
+	// RoleSupport may read any user and task but change nothing.
+	RoleSupport = "support"
+)
+
+// AllScopes lists every scope.
+var AllScopes = []string{
+	ScopeUsersRead,
+	ScopeUsersWrite,
+	ScopeUsersDelete,
+	ScopeTasksRead,
+	ScopeTasksWrite,
+	ScopeTasksDelete,
+	ScopeRolesManage,
+}
+
+// OwnerScopes lists every owner scope.
+var OwnerScopes = []string{
+	ScopeOwnUsersRead,
+	ScopeOwnUsersWrite,
+	ScopeOwnUsersDelete,
+	ScopeOwnTasksRead,
+	ScopeOwnTasksWrite,
+	ScopeOwnTasksDelete,
+}
+
+// OwnerScope returns the owner scope that grants scope on the caller's own
+// data, such as own:tasks:read for tasks:read.
+func OwnerScope(scope string) string {
+	return "own:" + scope
+}
@@ -0,0 +1,230 @@
+package auth
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	authpb "github.com/todo/proto/auth"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+)
+
+// maxCachedTokens bounds the RemoteValidator cache; expired entries are
+// swept once it is reached.
+const maxCachedTokens = 10000
+
+// JWKSCacheTTL is how long NewValidator caches the JWKS. Keys that were
+// rotated out must stay published at least this long.
+const JWKSCacheTTL = 5 * time.Minute
+
+// Validator turns a bearer token into the identity of its caller.
+type Validator interface {
+	Validate(ctx context.Context, token string) (*Identity, error)
+}
+
+// PersonalAccessTokenPrefix starts every personal access token, which tells
+// them apart from JWTs.
+const PersonalAccessTokenPrefix = "pat_"
+
+// NewValidator returns a validator that verifies JWTs locally against the
+// JWKS at jwksURL when it is set, and asks auth-service at authAddr
+// otherwise. Locally verified tokens are checked against auth-service's
+// revocation list, fetched with the service token. Personal access tokens
+// are always checked by auth-service. Either way the service token, when
+// set, identifies other services.
+func NewValidator(jwksURL, authAddr, serviceToken string) (Validator, error) {
+	conn, err := grpc.NewClient(authAddr, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to auth service: %v", err)
+	}
+
+	client := authpb.NewAuthServiceClient(conn)
+	var validator Validator = NewRemoteValidator(client, 30*time.Second)
+	if jwksURL != "" {
+		validator = &localValidator{
+			jwks:        NewJWKSClient(jwksURL, JWKSCacheTTL),
+			revocations: NewRevocationList(client, serviceToken, RevocationListTTL),
+			remote:      validator,
+		}
+	}
+
+	return WithServiceToken(validator, serviceToken), nil
+}
+
+// localValidator verifies JWTs against the JWKS and refuses those on the
+// revocation list. It leaves personal access tokens, which only auth-service
+// can look up, to the remote validator.
+type localValidator struct {
+	jwks        *JWKSClient
+	revocations *RevocationList
+	remote      Validator
+}
+
+func (v *localValidator) Validate(ctx context.Context, token string) (*Identity, error) {
+	if strings.HasPrefix(token, PersonalAccessTokenPrefix) {
+		return v.remote.Validate(ctx, token)
+	}
+
+	claims, err := v.jwks.Verify(token)
+	if err != nil {
+		return nil, err
+	}
+
+	revoked, err := v.revocations.Revoked(ctx, claims.ID)
+	if err != nil {
+		return nil, err
+	}
+	if revoked {
+		return nil, fmt.Errorf("invalid token: token has been revoked")
+	}
+
+	return claimsIdentity(claims), nil
+}
+
+// Validate verifies the token locally. Revoked tokens are accepted until
+// they expire, which auth-service keeps to minutes for the asymmetrically
+// signed tokens a JWKS can verify; NewValidator also checks revocations.
+func (c *JWKSClient) Validate(ctx context.Context, token string) (*Identity, error) {
+	claims, err := c.Verify(token)
+	if err != nil {
+		return nil, err
+	}
+
+	return claimsIdentity(claims), nil
+}
+
+func claimsIdentity(claims *Claims) *Identity {
+	return &Identity{
+		UserID:   claims.UserID,
+		Username: claims.Username,
+		Roles:    claims.Roles,
+		Scopes:   claims.Scopes,
+	}
+}
+
+type cachedIdentity struct {
+	identity  *Identity
+	expiresAt time.Time
+}
+
+// RemoteValidator validates tokens with AuthService.ValidateToken, which
+// also rejects revoked tokens. Valid tokens are cached for the TTL, so a
+// revocation can take that long to be noticed.
+type RemoteValidator struct {
+	client authpb.AuthServiceClient
+	ttl    time.Duration
+
+	mu    sync.Mutex
+	cache map[string]cachedIdentity
+}
+
+func NewRemoteValidator(client authpb.AuthServiceClient, ttl time.Duration) *RemoteValidator {
+	return &RemoteValidator{
+		client: client,
+		ttl:    ttl,
+		cache:  make(map[string]cachedIdentity),
+	}
+}
+
+func (v *RemoteValidator) Validate(ctx context.Context, token string) (*Identity, error) {
+	sum := sha256.Sum256([]byte(token))
+	key := hex.EncodeToString(sum[:])
+
+	v.mu.Lock()
+	cached, ok := v.cache[key]
+	v.mu.Unlock()
+	if ok && time.Now().Before(cached.expiresAt) {
+		return cached.identity, nil
+	}
+
+	resp, err := v.client.ValidateToken(ctx, &authpb.ValidateTokenRequest{Token: token})
+	if err != nil {
+		return nil, err
+	}
+	if !resp.Valid {
+		return nil, fmt.Errorf("invalid token: %s", resp.Error)
+	}
+
+	identity := &Identity{
+		UserID:              resp.UserId,
+		Username:            resp.Username,
+		Roles:               resp.Roles,
+		Scopes:              resp.Scopes,
+		PersonalAccessToken: resp.PersonalAccessToken,
+	}
+
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	if len(v.cache) >= maxCachedTokens {
+		now := time.Now()
+		for k, entry := range v.cache {
+			if now.After(entry.expiresAt) {
+				delete(v.cache, k)
+			}
+		}
+	}
+	if len(v.cache) < maxCachedTokens {
+		v.cache[key] = cachedIdentity{identity: identity, expiresAt: time.Now().Add(v.ttl)}
+	}
+
+	return identity, nil
+}
+
+// placeholderServiceToken is the SERVICE_TOKEN the example configurations
+// once shipped with. Anyone could read it, so it is refused like no token.
+const placeholderServiceToken = "your-service-token-change-in-production"
+
+// minServiceTokenLength keeps the service token out of guessing range.
+const minServiceTokenLength = 32
+
+// CheckServiceToken reports why token can't be used as the shared service
+// token, which can act for any user: it must be set, to a random secret of
+// reasonable length rather than the old placeholder.
+func CheckServiceToken(token string) error {
+	switch {
+	case token == "":
+		return fmt.Errorf("SERVICE_TOKEN is not set")
+	case token == placeholderServiceToken:
+		return fmt.Errorf("SERVICE_TOKEN is still the example placeholder")
+	case len(token) < minServiceTokenLength:
+		return fmt.Errorf("SERVICE_TOKEN must be at least %d characters", minServiceTokenLength)
+	}
+	return nil
+}
+
+type serviceTokenValidator struct {
+	next  Validator
+	token string
+}
+
+// WithServiceToken wraps a validator so that the shared service token is
+// accepted as a service identity. An empty token disables this.
+func WithServiceToken(next Validator, serviceToken string) Validator {
+	if serviceToken == "" {
+		return next
+	}
+	return &serviceTokenValidator{next: next, token: serviceToken}
+}
+
+func (v *serviceTokenValidator) Validate(ctx context.Context, token string) (*Identity, error) {
+	if subtle.ConstantTimeCompare([]byte(token), []byte(v.token)) == 1 {
+		return &Identity{Service: true}, nil
+	}
+	return v.next.Validate(ctx, token)
+}
+
+// BearerToken extracts the token from an Authorization header value.
+func BearerToken(header string) (string, bool) {
+	const prefix = "Bearer "
+	if len(header) < len(prefix) || !strings.EqualFold(header[:len(prefix)], prefix) {
+		return "", false
+	}
+	token := strings.TrimSpace(header[len(prefix):])
+	return token, token != ""
+}
@@ -0,0 +1,155 @@
+package events
+
+import (
+	"database/sql"
+	"encoding/json"
+	"log"
+	"net/http"
+	"time"
+)
+
+// HandlerFunc handles an event. Its database writes go through tx, which
+// commits them together with the record that the handler has run. tx is nil
+// for a Consumer without a database.
+type HandlerFunc func(tx *sql.Tx, event *Event) error
+
+type namedHandler struct {
+	name    string
+	handler HandlerFunc
+}
+
+// Consumer receives events pushed by an HTTPPublisher and dispatches them to
+// the handlers registered for their type. When constructed with a database it
+// records, per handler, the events each has processed, so a redelivered
+// event only runs the handlers that have not succeeded yet.
+type Consumer struct {
+	db       *sql.DB
+	handlers map[EventType][]namedHandler
+}
+
+// Events processed before handlers were recorded separately have a single
+// row with an empty handler name, which covers all of them.
+const processedEventsSchema = `
+	CREATE TABLE IF NOT EXISTS processed_events (
+		id VARCHAR(36) NOT NULL,
+		handler VARCHAR(100) NOT NULL DEFAULT '',
+		event_type VARCHAR(100) NOT NULL,
+		processed_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+		PRIMARY KEY (id, handler)
+	);
+
+	ALTER TABLE processed_events ADD COLUMN IF NOT EXISTS handler VARCHAR(100) NOT NULL DEFAULT '';
+
+	DO $$
+	BEGIN
+		IF (SELECT array_length(conkey, 1) FROM pg_constraint
+			WHERE conrelid = 'processed_events'::regclass AND contype = 'p') = 1 THEN
+			ALTER TABLE processed_events DROP CONSTRAINT processed_events_pkey;
+			ALTER TABLE processed_events ADD PRIMARY KEY (id, handler);
+		END IF;
+	END $$;
+`
+
+func NewConsumer(db *sql.DB) (*Consumer, error) {
+	if db != nil {
+		if _, err := db.Exec(processedEventsSchema); err != nil {
+			return nil, err
+		}
+	}
+
+	return &Consumer{
+		db:       db,
+		handlers: make(map[EventType][]namedHandler),
+	}, nil
+}
+
+// Handle registers a handler for an event type. The name records which
+// events the handler has processed, so it must be unique within the
+// consumer and stay the same across releases.
+func (c *Consumer) Handle(eventType EventType, name string, handler HandlerFunc) {
+	c.handlers[eventType] = append(c.handlers[eventType], namedHandler{name: name, handler: handler})
+}
+
+func (c *Consumer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var event Event
+	if err := json.NewDecoder(r.Body).Decode(&event); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := c.Dispatch(&event); err != nil {
+		log.Printf("Failed to handle event %s (%s): %v", event.ID, event.Type, err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// Dispatch runs the handlers registered for the event type, stopping at the
+// first that fails. Events without handlers are acknowledged and ignored.
+func (c *Consumer) Dispatch(event *Event) error {
+	handlers := c.handlers[event.Type]
+	if len(handlers) == 0 {
+		return nil
+	}
+
+	if c.db == nil {
+		for _, h := range handlers {
+			if err := h.handler(nil, event); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	var processed bool
+	err := c.db.QueryRow("SELECT EXISTS(SELECT 1 FROM processed_events WHERE id = $1 AND handler = '')", event.ID).Scan(&processed)
+	if err != nil {
+		return err
+	}
+	if processed {
+		return nil
+	}
+
+	for _, h := range handlers {
+		if err := c.dispatchTo(h, event); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// dispatchTo runs a handler unless it has already processed the event. The
+// record that it has is inserted first, in the handler's transaction: a
+// concurrent delivery of the same event waits on it, and a failing handler
+// rolls it back along with its writes.
+func (c *Consumer) dispatchTo(h namedHandler, event *Event) error {
+	tx, err := c.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	result, err := tx.Exec(
+		"INSERT INTO processed_events (id, handler, event_type, processed_at) VALUES ($1, $2, $3, $4) ON CONFLICT (id, handler) DO NOTHING",
+		event.ID, h.name, event.Type, time.Now(),
+	)
+	if err != nil {
+		return err
+	}
+	inserted, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if inserted == 0 {
+		return nil
+	}
+
+	if err := h.handler(tx, event); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
@@ -0,0 +1,69 @@
+package events
+
+import (
+	"encoding/json"
+	"time"
+)
+
+type EventType string
+
+const (
+	TaskCreated   EventType = "TaskCreated"
+	TaskUpdated   EventType = "TaskUpdated"
+	TaskCompleted EventType = "TaskCompleted"
+	TaskDeleted   EventType = "TaskDeleted"
+	TaskWoken     EventType = "TaskWoken"
+	TaskEscalated EventType = "TaskEscalated"
+	TaskNotified  EventType = "TaskNotified"
+	UserCreated   EventType = "UserCreated"
+	UserUpdated   EventType = "UserUpdated"
+	UserDeleted   EventType = "UserDeleted"
+)
+
+type Event struct {
+	ID          string          `json:"id"`
+	Type        EventType       `json:"type"`
+	AggregateID string          `json:"aggregate_id"`
+	Payload     json.RawMessage `json:"payload"`
+	OccurredAt  time.Time       `json:"occurred_at"`
+}
+
+// Decode unmarshals the event payload into v.
+func (e *Event) Decode(v interface{}) error {
+	return json.Unmarshal(e.Payload, v)
+}
+
+type TaskPayload struct {
+	ID        string     `json:"id"`
+	Title     string     `json:"title"`
+	Status    string     `json:"status"`
+	Priority  string     `json:"priority"`
+	UserID    string     `json:"user_id"`
+	ProjectID string     `json:"project_id,omitempty"`
+	DueDate   *time.Time `json:"due_date,omitempty"`
+}
+
+// TaskEscalatedPayload describes an overdue task escalation and the users it
+// notifies, who may be none when only the priority was raised.
+type TaskEscalatedPayload struct {
+	Task         TaskPayload `json:"task"`
+	Action       string      `json:"action"`
+	HoursOverdue int         `json:"hours_overdue"`
+	RecipientIDs []string    `json:"recipient_ids"`
+}
+
+// TaskNotificationPayload is a message about a task for the given users,
+// sent by an automation rule.
+type TaskNotificationPayload struct {
+	Task         TaskPayload `json:"task"`
+	Subject      string      `json:"subject"`
+	Message      string      `json:"message"`
+	RecipientIDs []string    `json:"recipient_ids"`
+}
+
+type UserPayload struct {
+	ID       string `json:"id"`
+	Username string `json:"username"`
+	Email    string `json:"email"`
+	FullName string `json:"full_name"`
+}
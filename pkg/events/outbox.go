@@ -0,0 +1,45 @@
+package events
+
+import (
+	"database/sql"
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// CreateOutboxTable creates the outbox table used by Append and Relay.
+func CreateOutboxTable(db *sql.DB) error {
+	_, err := db.Exec(`
+		CREATE TABLE IF NOT EXISTS outbox_events (
+			id VARCHAR(36) PRIMARY KEY,
+			event_type VARCHAR(100) NOT NULL,
+			aggregate_id VARCHAR(36) NOT NULL,
+			payload JSONB NOT NULL,
+			attempts INT NOT NULL DEFAULT 0,
+			last_error TEXT,
+			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+			published_at TIMESTAMP
+		);
+		CREATE INDEX IF NOT EXISTS idx_outbox_events_unpublished ON outbox_events(created_at) WHERE published_at IS NULL;
+		ALTER TABLE outbox_events ADD COLUMN IF NOT EXISTS next_attempt_at TIMESTAMP;
+		ALTER TABLE outbox_events ADD COLUMN IF NOT EXISTS dead_lettered_at TIMESTAMP;
+		ALTER TABLE outbox_events ADD COLUMN IF NOT EXISTS locked_until TIMESTAMP;
+	`)
+	return err
+}
+
+// Append records an event in the outbox as part of tx, so it is only
+// published if the surrounding mutation commits.
+func Append(tx *sql.Tx, eventType EventType, aggregateID string, payload interface{}) error {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(
+		"INSERT INTO outbox_events (id, event_type, aggregate_id, payload, created_at) VALUES ($1, $2, $3, $4, $5)",
+		uuid.New().String(), eventType, aggregateID, data, time.Now(),
+	)
+	return err
+}
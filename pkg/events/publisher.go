@@ -0,0 +1,83 @@
+package events
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// ErrNoSubscribers is returned by an HTTPPublisher that has no endpoints.
+var ErrNoSubscribers = errors.New("no event subscribers configured")
+
+type Publisher interface {
+	Publish(ctx context.Context, event *Event) error
+}
+
+// HTTPPublisher delivers every event to each subscriber endpoint as a JSON
+// POST, authenticated with the service token when one is set.
+type HTTPPublisher struct {
+	endpoints    []string
+	serviceToken string
+	client       *http.Client
+}
+
+func NewHTTPPublisher(endpoints []string, serviceToken string) *HTTPPublisher {
+	return &HTTPPublisher{
+		endpoints:    endpoints,
+		serviceToken: serviceToken,
+		client:       &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// ParseEndpoints splits a comma-separated subscriber list, skipping blanks.
+func ParseEndpoints(value string) []string {
+	var endpoints []string
+	for _, endpoint := range strings.Split(value, ",") {
+		endpoint = strings.TrimSpace(endpoint)
+		if endpoint != "" {
+			endpoints = append(endpoints, endpoint)
+		}
+	}
+	return endpoints
+}
+
+// Publish delivers the event to every endpoint. Without endpoints it fails,
+// so that the event stays in the outbox instead of being dropped.
+func (p *HTTPPublisher) Publish(ctx context.Context, event *Event) error {
+	if len(p.endpoints) == 0 {
+		return ErrNoSubscribers
+	}
+
+	body, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	for _, endpoint := range p.endpoints {
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(body))
+		if err != nil {
+			return err
+		}
+		req.Header.Set("Content-Type", "application/json")
+		if p.serviceToken != "" {
+			req.Header.Set("Authorization", "Bearer "+p.serviceToken)
+		}
+
+		resp, err := p.client.Do(req)
+		if err != nil {
+			return fmt.Errorf("failed to deliver event to %s: %v", endpoint, err)
+		}
+		resp.Body.Close()
+
+		if resp.StatusCode >= 300 {
+			return fmt.Errorf("failed to deliver event to %s: status %d", endpoint, resp.StatusCode)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,218 @@
+package events
+
+import (
+	"context"
+	"database/sql"
+	"log"
+	"time"
+)
+
+const (
+	// maxPublishAttempts is how often an event is tried before the relay
+	// gives up on it and moves on.
+	maxPublishAttempts = 10
+
+	maxPublishBackoff = 10 * time.Minute
+
+	// claimTimeout is how long a relay may take to publish the events it
+	// claimed before other relays take them over.
+	claimTimeout = 5 * time.Minute
+)
+
+// Relay polls the outbox table and hands unpublished events to a Publisher
+// in creation order. Events are claimed in a short transaction, by setting
+// locked_until, and published after it commits, so no transaction or row
+// lock is held while the publisher waits on the network. A relay that dies
+// mid-batch leaves its claims to expire, and the events are published again:
+// delivery is at-least-once and consumers deduplicate by ID. A failing event
+// is retried with exponential backoff and holds back the events after it.
+// Every claim counts as an attempt; after maxPublishAttempts the event is
+// dead-lettered, by setting dead_lettered_at, and skipped.
+type Relay struct {
+	db        *sql.DB
+	publisher Publisher
+	interval  time.Duration
+	batchSize int
+}
+
+func NewRelay(db *sql.DB, publisher Publisher, interval time.Duration) *Relay {
+	return &Relay{
+		db:        db,
+		publisher: publisher,
+		interval:  interval,
+		batchSize: 100,
+	}
+}
+
+func (r *Relay) Run(ctx context.Context) {
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+
+	for {
+		if err := r.publishBatch(ctx); err != nil {
+			log.Printf("Outbox relay: %v", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// claimedEvent is an outbox event claimed for publishing. attempts includes
+// the current one.
+type claimedEvent struct {
+	event     *Event
+	attempts  int
+	lastError string
+}
+
+func (r *Relay) publishBatch(ctx context.Context) error {
+	claimed, err := r.claimBatch(ctx)
+	if err != nil {
+		return err
+	}
+
+	for i, c := range claimed {
+		publishErr := r.publisher.Publish(ctx, c.event)
+		if publishErr == nil {
+			if _, err := r.db.ExecContext(ctx,
+				"UPDATE outbox_events SET published_at = $2, locked_until = NULL WHERE id = $1",
+				c.event.ID, time.Now(),
+			); err != nil {
+				return err
+			}
+			continue
+		}
+
+		if c.attempts >= maxPublishAttempts {
+			if err := deadLetter(ctx, r.db, c, publishErr.Error()); err != nil {
+				return err
+			}
+			continue
+		}
+
+		// Later events wait for a failed one, so they are not delivered out
+		// of order
+		if _, err := r.db.ExecContext(ctx,
+			"UPDATE outbox_events SET last_error = $2, next_attempt_at = $3, locked_until = NULL WHERE id = $1",
+			c.event.ID, publishErr.Error(), time.Now().Add(publishBackoff(c.attempts)),
+		); err != nil {
+			return err
+		}
+		if err := r.release(ctx, claimed[i+1:]); err != nil {
+			return err
+		}
+		return publishErr
+	}
+
+	return nil
+}
+
+// claimBatch claims the oldest unpublished events, up to the first that is
+// claimed by another relay or waiting to be retried, and bumps their
+// attempts. Events that were claimed maxPublishAttempts times without being
+// published or failing, because their relays died, are dead-lettered.
+func (r *Relay) claimBatch(ctx context.Context) ([]claimedEvent, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	// Without SKIP LOCKED a relay claiming at the same time is waited for,
+	// and its claims then stop this one, keeping events in order
+	rows, err := tx.QueryContext(ctx,
+		"SELECT id, event_type, aggregate_id, payload, created_at, attempts, COALESCE(last_error, ''), next_attempt_at, locked_until FROM outbox_events"+
+			" WHERE published_at IS NULL AND dead_lettered_at IS NULL ORDER BY created_at LIMIT $1 FOR UPDATE",
+		r.batchSize,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	var claimed []claimedEvent
+	for rows.Next() {
+		c := claimedEvent{event: &Event{}}
+		var nextAttemptAt, lockedUntil sql.NullTime
+		if err := rows.Scan(&c.event.ID, &c.event.Type, &c.event.AggregateID, &c.event.Payload, &c.event.OccurredAt, &c.attempts, &c.lastError, &nextAttemptAt, &lockedUntil); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		if (nextAttemptAt.Valid && now.Before(nextAttemptAt.Time)) || (lockedUntil.Valid && now.Before(lockedUntil.Time)) {
+			break
+		}
+		claimed = append(claimed, c)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	batch := claimed[:0]
+	for _, c := range claimed {
+		if c.attempts >= maxPublishAttempts {
+			if err := deadLetter(ctx, tx, c, "claim expired: "+c.lastError); err != nil {
+				return nil, err
+			}
+			continue
+		}
+
+		c.attempts++
+		if _, err := tx.ExecContext(ctx,
+			"UPDATE outbox_events SET attempts = $2, locked_until = $3 WHERE id = $1",
+			c.event.ID, c.attempts, now.Add(claimTimeout),
+		); err != nil {
+			return nil, err
+		}
+		batch = append(batch, c)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return batch, nil
+}
+
+// release gives up the claims on events that were not tried.
+func (r *Relay) release(ctx context.Context, claimed []claimedEvent) error {
+	for _, c := range claimed {
+		if _, err := r.db.ExecContext(ctx,
+			"UPDATE outbox_events SET attempts = attempts - 1, locked_until = NULL WHERE id = $1",
+			c.event.ID,
+		); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// execer is satisfied by both *sql.DB and *sql.Tx.
+type execer interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+}
+
+func deadLetter(ctx context.Context, db execer, c claimedEvent, lastError string) error {
+	if _, err := db.ExecContext(ctx,
+		"UPDATE outbox_events SET attempts = $2, last_error = $3, dead_lettered_at = $4, locked_until = NULL WHERE id = $1",
+		c.event.ID, c.attempts, lastError, time.Now(),
+	); err != nil {
+		return err
+	}
+	log.Printf("Outbox relay: gave up on event %s (%s) after %d attempts: %s", c.event.ID, c.event.Type, c.attempts, lastError)
+	return nil
+}
+
+// publishBackoff returns how long to wait before trying an event again after
+// the given number of failed attempts.
+func publishBackoff(attempts int) time.Duration {
+	backoff := time.Second << attempts
+	if backoff <= 0 || backoff > maxPublishBackoff {
+		backoff = maxPublishBackoff
+	}
+	return backoff
+}
@@ -1,19 +1,23 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"log"
 	"net"
 	"net/http"
 	"os"
+	"strconv"
 	"time"
 
 	"github.com/gorilla/mux"
+	"github.com/todo/pkg/auth"
 	pb "github.com/todo/proto/auth"
 	grpcServer "github.com/todo/services/auth-service/internal/grpc"
 	httpHandler "github.com/todo/services/auth-service/internal/http"
 	"github.com/todo/services/auth-service/internal/jwt"
 	"github.com/todo/services/auth-service/internal/repository"
+	"github.com/todo/services/auth-service/internal/service"
 	"google.golang.org/grpc"
 )
 
@@ -27,6 +31,17 @@ func main() {
 	grpcPort := getEnv("GRPC_PORT", "50052")
 	httpPort := getEnv("HTTP_PORT", "8082")
 	jwtSecret := getEnv("JWT_SECRET", "your-secret-key-change-in-production")
+	jwtAlgorithm := getEnv("JWT_ALGORITHM", "HS256")
+	keyRotationDays := getEnv("JWT_KEY_ROTATION_DAYS", "30")
+	totpIssuer := getEnv("TOTP_ISSUER", "Todo")
+	serviceToken := getEnv("SERVICE_TOKEN", "")
+	if err := auth.CheckServiceToken(serviceToken); err != nil {
+		log.Fatalf("Refusing to start: %v", err)
+	}
+	trustedProxies, err := httpHandler.ParseTrustedProxies(getEnv("TRUSTED_PROXIES", ""))
+	if err != nil {
+		log.Fatalf("Invalid TRUSTED_PROXIES: %v", err)
+	}
 
 	// Connect to database
 	connStr := fmt.Sprintf("host=%s port=%s user=%s password=%s dbname=%s sslmode=disable",
@@ -38,8 +53,56 @@ func main() {
 	}
 	defer repo.Close()
 
-	// Create JWT manager
-	jwtManager := jwt.NewJWTManager(jwtSecret, 24*time.Hour)
+	// Create JWT manager; revoked tokens are rejected on validation. With an
+	// asymmetric algorithm tokens are signed from a rotating key set, and
+	// since services verifying them against the JWKS can't see revocations
+	// they only last minutes and are refreshed from there.
+	tokenDuration := 24 * time.Hour
+	var jwtManager *jwt.JWTManager
+	if jwtAlgorithm == "HS256" {
+		jwtManager = jwt.NewJWTManager(jwtSecret, tokenDuration, repo)
+	} else {
+		tokenDuration = 15 * time.Minute
+
+		rotationDays, err := strconv.Atoi(keyRotationDays)
+		if err != nil || rotationDays <= 0 {
+			log.Fatalf("Invalid JWT_KEY_ROTATION_DAYS: %s", keyRotationDays)
+		}
+
+		// Rotated keys keep verifying until every token they signed has
+		// expired: other replicas may sign with a key until their next reload,
+		// and verifiers may hold an older JWKS for up to its cache TTL
+		const keyReloadInterval = time.Hour
+		overlap := keyReloadInterval + tokenDuration + auth.JWKSCacheTTL
+		keySet, err := jwt.NewKeySet(repo, jwtAlgorithm, time.Duration(rotationDays)*24*time.Hour, overlap)
+		if err != nil {
+			log.Fatalf("Failed to load JWT signing keys: %v", err)
+		}
+		go keySet.Run(context.Background(), keyReloadInterval)
+
+		jwtManager = jwt.NewKeySetJWTManager(keySet, tokenDuration, repo)
+	}
+
+	// Drop revocations, refresh tokens, MFA challenges and failed logins that
+	// have since expired on their own
+	go func() {
+		for range time.Tick(time.Hour) {
+			if _, err := repo.PurgeExpiredRevocations(time.Now()); err != nil {
+				log.Printf("Failed to purge expired token revocations: %v", err)
+			}
+			if _, err := repo.PurgeExpiredRefreshTokens(time.Now()); err != nil {
+				log.Printf("Failed to purge expired refresh tokens: %v", err)
+			}
+			if _, err := repo.PurgeExpiredMFAChallenges(time.Now()); err != nil {
+				log.Printf("Failed to purge expired MFA challenges: %v", err)
+			}
+			if _, err := repo.PurgeLoginFailures(time.Now(), time.Now().Add(-service.LoginFailureWindow)); err != nil {
+				log.Printf("Failed to purge old login failures: %v", err)
+			}
+		}
+	}()
+
+	authService := service.NewAuthService(repo, jwtManager, totpIssuer)
 
 	// Start gRPC server
 	go func() {
@@ -48,8 +111,36 @@ func main() {
 			log.Fatalf("Failed to listen on gRPC port: %v", err)
 		}
 
-		s := grpc.NewServer()
-		pb.RegisterAuthServiceServer(s, grpcServer.NewAuthServer(repo, jwtManager))
+		// Logging in and validating tokens need no credentials, and the calls
+		// that act for a user authenticate with the token in their request.
+		// The rest are for other services.
+		public := []string{
+			pb.AuthService_Login_FullMethodName,
+			pb.AuthService_VerifyMFA_FullMethodName,
+			pb.AuthService_RefreshToken_FullMethodName,
+			pb.AuthService_ValidateToken_FullMethodName,
+			pb.AuthService_Logout_FullMethodName,
+			pb.AuthService_ListSessions_FullMethodName,
+			pb.AuthService_RevokeSession_FullMethodName,
+			pb.AuthService_RevokeAllOtherSessions_FullMethodName,
+			pb.AuthService_CreatePersonalAccessToken_FullMethodName,
+			pb.AuthService_ListPersonalAccessTokens_FullMethodName,
+			pb.AuthService_RevokePersonalAccessToken_FullMethodName,
+			pb.AuthService_GetMFAStatus_FullMethodName,
+			pb.AuthService_EnrollTOTP_FullMethodName,
+			pb.AuthService_ConfirmTOTP_FullMethodName,
+			pb.AuthService_DisableTOTP_FullMethodName,
+			pb.AuthService_RegenerateRecoveryCodes_FullMethodName,
+			pb.AuthService_ListLoginLockouts_FullMethodName,
+			pb.AuthService_UnlockLogin_FullMethodName,
+		}
+		validator := auth.WithServiceToken(grpcServer.NewValidator(authService), serviceToken)
+
+		s := grpc.NewServer(
+			grpc.UnaryInterceptor(auth.UnaryServerInterceptor(validator, public...)),
+			grpc.StreamInterceptor(auth.StreamServerInterceptor(validator, public...)),
+		)
+		pb.RegisterAuthServiceServer(s, grpcServer.NewAuthServer(authService))
 
 		log.Printf("gRPC server listening on :%s", grpcPort)
 		if err := s.Serve(lis); err != nil {
@@ -59,7 +150,7 @@ func main() {
 
 	// Start HTTP server
 	router := mux.NewRouter()
-	handler := httpHandler.NewHandler(repo, jwtManager)
+	handler := httpHandler.NewHandler(authService, trustedProxies)
 	handler.RegisterRoutes(router)
 
 	log.Printf("HTTP server listening on :%s", httpPort)
@@ -0,0 +1,75 @@
+package grpc
+
+import (
+	"context"
+	"errors"
+	"math"
+
+	pb "github.com/todo/proto/auth"
+	"github.com/todo/services/auth-service/internal/service"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func (s *AuthServer) ListLoginLockouts(ctx context.Context, req *pb.ListLoginLockoutsRequest) (*pb.ListLoginLockoutsResponse, error) {
+	claims, err := s.auth.Validate(req.Token)
+	if err != nil {
+		return &pb.ListLoginLockoutsResponse{
+			Error: err.Error(),
+		}, nil
+	}
+
+	lockouts, err := s.auth.ListLoginLockouts(claims)
+	if err != nil {
+		return &pb.ListLoginLockoutsResponse{
+			Error: err.Error(),
+		}, nil
+	}
+
+	pbLockouts := make([]*pb.LoginLockout, len(lockouts))
+	for i, lockout := range lockouts {
+		pbLockouts[i] = &pb.LoginLockout{
+			Kind:         lockout.Kind,
+			Value:        lockout.Value,
+			Failures:     int32(lockout.Failures),
+			LastFailedAt: timestamppb.New(lockout.LastFailedAt),
+		}
+		if lockout.LockedUntil != nil {
+			pbLockouts[i].LockedUntil = timestamppb.New(*lockout.LockedUntil)
+		}
+	}
+
+	return &pb.ListLoginLockoutsResponse{
+		Lockouts: pbLockouts,
+	}, nil
+}
+
+func (s *AuthServer) UnlockLogin(ctx context.Context, req *pb.UnlockLoginRequest) (*pb.UnlockLoginResponse, error) {
+	claims, err := s.auth.Validate(req.Token)
+	if err != nil {
+		return &pb.UnlockLoginResponse{
+			Success: false,
+			Error:   err.Error(),
+		}, nil
+	}
+
+	if err := s.auth.UnlockLogin(claims, req.Username, req.IpAddress); err != nil {
+		return &pb.UnlockLoginResponse{
+			Success: false,
+			Error:   err.Error(),
+		}, nil
+	}
+
+	return &pb.UnlockLoginResponse{
+		Success: true,
+	}, nil
+}
+
+// loginErrorCode returns the code of a refused login and how many seconds to
+// wait before trying again, rounded up.
+func loginErrorCode(err error) (string, int32) {
+	var loginErr *service.LoginError
+	if !errors.As(err, &loginErr) {
+		return "", 0
+	}
+	return loginErr.Code, int32(math.Ceil(loginErr.RetryAfter.Seconds()))
+}
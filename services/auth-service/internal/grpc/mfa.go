@@ -0,0 +1,129 @@
+package grpc
+
+import (
+	"context"
+
+	pb "github.com/todo/proto/auth"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func (s *AuthServer) VerifyMFA(ctx context.Context, req *pb.VerifyMFARequest) (*pb.VerifyMFAResponse, error) {
+	tokens, err := s.auth.VerifyMFA(req.MfaToken, req.Code, clientInfo(ctx, req.UserAgent, req.IpAddress))
+	if err != nil {
+		code, retryAfter := loginErrorCode(err)
+		return &pb.VerifyMFAResponse{
+			Error:             err.Error(),
+			ErrorCode:         code,
+			RetryAfterSeconds: retryAfter,
+		}, nil
+	}
+
+	return &pb.VerifyMFAResponse{
+		AccessToken:  tokens.AccessToken,
+		RefreshToken: tokens.RefreshToken,
+		ExpiresAt:    timestamppb.New(tokens.ExpiresAt),
+	}, nil
+}
+
+func (s *AuthServer) GetMFAStatus(ctx context.Context, req *pb.GetMFAStatusRequest) (*pb.GetMFAStatusResponse, error) {
+	claims, err := s.auth.Validate(req.Token)
+	if err != nil {
+		return &pb.GetMFAStatusResponse{
+			Error: err.Error(),
+		}, nil
+	}
+
+	status, err := s.auth.MFAStatus(claims.UserID)
+	if err != nil {
+		return &pb.GetMFAStatusResponse{
+			Error: err.Error(),
+		}, nil
+	}
+
+	return &pb.GetMFAStatusResponse{
+		TotpEnabled:            status.TOTPEnabled,
+		RecoveryCodesRemaining: int32(status.RecoveryCodesRemaining),
+	}, nil
+}
+
+func (s *AuthServer) EnrollTOTP(ctx context.Context, req *pb.EnrollTOTPRequest) (*pb.EnrollTOTPResponse, error) {
+	claims, err := s.auth.Validate(req.Token)
+	if err != nil {
+		return &pb.EnrollTOTPResponse{
+			Error: err.Error(),
+		}, nil
+	}
+
+	enrollment, err := s.auth.EnrollTOTP(claims)
+	if err != nil {
+		return &pb.EnrollTOTPResponse{
+			Error: err.Error(),
+		}, nil
+	}
+
+	return &pb.EnrollTOTPResponse{
+		Secret:     enrollment.Secret,
+		OtpauthUri: enrollment.URI,
+	}, nil
+}
+
+func (s *AuthServer) ConfirmTOTP(ctx context.Context, req *pb.ConfirmTOTPRequest) (*pb.ConfirmTOTPResponse, error) {
+	claims, err := s.auth.Validate(req.Token)
+	if err != nil {
+		return &pb.ConfirmTOTPResponse{
+			Error: err.Error(),
+		}, nil
+	}
+
+	codes, err := s.auth.ConfirmTOTP(claims, req.Code)
+	if err != nil {
+		return &pb.ConfirmTOTPResponse{
+			Error: err.Error(),
+		}, nil
+	}
+
+	return &pb.ConfirmTOTPResponse{
+		RecoveryCodes: codes,
+	}, nil
+}
+
+func (s *AuthServer) DisableTOTP(ctx context.Context, req *pb.DisableTOTPRequest) (*pb.DisableTOTPResponse, error) {
+	claims, err := s.auth.Validate(req.Token)
+	if err != nil {
+		return &pb.DisableTOTPResponse{
+			Success: false,
+			Error:   err.Error(),
+		}, nil
+	}
+
+	if err := s.auth.DisableTOTP(claims, req.Code); err != nil {
+		return &pb.DisableTOTPResponse{
+			Success: false,
+			Error:   err.Error(),
+		}, nil
+	}
+
+	return &pb.DisableTOTPResponse{
+		Success: true,
+	}, nil
+}
+
+func (s *AuthServer) RegenerateRecoveryCodes(ctx context.Context, req *pb.RegenerateRecoveryCodesRequest) (*pb.RegenerateRecoveryCodesResponse, error) {
+	claims, err := s.auth.Validate(req.Token)
+	if err != nil {
+		return &pb.RegenerateRecoveryCodesResponse{
+			Error: err.Error(),
+		}, nil
+	}
+
+	codes, err := s.auth.RegenerateRecoveryCodes(claims, req.Code)
+	if err != nil {
+		return &pb.RegenerateRecoveryCodesResponse{
+			Error: err.Error(),
+		}, nil
+	}
+
+	return &pb.RegenerateRecoveryCodesResponse{
+		RecoveryCodes: codes,
+	}, nil
+}
@@ -0,0 +1,92 @@
+package grpc
+
+import (
+	"context"
+
+	pb "github.com/todo/proto/auth"
+	"github.com/todo/services/auth-service/internal/models"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func (s *AuthServer) CreatePersonalAccessToken(ctx context.Context, req *pb.CreatePersonalAccessTokenRequest) (*pb.CreatePersonalAccessTokenResponse, error) {
+	claims, err := s.auth.Validate(req.Token)
+	if err != nil {
+		return &pb.CreatePersonalAccessTokenResponse{
+			Error: err.Error(),
+		}, nil
+	}
+
+	pat, secret, err := s.auth.CreatePersonalAccessToken(claims, req.Name, req.Scopes, int(req.ExpiresInDays))
+	if err != nil {
+		return &pb.CreatePersonalAccessTokenResponse{
+			Error: err.Error(),
+		}, nil
+	}
+
+	return &pb.CreatePersonalAccessTokenResponse{
+		PersonalAccessToken: personalAccessTokenToProto(pat),
+		Secret:              secret,
+	}, nil
+}
+
+func (s *AuthServer) ListPersonalAccessTokens(ctx context.Context, req *pb.ListPersonalAccessTokensRequest) (*pb.ListPersonalAccessTokensResponse, error) {
+	claims, err := s.auth.Validate(req.Token)
+	if err != nil {
+		return &pb.ListPersonalAccessTokensResponse{
+			Error: err.Error(),
+		}, nil
+	}
+
+	pats, err := s.auth.ListPersonalAccessTokens(claims.UserID)
+	if err != nil {
+		return &pb.ListPersonalAccessTokensResponse{
+			Error: err.Error(),
+		}, nil
+	}
+
+	pbPats := make([]*pb.PersonalAccessToken, len(pats))
+	for i, pat := range pats {
+		pbPats[i] = personalAccessTokenToProto(pat)
+	}
+
+	return &pb.ListPersonalAccessTokensResponse{
+		PersonalAccessTokens: pbPats,
+	}, nil
+}
+
+func (s *AuthServer) RevokePersonalAccessToken(ctx context.Context, req *pb.RevokePersonalAccessTokenRequest) (*pb.RevokePersonalAccessTokenResponse, error) {
+	claims, err := s.auth.Validate(req.Token)
+	if err != nil {
+		return &pb.RevokePersonalAccessTokenResponse{
+			Success: false,
+			Error:   err.Error(),
+		}, nil
+	}
+
+	if err := s.auth.RevokePersonalAccessToken(claims, req.Id); err != nil {
+		return &pb.RevokePersonalAccessTokenResponse{
+			Success: false,
+			Error:   err.Error(),
+		}, nil
+	}
+
+	return &pb.RevokePersonalAccessTokenResponse{
+		Success: true,
+	}, nil
+}
+
+func personalAccessTokenToProto(pat *models.PersonalAccessToken) *pb.PersonalAccessToken {
+	pbPat := &pb.PersonalAccessToken{
+		Id:        pat.ID,
+		Name:      pat.Name,
+		Scopes:    pat.Scopes,
+		CreatedAt: timestamppb.New(pat.CreatedAt),
+	}
+	if pat.ExpiresAt != nil {
+		pbPat.ExpiresAt = timestamppb.New(*pat.ExpiresAt)
+	}
+	if pat.LastUsedAt != nil {
+		pbPat.LastUsedAt = timestamppb.New(*pat.LastUsedAt)
+	}
+	return pbPat
+}
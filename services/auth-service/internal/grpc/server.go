@@ -2,65 +2,52 @@ package grpc
 
 import (
 	"context"
-	"time"
+	"encoding/json"
 
-	"github.com/google/uuid"
+	"github.com/todo/pkg/auth"
 	pb "github.com/todo/proto/auth"
-	"github.com/todo/services/auth-service/internal/jwt"
-	"github.com/todo/services/auth-service/internal/repository"
+	"github.com/todo/services/auth-service/internal/service"
 	"google.golang.org/protobuf/types/known/timestamppb"
 )
 
 type AuthServer struct {
 	pb.UnimplementedAuthServiceServer
-	repo       *repository.PostgresRepository
-	jwtManager *jwt.JWTManager
+	auth *service.AuthService
 }
 
-func NewAuthServer(repo *repository.PostgresRepository, jwtManager *jwt.JWTManager) *AuthServer {
+func NewAuthServer(auth *service.AuthService) *AuthServer {
 	return &AuthServer{
-		repo:       repo,
-		jwtManager: jwtManager,
+		auth: auth,
 	}
 }
 
 func (s *AuthServer) Login(ctx context.Context, req *pb.LoginRequest) (*pb.LoginResponse, error) {
-	// Validate credentials
-	userID, username, err := s.repo.ValidateCredentials(req.Username, req.Password)
+	tokens, challenge, err := s.auth.Login(req.Username, req.Password, clientInfo(ctx, req.UserAgent, req.IpAddress))
 	if err != nil {
+		code, retryAfter := loginErrorCode(err)
 		return &pb.LoginResponse{
-			Error: err.Error(),
+			Error:             err.Error(),
+			ErrorCode:         code,
+			RetryAfterSeconds: retryAfter,
 		}, nil
 	}
-
-	// Generate access token
-	accessToken, expiresAt, err := s.jwtManager.Generate(userID, username)
-	if err != nil {
-		return &pb.LoginResponse{
-			Error: err.Error(),
-		}, nil
-	}
-
-	// Generate refresh token
-	refreshToken := uuid.New().String()
-	refreshExpiresAt := time.Now().Add(7 * 24 * time.Hour) // 7 days
-
-	// Store refresh token
-	if err := s.repo.StoreRefreshToken(userID, refreshToken, refreshExpiresAt); err != nil {
+	if challenge != nil {
 		return &pb.LoginResponse{
-			Error: err.Error(),
+			MfaRequired: true,
+			MfaToken:    challenge.Token,
+			ExpiresAt:   timestamppb.New(challenge.ExpiresAt),
 		}, nil
 	}
 
 	return &pb.LoginResponse{
-		AccessToken:  accessToken,
-		RefreshToken: refreshToken,
-		ExpiresAt:    timestamppb.New(expiresAt),
+		AccessToken:  tokens.AccessToken,
+		RefreshToken: tokens.RefreshToken,
+		ExpiresAt:    timestamppb.New(tokens.ExpiresAt),
 	}, nil
 }
 
 func (s *AuthServer) ValidateToken(ctx context.Context, req *pb.ValidateTokenRequest) (*pb.ValidateTokenResponse, error) {
-	claims, err := s.jwtManager.Validate(req.Token)
+	claims, err := s.auth.Validate(req.Token)
 	if err != nil {
 		return &pb.ValidateTokenResponse{
 			Valid: false,
@@ -69,66 +56,132 @@ func (s *AuthServer) ValidateToken(ctx context.Context, req *pb.ValidateTokenReq
 	}
 
 	return &pb.ValidateTokenResponse{
-		Valid:    true,
-		UserId:   claims.UserID,
-		Username: claims.Username,
+		Valid:               true,
+		UserId:              claims.UserID,
+		Username:            claims.Username,
+		Roles:               claims.Roles,
+		Scopes:              claims.Scopes,
+		PersonalAccessToken: service.IsPersonalAccessToken(claims),
 	}, nil
 }
 
 func (s *AuthServer) RefreshToken(ctx context.Context, req *pb.RefreshTokenRequest) (*pb.RefreshTokenResponse, error) {
-	// Validate refresh token
-	userID, err := s.repo.GetRefreshToken(req.RefreshToken)
+	tokens, err := s.auth.Refresh(req.RefreshToken, clientInfo(ctx, req.UserAgent, req.IpAddress))
 	if err != nil {
 		return &pb.RefreshTokenResponse{
 			Error: err.Error(),
 		}, nil
 	}
 
-	// Get username from user service (simplified - in real app would call user service via gRPC)
-	username := userID // Placeholder
+	return &pb.RefreshTokenResponse{
+		AccessToken:  tokens.AccessToken,
+		RefreshToken: tokens.RefreshToken,
+		ExpiresAt:    timestamppb.New(tokens.ExpiresAt),
+	}, nil
+}
 
-	// Generate new access token
-	accessToken, expiresAt, err := s.jwtManager.Generate(userID, username)
+func (s *AuthServer) Logout(ctx context.Context, req *pb.LogoutRequest) (*pb.LogoutResponse, error) {
+	// Validate and get claims from token
+	claims, err := s.auth.Validate(req.Token)
 	if err != nil {
-		return &pb.RefreshTokenResponse{
+		return &pb.LogoutResponse{
+			Success: false,
+			Error:   err.Error(),
+		}, nil
+	}
+
+	if err := s.auth.Logout(claims); err != nil {
+		return &pb.LogoutResponse{
+			Success: false,
+			Error:   err.Error(),
+		}, nil
+	}
+
+	return &pb.LogoutResponse{
+		Success: true,
+	}, nil
+}
+
+func (s *AuthServer) RevokeUserTokens(ctx context.Context, req *pb.RevokeUserTokensRequest) (*pb.RevokeUserTokensResponse, error) {
+	if err := auth.RequireService(ctx); err != nil {
+		return &pb.RevokeUserTokensResponse{
+			Error: err.Error(),
+		}, nil
+	}
+
+	revoked, err := s.auth.RevokeUserTokens(req.UserId, req.Username)
+	if err != nil {
+		return &pb.RevokeUserTokensResponse{
 			Error: err.Error(),
 		}, nil
 	}
 
-	// Generate new refresh token
-	newRefreshToken := uuid.New().String()
-	refreshExpiresAt := time.Now().Add(7 * 24 * time.Hour)
+	return &pb.RevokeUserTokensResponse{
+		Revoked: int32(revoked),
+	}, nil
+}
 
-	// Delete old refresh token
-	s.repo.DeleteRefreshToken(req.RefreshToken)
+func (s *AuthServer) ListRevokedTokens(ctx context.Context, req *pb.ListRevokedTokensRequest) (*pb.ListRevokedTokensResponse, error) {
+	if err := auth.RequireService(ctx); err != nil {
+		return &pb.ListRevokedTokensResponse{
+			Error: err.Error(),
+		}, nil
+	}
 
-	// Store new refresh token
-	if err := s.repo.StoreRefreshToken(userID, newRefreshToken, refreshExpiresAt); err != nil {
-		return &pb.RefreshTokenResponse{
+	tokens, err := s.auth.ListRevokedTokens()
+	if err != nil {
+		return &pb.ListRevokedTokensResponse{
 			Error: err.Error(),
 		}, nil
 	}
 
-	return &pb.RefreshTokenResponse{
-		AccessToken:  accessToken,
-		RefreshToken: newRefreshToken,
-		ExpiresAt:    timestamppb.New(expiresAt),
+	pbTokens := make([]*pb.RevokedToken, len(tokens))
+	for i, token := range tokens {
+		pbTokens[i] = &pb.RevokedToken{
+			Jti:       token.JTI,
+			ExpiresAt: timestamppb.New(token.ExpiresAt),
+		}
+	}
+
+	return &pb.ListRevokedTokensResponse{
+		Tokens: pbTokens,
 	}, nil
 }
 
-func (s *AuthServer) Logout(ctx context.Context, req *pb.LogoutRequest) (*pb.LogoutResponse, error) {
-	// Validate and get claims from token
-	_, err := s.jwtManager.Validate(req.Token)
+// ExportUserData returns the user's sessions and personal access tokens as a
+// JSON document for data access requests. Token values are never included.
+func (s *AuthServer) ExportUserData(ctx context.Context, req *pb.ExportUserDataRequest) (*pb.ExportUserDataResponse, error) {
+	if err := auth.RequireService(ctx); err != nil {
+		return &pb.ExportUserDataResponse{
+			Error: err.Error(),
+		}, nil
+	}
+
+	sessions, err := s.auth.ListSessions(req.UserId, "")
 	if err != nil {
-		return &pb.LogoutResponse{
-			Success: false,
-			Error:   err.Error(),
+		return &pb.ExportUserDataResponse{
+			Error: err.Error(),
 		}, nil
 	}
 
-	// In a real implementation, you would add the token to a blacklist
-	// For now, we just return success
-	return &pb.LogoutResponse{
-		Success: true,
+	pats, err := s.auth.ListPersonalAccessTokens(req.UserId)
+	if err != nil {
+		return &pb.ExportUserDataResponse{
+			Error: err.Error(),
+		}, nil
+	}
+
+	data, err := json.Marshal(map[string]interface{}{
+		"sessions":               sessions,
+		"personal_access_tokens": pats,
+	})
+	if err != nil {
+		return &pb.ExportUserDataResponse{
+			Error: err.Error(),
+		}, nil
+	}
+
+	return &pb.ExportUserDataResponse{
+		Data: data,
 	}, nil
 }
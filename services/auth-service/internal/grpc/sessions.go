@@ -0,0 +1,122 @@
+package grpc
+
+import (
+	"context"
+	"net"
+
+	"github.com/todo/pkg/auth"
+	pb "github.com/todo/proto/auth"
+	"github.com/todo/services/auth-service/internal/models"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func (s *AuthServer) ListSessions(ctx context.Context, req *pb.ListSessionsRequest) (*pb.ListSessionsResponse, error) {
+	claims, err := s.auth.Validate(req.Token)
+	if err != nil {
+		return &pb.ListSessionsResponse{
+			Error: err.Error(),
+		}, nil
+	}
+
+	sessions, err := s.auth.ListSessions(claims.UserID, claims.SessionID)
+	if err != nil {
+		return &pb.ListSessionsResponse{
+			Error: err.Error(),
+		}, nil
+	}
+
+	pbSessions := make([]*pb.Session, len(sessions))
+	for i, session := range sessions {
+		pbSessions[i] = sessionToProto(session)
+	}
+
+	return &pb.ListSessionsResponse{
+		Sessions: pbSessions,
+	}, nil
+}
+
+func (s *AuthServer) RevokeSession(ctx context.Context, req *pb.RevokeSessionRequest) (*pb.RevokeSessionResponse, error) {
+	claims, err := s.auth.Validate(req.Token)
+	if err != nil {
+		return &pb.RevokeSessionResponse{
+			Success: false,
+			Error:   err.Error(),
+		}, nil
+	}
+
+	if err := s.auth.RevokeSession(claims, req.SessionId); err != nil {
+		return &pb.RevokeSessionResponse{
+			Success: false,
+			Error:   err.Error(),
+		}, nil
+	}
+
+	return &pb.RevokeSessionResponse{
+		Success: true,
+	}, nil
+}
+
+func (s *AuthServer) RevokeAllOtherSessions(ctx context.Context, req *pb.RevokeAllOtherSessionsRequest) (*pb.RevokeAllOtherSessionsResponse, error) {
+	claims, err := s.auth.Validate(req.Token)
+	if err != nil {
+		return &pb.RevokeAllOtherSessionsResponse{
+			Error: err.Error(),
+		}, nil
+	}
+
+	revoked, err := s.auth.RevokeAllOtherSessions(claims)
+	if err != nil {
+		return &pb.RevokeAllOtherSessionsResponse{
+			Error: err.Error(),
+		}, nil
+	}
+
+	return &pb.RevokeAllOtherSessionsResponse{
+		Revoked: int32(revoked),
+	}, nil
+}
+
+// clientInfo describes the client of a call. Other services that proxy for
+// an end user, such as a gateway, may pass its details explicitly; from
+// anyone else they are ignored and taken from the call's metadata and peer
+// address.
+func clientInfo(ctx context.Context, userAgent, ipAddress string) models.ClientInfo {
+	if identity, ok := auth.FromContext(ctx); !ok || !identity.Service {
+		userAgent, ipAddress = "", ""
+	}
+
+	if userAgent == "" {
+		if md, ok := metadata.FromIncomingContext(ctx); ok {
+			if values := md.Get("user-agent"); len(values) > 0 {
+				userAgent = values[0]
+			}
+		}
+	}
+
+	if ipAddress == "" {
+		if p, ok := peer.FromContext(ctx); ok {
+			ipAddress = p.Addr.String()
+			if host, _, err := net.SplitHostPort(ipAddress); err == nil {
+				ipAddress = host
+			}
+		}
+	}
+
+	return models.ClientInfo{
+		UserAgent: userAgent,
+		IPAddress: ipAddress,
+	}
+}
+
+func sessionToProto(session *models.Session) *pb.Session {
+	return &pb.Session{
+		Id:         session.ID,
+		UserAgent:  session.UserAgent,
+		IpAddress:  session.IPAddress,
+		CreatedAt:  timestamppb.New(session.CreatedAt),
+		LastUsedAt: timestamppb.New(session.LastUsedAt),
+		Current:    session.Current,
+	}
+}
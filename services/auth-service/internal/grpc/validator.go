@@ -0,0 +1,35 @@
+package grpc
+
+import (
+	"context"
+
+	"github.com/todo/pkg/auth"
+	"github.com/todo/services/auth-service/internal/service"
+)
+
+// Validator validates the bearer tokens of gRPC calls with the auth service
+// itself, rather than calling back into it like the other services do.
+type Validator struct {
+	auth *service.AuthService
+}
+
+func NewValidator(auth *service.AuthService) *Validator {
+	return &Validator{
+		auth: auth,
+	}
+}
+
+func (v *Validator) Validate(ctx context.Context, token string) (*auth.Identity, error) {
+	claims, err := v.auth.Validate(token)
+	if err != nil {
+		return nil, err
+	}
+
+	return &auth.Identity{
+		UserID:              claims.UserID,
+		Username:            claims.Username,
+		Roles:               claims.Roles,
+		Scopes:              claims.Scopes,
+		PersonalAccessToken: service.IsPersonalAccessToken(claims),
+	}, nil
+}
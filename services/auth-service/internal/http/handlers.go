@@ -2,22 +2,26 @@ package http
 
 import (
 	"encoding/json"
+	"fmt"
+	"net"
 	"net/http"
+	"strings"
 
 	"github.com/gorilla/mux"
 	"github.com/todo/services/auth-service/internal/jwt"
-	"github.com/todo/services/auth-service/internal/repository"
+	"github.com/todo/services/auth-service/internal/models"
+	"github.com/todo/services/auth-service/internal/service"
 )
 
 type Handler struct {
-	repo       *repository.PostgresRepository
-	jwtManager *jwt.JWTManager
+	auth           *service.AuthService
+	trustedProxies []*net.IPNet
 }
 
-func NewHandler(repo *repository.PostgresRepository, jwtManager *jwt.JWTManager) *Handler {
+func NewHandler(auth *service.AuthService, trustedProxies []*net.IPNet) *Handler {
 	return &Handler{
-		repo:       repo,
-		jwtManager: jwtManager,
+		auth:           auth,
+		trustedProxies: trustedProxies,
 	}
 }
 
@@ -32,14 +36,21 @@ type LoginResponse struct {
 	ExpiresAt    string `json:"expires_at"`
 }
 
+type RefreshRequest struct {
+	RefreshToken string `json:"refresh_token"`
+}
+
 type ValidateRequest struct {
 	Token string `json:"token"`
 }
 
 type ValidateResponse struct {
-	Valid    bool   `json:"valid"`
-	UserID   string `json:"user_id,omitempty"`
-	Username string `json:"username,omitempty"`
+	Valid               bool     `json:"valid"`
+	UserID              string   `json:"user_id,omitempty"`
+	Username            string   `json:"username,omitempty"`
+	Roles               []string `json:"roles,omitempty"`
+	Scopes              []string `json:"scopes,omitempty"`
+	PersonalAccessToken bool     `json:"personal_access_token,omitempty"`
 }
 
 func (h *Handler) Login(w http.ResponseWriter, r *http.Request) {
@@ -49,27 +60,33 @@ func (h *Handler) Login(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Validate credentials
-	userID, username, err := h.repo.ValidateCredentials(req.Username, req.Password)
+	tokens, challenge, err := h.auth.Login(req.Username, req.Password, h.clientInfo(r))
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusUnauthorized)
+		writeLoginError(w, err)
+		return
+	}
+	if challenge != nil {
+		writeChallenge(w, challenge)
 		return
 	}
 
-	// Generate tokens
-	accessToken, expiresAt, err := h.jwtManager.Generate(userID, username)
-	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+	writeTokens(w, tokens)
+}
+
+func (h *Handler) Refresh(w http.ResponseWriter, r *http.Request) {
+	var req RefreshRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
 
-	response := LoginResponse{
-		AccessToken: accessToken,
-		ExpiresAt:   expiresAt.Format("2006-01-02T15:04:05Z"),
+	tokens, err := h.auth.Refresh(req.RefreshToken, h.clientInfo(r))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+		return
 	}
 
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(response)
+	writeTokens(w, tokens)
 }
 
 func (h *Handler) Validate(w http.ResponseWriter, r *http.Request) {
@@ -79,7 +96,7 @@ func (h *Handler) Validate(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	claims, err := h.jwtManager.Validate(req.Token)
+	claims, err := h.auth.Validate(req.Token)
 	if err != nil {
 		response := ValidateResponse{Valid: false}
 		w.Header().Set("Content-Type", "application/json")
@@ -88,9 +105,58 @@ func (h *Handler) Validate(w http.ResponseWriter, r *http.Request) {
 	}
 
 	response := ValidateResponse{
-		Valid:    true,
-		UserID:   claims.UserID,
-		Username: claims.Username,
+		Valid:               true,
+		UserID:              claims.UserID,
+		Username:            claims.Username,
+		Roles:               claims.Roles,
+		Scopes:              claims.Scopes,
+		PersonalAccessToken: service.IsPersonalAccessToken(claims),
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+// Logout revokes the bearer token of the request and its refresh token.
+func (h *Handler) Logout(w http.ResponseWriter, r *http.Request) {
+	claims, err := h.authenticate(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+		return
+	}
+
+	if err := h.auth.Logout(claims); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// authenticate validates the bearer token in the Authorization header.
+func (h *Handler) authenticate(r *http.Request) (*jwt.Claims, error) {
+	header := r.Header.Get("Authorization")
+	token, ok := strings.CutPrefix(header, "Bearer ")
+	if !ok || token == "" {
+		return nil, fmt.Errorf("missing bearer token")
+	}
+
+	return h.auth.Validate(token)
+}
+
+// JWKS serves the public keys tokens are signed with. Other services cache
+// the set and refetch it when they see an unknown key ID.
+func (h *Handler) JWKS(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "public, max-age=300")
+	json.NewEncoder(w).Encode(h.auth.JWKS())
+}
+
+func writeTokens(w http.ResponseWriter, tokens *models.TokenPair) {
+	response := LoginResponse{
+		AccessToken:  tokens.AccessToken,
+		RefreshToken: tokens.RefreshToken,
+		ExpiresAt:    tokens.ExpiresAt.UTC().Format("2006-01-02T15:04:05Z"),
 	}
 
 	w.Header().Set("Content-Type", "application/json")
@@ -98,6 +164,31 @@ func (h *Handler) Validate(w http.ResponseWriter, r *http.Request) {
 }
 
 func (h *Handler) RegisterRoutes(router *mux.Router) {
+	router.HandleFunc("/.well-known/jwks.json", h.JWKS).Methods("GET")
 	router.HandleFunc("/api/auth/login", h.Login).Methods("POST")
+	router.HandleFunc("/api/auth/refresh", h.Refresh).Methods("POST")
 	router.HandleFunc("/api/auth/validate", h.Validate).Methods("POST")
+	router.HandleFunc("/api/auth/login/mfa", h.VerifyMFA).Methods("POST")
+	router.HandleFunc("/api/auth/logout", h.Logout).Methods("POST")
+
+	// Sessions
+	router.HandleFunc("/api/auth/sessions", h.ListSessions).Methods("GET")
+	router.HandleFunc("/api/auth/sessions/revoke-others", h.RevokeAllOtherSessions).Methods("POST")
+	router.HandleFunc("/api/auth/sessions/{id}", h.RevokeSession).Methods("DELETE")
+
+	// Personal access tokens
+	router.HandleFunc("/api/auth/tokens", h.CreatePersonalAccessToken).Methods("POST")
+	router.HandleFunc("/api/auth/tokens", h.ListPersonalAccessTokens).Methods("GET")
+	router.HandleFunc("/api/auth/tokens/{id}", h.RevokePersonalAccessToken).Methods("DELETE")
+
+	// Two-factor authentication
+	router.HandleFunc("/api/auth/mfa", h.MFAStatus).Methods("GET")
+	router.HandleFunc("/api/auth/mfa/totp", h.EnrollTOTP).Methods("POST")
+	router.HandleFunc("/api/auth/mfa/totp/confirm", h.ConfirmTOTP).Methods("POST")
+	router.HandleFunc("/api/auth/mfa/totp/disable", h.DisableTOTP).Methods("POST")
+	router.HandleFunc("/api/auth/mfa/recovery-codes", h.RegenerateRecoveryCodes).Methods("POST")
+
+	// Login lockouts
+	router.HandleFunc("/api/auth/lockouts", h.ListLoginLockouts).Methods("GET")
+	router.HandleFunc("/api/auth/lockouts/unlock", h.UnlockLogin).Methods("POST")
 }
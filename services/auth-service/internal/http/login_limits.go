@@ -0,0 +1,87 @@
+package http
+
+import (
+	"encoding/json"
+	"errors"
+	"math"
+	"net/http"
+	"strconv"
+
+	"github.com/todo/pkg/auth"
+	"github.com/todo/services/auth-service/internal/service"
+)
+
+// LoginErrorResponse is the body of a refused login. Code tells clients why,
+// and RetryAfterSeconds, also sent as Retry-After, when to try again.
+type LoginErrorResponse struct {
+	Error             string `json:"error"`
+	Code              string `json:"code,omitempty"`
+	RetryAfterSeconds int    `json:"retry_after_seconds,omitempty"`
+}
+
+type UnlockLoginRequest struct {
+	Username  string `json:"username"`
+	IPAddress string `json:"ip_address"`
+}
+
+func (h *Handler) ListLoginLockouts(w http.ResponseWriter, r *http.Request) {
+	claims, err := h.authenticate(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+		return
+	}
+
+	lockouts, err := h.auth.ListLoginLockouts(claims)
+	if err != nil {
+		auth.HTTPError(w, err, http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"lockouts": lockouts,
+	})
+}
+
+// UnlockLogin lifts the lockout of a username or an IP address.
+func (h *Handler) UnlockLogin(w http.ResponseWriter, r *http.Request) {
+	claims, err := h.authenticate(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+		return
+	}
+
+	var req UnlockLoginRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := h.auth.UnlockLogin(claims, req.Username, req.IPAddress); err != nil {
+		auth.HTTPError(w, err, http.StatusBadRequest)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// writeLoginError writes a refused login as JSON: 429 with Retry-After while
+// attempts are being throttled or locked out, and 401 otherwise.
+func writeLoginError(w http.ResponseWriter, err error) {
+	status := http.StatusUnauthorized
+	response := LoginErrorResponse{Error: err.Error()}
+
+	var loginErr *service.LoginError
+	if errors.As(err, &loginErr) {
+		response.Code = loginErr.Code
+		if loginErr.RetryAfter > 0 {
+			response.RetryAfterSeconds = int(math.Ceil(loginErr.RetryAfter.Seconds()))
+			w.Header().Set("Retry-After", strconv.Itoa(response.RetryAfterSeconds))
+			status = http.StatusTooManyRequests
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(response)
+}
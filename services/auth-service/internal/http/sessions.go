@@ -0,0 +1,141 @@
+package http
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+
+	"github.com/gorilla/mux"
+	"github.com/todo/services/auth-service/internal/models"
+)
+
+// ListSessions lists the sessions of the user the bearer token belongs to.
+func (h *Handler) ListSessions(w http.ResponseWriter, r *http.Request) {
+	claims, err := h.authenticate(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+		return
+	}
+
+	sessions, err := h.auth.ListSessions(claims.UserID, claims.SessionID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"sessions": sessions,
+	})
+}
+
+func (h *Handler) RevokeSession(w http.ResponseWriter, r *http.Request) {
+	claims, err := h.authenticate(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+		return
+	}
+
+	vars := mux.Vars(r)
+	if err := h.auth.RevokeSession(claims, vars["id"]); err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *Handler) RevokeAllOtherSessions(w http.ResponseWriter, r *http.Request) {
+	claims, err := h.authenticate(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+		return
+	}
+
+	revoked, err := h.auth.RevokeAllOtherSessions(claims)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"revoked": revoked,
+	})
+}
+
+// clientInfo describes the client of a request. Behind trusted proxies the
+// client's address is the last X-Forwarded-For address that is not itself a
+// trusted proxy; X-Forwarded-For from anyone else is ignored, since clients
+// can set it to anything.
+func (h *Handler) clientInfo(r *http.Request) models.ClientInfo {
+	ipAddress := r.RemoteAddr
+	if host, _, err := net.SplitHostPort(ipAddress); err == nil {
+		ipAddress = host
+	}
+
+	if h.trustedProxy(ipAddress) {
+		forwarded := strings.Split(strings.Join(r.Header.Values("X-Forwarded-For"), ","), ",")
+		for i := len(forwarded) - 1; i >= 0; i-- {
+			address := strings.TrimSpace(forwarded[i])
+			if net.ParseIP(address) == nil {
+				break
+			}
+			ipAddress = address
+			if !h.trustedProxy(address) {
+				break
+			}
+		}
+	}
+
+	return models.ClientInfo{
+		UserAgent: r.UserAgent(),
+		IPAddress: ipAddress,
+	}
+}
+
+func (h *Handler) trustedProxy(address string) bool {
+	ip := net.ParseIP(address)
+	if ip == nil {
+		return false
+	}
+	for _, network := range h.trustedProxies {
+		if network.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// ParseTrustedProxies parses a comma-separated list of the CIDR ranges, or
+// single addresses, of the proxies whose X-Forwarded-For is trusted.
+func ParseTrustedProxies(value string) ([]*net.IPNet, error) {
+	var networks []*net.IPNet
+	for _, entry := range strings.Split(value, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		if !strings.Contains(entry, "/") {
+			ip := net.ParseIP(entry)
+			if ip == nil {
+				return nil, fmt.Errorf("invalid trusted proxy: %s", entry)
+			}
+			bits := 8 * net.IPv4len
+			if ip.To4() == nil {
+				bits = 8 * net.IPv6len
+			}
+			entry = fmt.Sprintf("%s/%d", entry, bits)
+		}
+
+		_, network, err := net.ParseCIDR(entry)
+		if err != nil {
+			return nil, fmt.Errorf("invalid trusted proxy: %s", entry)
+		}
+		networks = append(networks, network)
+	}
+	return networks, nil
+}
@@ -1,37 +1,85 @@
 package jwt
 
 import (
+	"errors"
 	"fmt"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
 	"github.com/google/uuid"
+	"github.com/todo/pkg/auth"
 )
 
+// ErrTokenRevoked is returned by Validate for a token that was logged out
+// or otherwise revoked before it expired.
+var ErrTokenRevoked = errors.New("token has been revoked")
+
+// RevocationChecker reports whether the token with the given JWT ID has been
+// revoked.
+type RevocationChecker interface {
+	IsTokenRevoked(jti string) (bool, error)
+}
+
+// JWTManager signs tokens with an asymmetric key set when it has one, and
+// with the HS256 shared secret otherwise.
 type JWTManager struct {
 	secretKey     string
+	keys          *KeySet
 	tokenDuration time.Duration
+	revocations   RevocationChecker
 }
 
-type Claims struct {
-	UserID   string `json:"user_id"`
-	Username string `json:"username"`
-	jwt.RegisteredClaims
+// Claims are shared with the services that verify tokens locally.
+type Claims = auth.Claims
+
+// NewClaims builds claims for a credential that is not a JWT, such as a
+// personal access token, so that it can be handled like one.
+func NewClaims(id, userID, username string, scopes []string, issuedAt time.Time, expiresAt *time.Time) *Claims {
+	claims := &Claims{
+		UserID:   userID,
+		Username: username,
+		Scopes:   scopes,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:       id,
+			IssuedAt: jwt.NewNumericDate(issuedAt),
+		},
+	}
+	if expiresAt != nil {
+		claims.ExpiresAt = jwt.NewNumericDate(*expiresAt)
+	}
+	return claims
 }
 
-func NewJWTManager(secretKey string, tokenDuration time.Duration) *JWTManager {
+func NewJWTManager(secretKey string, tokenDuration time.Duration, revocations RevocationChecker) *JWTManager {
 	return &JWTManager{
 		secretKey:     secretKey,
 		tokenDuration: tokenDuration,
+		revocations:   revocations,
+	}
+}
+
+// NewKeySetJWTManager returns a manager that signs with the current key of
+// the key set and names it in the kid header.
+func NewKeySetJWTManager(keys *KeySet, tokenDuration time.Duration, revocations RevocationChecker) *JWTManager {
+	return &JWTManager{
+		keys:          keys,
+		tokenDuration: tokenDuration,
+		revocations:   revocations,
 	}
 }
 
-func (m *JWTManager) Generate(userID, username string) (string, time.Time, error) {
+// Generate signs an access token for the user's session carrying the user's
+// roles and the scopes they grant, and returns it together with its claims,
+// whose ID identifies the token for revocation.
+func (m *JWTManager) Generate(userID, username, sessionID string, roles, scopes []string) (string, *Claims, error) {
 	expiresAt := time.Now().Add(m.tokenDuration)
 
-	claims := Claims{
-		UserID:   userID,
-		Username: username,
+	claims := &Claims{
+		UserID:    userID,
+		Username:  username,
+		SessionID: sessionID,
+		Roles:     roles,
+		Scopes:    scopes,
 		RegisteredClaims: jwt.RegisteredClaims{
 			ExpiresAt: jwt.NewNumericDate(expiresAt),
 			IssuedAt:  jwt.NewNumericDate(time.Now()),
@@ -39,17 +87,43 @@ func (m *JWTManager) Generate(userID, username string) (string, time.Time, error
 		},
 	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	tokenString, err := token.SignedString([]byte(m.secretKey))
+	var token *jwt.Token
+	var key interface{}
+	if m.keys != nil {
+		signingKey, err := m.keys.signingKey()
+		if err != nil {
+			return "", nil, err
+		}
+		token = jwt.NewWithClaims(signingKey.method, claims)
+		token.Header["kid"] = signingKey.id
+		key = signingKey.privateKey
+	} else {
+		token = jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+		key = []byte(m.secretKey)
+	}
+
+	tokenString, err := token.SignedString(key)
 	if err != nil {
-		return "", time.Time{}, err
+		return "", nil, err
 	}
 
-	return tokenString, expiresAt, nil
+	return tokenString, claims, nil
 }
 
 func (m *JWTManager) Validate(tokenString string) (*Claims, error) {
 	token, err := jwt.ParseWithClaims(tokenString, &Claims{}, func(token *jwt.Token) (interface{}, error) {
+		if m.keys != nil {
+			kid, _ := token.Header["kid"].(string)
+			key, err := m.keys.verificationKey(kid)
+			if err != nil {
+				return nil, err
+			}
+			if token.Method.Alg() != key.algorithm {
+				return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+			}
+			return key.privateKey.Public(), nil
+		}
+
 		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
 			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
 		}
@@ -65,5 +139,24 @@ func (m *JWTManager) Validate(tokenString string) (*Claims, error) {
 		return nil, fmt.Errorf("invalid token")
 	}
 
+	if m.revocations != nil {
+		revoked, err := m.revocations.IsTokenRevoked(claims.ID)
+		if err != nil {
+			return nil, err
+		}
+		if revoked {
+			return nil, ErrTokenRevoked
+		}
+	}
+
 	return claims, nil
 }
+
+// JWKS returns the public keys tokens can be verified with. It is empty when
+// tokens are signed with the shared secret.
+func (m *JWTManager) JWKS() auth.JWKS {
+	if m.keys == nil {
+		return auth.JWKS{Keys: []auth.JWK{}}
+	}
+	return m.keys.JWKS()
+}
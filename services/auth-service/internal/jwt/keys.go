@@ -0,0 +1,265 @@
+package jwt
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+	"github.com/todo/pkg/auth"
+	"github.com/todo/services/auth-service/internal/models"
+)
+
+// reloadInterval limits how often an unknown kid makes the key set reload
+// from the store.
+const reloadInterval = 30 * time.Second
+
+// KeyStore persists the signing key set so that every replica signs and
+// verifies with the same keys.
+type KeyStore interface {
+	ListSigningKeys(now time.Time) ([]*models.SigningKey, error)
+	RotateSigningKey(key *models.SigningKey, rotatedBefore, retireAt time.Time) (bool, error)
+	DeleteExpiredSigningKeys(now time.Time) (int, error)
+}
+
+type signingKey struct {
+	id         string
+	algorithm  string
+	method     jwt.SigningMethod
+	privateKey crypto.Signer
+	createdAt  time.Time
+}
+
+// KeySet holds the asymmetric keys tokens are signed with. The newest key
+// signs; keys that were rotated out stay in the set, and in the JWKS, for
+// the overlap period so tokens they signed keep verifying until they expire.
+type KeySet struct {
+	store       KeyStore
+	algorithm   string
+	rotateEvery time.Duration
+	overlap     time.Duration
+
+	mu       sync.RWMutex
+	keys     map[string]*signingKey
+	current  *signingKey
+	loadedAt time.Time
+}
+
+// NewKeySet loads the key set from the store, generating a first key if
+// there is none or if the current key uses a different algorithm.
+func NewKeySet(store KeyStore, algorithm string, rotateEvery, overlap time.Duration) (*KeySet, error) {
+	if _, err := auth.SigningMethod(algorithm); err != nil {
+		return nil, err
+	}
+
+	s := &KeySet{
+		store:       store,
+		algorithm:   algorithm,
+		rotateEvery: rotateEvery,
+		overlap:     overlap,
+		keys:        make(map[string]*signingKey),
+	}
+
+	if err := s.Reload(); err != nil {
+		return nil, err
+	}
+	if err := s.RotateIfDue(time.Now()); err != nil {
+		return nil, err
+	}
+
+	return s, nil
+}
+
+// Reload replaces the in-memory key set with the unexpired keys in the store.
+func (s *KeySet) Reload() error {
+	stored, err := s.store.ListSigningKeys(time.Now())
+	if err != nil {
+		return err
+	}
+
+	keys := make(map[string]*signingKey, len(stored))
+	var current *signingKey
+	for _, k := range stored {
+		key, err := parseSigningKey(k)
+		if err != nil {
+			log.Printf("Skipping signing key %s: %v", k.ID, err)
+			continue
+		}
+		keys[key.id] = key
+		if k.ExpiresAt == nil && (current == nil || key.createdAt.After(current.createdAt)) {
+			current = key
+		}
+	}
+
+	s.mu.Lock()
+	s.keys = keys
+	s.current = current
+	s.loadedAt = time.Now()
+	s.mu.Unlock()
+
+	return nil
+}
+
+// RotateIfDue installs a new signing key once the current one is older than
+// the rotation period or uses a different algorithm than configured.
+func (s *KeySet) RotateIfDue(now time.Time) error {
+	s.mu.RLock()
+	current := s.current
+	s.mu.RUnlock()
+
+	if current != nil && current.algorithm == s.algorithm && now.Sub(current.createdAt) < s.rotateEvery {
+		return nil
+	}
+
+	privateKey, err := generateKey(s.algorithm)
+	if err != nil {
+		return err
+	}
+
+	der, err := x509.MarshalPKCS8PrivateKey(privateKey)
+	if err != nil {
+		return err
+	}
+
+	key := &models.SigningKey{
+		ID:         uuid.New().String(),
+		Algorithm:  s.algorithm,
+		PrivateKey: der,
+		CreatedAt:  now,
+	}
+
+	rotated, err := s.store.RotateSigningKey(key, now.Add(-s.rotateEvery), now.Add(s.overlap))
+	if err != nil {
+		return err
+	}
+	if rotated {
+		log.Printf("Rotated JWT signing key, new kid %s (%s)", key.ID, key.Algorithm)
+	}
+
+	// Pick up the new key, or the one another replica installed first
+	return s.Reload()
+}
+
+// Run reloads the key set, rotates it when due and deletes expired keys on
+// every tick until the context is cancelled.
+func (s *KeySet) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			if err := s.Reload(); err != nil {
+				log.Printf("Failed to reload JWT signing keys: %v", err)
+				continue
+			}
+			if err := s.RotateIfDue(now); err != nil {
+				log.Printf("Failed to rotate JWT signing key: %v", err)
+			}
+			if _, err := s.store.DeleteExpiredSigningKeys(now); err != nil {
+				log.Printf("Failed to delete expired JWT signing keys: %v", err)
+			}
+		}
+	}
+}
+
+// JWKS returns the public half of every key in the set.
+func (s *KeySet) JWKS() auth.JWKS {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	set := auth.JWKS{Keys: []auth.JWK{}}
+	for _, key := range s.keys {
+		jwk, err := auth.NewJWK(key.id, key.algorithm, key.privateKey.Public())
+		if err != nil {
+			continue
+		}
+		set.Keys = append(set.Keys, jwk)
+	}
+
+	return set
+}
+
+func (s *KeySet) signingKey() (*signingKey, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if s.current == nil {
+		return nil, fmt.Errorf("no signing key available")
+	}
+	return s.current, nil
+}
+
+// verificationKey looks a key up by its ID, reloading the set when the ID is
+// unknown since another replica may have rotated.
+func (s *KeySet) verificationKey(kid string) (*signingKey, error) {
+	s.mu.RLock()
+	key, ok := s.keys[kid]
+	stale := time.Since(s.loadedAt) >= reloadInterval
+	s.mu.RUnlock()
+
+	if !ok && stale {
+		if err := s.Reload(); err != nil {
+			return nil, err
+		}
+		s.mu.RLock()
+		key, ok = s.keys[kid]
+		s.mu.RUnlock()
+	}
+
+	if !ok {
+		return nil, fmt.Errorf("unknown key ID: %s", kid)
+	}
+	return key, nil
+}
+
+func generateKey(algorithm string) (crypto.Signer, error) {
+	switch algorithm {
+	case auth.RS256:
+		return rsa.GenerateKey(rand.Reader, 2048)
+	case auth.ES256:
+		return ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	case auth.EdDSA:
+		_, privateKey, err := ed25519.GenerateKey(rand.Reader)
+		return privateKey, err
+	default:
+		return nil, fmt.Errorf("unsupported signing algorithm: %s", algorithm)
+	}
+}
+
+func parseSigningKey(k *models.SigningKey) (*signingKey, error) {
+	method, err := auth.SigningMethod(k.Algorithm)
+	if err != nil {
+		return nil, err
+	}
+
+	parsed, err := x509.ParsePKCS8PrivateKey(k.PrivateKey)
+	if err != nil {
+		return nil, err
+	}
+
+	privateKey, ok := parsed.(crypto.Signer)
+	if !ok {
+		return nil, fmt.Errorf("unsupported private key type %T", parsed)
+	}
+
+	return &signingKey{
+		id:         k.ID,
+		algorithm:  k.Algorithm,
+		method:     method,
+		privateKey: privateKey,
+		createdAt:  k.CreatedAt,
+	}, nil
+}
@@ -0,0 +1,20 @@
+package models
+
+import "time"
+
+// Login failures are counted separately for the username tried and for the
+// address the attempt came from.
+const (
+	LoginFailureUsername  = "username"
+	LoginFailureIPAddress = "ip_address"
+)
+
+// LoginFailures counts the recent failed logins for a username or an IP
+// address. Usernames are counted whether or not they exist.
+type LoginFailures struct {
+	Kind         string     `json:"kind"`
+	Value        string     `json:"value"`
+	Failures     int        `json:"failures"`
+	LastFailedAt time.Time  `json:"last_failed_at"`
+	LockedUntil  *time.Time `json:"locked_until,omitempty"`
+}
@@ -0,0 +1,34 @@
+package models
+
+import "time"
+
+// TOTPFactor is a user's authenticator app. It only guards logins once the
+// user has confirmed it with a code. LastUsedStep is the time step of the
+// last accepted code, so that no code is accepted twice.
+type TOTPFactor struct {
+	UserID       string
+	Secret       string
+	CreatedAt    time.Time
+	ConfirmedAt  *time.Time
+	LastUsedStep int64
+}
+
+// TOTPEnrollment is what an authenticator app needs to add a factor.
+type TOTPEnrollment struct {
+	Secret string `json:"secret"`
+	URI    string `json:"otpauth_uri"`
+}
+
+// MFAChallenge is returned instead of tokens when a login still needs a
+// second factor. Its token is exchanged, together with a code, for the
+// token pair.
+type MFAChallenge struct {
+	Token     string    `json:"mfa_token"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// MFAStatus describes the second factors a user has set up.
+type MFAStatus struct {
+	TOTPEnabled            bool `json:"totp_enabled"`
+	RecoveryCodesRemaining int  `json:"recovery_codes_remaining"`
+}
@@ -0,0 +1,16 @@
+package models
+
+import "time"
+
+// PersonalAccessToken is a long-lived token a user creates for scripts. Only
+// a hash of its secret is stored. A token without an expiry lives until it is
+// revoked.
+type PersonalAccessToken struct {
+	ID         string     `json:"id"`
+	UserID     string     `json:"user_id"`
+	Name       string     `json:"name"`
+	Scopes     []string   `json:"scopes"`
+	CreatedAt  time.Time  `json:"created_at"`
+	ExpiresAt  *time.Time `json:"expires_at,omitempty"`
+	LastUsedAt *time.Time `json:"last_used_at,omitempty"`
+}
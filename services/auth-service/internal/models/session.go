@@ -0,0 +1,21 @@
+package models
+
+import "time"
+
+// Session is a login on one device. It lives as long as its refresh token
+// family and shares the family's ID.
+type Session struct {
+	ID         string    `json:"id"`
+	UserID     string    `json:"user_id"`
+	UserAgent  string    `json:"user_agent"`
+	IPAddress  string    `json:"ip_address"`
+	CreatedAt  time.Time `json:"created_at"`
+	LastUsedAt time.Time `json:"last_used_at"`
+	Current    bool      `json:"current,omitempty"`
+}
+
+// ClientInfo describes the client a login or refresh came from.
+type ClientInfo struct {
+	UserAgent string
+	IPAddress string
+}
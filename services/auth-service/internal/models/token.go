@@ -12,3 +12,29 @@ type Claims struct {
 	UserID   string `json:"user_id"`
 	Username string `json:"username"`
 }
+
+// RefreshTokenInfo describes an issued refresh token without its secret value.
+type RefreshTokenInfo struct {
+	ID        int       `json:"id"`
+	UserID    string    `json:"user_id"`
+	FamilyID  string    `json:"family_id"`
+	ExpiresAt time.Time `json:"expires_at"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// RevokedToken is an access token revoked by its JWT ID before it expired.
+type RevokedToken struct {
+	JTI       string    `json:"jti"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// SigningKey is a key of the JWT signing key set. The private key is stored
+// PKCS #8 encoded. The current key has no expiry; once rotated out a key
+// keeps verifying tokens until it expires.
+type SigningKey struct {
+	ID         string
+	Algorithm  string
+	PrivateKey []byte
+	CreatedAt  time.Time
+	ExpiresAt  *time.Time
+}
@@ -0,0 +1,150 @@
+package repository
+
+import (
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/todo/services/auth-service/internal/models"
+)
+
+// ErrRefreshTokenReused is returned when a refresh token that has already
+// been rotated is presented again. Its whole family has been revoked.
+var ErrRefreshTokenReused = errors.New("refresh token reuse detected")
+
+// Every login starts a refresh token family. Refreshing marks the presented
+// token as rotated and issues its successor in the same family; rotated rows
+// are kept until they expire so that a replayed token can be recognised.
+// Only a SHA-256 hash of each token is stored. Tokens issued before families
+// existed are hashed in place and each becomes a family of its own.
+const familiesSchema = `
+	ALTER TABLE refresh_tokens ADD COLUMN IF NOT EXISTS token_hash VARCHAR(64);
+	ALTER TABLE refresh_tokens ADD COLUMN IF NOT EXISTS family_id VARCHAR(36);
+	ALTER TABLE refresh_tokens ADD COLUMN IF NOT EXISTS rotated_at TIMESTAMP;
+	ALTER TABLE refresh_tokens ALTER COLUMN token DROP NOT NULL;
+
+	UPDATE refresh_tokens
+	SET token_hash = encode(sha256(convert_to(token, 'UTF8')), 'hex'),
+		family_id = COALESCE(family_id, id::text),
+		token = NULL
+	WHERE token IS NOT NULL;
+
+	CREATE UNIQUE INDEX IF NOT EXISTS idx_refresh_tokens_token_hash ON refresh_tokens(token_hash);
+	CREATE INDEX IF NOT EXISTS idx_refresh_tokens_family_id ON refresh_tokens(family_id);
+`
+
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// ConsumeRefreshToken rotates a refresh token out and returns it, so that a
+// successor can be stored in its family. Presenting a token that was already
+// rotated revokes every token in its family, along with their access tokens,
+// and returns the token with ErrRefreshTokenReused.
+func (r *PostgresRepository) ConsumeRefreshToken(token string) (*models.RefreshTokenInfo, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	info := &models.RefreshTokenInfo{}
+	var rotatedAt sql.NullTime
+	err = tx.QueryRow(
+		`SELECT id, user_id, family_id, expires_at, created_at, rotated_at
+		 FROM refresh_tokens WHERE token_hash = $1 FOR UPDATE`,
+		hashToken(token),
+	).Scan(&info.ID, &info.UserID, &info.FamilyID, &info.ExpiresAt, &info.CreatedAt, &rotatedAt)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("invalid refresh token")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if rotatedAt.Valid {
+		if err := revokeFamily(tx, info.FamilyID); err != nil {
+			return nil, err
+		}
+		if err := tx.Commit(); err != nil {
+			return nil, err
+		}
+		return info, ErrRefreshTokenReused
+	}
+
+	if time.Now().After(info.ExpiresAt) {
+		return nil, fmt.Errorf("refresh token expired")
+	}
+
+	_, err = tx.Exec("UPDATE refresh_tokens SET rotated_at = $1 WHERE id = $2", time.Now().UTC(), info.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return info, nil
+}
+
+// revokeFamily revokes the unexpired access tokens issued within a refresh
+// token family and deletes the family's refresh tokens and session.
+func revokeFamily(tx *sql.Tx, familyID string) error {
+	_, err := tx.Exec(
+		`INSERT INTO revoked_tokens (jti, user_id, expires_at)
+		 SELECT access_token_id, user_id, access_expires_at FROM refresh_tokens
+		 WHERE family_id = $1 AND access_token_id IS NOT NULL AND access_expires_at > $2
+		 ON CONFLICT (jti) DO NOTHING`,
+		familyID, time.Now().UTC(),
+	)
+	if err != nil {
+		return err
+	}
+
+	if _, err := tx.Exec("DELETE FROM refresh_tokens WHERE family_id = $1", familyID); err != nil {
+		return err
+	}
+
+	_, err = tx.Exec("DELETE FROM sessions WHERE id = $1", familyID)
+	return err
+}
+
+// PurgeExpiredRefreshTokens deletes expired refresh tokens, including rotated
+// ones kept for reuse detection, and the sessions left without any. It
+// returns how many refresh tokens were removed.
+func (r *PostgresRepository) PurgeExpiredRefreshTokens(now time.Time) (int, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	result, err := tx.Exec("DELETE FROM refresh_tokens WHERE expires_at <= $1", now.UTC())
+	if err != nil {
+		return 0, err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+
+	_, err = tx.Exec(
+		`DELETE FROM sessions s
+		 WHERE NOT EXISTS (SELECT 1 FROM refresh_tokens t WHERE t.family_id = s.id)`,
+	)
+	if err != nil {
+		return 0, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, err
+	}
+
+	return int(rowsAffected), nil
+}
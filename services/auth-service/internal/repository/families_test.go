@@ -0,0 +1,102 @@
+package repository
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/todo/services/auth-service/internal/models"
+)
+
+func TestConsumeRefreshTokenRotates(t *testing.T) {
+	repo := newTestRepository(t)
+
+	userID := uuid.New().String()
+	familyID := uuid.New().String()
+	token := uuid.New().String()
+	expiresAt := time.Now().Add(time.Hour)
+
+	if err := repo.StoreRefreshToken(userID, familyID, token, expiresAt, uuid.New().String(), expiresAt); err != nil {
+		t.Fatalf("StoreRefreshToken: %v", err)
+	}
+
+	info, err := repo.ConsumeRefreshToken(token)
+	if err != nil {
+		t.Fatalf("ConsumeRefreshToken: %v", err)
+	}
+	if info.UserID != userID || info.FamilyID != familyID {
+		t.Errorf("consumed token of user %s in family %s, want user %s in family %s", info.UserID, info.FamilyID, userID, familyID)
+	}
+}
+
+func TestConsumeRefreshTokenReuseRevokesFamily(t *testing.T) {
+	repo := newTestRepository(t)
+
+	userID := uuid.New().String()
+	familyID := uuid.New().String()
+	expiresAt := time.Now().Add(time.Hour)
+	accessExpiresAt := time.Now().Add(15 * time.Minute)
+
+	if err := repo.CreateSession(familyID, userID, models.ClientInfo{}); err != nil {
+		t.Fatalf("CreateSession: %v", err)
+	}
+
+	first, firstAccess := uuid.New().String(), uuid.New().String()
+	if err := repo.StoreRefreshToken(userID, familyID, first, expiresAt, firstAccess, accessExpiresAt); err != nil {
+		t.Fatalf("StoreRefreshToken: %v", err)
+	}
+	if _, err := repo.ConsumeRefreshToken(first); err != nil {
+		t.Fatalf("ConsumeRefreshToken: %v", err)
+	}
+
+	second, secondAccess := uuid.New().String(), uuid.New().String()
+	if err := repo.StoreRefreshToken(userID, familyID, second, expiresAt, secondAccess, accessExpiresAt); err != nil {
+		t.Fatalf("StoreRefreshToken: %v", err)
+	}
+
+	// Replaying the rotated token gives the whole family away
+	info, err := repo.ConsumeRefreshToken(first)
+	if !errors.Is(err, ErrRefreshTokenReused) {
+		t.Fatalf("replayed token: got error %v, want %v", err, ErrRefreshTokenReused)
+	}
+	if info == nil || info.FamilyID != familyID {
+		t.Fatalf("replayed token: got %+v, want family %s", info, familyID)
+	}
+
+	if _, err := repo.ConsumeRefreshToken(second); err == nil {
+		t.Error("successor token still works after reuse was detected")
+	}
+
+	for _, jti := range []string{firstAccess, secondAccess} {
+		revoked, err := repo.IsTokenRevoked(jti)
+		if err != nil {
+			t.Fatalf("IsTokenRevoked: %v", err)
+		}
+		if !revoked {
+			t.Errorf("access token %s of the family is not revoked", jti)
+		}
+	}
+
+	sessions, err := repo.ListSessions(userID)
+	if err != nil {
+		t.Fatalf("ListSessions: %v", err)
+	}
+	if len(sessions) != 0 {
+		t.Errorf("got %d sessions after reuse, want none", len(sessions))
+	}
+}
+
+func TestConsumeRefreshTokenExpired(t *testing.T) {
+	repo := newTestRepository(t)
+
+	token := uuid.New().String()
+	expiredAt := time.Now().Add(-time.Minute)
+	if err := repo.StoreRefreshToken(uuid.New().String(), uuid.New().String(), token, expiredAt, uuid.New().String(), expiredAt); err != nil {
+		t.Fatalf("StoreRefreshToken: %v", err)
+	}
+
+	if _, err := repo.ConsumeRefreshToken(token); err == nil {
+		t.Error("expired refresh token was accepted")
+	}
+}
@@ -0,0 +1,161 @@
+package repository
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/todo/services/auth-service/internal/models"
+)
+
+const loginFailuresSchema = `
+	CREATE TABLE IF NOT EXISTS login_failures (
+		kind VARCHAR(20) NOT NULL,
+		value VARCHAR(255) NOT NULL,
+		failures INT NOT NULL,
+		last_failed_at TIMESTAMP NOT NULL,
+		locked_until TIMESTAMP,
+		PRIMARY KEY (kind, value)
+	);
+`
+
+const loginFailuresColumns = "kind, value, failures, last_failed_at, locked_until"
+
+func scanLoginFailures(row rowScanner) (*models.LoginFailures, error) {
+	failures := &models.LoginFailures{}
+	var lockedUntil sql.NullTime
+	if err := row.Scan(&failures.Kind, &failures.Value, &failures.Failures, &failures.LastFailedAt, &lockedUntil); err != nil {
+		return nil, err
+	}
+
+	if lockedUntil.Valid {
+		failures.LockedUntil = &lockedUntil.Time
+	}
+	return failures, nil
+}
+
+// CountLoginAttempt counts a login attempt for a username or an IP address
+// as a failure before its credentials are checked, unless allow refuses it
+// given the failures so far. The count's row is locked from the check to the
+// count, so parallel attempts are let through one at a time and each sees the
+// ones before it. A count whose last failure is older than windowStart, or
+// whose lockout has run out, starts over.
+func (r *PostgresRepository) CountLoginAttempt(kind, value string, now, windowStart time.Time, allow func(*models.LoginFailures) error) (*models.LoginFailures, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	// Create the row first so that there is always one to lock
+	if _, err := tx.Exec(
+		`INSERT INTO login_failures (kind, value, failures, last_failed_at)
+		 VALUES ($1, $2, 0, $3) ON CONFLICT (kind, value) DO NOTHING`,
+		kind, value, now.UTC(),
+	); err != nil {
+		return nil, err
+	}
+
+	current, err := scanLoginFailures(tx.QueryRow(
+		"SELECT "+loginFailuresColumns+" FROM login_failures WHERE kind = $1 AND value = $2 FOR UPDATE",
+		kind, value,
+	))
+	if err != nil {
+		return nil, err
+	}
+	if err := allow(current); err != nil {
+		return nil, err
+	}
+
+	counted, err := scanLoginFailures(tx.QueryRow(
+		`UPDATE login_failures SET
+			failures = CASE
+				WHEN last_failed_at <= $4 OR locked_until <= $3 THEN 1
+				ELSE failures + 1
+			END,
+			locked_until = CASE WHEN locked_until <= $3 THEN NULL ELSE locked_until END,
+			last_failed_at = $3
+		 WHERE kind = $1 AND value = $2
+		 RETURNING `+loginFailuresColumns,
+		kind, value, now.UTC(), windowStart.UTC(),
+	))
+	if err != nil {
+		return nil, err
+	}
+
+	return counted, tx.Commit()
+}
+
+// ForgiveLoginAttempt takes back an attempt counted by CountLoginAttempt
+// that turned out to have the right credentials.
+func (r *PostgresRepository) ForgiveLoginAttempt(kind, value string) error {
+	_, err := r.db.Exec(
+		"UPDATE login_failures SET failures = failures - 1 WHERE kind = $1 AND value = $2 AND failures > 0",
+		kind, value,
+	)
+	return err
+}
+
+// LockLogin rejects logins for a username or an IP address until the given
+// time.
+func (r *PostgresRepository) LockLogin(kind, value string, until time.Time) error {
+	_, err := r.db.Exec(
+		"UPDATE login_failures SET locked_until = $3 WHERE kind = $1 AND value = $2",
+		kind, value, until.UTC(),
+	)
+	return err
+}
+
+// ClearLoginFailures forgets the failed logins and any lockout of a username
+// or an IP address. It reports whether there were any.
+func (r *PostgresRepository) ClearLoginFailures(kind, value string) (bool, error) {
+	result, err := r.db.Exec("DELETE FROM login_failures WHERE kind = $1 AND value = $2", kind, value)
+	if err != nil {
+		return false, err
+	}
+
+	n, err := result.RowsAffected()
+	return n > 0, err
+}
+
+// ListLoginLockouts returns the usernames and IP addresses that are locked
+// out, most recently failed first.
+func (r *PostgresRepository) ListLoginLockouts(now time.Time) ([]*models.LoginFailures, error) {
+	rows, err := r.db.Query(
+		"SELECT "+loginFailuresColumns+" FROM login_failures WHERE locked_until > $1 ORDER BY last_failed_at DESC",
+		now.UTC(),
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	lockouts := []*models.LoginFailures{}
+	for rows.Next() {
+		failures, err := scanLoginFailures(rows)
+		if err != nil {
+			return nil, err
+		}
+		lockouts = append(lockouts, failures)
+	}
+
+	return lockouts, rows.Err()
+}
+
+// PurgeLoginFailures deletes counts whose last failure is older than
+// windowStart and that hold no lockout, and returns how many there were.
+func (r *PostgresRepository) PurgeLoginFailures(now, windowStart time.Time) (int, error) {
+	result, err := r.db.Exec(
+		"DELETE FROM login_failures WHERE last_failed_at <= $1 AND (locked_until IS NULL OR locked_until <= $2)",
+		windowStart.UTC(), now.UTC(),
+	)
+	if err != nil {
+		return 0, err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+
+	return int(rowsAffected), nil
+}
@@ -0,0 +1,106 @@
+package repository
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/todo/services/auth-service/internal/models"
+)
+
+func allowAll(*models.LoginFailures) error { return nil }
+
+func TestCountLoginAttempt(t *testing.T) {
+	repo := newTestRepository(t)
+
+	value := uuid.New().String()
+	t.Cleanup(func() { repo.ClearLoginFailures(models.LoginFailureUsername, value) })
+
+	now := time.Now()
+	windowStart := now.Add(-15 * time.Minute)
+
+	for want := 1; want <= 3; want++ {
+		counted, err := repo.CountLoginAttempt(models.LoginFailureUsername, value, now, windowStart, allowAll)
+		if err != nil {
+			t.Fatalf("CountLoginAttempt: %v", err)
+		}
+		if counted.Failures != want {
+			t.Fatalf("got %d failures, want %d", counted.Failures, want)
+		}
+	}
+
+	// A refused attempt sees the count so far and isn't counted itself
+	refused := errors.New("refused")
+	_, err := repo.CountLoginAttempt(models.LoginFailureUsername, value, now, windowStart, func(current *models.LoginFailures) error {
+		if current.Failures != 3 {
+			t.Errorf("allow saw %d failures, want 3", current.Failures)
+		}
+		return refused
+	})
+	if !errors.Is(err, refused) {
+		t.Fatalf("refused attempt: got %v, want %v", err, refused)
+	}
+
+	// An attempt with the right credentials is taken back
+	if err := repo.ForgiveLoginAttempt(models.LoginFailureUsername, value); err != nil {
+		t.Fatalf("ForgiveLoginAttempt: %v", err)
+	}
+	counted, err := repo.CountLoginAttempt(models.LoginFailureUsername, value, now, windowStart, allowAll)
+	if err != nil {
+		t.Fatalf("CountLoginAttempt: %v", err)
+	}
+	if counted.Failures != 3 {
+		t.Errorf("got %d failures after a forgiven attempt, want 3", counted.Failures)
+	}
+
+	// Failures before the window don't count
+	later := now.Add(20 * time.Minute)
+	counted, err = repo.CountLoginAttempt(models.LoginFailureUsername, value, later, later.Add(-15*time.Minute), allowAll)
+	if err != nil {
+		t.Fatalf("CountLoginAttempt: %v", err)
+	}
+	if counted.Failures != 1 {
+		t.Errorf("got %d failures after the window, want 1", counted.Failures)
+	}
+}
+
+func TestCountLoginAttemptAfterLockout(t *testing.T) {
+	repo := newTestRepository(t)
+
+	value := uuid.New().String()
+	t.Cleanup(func() { repo.ClearLoginFailures(models.LoginFailureIPAddress, value) })
+
+	now := time.Now()
+	windowStart := now.Add(-15 * time.Minute)
+
+	if _, err := repo.CountLoginAttempt(models.LoginFailureIPAddress, value, now, windowStart, allowAll); err != nil {
+		t.Fatalf("CountLoginAttempt: %v", err)
+	}
+	lockedUntil := now.Add(time.Minute)
+	if err := repo.LockLogin(models.LoginFailureIPAddress, value, lockedUntil); err != nil {
+		t.Fatalf("LockLogin: %v", err)
+	}
+
+	lockouts, err := repo.ListLoginLockouts(now)
+	if err != nil {
+		t.Fatalf("ListLoginLockouts: %v", err)
+	}
+	found := false
+	for _, lockout := range lockouts {
+		found = found || lockout.Value == value
+	}
+	if !found {
+		t.Errorf("locked address %s is not listed", value)
+	}
+
+	// Once the lockout has run out the count starts over
+	later := lockedUntil.Add(time.Second)
+	counted, err := repo.CountLoginAttempt(models.LoginFailureIPAddress, value, later, later.Add(-15*time.Minute), allowAll)
+	if err != nil {
+		t.Fatalf("CountLoginAttempt: %v", err)
+	}
+	if counted.Failures != 1 || counted.LockedUntil != nil {
+		t.Errorf("got %d failures locked until %v, want 1 and no lockout", counted.Failures, counted.LockedUntil)
+	}
+}
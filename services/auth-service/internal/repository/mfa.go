@@ -0,0 +1,297 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/todo/services/auth-service/internal/models"
+)
+
+// A user has at most one TOTP factor. Recovery codes and MFA challenges are
+// stored as hashes, like refresh tokens.
+const mfaSchema = `
+	CREATE TABLE IF NOT EXISTS totp_factors (
+		user_id VARCHAR(36) PRIMARY KEY,
+		secret VARCHAR(64) NOT NULL,
+		created_at TIMESTAMP NOT NULL,
+		confirmed_at TIMESTAMP,
+		last_used_step BIGINT NOT NULL DEFAULT 0
+	);
+
+	CREATE TABLE IF NOT EXISTS recovery_codes (
+		id SERIAL PRIMARY KEY,
+		user_id VARCHAR(36) NOT NULL,
+		code_hash VARCHAR(64) NOT NULL,
+		used_at TIMESTAMP
+	);
+
+	CREATE INDEX IF NOT EXISTS idx_recovery_codes_user_id ON recovery_codes(user_id);
+
+	CREATE TABLE IF NOT EXISTS mfa_challenges (
+		token_hash VARCHAR(64) PRIMARY KEY,
+		user_id VARCHAR(36) NOT NULL,
+		attempts INT NOT NULL DEFAULT 0,
+		expires_at TIMESTAMP NOT NULL
+	);
+`
+
+// GetTOTPFactor returns the user's TOTP factor, or nil if they have none.
+func (r *PostgresRepository) GetTOTPFactor(userID string) (*models.TOTPFactor, error) {
+	factor := &models.TOTPFactor{}
+	var confirmedAt sql.NullTime
+	err := r.db.QueryRow(
+		"SELECT user_id, secret, created_at, confirmed_at, last_used_step FROM totp_factors WHERE user_id = $1",
+		userID,
+	).Scan(&factor.UserID, &factor.Secret, &factor.CreatedAt, &confirmedAt, &factor.LastUsedStep)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if confirmedAt.Valid {
+		factor.ConfirmedAt = &confirmedAt.Time
+	}
+	return factor, nil
+}
+
+// SavePendingTOTPFactor stores a new, unconfirmed secret for the user,
+// replacing an earlier unconfirmed one. A confirmed factor is left alone and
+// reported as an error.
+func (r *PostgresRepository) SavePendingTOTPFactor(userID, secret string) error {
+	result, err := r.db.Exec(
+		`INSERT INTO totp_factors (user_id, secret, created_at)
+		 VALUES ($1, $2, $3)
+		 ON CONFLICT (user_id) DO UPDATE SET secret = $2, created_at = $3, last_used_step = 0
+		 WHERE totp_factors.confirmed_at IS NULL`,
+		userID, secret, time.Now().UTC(),
+	)
+	if err != nil {
+		return err
+	}
+
+	n, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return fmt.Errorf("two-factor authentication is already enabled")
+	}
+	return nil
+}
+
+// ConfirmTOTPFactor enables the user's pending factor, recording the step of
+// the code that confirmed it, and replaces their recovery codes.
+func (r *PostgresRepository) ConfirmTOTPFactor(userID string, step int64, recoveryCodes []string) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	result, err := tx.Exec(
+		`UPDATE totp_factors SET confirmed_at = $2, last_used_step = $3
+		 WHERE user_id = $1 AND confirmed_at IS NULL`,
+		userID, time.Now().UTC(), step,
+	)
+	if err != nil {
+		return err
+	}
+	n, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return fmt.Errorf("no pending two-factor enrollment")
+	}
+
+	if err := replaceRecoveryCodes(tx, userID, recoveryCodes); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// UseTOTPStep records that a code for the given step was accepted. It
+// reports false if a code for that step or a later one was already used.
+func (r *PostgresRepository) UseTOTPStep(userID string, step int64) (bool, error) {
+	result, err := r.db.Exec(
+		"UPDATE totp_factors SET last_used_step = $2 WHERE user_id = $1 AND last_used_step < $2",
+		userID, step,
+	)
+	if err != nil {
+		return false, err
+	}
+
+	n, err := result.RowsAffected()
+	return n == 1, err
+}
+
+// DeleteTOTPFactor removes the user's factor and recovery codes.
+func (r *PostgresRepository) DeleteTOTPFactor(userID string) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec("DELETE FROM totp_factors WHERE user_id = $1", userID); err != nil {
+		return err
+	}
+	if _, err := tx.Exec("DELETE FROM recovery_codes WHERE user_id = $1", userID); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// DeleteUserMFA removes the user's factor, recovery codes and pending MFA
+// challenges.
+func (r *PostgresRepository) DeleteUserMFA(userID string) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec("DELETE FROM totp_factors WHERE user_id = $1", userID); err != nil {
+		return err
+	}
+	if _, err := tx.Exec("DELETE FROM recovery_codes WHERE user_id = $1", userID); err != nil {
+		return err
+	}
+	if _, err := tx.Exec("DELETE FROM mfa_challenges WHERE user_id = $1", userID); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// ReplaceRecoveryCodes swaps the user's recovery codes for new ones.
+func (r *PostgresRepository) ReplaceRecoveryCodes(userID string, codes []string) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := replaceRecoveryCodes(tx, userID, codes); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func replaceRecoveryCodes(tx *sql.Tx, userID string, codes []string) error {
+	if _, err := tx.Exec("DELETE FROM recovery_codes WHERE user_id = $1", userID); err != nil {
+		return err
+	}
+	for _, code := range codes {
+		if _, err := tx.Exec(
+			"INSERT INTO recovery_codes (user_id, code_hash) VALUES ($1, $2)",
+			userID, hashToken(code),
+		); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// UseRecoveryCode marks one of the user's unused recovery codes as used. It
+// reports false if the code is not one of them.
+func (r *PostgresRepository) UseRecoveryCode(userID, code string) (bool, error) {
+	result, err := r.db.Exec(
+		"UPDATE recovery_codes SET used_at = $3 WHERE user_id = $1 AND code_hash = $2 AND used_at IS NULL",
+		userID, hashToken(code), time.Now().UTC(),
+	)
+	if err != nil {
+		return false, err
+	}
+
+	n, err := result.RowsAffected()
+	return n > 0, err
+}
+
+// CountRecoveryCodes returns how many unused recovery codes the user has.
+func (r *PostgresRepository) CountRecoveryCodes(userID string) (int, error) {
+	var count int
+	err := r.db.QueryRow(
+		"SELECT COUNT(*) FROM recovery_codes WHERE user_id = $1 AND used_at IS NULL",
+		userID,
+	).Scan(&count)
+	return count, err
+}
+
+// CreateMFAChallenge stores a challenge for a login that passed the password
+// check.
+func (r *PostgresRepository) CreateMFAChallenge(token, userID string, expiresAt time.Time) error {
+	_, err := r.db.Exec(
+		"INSERT INTO mfa_challenges (token_hash, user_id, expires_at) VALUES ($1, $2, $3)",
+		hashToken(token), userID, expiresAt.UTC(),
+	)
+	return err
+}
+
+// GetMFAChallenge returns the user an unexpired challenge belongs to.
+func (r *PostgresRepository) GetMFAChallenge(token string) (string, error) {
+	var userID string
+	err := r.db.QueryRow(
+		"SELECT user_id FROM mfa_challenges WHERE token_hash = $1 AND expires_at > $2",
+		hashToken(token), time.Now().UTC(),
+	).Scan(&userID)
+	if err == sql.ErrNoRows {
+		return "", fmt.Errorf("invalid or expired MFA token")
+	}
+	return userID, err
+}
+
+// FailMFAChallenge counts a wrong code against a challenge and deletes the
+// challenge once maxAttempts is reached.
+func (r *PostgresRepository) FailMFAChallenge(token string, maxAttempts int) error {
+	var attempts int
+	err := r.db.QueryRow(
+		"UPDATE mfa_challenges SET attempts = attempts + 1 WHERE token_hash = $1 RETURNING attempts",
+		hashToken(token),
+	).Scan(&attempts)
+	if err == sql.ErrNoRows {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	if attempts >= maxAttempts {
+		_, err = r.db.Exec("DELETE FROM mfa_challenges WHERE token_hash = $1", hashToken(token))
+	}
+	return err
+}
+
+// ConsumeMFAChallenge deletes a challenge once it has been answered. It
+// reports false if the challenge was already consumed.
+func (r *PostgresRepository) ConsumeMFAChallenge(token string) (bool, error) {
+	result, err := r.db.Exec("DELETE FROM mfa_challenges WHERE token_hash = $1", hashToken(token))
+	if err != nil {
+		return false, err
+	}
+
+	n, err := result.RowsAffected()
+	return n == 1, err
+}
+
+// PurgeExpiredMFAChallenges deletes challenges that were never answered and
+// returns how many there were.
+func (r *PostgresRepository) PurgeExpiredMFAChallenges(now time.Time) (int, error) {
+	result, err := r.db.Exec("DELETE FROM mfa_challenges WHERE expires_at <= $1", now.UTC())
+	if err != nil {
+		return 0, err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+
+	return int(rowsAffected), nil
+}
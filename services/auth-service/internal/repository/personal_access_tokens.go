@@ -0,0 +1,157 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/todo/services/auth-service/internal/models"
+)
+
+const personalAccessTokensSchema = `
+	CREATE TABLE IF NOT EXISTS personal_access_tokens (
+		id VARCHAR(36) PRIMARY KEY,
+		user_id VARCHAR(36) NOT NULL,
+		name VARCHAR(100) NOT NULL,
+		token_hash VARCHAR(64) UNIQUE NOT NULL,
+		scopes TEXT[] NOT NULL DEFAULT '{}',
+		created_at TIMESTAMP NOT NULL,
+		expires_at TIMESTAMP,
+		last_used_at TIMESTAMP
+	);
+
+	CREATE INDEX IF NOT EXISTS idx_personal_access_tokens_user_id ON personal_access_tokens(user_id);
+`
+
+// lastUsedResolution bounds how often using a token writes its last-used
+// time.
+const lastUsedResolution = time.Minute
+
+const personalAccessTokenColumns = "id, user_id, name, scopes, created_at, expires_at, last_used_at"
+
+// rowScanner is satisfied by both *sql.Row and *sql.Rows.
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+// scanPersonalAccessToken scans the personalAccessTokenColumns followed by
+// any extra columns.
+func scanPersonalAccessToken(row rowScanner, extra ...interface{}) (*models.PersonalAccessToken, error) {
+	pat := &models.PersonalAccessToken{}
+	var expiresAt, lastUsedAt sql.NullTime
+
+	dest := append([]interface{}{&pat.ID, &pat.UserID, &pat.Name, pq.Array(&pat.Scopes), &pat.CreatedAt, &expiresAt, &lastUsedAt}, extra...)
+	if err := row.Scan(dest...); err != nil {
+		return nil, err
+	}
+
+	if pat.Scopes == nil {
+		pat.Scopes = []string{}
+	}
+	if expiresAt.Valid {
+		pat.ExpiresAt = &expiresAt.Time
+	}
+	if lastUsedAt.Valid {
+		pat.LastUsedAt = &lastUsedAt.Time
+	}
+
+	return pat, nil
+}
+
+// CreatePersonalAccessToken stores a new token under the hash of its secret.
+func (r *PostgresRepository) CreatePersonalAccessToken(pat *models.PersonalAccessToken, token string) error {
+	var expiresAt interface{}
+	if pat.ExpiresAt != nil {
+		expiresAt = pat.ExpiresAt.UTC()
+	}
+
+	_, err := r.db.Exec(
+		`INSERT INTO personal_access_tokens (id, user_id, name, token_hash, scopes, created_at, expires_at)
+		 VALUES ($1, $2, $3, $4, $5, $6, $7)`,
+		pat.ID, pat.UserID, pat.Name, hashToken(token), pq.Array(pat.Scopes), pat.CreatedAt.UTC(), expiresAt,
+	)
+	return err
+}
+
+// GetPersonalAccessToken looks a token up by its secret and returns it with
+// its owner's username. Tokens of deleted users are not found.
+func (r *PostgresRepository) GetPersonalAccessToken(token string) (*models.PersonalAccessToken, string, error) {
+	var username string
+	pat, err := scanPersonalAccessToken(r.db.QueryRow(
+		`SELECT t.id, t.user_id, t.name, t.scopes, t.created_at, t.expires_at, t.last_used_at, u.username
+		 FROM personal_access_tokens t JOIN users u ON u.id = t.user_id
+		 WHERE t.token_hash = $1`,
+		hashToken(token),
+	), &username)
+	if err == sql.ErrNoRows {
+		return nil, "", fmt.Errorf("invalid token")
+	}
+	if err != nil {
+		return nil, "", err
+	}
+
+	return pat, username, nil
+}
+
+// TouchPersonalAccessToken records that a token was used.
+func (r *PostgresRepository) TouchPersonalAccessToken(id string) error {
+	now := time.Now().UTC()
+	_, err := r.db.Exec(
+		`UPDATE personal_access_tokens SET last_used_at = $2
+		 WHERE id = $1 AND (last_used_at IS NULL OR last_used_at < $3)`,
+		id, now, now.Add(-lastUsedResolution),
+	)
+	return err
+}
+
+func (r *PostgresRepository) ListPersonalAccessTokens(userID string) ([]*models.PersonalAccessToken, error) {
+	rows, err := r.db.Query(
+		"SELECT "+personalAccessTokenColumns+" FROM personal_access_tokens WHERE user_id = $1 ORDER BY created_at DESC",
+		userID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	pats := []*models.PersonalAccessToken{}
+	for rows.Next() {
+		pat, err := scanPersonalAccessToken(rows)
+		if err != nil {
+			return nil, err
+		}
+		pats = append(pats, pat)
+	}
+
+	return pats, rows.Err()
+}
+
+// DeletePersonalAccessToken revokes one of the user's tokens.
+func (r *PostgresRepository) DeletePersonalAccessToken(userID, id string) error {
+	result, err := r.db.Exec("DELETE FROM personal_access_tokens WHERE id = $1 AND user_id = $2", id, userID)
+	if err != nil {
+		return err
+	}
+
+	n, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return fmt.Errorf("personal access token not found")
+	}
+	return nil
+}
+
+// DeleteUserPersonalAccessTokens revokes every token of a user and returns
+// how many there were.
+func (r *PostgresRepository) DeleteUserPersonalAccessTokens(userID string) (int, error) {
+	result, err := r.db.Exec("DELETE FROM personal_access_tokens WHERE user_id = $1", userID)
+	if err != nil {
+		return 0, err
+	}
+
+	n, err := result.RowsAffected()
+	return int(n), err
+}
@@ -2,6 +2,7 @@ package repository
 
 import (
 	"database/sql"
+	"errors"
 	"fmt"
 	"time"
 
@@ -9,6 +10,14 @@ import (
 	"golang.org/x/crypto/bcrypt"
 )
 
+// ErrInvalidCredentials is returned for an unknown username and for a wrong
+// password alike.
+var ErrInvalidCredentials = errors.New("invalid credentials")
+
+// dummyPasswordHash is compared against when a username is unknown. It uses
+// the same cost as the hashes user-service stores.
+var dummyPasswordHash, _ = bcrypt.GenerateFromPassword([]byte("dummy password"), bcrypt.DefaultCost)
+
 type PostgresRepository struct {
 	db *sql.DB
 }
@@ -37,6 +46,12 @@ func NewPostgresRepository(connStr string) (*PostgresRepository, error) {
 		return nil, err
 	}
 
+	for _, schema := range []string{revocationsSchema, familiesSchema, sessionsSchema, signingKeysSchema, personalAccessTokensSchema, mfaSchema, loginFailuresSchema} {
+		if _, err := db.Exec(schema); err != nil {
+			return nil, err
+		}
+	}
+
 	return &PostgresRepository{db: db}, nil
 }
 
@@ -49,7 +64,10 @@ func (r *PostgresRepository) ValidateCredentials(username, password string) (str
 	).Scan(&userID, &hashedPassword)
 
 	if err == sql.ErrNoRows {
-		return "", "", fmt.Errorf("invalid credentials")
+		// Spend as long as a real comparison would, so that response times
+		// don't tell which usernames exist
+		bcrypt.CompareHashAndPassword(dummyPasswordHash, []byte(password))
+		return "", "", ErrInvalidCredentials
 	}
 	if err != nil {
 		return "", "", err
@@ -57,46 +75,74 @@ func (r *PostgresRepository) ValidateCredentials(username, password string) (str
 
 	// Compare passwords
 	if err := bcrypt.CompareHashAndPassword([]byte(hashedPassword), []byte(password)); err != nil {
-		return "", "", fmt.Errorf("invalid credentials")
+		return "", "", ErrInvalidCredentials
 	}
 
 	return userID, username, nil
 }
 
-func (r *PostgresRepository) StoreRefreshToken(userID, token string, expiresAt time.Time) error {
+// GetUsername returns the username of a user.
+func (r *PostgresRepository) GetUsername(userID string) (string, error) {
+	var username string
+	err := r.db.QueryRow("SELECT username FROM users WHERE id = $1", userID).Scan(&username)
+	if err == sql.ErrNoRows {
+		return "", fmt.Errorf("user not found")
+	}
+	return username, err
+}
+
+// StoreRefreshToken stores the hash of a refresh token in the given family,
+// together with the ID and expiry of the access token issued alongside it so
+// logging out can revoke both.
+func (r *PostgresRepository) StoreRefreshToken(userID, familyID, token string, expiresAt time.Time, accessTokenID string, accessExpiresAt time.Time) error {
 	_, err := r.db.Exec(
-		"INSERT INTO refresh_tokens (user_id, token, expires_at) VALUES ($1, $2, $3)",
-		userID, token, expiresAt,
+		`INSERT INTO refresh_tokens (user_id, family_id, token_hash, expires_at, access_token_id, access_expires_at)
+		 VALUES ($1, $2, $3, $4, $5, $6)`,
+		userID, familyID, hashToken(token), expiresAt.UTC(), accessTokenID, accessExpiresAt.UTC(),
 	)
 	return err
 }
 
-func (r *PostgresRepository) GetRefreshToken(token string) (string, error) {
-	var userID string
-	var expiresAt time.Time
-
-	err := r.db.QueryRow(
-		"SELECT user_id, expires_at FROM refresh_tokens WHERE token = $1",
-		token,
-	).Scan(&userID, &expiresAt)
-
-	if err == sql.ErrNoRows {
-		return "", fmt.Errorf("invalid refresh token")
+// DeleteUserRefreshTokens revokes every refresh token issued to a user along
+// with the access tokens issued alongside them.
+func (r *PostgresRepository) DeleteUserRefreshTokens(userID string) (int, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	_, err = tx.Exec(
+		`INSERT INTO revoked_tokens (jti, user_id, expires_at)
+		 SELECT access_token_id, user_id, access_expires_at FROM refresh_tokens
+		 WHERE user_id = $1 AND access_token_id IS NOT NULL AND access_expires_at > $2
+		 ON CONFLICT (jti) DO NOTHING`,
+		userID, time.Now().UTC(),
+	)
+	if err != nil {
+		return 0, err
 	}
+
+	// Rotated tokens are deleted too but only live ones are counted
+	var revoked int
+	err = tx.QueryRow(
+		`WITH deleted AS (DELETE FROM refresh_tokens WHERE user_id = $1 RETURNING rotated_at)
+		 SELECT COUNT(*) FROM deleted WHERE rotated_at IS NULL`,
+		userID,
+	).Scan(&revoked)
 	if err != nil {
-		return "", err
+		return 0, err
 	}
 
-	if time.Now().After(expiresAt) {
-		return "", fmt.Errorf("refresh token expired")
+	if _, err := tx.Exec("DELETE FROM sessions WHERE user_id = $1", userID); err != nil {
+		return 0, err
 	}
 
-	return userID, nil
-}
+	if err := tx.Commit(); err != nil {
+		return 0, err
+	}
 
-func (r *PostgresRepository) DeleteRefreshToken(token string) error {
-	_, err := r.db.Exec("DELETE FROM refresh_tokens WHERE token = $1", token)
-	return err
+	return revoked, nil
 }
 
 func (r *PostgresRepository) Close() error {
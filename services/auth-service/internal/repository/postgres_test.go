@@ -0,0 +1,26 @@
+package repository
+
+import (
+	"os"
+	"testing"
+)
+
+// newTestRepository connects to the database in TEST_DATABASE_URL, skipping
+// the test when it is not set. Tests keep to rows of their own users so they
+// can share the database.
+func newTestRepository(t *testing.T) *PostgresRepository {
+	t.Helper()
+
+	connStr := os.Getenv("TEST_DATABASE_URL")
+	if connStr == "" {
+		t.Skip("TEST_DATABASE_URL is not set")
+	}
+
+	repo, err := NewPostgresRepository(connStr)
+	if err != nil {
+		t.Fatalf("NewPostgresRepository: %v", err)
+	}
+	t.Cleanup(func() { repo.Close() })
+
+	return repo
+}
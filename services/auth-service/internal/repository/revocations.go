@@ -0,0 +1,109 @@
+package repository
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/todo/services/auth-service/internal/models"
+)
+
+// Access tokens are revoked by their JWT ID. A revocation only has to outlive
+// the token it revokes, so each row carries the token's own expiry and is
+// ignored, then purged, once that has passed.
+const revocationsSchema = `
+	CREATE TABLE IF NOT EXISTS revoked_tokens (
+		jti VARCHAR(36) PRIMARY KEY,
+		user_id VARCHAR(36) NOT NULL,
+		expires_at TIMESTAMP NOT NULL,
+		revoked_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+	);
+
+	CREATE INDEX IF NOT EXISTS idx_revoked_tokens_expires_at ON revoked_tokens(expires_at);
+
+	ALTER TABLE refresh_tokens ADD COLUMN IF NOT EXISTS access_token_id VARCHAR(36);
+	ALTER TABLE refresh_tokens ADD COLUMN IF NOT EXISTS access_expires_at TIMESTAMP;
+
+	CREATE INDEX IF NOT EXISTS idx_refresh_tokens_access_token_id ON refresh_tokens(access_token_id);
+`
+
+// IsTokenRevoked reports whether the access token with the given JWT ID has
+// been revoked and would otherwise still be valid.
+func (r *PostgresRepository) IsTokenRevoked(jti string) (bool, error) {
+	var revoked bool
+	err := r.db.QueryRow(
+		"SELECT EXISTS(SELECT 1 FROM revoked_tokens WHERE jti = $1 AND expires_at > $2)",
+		jti, time.Now().UTC(),
+	).Scan(&revoked)
+	return revoked, err
+}
+
+// ListRevokedTokens returns the revocations of tokens that have not expired
+// yet.
+func (r *PostgresRepository) ListRevokedTokens(now time.Time) ([]*models.RevokedToken, error) {
+	rows, err := r.db.Query("SELECT jti, expires_at FROM revoked_tokens WHERE expires_at > $1", now.UTC())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	tokens := []*models.RevokedToken{}
+	for rows.Next() {
+		token := &models.RevokedToken{}
+		if err := rows.Scan(&token.JTI, &token.ExpiresAt); err != nil {
+			return nil, err
+		}
+		tokens = append(tokens, token)
+	}
+
+	return tokens, rows.Err()
+}
+
+// RevokeAccessToken revokes an access token until it expires and deletes the
+// refresh token family it was issued in.
+func (r *PostgresRepository) RevokeAccessToken(jti, userID string, expiresAt time.Time) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	_, err = tx.Exec(
+		`INSERT INTO revoked_tokens (jti, user_id, expires_at) VALUES ($1, $2, $3)
+		 ON CONFLICT (jti) DO NOTHING`,
+		jti, userID, expiresAt.UTC(),
+	)
+	if err != nil {
+		return err
+	}
+
+	var familyID string
+	err = tx.QueryRow("SELECT family_id FROM refresh_tokens WHERE access_token_id = $1", jti).Scan(&familyID)
+	if err == sql.ErrNoRows {
+		return tx.Commit()
+	}
+	if err != nil {
+		return err
+	}
+
+	if err := revokeFamily(tx, familyID); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// PurgeExpiredRevocations deletes revocations for tokens that have expired on
+// their own and returns how many were removed.
+func (r *PostgresRepository) PurgeExpiredRevocations(now time.Time) (int, error) {
+	result, err := r.db.Exec("DELETE FROM revoked_tokens WHERE expires_at <= $1", now.UTC())
+	if err != nil {
+		return 0, err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+
+	return int(rowsAffected), nil
+}
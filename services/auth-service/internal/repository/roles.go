@@ -0,0 +1,45 @@
+package repository
+
+// GetUserRoles returns the roles assigned to a user in user-service and the
+// scopes they grant.
+func (r *PostgresRepository) GetUserRoles(userID string) ([]string, []string, error) {
+	roles := []string{}
+	rows, err := r.db.Query("SELECT role FROM user_roles WHERE user_id = $1 ORDER BY role", userID)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var role string
+		if err := rows.Scan(&role); err != nil {
+			return nil, nil, err
+		}
+		roles = append(roles, role)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, nil, err
+	}
+
+	scopes := []string{}
+	permissions, err := r.db.Query(
+		`SELECT DISTINCT p.permission FROM user_roles u
+		JOIN role_permissions p ON p.role = u.role
+		WHERE u.user_id = $1 ORDER BY p.permission`,
+		userID,
+	)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer permissions.Close()
+
+	for permissions.Next() {
+		var scope string
+		if err := permissions.Scan(&scope); err != nil {
+			return nil, nil, err
+		}
+		scopes = append(scopes, scope)
+	}
+
+	return roles, scopes, permissions.Err()
+}
@@ -0,0 +1,147 @@
+package repository
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/todo/services/auth-service/internal/models"
+)
+
+// A session is recorded per refresh token family and shares its ID. Families
+// created before sessions were tracked get a session without client details.
+const sessionsSchema = `
+	CREATE TABLE IF NOT EXISTS sessions (
+		id VARCHAR(36) PRIMARY KEY,
+		user_id VARCHAR(36) NOT NULL,
+		user_agent TEXT NOT NULL DEFAULT '',
+		ip_address VARCHAR(45) NOT NULL DEFAULT '',
+		created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+		last_used_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+	);
+
+	CREATE INDEX IF NOT EXISTS idx_sessions_user_id ON sessions(user_id);
+
+	INSERT INTO sessions (id, user_id, created_at, last_used_at)
+	SELECT family_id, user_id, MIN(created_at), MAX(created_at) FROM refresh_tokens
+	GROUP BY family_id, user_id
+	ON CONFLICT (id) DO NOTHING;
+`
+
+// CreateSession records a new session for a login.
+func (r *PostgresRepository) CreateSession(id, userID string, client models.ClientInfo) error {
+	now := time.Now().UTC()
+	_, err := r.db.Exec(
+		`INSERT INTO sessions (id, user_id, user_agent, ip_address, created_at, last_used_at)
+		 VALUES ($1, $2, $3, $4, $5, $5)`,
+		id, userID, client.UserAgent, client.IPAddress, now,
+	)
+	return err
+}
+
+// TouchSession records that a session was used from the given client.
+func (r *PostgresRepository) TouchSession(id string, client models.ClientInfo) error {
+	_, err := r.db.Exec(
+		`UPDATE sessions SET user_agent = $2, ip_address = $3, last_used_at = $4 WHERE id = $1`,
+		id, client.UserAgent, client.IPAddress, time.Now().UTC(),
+	)
+	return err
+}
+
+// ListSessions returns the user's sessions that still hold a live refresh
+// token, most recently used first.
+func (r *PostgresRepository) ListSessions(userID string) ([]*models.Session, error) {
+	rows, err := r.db.Query(
+		`SELECT s.id, s.user_id, s.user_agent, s.ip_address, s.created_at, s.last_used_at
+		 FROM sessions s
+		 WHERE s.user_id = $1 AND EXISTS (
+			SELECT 1 FROM refresh_tokens t
+			WHERE t.family_id = s.id AND t.rotated_at IS NULL AND t.expires_at > $2
+		 )
+		 ORDER BY s.last_used_at DESC`,
+		userID, time.Now().UTC(),
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var sessions []*models.Session
+	for rows.Next() {
+		session := &models.Session{}
+		if err := rows.Scan(&session.ID, &session.UserID, &session.UserAgent, &session.IPAddress, &session.CreatedAt, &session.LastUsedAt); err != nil {
+			return nil, err
+		}
+		sessions = append(sessions, session)
+	}
+
+	return sessions, rows.Err()
+}
+
+// RevokeSession revokes one of the user's sessions along with its tokens.
+func (r *PostgresRepository) RevokeSession(userID, sessionID string) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	result, err := tx.Exec("DELETE FROM sessions WHERE id = $1 AND user_id = $2", sessionID, userID)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return fmt.Errorf("session not found")
+	}
+
+	if err := revokeFamily(tx, sessionID); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// RevokeOtherSessions revokes every session of the user except the current
+// one and returns how many were revoked.
+func (r *PostgresRepository) RevokeOtherSessions(userID, currentID string) (int, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	rows, err := tx.Query("DELETE FROM sessions WHERE user_id = $1 AND id <> $2 RETURNING id", userID, currentID)
+	if err != nil {
+		return 0, err
+	}
+
+	var ids []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			rows.Close()
+			return 0, err
+		}
+		ids = append(ids, id)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, err
+	}
+
+	for _, id := range ids {
+		if err := revokeFamily(tx, id); err != nil {
+			return 0, err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, err
+	}
+
+	return len(ids), nil
+}
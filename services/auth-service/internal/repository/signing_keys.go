@@ -0,0 +1,113 @@
+package repository
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/todo/services/auth-service/internal/models"
+)
+
+const signingKeysSchema = `
+	CREATE TABLE IF NOT EXISTS signing_keys (
+		id VARCHAR(36) PRIMARY KEY,
+		algorithm VARCHAR(10) NOT NULL,
+		private_key BYTEA NOT NULL,
+		created_at TIMESTAMP NOT NULL,
+		expires_at TIMESTAMP
+	);
+`
+
+// ListSigningKeys returns the signing keys that haven't expired, newest first.
+func (r *PostgresRepository) ListSigningKeys(now time.Time) ([]*models.SigningKey, error) {
+	rows, err := r.db.Query(
+		`SELECT id, algorithm, private_key, created_at, expires_at FROM signing_keys
+		 WHERE expires_at IS NULL OR expires_at > $1
+		 ORDER BY created_at DESC`,
+		now.UTC(),
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var keys []*models.SigningKey
+	for rows.Next() {
+		key := &models.SigningKey{}
+		var expiresAt sql.NullTime
+		if err := rows.Scan(&key.ID, &key.Algorithm, &key.PrivateKey, &key.CreatedAt, &expiresAt); err != nil {
+			return nil, err
+		}
+		if expiresAt.Valid {
+			key.ExpiresAt = &expiresAt.Time
+		}
+		keys = append(keys, key)
+	}
+
+	return keys, rows.Err()
+}
+
+// RotateSigningKey makes key the current signing key unless another replica
+// already installed a current key with the same algorithm created after
+// rotatedBefore. The replaced key expires at retireAt. It reports whether the
+// key was installed.
+func (r *PostgresRepository) RotateSigningKey(key *models.SigningKey, rotatedBefore, retireAt time.Time) (bool, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback()
+
+	// Serialize rotations across replicas
+	if _, err := tx.Exec("LOCK TABLE signing_keys IN SHARE ROW EXCLUSIVE MODE"); err != nil {
+		return false, err
+	}
+
+	var fresh bool
+	err = tx.QueryRow(
+		`SELECT EXISTS(
+			SELECT 1 FROM signing_keys
+			WHERE expires_at IS NULL AND algorithm = $1 AND created_at > $2
+		 )`,
+		key.Algorithm, rotatedBefore.UTC(),
+	).Scan(&fresh)
+	if err != nil {
+		return false, err
+	}
+	if fresh {
+		return false, nil
+	}
+
+	if _, err := tx.Exec("UPDATE signing_keys SET expires_at = $1 WHERE expires_at IS NULL", retireAt.UTC()); err != nil {
+		return false, err
+	}
+
+	_, err = tx.Exec(
+		"INSERT INTO signing_keys (id, algorithm, private_key, created_at) VALUES ($1, $2, $3, $4)",
+		key.ID, key.Algorithm, key.PrivateKey, key.CreatedAt.UTC(),
+	)
+	if err != nil {
+		return false, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+// DeleteExpiredSigningKeys deletes keys that no longer verify any token and
+// returns how many were removed.
+func (r *PostgresRepository) DeleteExpiredSigningKeys(now time.Time) (int, error) {
+	result, err := r.db.Exec("DELETE FROM signing_keys WHERE expires_at <= $1", now.UTC())
+	if err != nil {
+		return 0, err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+
+	return int(rowsAffected), nil
+}
@@ -0,0 +1,238 @@
+package service
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/todo/pkg/auth"
+	"github.com/todo/services/auth-service/internal/jwt"
+	"github.com/todo/services/auth-service/internal/models"
+	"github.com/todo/services/auth-service/internal/repository"
+)
+
+const refreshTokenDuration = 7 * 24 * time.Hour
+
+// AuthService implements the authentication flows shared by the gRPC and HTTP
+// APIs, so that both issue, rotate and revoke tokens the same way.
+type AuthService struct {
+	repo       *repository.PostgresRepository
+	jwtManager *jwt.JWTManager
+	totpIssuer string
+}
+
+// NewAuthService creates the service. totpIssuer names the service in
+// authenticator apps.
+func NewAuthService(repo *repository.PostgresRepository, jwtManager *jwt.JWTManager, totpIssuer string) *AuthService {
+	return &AuthService{
+		repo:       repo,
+		jwtManager: jwtManager,
+		totpIssuer: totpIssuer,
+	}
+}
+
+// Login checks the user's credentials, starts a session for the client and
+// issues an access token together with the first refresh token of the
+// session's family. Users with two-factor authentication get an MFA
+// challenge instead, to be answered with VerifyMFA. Failed logins slow down
+// and then lock out further attempts for the username and the client's
+// address; refusals are reported as a *LoginError.
+func (s *AuthService) Login(username, password string, client models.ClientInfo) (*models.TokenPair, *models.MFAChallenge, error) {
+	attempts, err := s.beginLoginAttempt(username, client)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	userID, _, err := s.repo.ValidateCredentials(username, password)
+	if err == repository.ErrInvalidCredentials {
+		if err := s.failLoginAttempt(attempts); err != nil {
+			return nil, nil, err
+		}
+		return nil, nil, &LoginError{
+			Code:    LoginErrorInvalidCredentials,
+			Message: err.Error(),
+		}
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if err := s.forgiveLoginAttempt(attempts); err != nil {
+		return nil, nil, err
+	}
+
+	factor, err := s.repo.GetTOTPFactor(userID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if factor != nil && factor.ConfirmedAt != nil {
+		challenge, err := s.challenge(userID)
+		if err != nil {
+			return nil, nil, err
+		}
+		return nil, challenge, nil
+	}
+
+	if err := s.clearLoginFailures(username); err != nil {
+		return nil, nil, err
+	}
+
+	tokens, err := s.startSession(userID, username, client)
+	if err != nil {
+		return nil, nil, err
+	}
+	return tokens, nil, nil
+}
+
+// JWKS returns the public keys other services verify tokens with.
+func (s *AuthService) JWKS() auth.JWKS {
+	return s.jwtManager.JWKS()
+}
+
+// Validate returns the claims of a valid, unrevoked access token or personal
+// access token.
+func (s *AuthService) Validate(token string) (*jwt.Claims, error) {
+	if strings.HasPrefix(token, auth.PersonalAccessTokenPrefix) {
+		return s.validatePersonalAccessToken(token)
+	}
+	return s.jwtManager.Validate(token)
+}
+
+// Refresh rotates a refresh token and issues a new token pair in the same
+// family. Reusing an already rotated token revokes the whole family.
+func (s *AuthService) Refresh(refreshToken string, client models.ClientInfo) (*models.TokenPair, error) {
+	consumed, err := s.repo.ConsumeRefreshToken(refreshToken)
+	if err == repository.ErrRefreshTokenReused {
+		log.Printf("SECURITY: refresh token reuse detected for user %s, revoked token family %s", consumed.UserID, consumed.FamilyID)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	username, err := s.repo.GetUsername(consumed.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.TouchSession(consumed.FamilyID, client); err != nil {
+		return nil, err
+	}
+
+	return s.issue(consumed.UserID, username, consumed.FamilyID)
+}
+
+// Logout revokes the access token the claims belong to until it expires,
+// along with its refresh token family. Logging out with a personal access
+// token revokes that token.
+func (s *AuthService) Logout(claims *jwt.Claims) error {
+	if id, ok := personalAccessTokenID(claims); ok {
+		return s.repo.DeletePersonalAccessToken(claims.UserID, id)
+	}
+	return s.repo.RevokeAccessToken(claims.ID, claims.UserID, claims.ExpiresAt.Time)
+}
+
+// ListRevokedTokens returns the revocations of access tokens that would
+// otherwise still be valid, so that services verifying tokens locally can
+// refuse them.
+func (s *AuthService) ListRevokedTokens() ([]*models.RevokedToken, error) {
+	return s.repo.ListRevokedTokens(time.Now())
+}
+
+// RevokeUserTokens revokes every session and personal access token of a user
+// and returns how many there were. It also deletes the user's two-factor
+// authentication and, when the username is given, the failed logins counted
+// against it, so nothing of a deleted user is left behind.
+func (s *AuthService) RevokeUserTokens(userID, username string) (int, error) {
+	sessions, err := s.repo.DeleteUserRefreshTokens(userID)
+	if err != nil {
+		return 0, err
+	}
+
+	tokens, err := s.repo.DeleteUserPersonalAccessTokens(userID)
+	if err != nil {
+		return 0, err
+	}
+
+	if err := s.repo.DeleteUserMFA(userID); err != nil {
+		return 0, err
+	}
+
+	if username != "" {
+		if _, err := s.repo.ClearLoginFailures(models.LoginFailureUsername, username); err != nil {
+			return 0, err
+		}
+	}
+
+	return sessions + tokens, nil
+}
+
+// ListSessions returns the user's active sessions, flagging the one with the
+// given ID as current.
+func (s *AuthService) ListSessions(userID, currentSessionID string) ([]*models.Session, error) {
+	sessions, err := s.repo.ListSessions(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, session := range sessions {
+		session.Current = session.ID == currentSessionID
+	}
+
+	return sessions, nil
+}
+
+// RevokeSession logs one of the user's sessions out.
+func (s *AuthService) RevokeSession(claims *jwt.Claims, sessionID string) error {
+	return s.repo.RevokeSession(claims.UserID, sessionID)
+}
+
+// RevokeAllOtherSessions logs out every session of the user except the one
+// the claims belong to and returns how many were revoked.
+func (s *AuthService) RevokeAllOtherSessions(claims *jwt.Claims) (int, error) {
+	if claims.SessionID == "" {
+		return 0, fmt.Errorf("token is not bound to a session")
+	}
+
+	return s.repo.RevokeOtherSessions(claims.UserID, claims.SessionID)
+}
+
+// startSession starts a session for a user who has logged in and issues its
+// first token pair.
+func (s *AuthService) startSession(userID, username string, client models.ClientInfo) (*models.TokenPair, error) {
+	sessionID := uuid.New().String()
+	if err := s.repo.CreateSession(sessionID, userID, client); err != nil {
+		return nil, err
+	}
+
+	return s.issue(userID, username, sessionID)
+}
+
+// issue generates a token pair for a session. The session's ID doubles as
+// its refresh token family. Roles are read afresh, so role changes reach a
+// session at its next refresh.
+func (s *AuthService) issue(userID, username, sessionID string) (*models.TokenPair, error) {
+	roles, scopes, err := s.repo.GetUserRoles(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	accessToken, claims, err := s.jwtManager.Generate(userID, username, sessionID, roles, scopes)
+	if err != nil {
+		return nil, err
+	}
+
+	refreshToken := uuid.New().String()
+	refreshExpiresAt := time.Now().Add(refreshTokenDuration)
+
+	if err := s.repo.StoreRefreshToken(userID, sessionID, refreshToken, refreshExpiresAt, claims.ID, claims.ExpiresAt.Time); err != nil {
+		return nil, err
+	}
+
+	return &models.TokenPair{
+		AccessToken:  accessToken,
+		RefreshToken: refreshToken,
+		ExpiresAt:    claims.ExpiresAt.Time,
+	}, nil
+}
@@ -0,0 +1,228 @@
+package service
+
+import (
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/todo/pkg/auth"
+	"github.com/todo/services/auth-service/internal/jwt"
+	"github.com/todo/services/auth-service/internal/models"
+)
+
+// Codes that tell clients why a login was refused. A locked username is
+// reported whether or not it exists, so the codes don't reveal which
+// usernames do.
+const (
+	LoginErrorInvalidCredentials = "invalid_credentials"
+	LoginErrorInvalidCode        = "invalid_code"
+	LoginErrorTooManyAttempts    = "too_many_attempts"
+	LoginErrorAccountLocked      = "account_locked"
+)
+
+const (
+	// LoginFailureWindow is how long a failed login counts against a username
+	// or an IP address.
+	LoginFailureWindow = 15 * time.Minute
+
+	loginLockoutDuration = 15 * time.Minute
+
+	maxLoginDelay = time.Minute
+)
+
+// loginLimit is the policy for one kind of login failure count. From
+// delayAfter failures on, each attempt has to wait twice as long after the
+// last failure as the one before; at lockAfter failures logins are refused
+// for loginLockoutDuration.
+type loginLimit struct {
+	kind       string
+	delayAfter int
+	lockAfter  int
+}
+
+var (
+	usernameLoginLimit = loginLimit{kind: models.LoginFailureUsername, delayAfter: 3, lockAfter: 10}
+
+	// Addresses get more room, since many users can share one behind NAT
+	ipAddressLoginLimit = loginLimit{kind: models.LoginFailureIPAddress, delayAfter: 20, lockAfter: 100}
+)
+
+// LoginError is a refused login. RetryAfter is set when trying again later
+// can succeed.
+type LoginError struct {
+	Code       string
+	Message    string
+	RetryAfter time.Duration
+}
+
+func (e *LoginError) Error() string {
+	return e.Message
+}
+
+// ListLoginLockouts returns the usernames and IP addresses that are locked
+// out. It takes the users:write scope.
+func (s *AuthService) ListLoginLockouts(claims *jwt.Claims) ([]*models.LoginFailures, error) {
+	if !contains(claims.Scopes, auth.ScopeUsersWrite) {
+		return nil, auth.ErrForbidden
+	}
+
+	return s.repo.ListLoginLockouts(time.Now())
+}
+
+// UnlockLogin lifts the lockout of a username or of an IP address, whichever
+// is given, and forgets its failed logins. It takes the users:write scope.
+func (s *AuthService) UnlockLogin(claims *jwt.Claims, username, ipAddress string) error {
+	if !contains(claims.Scopes, auth.ScopeUsersWrite) {
+		return auth.ErrForbidden
+	}
+
+	kind, value := models.LoginFailureUsername, username
+	if ipAddress != "" {
+		kind, value = models.LoginFailureIPAddress, ipAddress
+	}
+	if (username == "") == (ipAddress == "") {
+		return fmt.Errorf("exactly one of username and ip_address is required")
+	}
+
+	cleared, err := s.repo.ClearLoginFailures(kind, value)
+	if err != nil {
+		return err
+	}
+	if !cleared {
+		return fmt.Errorf("no failed logins for %s %s", kind, value)
+	}
+
+	log.Printf("SECURITY: %s cleared failed logins for %s %s", claims.Username, kind, value)
+	return nil
+}
+
+// loginAttempt is a login attempt counted against one limit.
+type loginAttempt struct {
+	limit    loginLimit
+	value    string
+	failures *models.LoginFailures
+}
+
+// beginLoginAttempt counts a login attempt against the username and the
+// client's address before the credentials are checked, refusing it while
+// either is locked out or has to wait after recent failures. Checking and
+// counting together means parallel guesses can't all pass the check before
+// any of them is counted. An attempt that succeeds is taken back with
+// forgiveLoginAttempt; one that fails with failLoginAttempt.
+func (s *AuthService) beginLoginAttempt(username string, client models.ClientInfo) ([]loginAttempt, error) {
+	now := time.Now()
+
+	var attempts []loginAttempt
+	for _, limit := range []loginLimit{usernameLoginLimit, ipAddressLoginLimit} {
+		value := limit.value(username, client)
+		if value == "" {
+			continue
+		}
+
+		failures, err := s.repo.CountLoginAttempt(limit.kind, value, now, now.Add(-LoginFailureWindow), func(failures *models.LoginFailures) error {
+			return limit.allow(failures, now)
+		})
+		if err != nil {
+			// Don't hold an attempt that was never made against the others
+			if forgiveErr := s.forgiveLoginAttempt(attempts); forgiveErr != nil {
+				return nil, forgiveErr
+			}
+			return nil, err
+		}
+
+		attempts = append(attempts, loginAttempt{limit: limit, value: value, failures: failures})
+	}
+
+	return attempts, nil
+}
+
+// failLoginAttempt locks out the username or the client's address once a
+// failed attempt has brought it to its limit.
+func (s *AuthService) failLoginAttempt(attempts []loginAttempt) error {
+	now := time.Now()
+	for _, attempt := range attempts {
+		if attempt.failures.Failures < attempt.limit.lockAfter || attempt.failures.LockedUntil != nil {
+			continue
+		}
+
+		if err := s.repo.LockLogin(attempt.limit.kind, attempt.value, now.Add(loginLockoutDuration)); err != nil {
+			return err
+		}
+		log.Printf("SECURITY: locked out %s %s after %d failed logins", attempt.limit.kind, attempt.value, attempt.failures.Failures)
+	}
+
+	return nil
+}
+
+// forgiveLoginAttempt takes back the counts of an attempt with the right
+// credentials.
+func (s *AuthService) forgiveLoginAttempt(attempts []loginAttempt) error {
+	for _, attempt := range attempts {
+		if err := s.repo.ForgiveLoginAttempt(attempt.limit.kind, attempt.value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// clearLoginFailures forgets the failed logins of a username once someone
+// logs in with it. Those of the address stay, so that logging in to one
+// account doesn't reset guessing at others.
+func (s *AuthService) clearLoginFailures(username string) error {
+	_, err := s.repo.ClearLoginFailures(models.LoginFailureUsername, username)
+	return err
+}
+
+func (l loginLimit) value(username string, client models.ClientInfo) string {
+	if l.kind == models.LoginFailureUsername {
+		return username
+	}
+	return client.IPAddress
+}
+
+// allow refuses a login attempt while the limit's value is locked out or has
+// to wait after the given failures.
+func (l loginLimit) allow(failures *models.LoginFailures, now time.Time) error {
+	if failures.LockedUntil != nil && now.Before(*failures.LockedUntil) {
+		return l.lockedError(failures.LockedUntil.Sub(now))
+	}
+
+	if next := l.nextAttempt(failures); now.Before(next) {
+		return &LoginError{
+			Code:       LoginErrorTooManyAttempts,
+			Message:    "too many failed login attempts, try again later",
+			RetryAfter: next.Sub(now),
+		}
+	}
+
+	return nil
+}
+
+// nextAttempt returns when the next login may be tried after the given
+// failures.
+func (l loginLimit) nextAttempt(failures *models.LoginFailures) time.Time {
+	if failures.Failures < l.delayAfter || time.Since(failures.LastFailedAt) >= LoginFailureWindow {
+		return time.Time{}
+	}
+
+	delay := time.Second << (failures.Failures - l.delayAfter)
+	if delay <= 0 || delay > maxLoginDelay {
+		delay = maxLoginDelay
+	}
+	return failures.LastFailedAt.Add(delay)
+}
+
+func (l loginLimit) lockedError(retryAfter time.Duration) *LoginError {
+	if l.kind == models.LoginFailureUsername {
+		return &LoginError{
+			Code:       LoginErrorAccountLocked,
+			Message:    "account is temporarily locked after too many failed login attempts",
+			RetryAfter: retryAfter,
+		}
+	}
+	return &LoginError{
+		Code:       LoginErrorTooManyAttempts,
+		Message:    "too many failed login attempts, try again later",
+		RetryAfter: retryAfter,
+	}
+}
@@ -0,0 +1,60 @@
+package service
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/todo/services/auth-service/internal/models"
+)
+
+func TestLoginLimitAllow(t *testing.T) {
+	now := time.Now()
+	lockedUntil := now.Add(10 * time.Minute)
+	lockExpired := now.Add(-time.Second)
+
+	tests := []struct {
+		name           string
+		limit          loginLimit
+		failures       models.LoginFailures
+		wantCode       string
+		wantRetryAfter time.Duration
+	}{
+		{name: "no failures", limit: usernameLoginLimit},
+		{name: "below the delay", limit: usernameLoginLimit, failures: models.LoginFailures{Failures: 2, LastFailedAt: now}},
+		{name: "first delay", limit: usernameLoginLimit, failures: models.LoginFailures{Failures: 3, LastFailedAt: now}, wantCode: LoginErrorTooManyAttempts, wantRetryAfter: time.Second},
+		{name: "delay has passed", limit: usernameLoginLimit, failures: models.LoginFailures{Failures: 3, LastFailedAt: now.Add(-2 * time.Second)}},
+		{name: "delay doubles", limit: usernameLoginLimit, failures: models.LoginFailures{Failures: 5, LastFailedAt: now}, wantCode: LoginErrorTooManyAttempts, wantRetryAfter: 4 * time.Second},
+		{name: "delay is capped", limit: usernameLoginLimit, failures: models.LoginFailures{Failures: 9, LastFailedAt: now}, wantCode: LoginErrorTooManyAttempts, wantRetryAfter: maxLoginDelay},
+		{name: "delay doesn't overflow", limit: usernameLoginLimit, failures: models.LoginFailures{Failures: 200, LastFailedAt: now}, wantCode: LoginErrorTooManyAttempts, wantRetryAfter: maxLoginDelay},
+		{name: "failures outside the window", limit: usernameLoginLimit, failures: models.LoginFailures{Failures: 9, LastFailedAt: now.Add(-LoginFailureWindow)}},
+		{name: "address has more room", limit: ipAddressLoginLimit, failures: models.LoginFailures{Failures: 19, LastFailedAt: now}},
+		{name: "locked username", limit: usernameLoginLimit, failures: models.LoginFailures{Failures: 10, LastFailedAt: now, LockedUntil: &lockedUntil}, wantCode: LoginErrorAccountLocked, wantRetryAfter: 10 * time.Minute},
+		{name: "locked address", limit: ipAddressLoginLimit, failures: models.LoginFailures{Failures: 100, LastFailedAt: now, LockedUntil: &lockedUntil}, wantCode: LoginErrorTooManyAttempts, wantRetryAfter: 10 * time.Minute},
+		{name: "lockout has run out", limit: usernameLoginLimit, failures: models.LoginFailures{Failures: 10, LastFailedAt: now.Add(-LoginFailureWindow), LockedUntil: &lockExpired}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.limit.allow(&tt.failures, now)
+			if tt.wantCode == "" {
+				if err != nil {
+					t.Fatalf("allow: got %v, want nil", err)
+				}
+				return
+			}
+
+			var loginErr *LoginError
+			if !errors.As(err, &loginErr) {
+				t.Fatalf("allow: got %v, want a LoginError", err)
+			}
+			if loginErr.Code != tt.wantCode {
+				t.Errorf("got code %q, want %q", loginErr.Code, tt.wantCode)
+			}
+			// nextAttempt measures the window from the real clock
+			if diff := loginErr.RetryAfter - tt.wantRetryAfter; diff > time.Second || diff < -time.Second {
+				t.Errorf("got retry after %v, want %v", loginErr.RetryAfter, tt.wantRetryAfter)
+			}
+		})
+	}
+}
@@ -0,0 +1,294 @@
+package service
+
+import (
+	"crypto/rand"
+	"encoding/base32"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/todo/services/auth-service/internal/jwt"
+	"github.com/todo/services/auth-service/internal/models"
+	"github.com/todo/services/auth-service/internal/totp"
+)
+
+const (
+	mfaChallengeDuration = 5 * time.Minute
+
+	// maxMFAAttempts is how many wrong codes a challenge takes before it is
+	// discarded and the user has to log in again.
+	maxMFAAttempts = 5
+
+	recoveryCodeCount = 10
+)
+
+// VerifyMFA answers the MFA challenge of a login with a code from the user's
+// authenticator app or one of their recovery codes, and then finishes the
+// login like Login does. Wrong codes count as failed logins, so that getting
+// new challenges doesn't give unlimited guesses.
+func (s *AuthService) VerifyMFA(mfaToken, code string, client models.ClientInfo) (*models.TokenPair, error) {
+	userID, err := s.repo.GetMFAChallenge(mfaToken)
+	if err != nil {
+		return nil, err
+	}
+
+	username, err := s.repo.GetUsername(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	attempts, err := s.beginLoginAttempt(username, client)
+	if err != nil {
+		return nil, err
+	}
+
+	ok, err := s.checkSecondFactor(userID, code)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		if err := s.repo.FailMFAChallenge(mfaToken, maxMFAAttempts); err != nil {
+			return nil, err
+		}
+		if err := s.failLoginAttempt(attempts); err != nil {
+			return nil, err
+		}
+		return nil, &LoginError{
+			Code:    LoginErrorInvalidCode,
+			Message: "invalid code",
+		}
+	}
+
+	if err := s.forgiveLoginAttempt(attempts); err != nil {
+		return nil, err
+	}
+
+	// A challenge is answered once, even if two correct answers race
+	consumed, err := s.repo.ConsumeMFAChallenge(mfaToken)
+	if err != nil {
+		return nil, err
+	}
+	if !consumed {
+		return nil, fmt.Errorf("invalid or expired MFA token")
+	}
+
+	if err := s.clearLoginFailures(username); err != nil {
+		return nil, err
+	}
+
+	return s.startSession(userID, username, client)
+}
+
+// MFAStatus returns the second factors the user has set up.
+func (s *AuthService) MFAStatus(userID string) (*models.MFAStatus, error) {
+	factor, err := s.repo.GetTOTPFactor(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	remaining, err := s.repo.CountRecoveryCodes(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.MFAStatus{
+		TOTPEnabled:            factor != nil && factor.ConfirmedAt != nil,
+		RecoveryCodesRemaining: remaining,
+	}, nil
+}
+
+// EnrollTOTP generates a new authenticator secret for the user the claims
+// belong to. It takes effect once confirmed with ConfirmTOTP; enrolling again
+// before that replaces the secret.
+func (s *AuthService) EnrollTOTP(claims *jwt.Claims) (*models.TOTPEnrollment, error) {
+	if err := requireLogin(claims); err != nil {
+		return nil, err
+	}
+
+	secret, err := totp.GenerateSecret()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.SavePendingTOTPFactor(claims.UserID, secret); err != nil {
+		return nil, err
+	}
+
+	return &models.TOTPEnrollment{
+		Secret: secret,
+		URI:    totp.URI(s.totpIssuer, claims.Username, secret),
+	}, nil
+}
+
+// ConfirmTOTP enables two-factor authentication with a code from the newly
+// enrolled authenticator and returns the user's recovery codes, which are
+// not shown again.
+func (s *AuthService) ConfirmTOTP(claims *jwt.Claims, code string) ([]string, error) {
+	if err := requireLogin(claims); err != nil {
+		return nil, err
+	}
+
+	factor, err := s.repo.GetTOTPFactor(claims.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if factor == nil || factor.ConfirmedAt != nil {
+		return nil, fmt.Errorf("no pending two-factor enrollment")
+	}
+
+	step, ok := totp.Validate(factor.Secret, normalizeCode(code), time.Now())
+	if !ok {
+		return nil, fmt.Errorf("invalid code")
+	}
+
+	codes, err := generateRecoveryCodes()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.ConfirmTOTPFactor(claims.UserID, step, hashableRecoveryCodes(codes)); err != nil {
+		return nil, err
+	}
+
+	return codes, nil
+}
+
+// DisableTOTP turns two-factor authentication off. It takes a current code,
+// or a recovery code, so that a stolen access token is not enough.
+func (s *AuthService) DisableTOTP(claims *jwt.Claims, code string) error {
+	if err := requireLogin(claims); err != nil {
+		return err
+	}
+
+	factor, err := s.repo.GetTOTPFactor(claims.UserID)
+	if err != nil {
+		return err
+	}
+	if factor == nil {
+		return fmt.Errorf("two-factor authentication is not enabled")
+	}
+
+	// A factor that was never confirmed guards nothing yet
+	if factor.ConfirmedAt != nil {
+		ok, err := s.checkSecondFactor(claims.UserID, code)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return fmt.Errorf("invalid code")
+		}
+	}
+
+	return s.repo.DeleteTOTPFactor(claims.UserID)
+}
+
+// RegenerateRecoveryCodes replaces the user's recovery codes, given a current
+// code, and returns the new ones.
+func (s *AuthService) RegenerateRecoveryCodes(claims *jwt.Claims, code string) ([]string, error) {
+	if err := requireLogin(claims); err != nil {
+		return nil, err
+	}
+
+	factor, err := s.repo.GetTOTPFactor(claims.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if factor == nil || factor.ConfirmedAt == nil {
+		return nil, fmt.Errorf("two-factor authentication is not enabled")
+	}
+
+	ok, err := s.checkSecondFactor(claims.UserID, code)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, fmt.Errorf("invalid code")
+	}
+
+	codes, err := generateRecoveryCodes()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.ReplaceRecoveryCodes(claims.UserID, hashableRecoveryCodes(codes)); err != nil {
+		return nil, err
+	}
+
+	return codes, nil
+}
+
+// challenge starts an MFA challenge for a user whose password checked out.
+func (s *AuthService) challenge(userID string) (*models.MFAChallenge, error) {
+	challenge := &models.MFAChallenge{
+		Token:     uuid.New().String(),
+		ExpiresAt: time.Now().Add(mfaChallengeDuration),
+	}
+
+	if err := s.repo.CreateMFAChallenge(challenge.Token, userID, challenge.ExpiresAt); err != nil {
+		return nil, err
+	}
+
+	return challenge, nil
+}
+
+// checkSecondFactor checks a code from the user's authenticator app, or
+// failing that one of their recovery codes, and uses it up.
+func (s *AuthService) checkSecondFactor(userID, code string) (bool, error) {
+	factor, err := s.repo.GetTOTPFactor(userID)
+	if err != nil {
+		return false, err
+	}
+	if factor == nil || factor.ConfirmedAt == nil {
+		return false, nil
+	}
+
+	code = normalizeCode(code)
+	if step, ok := totp.Validate(factor.Secret, code, time.Now()); ok {
+		return s.repo.UseTOTPStep(userID, step)
+	}
+
+	return s.repo.UseRecoveryCode(userID, code)
+}
+
+// requireLogin rejects personal access tokens, which must not be able to
+// change how their user logs in.
+func requireLogin(claims *jwt.Claims) error {
+	if _, ok := personalAccessTokenID(claims); ok {
+		return fmt.Errorf("personal access tokens cannot manage two-factor authentication")
+	}
+	return nil
+}
+
+// generateRecoveryCodes returns new recovery codes of 80 random bits each,
+// formatted in groups of four characters for reading them out.
+func generateRecoveryCodes() ([]string, error) {
+	encoding := base32.StdEncoding.WithPadding(base32.NoPadding)
+
+	codes := make([]string, recoveryCodeCount)
+	for i := range codes {
+		raw := make([]byte, 10)
+		if _, err := rand.Read(raw); err != nil {
+			return nil, err
+		}
+
+		code := strings.ToLower(encoding.EncodeToString(raw))
+		codes[i] = code[0:4] + "-" + code[4:8] + "-" + code[8:12] + "-" + code[12:16]
+	}
+	return codes, nil
+}
+
+// hashableRecoveryCodes normalizes recovery codes the way codes typed in are
+// normalized before they are looked up.
+func hashableRecoveryCodes(codes []string) []string {
+	normalized := make([]string, len(codes))
+	for i, code := range codes {
+		normalized[i] = normalizeCode(code)
+	}
+	return normalized
+}
+
+// normalizeCode drops the spaces and dashes users type codes with.
+func normalizeCode(code string) string {
+	return strings.ToLower(strings.NewReplacer(" ", "", "-", "").Replace(code))
+}
@@ -0,0 +1,86 @@
+package service
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func TestNormalizeCode(t *testing.T) {
+	tests := []struct {
+		name string
+		code string
+		want string
+	}{
+		{name: "authenticator code", code: "123456", want: "123456"},
+		{name: "authenticator code with a space", code: "123 456", want: "123456"},
+		{name: "recovery code as shown", code: "abcd-efgh-ijkl-mnop", want: "abcdefghijklmnop"},
+		{name: "recovery code in capitals", code: "ABCD-EFGH-IJKL-MNOP", want: "abcdefghijklmnop"},
+		{name: "recovery code with spaces", code: " abcd efgh ijkl mnop ", want: "abcdefghijklmnop"},
+		{name: "recovery code without separators", code: "abcdefghijklmnop", want: "abcdefghijklmnop"},
+		{name: "empty", code: "", want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := normalizeCode(tt.code); got != tt.want {
+				t.Errorf("normalizeCode(%q) = %q, want %q", tt.code, got, tt.want)
+			}
+		})
+	}
+}
+
+var recoveryCodeRe = regexp.MustCompile(`^[a-z2-7]{4}-[a-z2-7]{4}-[a-z2-7]{4}-[a-z2-7]{4}$`)
+
+func TestGenerateRecoveryCodes(t *testing.T) {
+	codes, err := generateRecoveryCodes()
+	if err != nil {
+		t.Fatalf("generateRecoveryCodes: %v", err)
+	}
+
+	if len(codes) != recoveryCodeCount {
+		t.Fatalf("got %d codes, want %d", len(codes), recoveryCodeCount)
+	}
+
+	seen := make(map[string]bool)
+	for _, code := range codes {
+		if !recoveryCodeRe.MatchString(code) {
+			t.Errorf("code %q is not four groups of four base32 characters", code)
+		}
+		if seen[code] {
+			t.Errorf("code %q was generated twice", code)
+		}
+		seen[code] = true
+	}
+}
+
+func TestHashableRecoveryCodes(t *testing.T) {
+	codes, err := generateRecoveryCodes()
+	if err != nil {
+		t.Fatalf("generateRecoveryCodes: %v", err)
+	}
+
+	hashable := hashableRecoveryCodes(codes)
+	if len(hashable) != len(codes) {
+		t.Fatalf("got %d hashable codes, want %d", len(hashable), len(codes))
+	}
+
+	for i, code := range codes {
+		// However the user types a code in, it must normalize to what was
+		// stored for it
+		typed := []string{
+			code,
+			strings.ToUpper(code),
+			strings.ReplaceAll(code, "-", " "),
+			strings.ReplaceAll(code, "-", ""),
+		}
+		for _, input := range typed {
+			if got := normalizeCode(input); got != hashable[i] {
+				t.Errorf("typed code %q normalizes to %q, stored as %q", input, got, hashable[i])
+			}
+		}
+		if len(hashable[i]) != 16 {
+			t.Errorf("hashable code %q is %d characters, want 16", hashable[i], len(hashable[i]))
+		}
+	}
+}
@@ -0,0 +1,147 @@
+package service
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/todo/pkg/auth"
+	"github.com/todo/services/auth-service/internal/jwt"
+	"github.com/todo/services/auth-service/internal/models"
+)
+
+// personalAccessTokenIDPrefix marks the claims ID of a personal access token,
+// telling its claims apart from those of a JWT.
+const personalAccessTokenIDPrefix = "pat:"
+
+const maxTokenNameLength = 100
+
+// CreatePersonalAccessToken creates a token for the user the claims belong
+// to and returns it with its secret, which is not shown again. The token may
+// carry any owner scope and any of the scopes the user's roles grant. With
+// expiresInDays 0 it never expires.
+func (s *AuthService) CreatePersonalAccessToken(claims *jwt.Claims, name string, scopes []string, expiresInDays int) (*models.PersonalAccessToken, string, error) {
+	if _, ok := personalAccessTokenID(claims); ok {
+		return nil, "", fmt.Errorf("personal access tokens cannot create other tokens")
+	}
+
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return nil, "", fmt.Errorf("name is required")
+	}
+	if len(name) > maxTokenNameLength {
+		return nil, "", fmt.Errorf("name must be at most %d characters", maxTokenNameLength)
+	}
+	if expiresInDays < 0 {
+		return nil, "", fmt.Errorf("expires_in_days must not be negative")
+	}
+
+	_, granted, err := s.repo.GetUserRoles(claims.UserID)
+	if err != nil {
+		return nil, "", err
+	}
+	for _, scope := range scopes {
+		if !grantable(granted, scope) {
+			return nil, "", fmt.Errorf("scope %s is not granted to you", scope)
+		}
+	}
+
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return nil, "", err
+	}
+	token := auth.PersonalAccessTokenPrefix + base64.RawURLEncoding.EncodeToString(secret)
+
+	pat := &models.PersonalAccessToken{
+		ID:        uuid.New().String(),
+		UserID:    claims.UserID,
+		Name:      name,
+		Scopes:    scopes,
+		CreatedAt: time.Now(),
+	}
+	if pat.Scopes == nil {
+		pat.Scopes = []string{}
+	}
+	if expiresInDays > 0 {
+		expiresAt := pat.CreatedAt.Add(time.Duration(expiresInDays) * 24 * time.Hour)
+		pat.ExpiresAt = &expiresAt
+	}
+
+	if err := s.repo.CreatePersonalAccessToken(pat, token); err != nil {
+		return nil, "", err
+	}
+
+	return pat, token, nil
+}
+
+func (s *AuthService) ListPersonalAccessTokens(userID string) ([]*models.PersonalAccessToken, error) {
+	return s.repo.ListPersonalAccessTokens(userID)
+}
+
+// RevokePersonalAccessToken deletes one of the tokens of the user the claims
+// belong to.
+func (s *AuthService) RevokePersonalAccessToken(claims *jwt.Claims, id string) error {
+	return s.repo.DeletePersonalAccessToken(claims.UserID, id)
+}
+
+// validatePersonalAccessToken returns claims for a personal access token as
+// if it were a JWT. Its role scopes are limited to those the user's roles
+// still grant, so revoking a role also takes it away from the user's tokens.
+func (s *AuthService) validatePersonalAccessToken(token string) (*jwt.Claims, error) {
+	pat, username, err := s.repo.GetPersonalAccessToken(token)
+	if err != nil {
+		return nil, err
+	}
+	if pat.ExpiresAt != nil && time.Now().After(*pat.ExpiresAt) {
+		return nil, fmt.Errorf("token has expired")
+	}
+
+	_, granted, err := s.repo.GetUserRoles(pat.UserID)
+	if err != nil {
+		return nil, err
+	}
+	scopes := []string{}
+	for _, scope := range pat.Scopes {
+		if grantable(granted, scope) {
+			scopes = append(scopes, scope)
+		}
+	}
+
+	if err := s.repo.TouchPersonalAccessToken(pat.ID); err != nil {
+		return nil, err
+	}
+
+	return jwt.NewClaims(personalAccessTokenIDPrefix+pat.ID, pat.UserID, username, scopes, pat.CreatedAt, pat.ExpiresAt), nil
+}
+
+// personalAccessTokenID returns the ID of the personal access token the
+// claims belong to, if they belong to one.
+func personalAccessTokenID(claims *jwt.Claims) (string, bool) {
+	return strings.CutPrefix(claims.ID, personalAccessTokenIDPrefix)
+}
+
+// IsPersonalAccessToken reports whether the claims belong to a personal
+// access token.
+func IsPersonalAccessToken(claims *jwt.Claims) bool {
+	_, ok := personalAccessTokenID(claims)
+	return ok
+}
+
+// grantable reports whether a personal access token of a user whose roles
+// grant the scopes granted may carry scope. Owner scopes only cover the
+// user's own data, so every user may grant them.
+func grantable(granted []string, scope string) bool {
+	return contains(auth.OwnerScopes, scope) || contains(granted, scope)
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
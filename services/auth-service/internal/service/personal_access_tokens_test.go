@@ -0,0 +1,33 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/todo/pkg/auth"
+)
+
+func TestGrantable(t *testing.T) {
+	support := []string{auth.ScopeUsersRead, auth.ScopeTasksRead}
+
+	tests := []struct {
+		name    string
+		granted []string
+		scope   string
+		want    bool
+	}{
+		{name: "owner scope without roles", granted: nil, scope: auth.ScopeOwnTasksRead, want: true},
+		{name: "every owner scope without roles", granted: nil, scope: auth.ScopeOwnUsersDelete, want: true},
+		{name: "role scope without roles", granted: nil, scope: auth.ScopeTasksRead, want: false},
+		{name: "role scope the roles grant", granted: support, scope: auth.ScopeTasksRead, want: true},
+		{name: "role scope the roles don't grant", granted: support, scope: auth.ScopeTasksWrite, want: false},
+		{name: "unknown scope", granted: auth.AllScopes, scope: "own:roles:manage", want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := grantable(tt.granted, tt.scope); got != tt.want {
+				t.Errorf("grantable(%v, %q) = %v, want %v", tt.granted, tt.scope, got, tt.want)
+			}
+		})
+	}
+}
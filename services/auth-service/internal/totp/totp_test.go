@@ -0,0 +1,131 @@
+package totp
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+// rfcSecret is the SHA-1 seed of the RFC 6238 test vectors,
+// "12345678901234567890", base32 encoded.
+const rfcSecret = "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+
+// rfcVectors are the SHA-1 test vectors of RFC 6238, appendix B, cut down
+// to the six digits authenticator apps show.
+var rfcVectors = []struct {
+	unix int64
+	code string
+}{
+	{unix: 59, code: "287082"},
+	{unix: 1111111109, code: "081804"},
+	{unix: 1111111111, code: "050471"},
+	{unix: 1234567890, code: "005924"},
+	{unix: 2000000000, code: "279037"},
+	{unix: 20000000000, code: "353130"},
+}
+
+func TestGenerateRFC6238(t *testing.T) {
+	key := []byte("12345678901234567890")
+	for _, v := range rfcVectors {
+		if got := generate(key, v.unix/30); got != v.code {
+			t.Errorf("generate at %d = %s, want %s", v.unix, got, v.code)
+		}
+	}
+}
+
+func TestValidateRFC6238(t *testing.T) {
+	for _, v := range rfcVectors {
+		step, ok := Validate(rfcSecret, v.code, time.Unix(v.unix, 0))
+		if !ok {
+			t.Errorf("Validate(%s) at %d rejected the code", v.code, v.unix)
+			continue
+		}
+		if step != v.unix/30 {
+			t.Errorf("Validate(%s) at %d matched step %d, want %d", v.code, v.unix, step, v.unix/30)
+		}
+	}
+}
+
+func TestValidateSkew(t *testing.T) {
+	// The code of step 37037036 is 081804 (unix 1111111080 to 1111111109)
+	const code = "081804"
+	const step = 37037036
+	start := time.Unix(step*30, 0)
+
+	tests := []struct {
+		name  string
+		now   time.Time
+		valid bool
+	}{
+		{name: "start of its period", now: start, valid: true},
+		{name: "end of its period", now: start.Add(29 * time.Second), valid: true},
+		{name: "one period late", now: start.Add(30 * time.Second), valid: true},
+		{name: "end of one period late", now: start.Add(59 * time.Second), valid: true},
+		{name: "one period early", now: start.Add(-30 * time.Second), valid: true},
+		{name: "two periods late", now: start.Add(60 * time.Second), valid: false},
+		{name: "two periods early", now: start.Add(-31 * time.Second), valid: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := Validate(rfcSecret, code, tt.now)
+			if ok != tt.valid {
+				t.Fatalf("Validate at %d = %v, want %v", tt.now.Unix(), ok, tt.valid)
+			}
+			if ok && got != step {
+				t.Errorf("Validate at %d matched step %d, want %d", tt.now.Unix(), got, step)
+			}
+		})
+	}
+}
+
+func TestValidateRejects(t *testing.T) {
+	now := time.Unix(1111111109, 0)
+
+	tests := []struct {
+		name   string
+		secret string
+		code   string
+	}{
+		{name: "wrong code", secret: rfcSecret, code: "123456"},
+		{name: "eight digit code", secret: rfcSecret, code: "07081804"},
+		{name: "short code", secret: rfcSecret, code: "08180"},
+		{name: "empty code", secret: rfcSecret, code: ""},
+		{name: "secret that isn't base32", secret: "not base32!", code: "081804"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, ok := Validate(tt.secret, tt.code, now); ok {
+				t.Errorf("Validate(%q, %q) accepted the code", tt.secret, tt.code)
+			}
+		})
+	}
+}
+
+func TestValidateLowercaseSecret(t *testing.T) {
+	if _, ok := Validate(strings.ToLower(rfcSecret), "081804", time.Unix(1111111109, 0)); !ok {
+		t.Error("Validate rejected a lowercase secret")
+	}
+}
+
+func TestGenerateSecret(t *testing.T) {
+	secret, err := GenerateSecret()
+	if err != nil {
+		t.Fatalf("GenerateSecret: %v", err)
+	}
+
+	key, err := encoding.DecodeString(secret)
+	if err != nil {
+		t.Fatalf("secret %q is not base32: %v", secret, err)
+	}
+	if len(key) != 20 {
+		t.Errorf("secret is %d bytes, want 20", len(key))
+	}
+
+	now := time.Now()
+	code := generate(key, now.Unix()/30)
+	if _, ok := Validate(secret, code, now); !ok {
+		t.Errorf("Validate rejected the current code %s for a generated secret", code)
+	}
+}
@@ -8,7 +8,10 @@ import (
 	"os"
 
 	"github.com/gorilla/mux"
+	"github.com/todo/pkg/auth"
+	"github.com/todo/pkg/events"
 	pb "github.com/todo/proto/notification"
+	"github.com/todo/services/notification-service/internal/consumer"
 	"github.com/todo/services/notification-service/internal/email"
 	grpcServer "github.com/todo/services/notification-service/internal/grpc"
 	httpHandler "github.com/todo/services/notification-service/internal/http"
@@ -27,6 +30,12 @@ func main() {
 	grpcPort := getEnv("GRPC_PORT", "50054")
 	httpPort := getEnv("HTTP_PORT", "8084")
 	pushAPIKey := getEnv("PUSH_API_KEY", "")
+	authServiceAddr := getEnv("AUTH_SERVICE_ADDR", "localhost:50052")
+	authJWKSURL := getEnv("AUTH_JWKS_URL", "")
+	serviceToken := getEnv("SERVICE_TOKEN", "")
+	if err := auth.CheckServiceToken(serviceToken); err != nil {
+		log.Fatalf("Refusing to start: %v", err)
+	}
 
 	// Connect to database
 	connStr := fmt.Sprintf("host=%s port=%s user=%s password=%s dbname=%s sslmode=disable",
@@ -38,6 +47,12 @@ func main() {
 	}
 	defer repo.Close()
 
+	// Authenticate callers with auth-service tokens or the shared service token
+	validator, err := auth.NewValidator(authJWKSURL, authServiceAddr, serviceToken)
+	if err != nil {
+		log.Fatalf("Failed to create token validator: %v", err)
+	}
+
 	// Create email and push senders
 	emailSender := email.NewEmailSender()
 	pushSender := push.NewPushSender(pushAPIKey)
@@ -49,7 +64,10 @@ func main() {
 			log.Fatalf("Failed to listen on gRPC port: %v", err)
 		}
 
-		s := grpc.NewServer()
+		s := grpc.NewServer(
+			grpc.UnaryInterceptor(auth.UnaryServerInterceptor(validator)),
+			grpc.StreamInterceptor(auth.StreamServerInterceptor(validator)),
+		)
 		pb.RegisterNotificationServiceServer(s, grpcServer.NewNotificationServer(repo, emailSender, pushSender))
 
 		log.Printf("gRPC server listening on :%s", grpcPort)
@@ -63,6 +81,15 @@ func main() {
 	handler := httpHandler.NewHandler(repo, emailSender, pushSender)
 	handler.RegisterRoutes(router)
 
+	// Receive domain events published by other services, which send the
+	// service token
+	eventConsumer, err := events.NewConsumer(repo.DB())
+	if err != nil {
+		log.Fatalf("Failed to create event consumer: %v", err)
+	}
+	consumer.NewHandlers(repo, emailSender).Register(eventConsumer)
+	router.Handle("/api/events", auth.ServiceMiddleware(validator)(eventConsumer)).Methods("POST")
+
 	log.Printf("HTTP server listening on :%s", httpPort)
 	if err := http.ListenAndServe(":"+httpPort, router); err != nil {
 		log.Fatalf("Failed to serve HTTP: %v", err)
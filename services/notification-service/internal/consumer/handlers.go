@@ -0,0 +1,154 @@
+package consumer
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+
+	"github.com/todo/pkg/events"
+	"github.com/todo/services/notification-service/internal/email"
+	"github.com/todo/services/notification-service/internal/models"
+	"github.com/todo/services/notification-service/internal/repository"
+)
+
+type Handlers struct {
+	repo        *repository.PostgresRepository
+	emailSender *email.EmailSender
+}
+
+func NewHandlers(repo *repository.PostgresRepository, emailSender *email.EmailSender) *Handlers {
+	return &Handlers{
+		repo:        repo,
+		emailSender: emailSender,
+	}
+}
+
+// Register subscribes the handlers. Their names record which events each has
+// processed and must not change.
+func (h *Handlers) Register(consumer *events.Consumer) {
+	consumer.Handle(events.UserCreated, "user_contact", h.UserContact)
+	consumer.Handle(events.UserCreated, "welcome_email", h.WelcomeEmail)
+	consumer.Handle(events.UserUpdated, "user_contact", h.UserContact)
+	consumer.Handle(events.TaskCompleted, "task_completed_email", h.TaskCompleted)
+	consumer.Handle(events.TaskWoken, "task_woken_email", h.TaskWoken)
+	consumer.Handle(events.TaskEscalated, "task_escalated_email", h.TaskEscalated)
+	consumer.Handle(events.TaskNotified, "task_notified_email", h.TaskNotified)
+}
+
+// UserContact keeps the contact details of a created or updated user.
+func (h *Handlers) UserContact(tx *sql.Tx, event *events.Event) error {
+	var user events.UserPayload
+	if err := event.Decode(&user); err != nil {
+		return err
+	}
+
+	return h.repo.WithTx(tx).UpsertUserContact(user.ID, user.Username, user.Email)
+}
+
+func (h *Handlers) WelcomeEmail(tx *sql.Tx, event *events.Event) error {
+	var user events.UserPayload
+	if err := event.Decode(&user); err != nil {
+		return err
+	}
+
+	subject := "Welcome to Todo"
+	body := fmt.Sprintf("Hi %s, your account has been created.", user.Username)
+	return h.send(h.repo.WithTx(tx), user.ID, user.Email, subject, body)
+}
+
+func (h *Handlers) TaskCompleted(tx *sql.Tx, event *events.Event) error {
+	var task events.TaskPayload
+	if err := event.Decode(&task); err != nil {
+		return err
+	}
+
+	repo := h.repo.WithTx(tx)
+	contact, err := repo.GetUserContact(task.UserID)
+	if err != nil {
+		// Nothing to deliver to; don't block the event stream on it
+		log.Printf("Skipping TaskCompleted notification for task %s: %v", task.ID, err)
+		return nil
+	}
+
+	subject := "Task Completed"
+	body := fmt.Sprintf("Your task \"%s\" has been marked as completed.", task.Title)
+	return h.send(repo, task.UserID, contact.Email, subject, body)
+}
+
+func (h *Handlers) TaskWoken(tx *sql.Tx, event *events.Event) error {
+	var task events.TaskPayload
+	if err := event.Decode(&task); err != nil {
+		return err
+	}
+
+	repo := h.repo.WithTx(tx)
+	contact, err := repo.GetUserContact(task.UserID)
+	if err != nil {
+		log.Printf("Skipping TaskWoken notification for task %s: %v", task.ID, err)
+		return nil
+	}
+
+	subject := "Snoozed Task Is Back"
+	body := fmt.Sprintf("Your snoozed task \"%s\" is back on your list.", task.Title)
+	return h.send(repo, task.UserID, contact.Email, subject, body)
+}
+
+// TaskEscalated emails the watchers or manager an overdue task escalates to.
+func (h *Handlers) TaskEscalated(tx *sql.Tx, event *events.Event) error {
+	var escalation events.TaskEscalatedPayload
+	if err := event.Decode(&escalation); err != nil {
+		return err
+	}
+
+	repo := h.repo.WithTx(tx)
+	task := escalation.Task
+	subject := "Overdue Task Escalated"
+	body := fmt.Sprintf("The %s task \"%s\" has been overdue for %d hours.", task.Priority, task.Title, escalation.HoursOverdue)
+
+	for _, userID := range escalation.RecipientIDs {
+		contact, err := repo.GetUserContact(userID)
+		if err != nil {
+			log.Printf("Skipping TaskEscalated notification for user %s: %v", userID, err)
+			continue
+		}
+		if err := h.send(repo, userID, contact.Email, subject, body); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// TaskNotified emails the message an automation rule sent about a task.
+func (h *Handlers) TaskNotified(tx *sql.Tx, event *events.Event) error {
+	var notification events.TaskNotificationPayload
+	if err := event.Decode(&notification); err != nil {
+		return err
+	}
+
+	repo := h.repo.WithTx(tx)
+	for _, userID := range notification.RecipientIDs {
+		contact, err := repo.GetUserContact(userID)
+		if err != nil {
+			log.Printf("Skipping TaskNotified notification for user %s: %v", userID, err)
+			continue
+		}
+		if err := h.send(repo, userID, contact.Email, notification.Subject, notification.Message); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// send delivers an email and records the outcome in repo's transaction.
+// Delivery failures are recorded rather than returned so the event is not
+// redelivered forever; failing to record one is returned.
+func (h *Handlers) send(repo *repository.PostgresRepository, userID, to, subject, body string) error {
+	err := h.emailSender.SendEmail(to, subject, body)
+	if err != nil {
+		log.Printf("Failed to send email to %s: %v", to, err)
+	}
+	_, err = repo.SaveNotification(userID, models.TypeEmail, to, subject, body, err == nil)
+	return err
+}
@@ -2,8 +2,10 @@ package grpc
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 
+	"github.com/todo/pkg/auth"
 	pb "github.com/todo/proto/notification"
 	"github.com/todo/services/notification-service/internal/email"
 	"github.com/todo/services/notification-service/internal/models"
@@ -30,7 +32,7 @@ func (s *NotificationServer) SendEmail(ctx context.Context, req *pb.SendEmailReq
 	err := s.emailSender.SendEmail(req.To, req.Subject, req.Body)
 	if err != nil {
 		// Save failed notification
-		s.repo.SaveNotification(models.TypeEmail, req.To, req.Subject, req.Body, false)
+		s.repo.SaveNotification("", models.TypeEmail, req.To, req.Subject, req.Body, false)
 		return &pb.SendEmailResponse{
 			Success: false,
 			Error:   err.Error(),
@@ -38,7 +40,7 @@ func (s *NotificationServer) SendEmail(ctx context.Context, req *pb.SendEmailReq
 	}
 
 	// Save successful notification
-	s.repo.SaveNotification(models.TypeEmail, req.To, req.Subject, req.Body, true)
+	s.repo.SaveNotification("", models.TypeEmail, req.To, req.Subject, req.Body, true)
 
 	return &pb.SendEmailResponse{
 		Success: true,
@@ -49,7 +51,7 @@ func (s *NotificationServer) SendPushNotification(ctx context.Context, req *pb.S
 	err := s.pushSender.SendPushNotification(req.DeviceToken, req.Title, req.Body)
 	if err != nil {
 		// Save failed notification
-		s.repo.SaveNotification(models.TypePush, req.DeviceToken, req.Title, req.Body, false)
+		s.repo.SaveNotification(req.UserId, models.TypePush, req.DeviceToken, req.Title, req.Body, false)
 		return &pb.SendPushNotificationResponse{
 			Success: false,
 			Error:   err.Error(),
@@ -57,7 +59,7 @@ func (s *NotificationServer) SendPushNotification(ctx context.Context, req *pb.S
 	}
 
 	// Save successful notification
-	s.repo.SaveNotification(models.TypePush, req.DeviceToken, req.Title, req.Body, true)
+	s.repo.SaveNotification(req.UserId, models.TypePush, req.DeviceToken, req.Title, req.Body, true)
 
 	return &pb.SendPushNotificationResponse{
 		Success: true,
@@ -79,16 +81,70 @@ func (s *NotificationServer) SendTaskReminder(ctx context.Context, req *pb.SendT
 
 	err := s.emailSender.SendEmail(userEmail, subject, body)
 	if err != nil {
-		s.repo.SaveNotification(models.TypeEmail, userEmail, subject, body, false)
+		s.repo.SaveNotification(req.UserId, models.TypeEmail, userEmail, subject, body, false)
 		return &pb.SendTaskReminderResponse{
 			Success: false,
 			Error:   err.Error(),
 		}, nil
 	}
 
-	s.repo.SaveNotification(models.TypeEmail, userEmail, subject, body, true)
+	s.repo.SaveNotification(req.UserId, models.TypeEmail, userEmail, subject, body, true)
 
 	return &pb.SendTaskReminderResponse{
 		Success: true,
 	}, nil
 }
+
+func (s *NotificationServer) PurgeUserNotifications(ctx context.Context, req *pb.PurgeUserNotificationsRequest) (*pb.PurgeUserNotificationsResponse, error) {
+	if err := auth.RequireService(ctx); err != nil {
+		return &pb.PurgeUserNotificationsResponse{
+			Error: err.Error(),
+		}, nil
+	}
+
+	deleted, err := s.repo.PurgeUserNotifications(req.UserId)
+	if err != nil {
+		return &pb.PurgeUserNotificationsResponse{
+			Error: err.Error(),
+		}, nil
+	}
+
+	return &pb.PurgeUserNotificationsResponse{
+		Deleted: int32(deleted),
+	}, nil
+}
+
+// ExportUserData returns the user's notification history and cached contact
+// details as a JSON document for data access requests.
+func (s *NotificationServer) ExportUserData(ctx context.Context, req *pb.ExportUserDataRequest) (*pb.ExportUserDataResponse, error) {
+	if err := auth.RequireService(ctx); err != nil {
+		return &pb.ExportUserDataResponse{
+			Error: err.Error(),
+		}, nil
+	}
+
+	notifications, err := s.repo.ListUserNotifications(req.UserId)
+	if err != nil {
+		return &pb.ExportUserDataResponse{
+			Error: err.Error(),
+		}, nil
+	}
+
+	export := map[string]interface{}{
+		"notifications": notifications,
+	}
+	if contact, err := s.repo.GetUserContact(req.UserId); err == nil {
+		export["contact"] = contact
+	}
+
+	data, err := json.Marshal(export)
+	if err != nil {
+		return &pb.ExportUserDataResponse{
+			Error: err.Error(),
+		}, nil
+	}
+
+	return &pb.ExportUserDataResponse{
+		Data: data,
+	}, nil
+}
@@ -32,6 +32,7 @@ type SendEmailRequest struct {
 }
 
 type SendPushRequest struct {
+	UserID      string `json:"user_id,omitempty"`
 	DeviceToken string `json:"device_token"`
 	Title       string `json:"title"`
 	Body        string `json:"body"`
@@ -51,12 +52,12 @@ func (h *Handler) SendEmail(w http.ResponseWriter, r *http.Request) {
 
 	err := h.emailSender.SendEmail(req.To, req.Subject, req.Body)
 	if err != nil {
-		h.repo.SaveNotification(models.TypeEmail, req.To, req.Subject, req.Body, false)
+		h.repo.SaveNotification("", models.TypeEmail, req.To, req.Subject, req.Body, false)
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
 
-	h.repo.SaveNotification(models.TypeEmail, req.To, req.Subject, req.Body, true)
+	h.repo.SaveNotification("", models.TypeEmail, req.To, req.Subject, req.Body, true)
 
 	response := Response{
 		Success: true,
@@ -76,12 +77,12 @@ func (h *Handler) SendPush(w http.ResponseWriter, r *http.Request) {
 
 	err := h.pushSender.SendPushNotification(req.DeviceToken, req.Title, req.Body)
 	if err != nil {
-		h.repo.SaveNotification(models.TypePush, req.DeviceToken, req.Title, req.Body, false)
+		h.repo.SaveNotification(req.UserID, models.TypePush, req.DeviceToken, req.Title, req.Body, false)
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
 
-	h.repo.SaveNotification(models.TypePush, req.DeviceToken, req.Title, req.Body, true)
+	h.repo.SaveNotification(req.UserID, models.TypePush, req.DeviceToken, req.Title, req.Body, true)
 
 	response := Response{
 		Success: true,
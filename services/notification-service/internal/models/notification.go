@@ -11,6 +11,7 @@ const (
 
 type Notification struct {
 	ID        string           `json:"id"`
+	UserID    string           `json:"user_id,omitempty"`
 	Type      NotificationType `json:"type"`
 	Recipient string           `json:"recipient"`
 	Subject   string           `json:"subject,omitempty"`
@@ -18,3 +19,12 @@ type Notification struct {
 	Sent      bool             `json:"sent"`
 	CreatedAt time.Time        `json:"created_at"`
 }
+
+// UserContact caches the delivery details of a user, kept in sync from
+// user-service events.
+type UserContact struct {
+	UserID    string    `json:"user_id"`
+	Username  string    `json:"username"`
+	Email     string    `json:"email"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
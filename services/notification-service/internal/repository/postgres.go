@@ -2,6 +2,7 @@ package repository
 
 import (
 	"database/sql"
+	"fmt"
 	"time"
 
 	"github.com/google/uuid"
@@ -9,8 +10,17 @@ import (
 	"github.com/todo/services/notification-service/internal/models"
 )
 
+// queryer is satisfied by both *sql.DB and *sql.Tx.
+type queryer interface {
+	Exec(query string, args ...interface{}) (sql.Result, error)
+	Query(query string, args ...interface{}) (*sql.Rows, error)
+	QueryRow(query string, args ...interface{}) *sql.Row
+}
+
 type PostgresRepository struct {
 	db *sql.DB
+	// q runs queries, on db or on the transaction given to WithTx
+	q queryer
 }
 
 func NewPostgresRepository(connStr string) (*PostgresRepository, error) {
@@ -33,18 +43,37 @@ func NewPostgresRepository(connStr string) (*PostgresRepository, error) {
 			body TEXT NOT NULL,
 			sent BOOLEAN DEFAULT FALSE,
 			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
-		)
+		);
+		ALTER TABLE notifications ADD COLUMN IF NOT EXISTS user_id VARCHAR(36);
+		CREATE INDEX IF NOT EXISTS idx_notifications_user_id ON notifications(user_id);
+		CREATE TABLE IF NOT EXISTS user_contacts (
+			user_id VARCHAR(36) PRIMARY KEY,
+			username VARCHAR(100) NOT NULL,
+			email VARCHAR(255) NOT NULL,
+			updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+		);
 	`)
 	if err != nil {
 		return nil, err
 	}
 
-	return &PostgresRepository{db: db}, nil
+	return &PostgresRepository{db: db, q: db}, nil
+}
+
+// WithTx returns a repository whose queries run in tx, so that an event
+// handler's writes commit together with the record that it ran. A nil tx
+// leaves queries on the connection pool.
+func (r *PostgresRepository) WithTx(tx *sql.Tx) *PostgresRepository {
+	if tx == nil {
+		return r
+	}
+	return &PostgresRepository{db: r.db, q: tx}
 }
 
-func (r *PostgresRepository) SaveNotification(notifType models.NotificationType, recipient, subject, body string, sent bool) (*models.Notification, error) {
+func (r *PostgresRepository) SaveNotification(userID string, notifType models.NotificationType, recipient, subject, body string, sent bool) (*models.Notification, error) {
 	notification := &models.Notification{
 		ID:        uuid.New().String(),
+		UserID:    userID,
 		Type:      notifType,
 		Recipient: recipient,
 		Subject:   subject,
@@ -53,9 +82,9 @@ func (r *PostgresRepository) SaveNotification(notifType models.NotificationType,
 		CreatedAt: time.Now(),
 	}
 
-	_, err := r.db.Exec(
-		"INSERT INTO notifications (id, type, recipient, subject, body, sent, created_at) VALUES ($1, $2, $3, $4, $5, $6, $7)",
-		notification.ID, notification.Type, notification.Recipient, notification.Subject, notification.Body, notification.Sent, notification.CreatedAt,
+	_, err := r.q.Exec(
+		"INSERT INTO notifications (id, user_id, type, recipient, subject, body, sent, created_at) VALUES ($1, NULLIF($2, ''), $3, $4, $5, $6, $7, $8)",
+		notification.ID, notification.UserID, notification.Type, notification.Recipient, notification.Subject, notification.Body, notification.Sent, notification.CreatedAt,
 	)
 	if err != nil {
 		return nil, err
@@ -64,6 +93,97 @@ func (r *PostgresRepository) SaveNotification(notifType models.NotificationType,
 	return notification, nil
 }
 
+// DB exposes the connection pool for the event consumer.
+func (r *PostgresRepository) DB() *sql.DB {
+	return r.db
+}
+
+func (r *PostgresRepository) UpsertUserContact(userID, username, email string) error {
+	_, err := r.q.Exec(
+		`INSERT INTO user_contacts (user_id, username, email, updated_at) VALUES ($1, $2, $3, $4)
+		ON CONFLICT (user_id) DO UPDATE SET username = EXCLUDED.username, email = EXCLUDED.email, updated_at = EXCLUDED.updated_at`,
+		userID, username, email, time.Now(),
+	)
+	return err
+}
+
+func (r *PostgresRepository) GetUserContact(userID string) (*models.UserContact, error) {
+	contact := &models.UserContact{}
+	err := r.q.QueryRow(
+		"SELECT user_id, username, email, updated_at FROM user_contacts WHERE user_id = $1",
+		userID,
+	).Scan(&contact.UserID, &contact.Username, &contact.Email, &contact.UpdatedAt)
+
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("user contact not found")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return contact, nil
+}
+
+// ListUserNotifications returns a user's notification history, matching the
+// same rows PurgeUserNotifications would delete.
+func (r *PostgresRepository) ListUserNotifications(userID string) ([]*models.Notification, error) {
+	rows, err := r.q.Query(
+		`SELECT id, COALESCE(user_id, ''), type, recipient, COALESCE(subject, ''), body, sent, created_at FROM notifications
+		WHERE user_id = $1 OR recipient IN (SELECT email FROM user_contacts WHERE user_id = $1)
+		ORDER BY created_at`,
+		userID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var notifications []*models.Notification
+	for rows.Next() {
+		n := &models.Notification{}
+		if err := rows.Scan(&n.ID, &n.UserID, &n.Type, &n.Recipient, &n.Subject, &n.Body, &n.Sent, &n.CreatedAt); err != nil {
+			return nil, err
+		}
+		notifications = append(notifications, n)
+	}
+
+	return notifications, rows.Err()
+}
+
+// PurgeUserNotifications deletes a user's notification history, including
+// entries recorded before user IDs were tracked that went to their email,
+// and forgets their cached contact details.
+func (r *PostgresRepository) PurgeUserNotifications(userID string) (int, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	result, err := tx.Exec(
+		"DELETE FROM notifications WHERE user_id = $1 OR recipient IN (SELECT email FROM user_contacts WHERE user_id = $1)",
+		userID,
+	)
+	if err != nil {
+		return 0, err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+
+	if _, err := tx.Exec("DELETE FROM user_contacts WHERE user_id = $1", userID); err != nil {
+		return 0, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, err
+	}
+
+	return int(rowsAffected), nil
+}
+
 func (r *PostgresRepository) Close() error {
 	return r.db.Close()
 }
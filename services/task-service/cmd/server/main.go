@@ -1,16 +1,22 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"log"
 	"net"
 	"net/http"
 	"os"
+	"strconv"
+	"time"
 
 	"github.com/gorilla/mux"
+	"github.com/todo/pkg/auth"
+	"github.com/todo/pkg/events"
 	pb "github.com/todo/proto/task"
 	grpcServer "github.com/todo/services/task-service/internal/grpc"
 	httpHandler "github.com/todo/services/task-service/internal/http"
+	"github.com/todo/services/task-service/internal/jobs"
 	"github.com/todo/services/task-service/internal/repository"
 	"google.golang.org/grpc"
 )
@@ -24,6 +30,17 @@ func main() {
 	dbName := getEnv("DB_NAME", "task_db")
 	grpcPort := getEnv("GRPC_PORT", "50053")
 	httpPort := getEnv("HTTP_PORT", "8083")
+	eventSubscribers := getEnv("EVENT_SUBSCRIBERS", "")
+	authServiceAddr := getEnv("AUTH_SERVICE_ADDR", "localhost:50052")
+	authJWKSURL := getEnv("AUTH_JWKS_URL", "")
+	serviceToken := getEnv("SERVICE_TOKEN", "")
+	if err := auth.CheckServiceToken(serviceToken); err != nil {
+		log.Fatalf("Refusing to start: %v", err)
+	}
+	archiveAfterDays, err := strconv.Atoi(getEnv("ARCHIVE_AFTER_DAYS", "90"))
+	if err != nil {
+		log.Fatalf("Invalid ARCHIVE_AFTER_DAYS: %v", err)
+	}
 
 	// Connect to database
 	connStr := fmt.Sprintf("host=%s port=%s user=%s password=%s dbname=%s sslmode=disable",
@@ -35,6 +52,41 @@ func main() {
 	}
 	defer repo.Close()
 
+	// Authenticate callers with auth-service tokens or the shared service token
+	validator, err := auth.NewValidator(authJWKSURL, authServiceAddr, serviceToken)
+	if err != nil {
+		log.Fatalf("Failed to create token validator: %v", err)
+	}
+
+	// Publish outbox events to subscribers. Without any, events are kept in
+	// the outbox until some are configured.
+	subscribers := events.ParseEndpoints(eventSubscribers)
+	if len(subscribers) == 0 {
+		log.Printf("EVENT_SUBSCRIBERS is not set; events are kept in the outbox until it is")
+	} else {
+		publisher := events.NewHTTPPublisher(subscribers, serviceToken)
+		relay := events.NewRelay(repo.DB(), publisher, 2*time.Second)
+		go relay.Run(context.Background())
+	}
+
+	// Wake snoozed tasks once their start date passes
+	waker := jobs.NewSnoozeWaker(repo, time.Minute)
+	go waker.Run(context.Background())
+
+	// Escalate overdue tasks according to their owners' rules
+	escalation := jobs.NewEscalationWorker(repo, time.Minute)
+	go escalation.Run(context.Background())
+
+	// Fire due_soon automation rules
+	automation := jobs.NewAutomationWorker(repo, time.Minute)
+	go automation.Run(context.Background())
+
+	// Archive old completed tasks unless disabled with ARCHIVE_AFTER_DAYS=0
+	if archiveAfterDays > 0 {
+		archiver := jobs.NewArchiver(repo, time.Hour, time.Duration(archiveAfterDays)*24*time.Hour)
+		go archiver.Run(context.Background())
+	}
+
 	// Start gRPC server
 	go func() {
 		lis, err := net.Listen("tcp", ":"+grpcPort)
@@ -42,7 +94,10 @@ func main() {
 			log.Fatalf("Failed to listen on gRPC port: %v", err)
 		}
 
-		s := grpc.NewServer()
+		s := grpc.NewServer(
+			grpc.UnaryInterceptor(auth.UnaryServerInterceptor(validator)),
+			grpc.StreamInterceptor(auth.StreamServerInterceptor(validator)),
+		)
 		pb.RegisterTaskServiceServer(s, grpcServer.NewTaskServer(repo))
 
 		log.Printf("gRPC server listening on :%s", grpcPort)
@@ -53,6 +108,7 @@ func main() {
 
 	// Start HTTP server
 	router := mux.NewRouter()
+	router.Use(auth.Middleware(validator, nil))
 	handler := httpHandler.NewHandler(repo)
 	handler.RegisterRoutes(router)
 
@@ -0,0 +1,195 @@
+package authz
+
+import (
+	"context"
+
+	"github.com/todo/pkg/auth"
+	"github.com/todo/services/task-service/internal/models"
+	"github.com/todo/services/task-service/internal/repository"
+)
+
+// Authorizer checks that the caller of a request may touch a resource by
+// looking up who owns it. Users may only touch their own tasks and the
+// resources hanging off them, unless a role grants them a scope over
+// everyone's tasks; other services may touch anything. Each check takes the
+// scope the access needs, which personal access tokens must hold even for
+// their owner's own resources.
+type Authorizer struct {
+	repo *repository.PostgresRepository
+}
+
+func NewAuthorizer(repo *repository.PostgresRepository) *Authorizer {
+	return &Authorizer{repo: repo}
+}
+
+func (a *Authorizer) Task(ctx context.Context, taskID, scope string) error {
+	task, err := a.repo.GetTaskByID(taskID)
+	if err != nil {
+		return err
+	}
+	return auth.Authorize(ctx, task.UserID, scope)
+}
+
+// TaskScope is Task that also lets callers holding the scope touch other
+// users' tasks.
+func (a *Authorizer) TaskScope(ctx context.Context, taskID, scope string) error {
+	task, err := a.repo.GetTaskByID(taskID)
+	if err != nil {
+		return err
+	}
+	return auth.AuthorizeScope(ctx, task.UserID, scope)
+}
+
+// Project checks that the caller is a member of the project.
+func (a *Authorizer) Project(ctx context.Context, projectID, scope string) error {
+	identity, ok := auth.FromContext(ctx)
+	if !ok {
+		return auth.ErrUnauthenticated
+	}
+	if identity.Service {
+		return nil
+	}
+	if !identity.HasOwnerScope(scope) {
+		return auth.ErrForbidden
+	}
+
+	member, err := a.repo.IsProjectMember(projectID, identity.UserID)
+	if err != nil {
+		return err
+	}
+	if !member {
+		return auth.ErrForbidden
+	}
+	return nil
+}
+
+// JoinProject checks that the caller may put a task in the project. A
+// project nobody has claimed yet is claimed for the caller.
+func (a *Authorizer) JoinProject(ctx context.Context, projectID string) error {
+	if projectID == "" {
+		return nil
+	}
+
+	identity, ok := auth.FromContext(ctx)
+	if !ok {
+		return auth.ErrUnauthenticated
+	}
+	if identity.Service {
+		return nil
+	}
+	if !identity.HasOwnerScope(auth.ScopeTasksWrite) {
+		return auth.ErrForbidden
+	}
+
+	member, err := a.repo.JoinProject(projectID, identity.UserID)
+	if err != nil {
+		return err
+	}
+	if !member {
+		return auth.ErrForbidden
+	}
+	return nil
+}
+
+// TaskProject is JoinProject for moving an existing task into a project.
+// Leaving a task in the project it is in needs no check.
+func (a *Authorizer) TaskProject(ctx context.Context, taskID, projectID string) error {
+	task, err := a.repo.GetTaskByID(taskID)
+	if err != nil {
+		return err
+	}
+	if task.ProjectID == projectID {
+		return nil
+	}
+	return a.JoinProject(ctx, projectID)
+}
+
+// ProjectOwner checks that the caller owns the project.
+func (a *Authorizer) ProjectOwner(ctx context.Context, projectID, scope string) error {
+	ownerID, err := a.repo.GetProjectOwner(projectID)
+	if err != nil {
+		return err
+	}
+	return auth.Authorize(ctx, ownerID, scope)
+}
+
+// View checks access to a saved view. Built-in views belong to everyone.
+func (a *Authorizer) View(ctx context.Context, viewID, scope string) error {
+	if models.BuiltInView(viewID) != nil {
+		return nil
+	}
+
+	view, err := a.repo.GetView(viewID)
+	if err != nil {
+		return err
+	}
+	return auth.Authorize(ctx, view.UserID, scope)
+}
+
+func (a *Authorizer) Template(ctx context.Context, templateID, scope string) error {
+	template, err := a.repo.GetTemplate(templateID)
+	if err != nil {
+		return err
+	}
+	return auth.Authorize(ctx, template.UserID, scope)
+}
+
+func (a *Authorizer) TimeEntry(ctx context.Context, entryID, scope string) error {
+	userID, err := a.repo.TimeEntryOwner(entryID)
+	if err != nil {
+		return err
+	}
+	return auth.Authorize(ctx, userID, scope)
+}
+
+func (a *Authorizer) ChecklistItem(ctx context.Context, itemID, scope string) error {
+	userID, err := a.repo.ChecklistItemOwner(itemID)
+	if err != nil {
+		return err
+	}
+	return auth.Authorize(ctx, userID, scope)
+}
+
+func (a *Authorizer) EscalationRule(ctx context.Context, ruleID, scope string) error {
+	rule, err := a.repo.GetEscalationRule(ruleID)
+	if err != nil {
+		return err
+	}
+	return auth.Authorize(ctx, rule.UserID, scope)
+}
+
+func (a *Authorizer) CustomField(ctx context.Context, fieldID, scope string) error {
+	field, err := a.repo.GetCustomField(fieldID)
+	if err != nil {
+		return err
+	}
+	return a.Project(ctx, field.ProjectID, scope)
+}
+
+func (a *Authorizer) AutomationRule(ctx context.Context, ruleID, scope string) error {
+	rule, err := a.repo.GetAutomationRule(ruleID)
+	if err != nil {
+		return err
+	}
+	return a.Project(ctx, rule.ProjectID, scope)
+}
+
+// AutomationRuleOwner checks that the caller owns the rule. Project members
+// can see each other's rules but only change their own, since a rule acts on
+// its owner's tasks.
+func (a *Authorizer) AutomationRuleOwner(ctx context.Context, ruleID, scope string) error {
+	rule, err := a.repo.GetAutomationRule(ruleID)
+	if err != nil {
+		return err
+	}
+	return auth.Authorize(ctx, rule.UserID, scope)
+}
+
+// Report checks access to the tasks a report covers: the user's own, or all
+// of a project's.
+func (a *Authorizer) Report(ctx context.Context, filter models.ReportFilter) error {
+	if filter.UserID != "" {
+		return auth.Authorize(ctx, filter.UserID, auth.ScopeTasksRead)
+	}
+	return a.Project(ctx, filter.ProjectID, auth.ScopeTasksRead)
+}
@@ -0,0 +1,27 @@
+package grpc
+
+import (
+	"context"
+
+	"github.com/todo/pkg/auth"
+	pb "github.com/todo/proto/task"
+)
+
+func (s *TaskServer) UnarchiveTask(ctx context.Context, req *pb.UnarchiveTaskRequest) (*pb.UnarchiveTaskResponse, error) {
+	if err := s.authz.Task(ctx, req.Id, auth.ScopeTasksWrite); err != nil {
+		return &pb.UnarchiveTaskResponse{
+			Error: err.Error(),
+		}, nil
+	}
+
+	task, err := s.repo.UnarchiveTask(req.Id)
+	if err != nil {
+		return &pb.UnarchiveTaskResponse{
+			Error: err.Error(),
+		}, nil
+	}
+
+	return &pb.UnarchiveTaskResponse{
+		Task: convertTaskToProto(task),
+	}, nil
+}
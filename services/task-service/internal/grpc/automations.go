@@ -0,0 +1,252 @@
+package grpc
+
+import (
+	"context"
+
+	"github.com/todo/pkg/auth"
+	pb "github.com/todo/proto/task"
+	"github.com/todo/services/task-service/internal/models"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func (s *TaskServer) CreateAutomationRule(ctx context.Context, req *pb.CreateAutomationRuleRequest) (*pb.AutomationRuleResponse, error) {
+	rule := convertAutomationRuleFromProto(req.Rule)
+	userID, err := auth.ScopeUser(ctx, rule.UserID, auth.ScopeTasksWrite)
+	if err != nil {
+		return &pb.AutomationRuleResponse{
+			Error: err.Error(),
+		}, nil
+	}
+	rule.UserID = userID
+
+	if err := rule.Validate(); err != nil {
+		return &pb.AutomationRuleResponse{
+			Error: err.Error(),
+		}, nil
+	}
+
+	if err := s.authz.Project(ctx, rule.ProjectID, auth.ScopeTasksWrite); err != nil {
+		return &pb.AutomationRuleResponse{
+			Error: err.Error(),
+		}, nil
+	}
+
+	created, err := s.repo.CreateAutomationRule(rule)
+	if err != nil {
+		return &pb.AutomationRuleResponse{
+			Error: err.Error(),
+		}, nil
+	}
+
+	return &pb.AutomationRuleResponse{
+		Rule: convertAutomationRuleToProto(created),
+	}, nil
+}
+
+func (s *TaskServer) GetAutomationRule(ctx context.Context, req *pb.GetAutomationRuleRequest) (*pb.AutomationRuleResponse, error) {
+	if err := s.authz.AutomationRule(ctx, req.Id, auth.ScopeTasksRead); err != nil {
+		return &pb.AutomationRuleResponse{
+			Error: err.Error(),
+		}, nil
+	}
+
+	rule, err := s.repo.GetAutomationRule(req.Id)
+	if err != nil {
+		return &pb.AutomationRuleResponse{
+			Error: err.Error(),
+		}, nil
+	}
+
+	return &pb.AutomationRuleResponse{
+		Rule: convertAutomationRuleToProto(rule),
+	}, nil
+}
+
+func (s *TaskServer) UpdateAutomationRule(ctx context.Context, req *pb.UpdateAutomationRuleRequest) (*pb.AutomationRuleResponse, error) {
+	rule := convertAutomationRuleFromProto(req.Rule)
+	if err := s.authz.AutomationRuleOwner(ctx, rule.ID, auth.ScopeTasksWrite); err != nil {
+		return &pb.AutomationRuleResponse{
+			Error: err.Error(),
+		}, nil
+	}
+
+	existing, err := s.repo.GetAutomationRule(rule.ID)
+	if err != nil {
+		return &pb.AutomationRuleResponse{
+			Error: err.Error(),
+		}, nil
+	}
+
+	rule.ProjectID = existing.ProjectID
+	rule.UserID = existing.UserID
+	if err := rule.Validate(); err != nil {
+		return &pb.AutomationRuleResponse{
+			Error: err.Error(),
+		}, nil
+	}
+
+	updated, err := s.repo.UpdateAutomationRule(rule)
+	if err != nil {
+		return &pb.AutomationRuleResponse{
+			Error: err.Error(),
+		}, nil
+	}
+
+	return &pb.AutomationRuleResponse{
+		Rule: convertAutomationRuleToProto(updated),
+	}, nil
+}
+
+func (s *TaskServer) DeleteAutomationRuleOwner(ctx context.Context, req *pb.DeleteAutomationRuleRequest) (*pb.DeleteAutomationRuleResponse, error) {
+	if err := s.authz.AutomationRuleOwner(ctx, req.Id, auth.ScopeTasksWrite); err != nil {
+		return &pb.DeleteAutomationRuleResponse{
+			Success: false,
+			Error:   err.Error(),
+		}, nil
+	}
+
+	err := s.repo.DeleteAutomationRule(req.Id)
+	if err != nil {
+		return &pb.DeleteAutomationRuleResponse{
+			Success: false,
+			Error:   err.Error(),
+		}, nil
+	}
+
+	return &pb.DeleteAutomationRuleResponse{
+		Success: true,
+	}, nil
+}
+
+func (s *TaskServer) ListAutomationRules(ctx context.Context, req *pb.ListAutomationRulesRequest) (*pb.ListAutomationRulesResponse, error) {
+	if err := s.authz.Project(ctx, req.ProjectId, auth.ScopeTasksRead); err != nil {
+		return &pb.ListAutomationRulesResponse{
+			Error: err.Error(),
+		}, nil
+	}
+
+	rules, err := s.repo.ListAutomationRules(req.ProjectId)
+	if err != nil {
+		return &pb.ListAutomationRulesResponse{
+			Error: err.Error(),
+		}, nil
+	}
+
+	response := &pb.ListAutomationRulesResponse{}
+	for _, rule := range rules {
+		response.Rules = append(response.Rules, convertAutomationRuleToProto(rule))
+	}
+
+	return response, nil
+}
+
+func (s *TaskServer) ListAutomationRuns(ctx context.Context, req *pb.ListAutomationRunsRequest) (*pb.ListAutomationRunsResponse, error) {
+	if err := s.authz.AutomationRule(ctx, req.RuleId, auth.ScopeTasksRead); err != nil {
+		return &pb.ListAutomationRunsResponse{
+			Error: err.Error(),
+		}, nil
+	}
+
+	runs, err := s.repo.ListAutomationRuns(req.RuleId)
+	if err != nil {
+		return &pb.ListAutomationRunsResponse{
+			Error: err.Error(),
+		}, nil
+	}
+
+	response := &pb.ListAutomationRunsResponse{}
+	for _, run := range runs {
+		pbRun := &pb.AutomationRun{
+			Id:        run.ID,
+			RuleId:    run.RuleID,
+			TaskId:    run.TaskID,
+			Trigger:   string(run.Trigger),
+			Status:    string(run.Status),
+			Error:     run.Error,
+			Depth:     int32(run.Depth),
+			CreatedAt: timestamppb.New(run.CreatedAt),
+		}
+		if run.DueDate != nil {
+			pbRun.DueDate = timestamppb.New(*run.DueDate)
+		}
+		response.Runs = append(response.Runs, pbRun)
+	}
+
+	return response, nil
+}
+
+func convertAutomationRuleFromProto(rule *pb.AutomationRule) *models.AutomationRule {
+	if rule == nil {
+		return &models.AutomationRule{}
+	}
+
+	result := &models.AutomationRule{
+		ID:             rule.Id,
+		ProjectID:      rule.ProjectId,
+		UserID:         rule.UserId,
+		Name:           rule.Name,
+		Enabled:        rule.Enabled,
+		Trigger:        models.AutomationTrigger(rule.Trigger),
+		ToStatus:       models.TaskStatus(rule.ToStatus),
+		Tag:            rule.Tag,
+		DueWithinHours: int(rule.DueWithinHours),
+	}
+	for _, condition := range rule.Conditions {
+		result.Conditions = append(result.Conditions, models.AutomationCondition{
+			Field:    condition.Field,
+			Operator: models.ConditionOperator(condition.Operator),
+			Value:    condition.Value,
+		})
+	}
+	for _, action := range rule.Actions {
+		result.Actions = append(result.Actions, models.AutomationAction{
+			Type:     models.AutomationActionType(action.Type),
+			Field:    action.Field,
+			Value:    action.Value,
+			Title:    action.Title,
+			Message:  action.Message,
+			Priority: models.TaskPriority(action.Priority),
+			Tags:     action.Tags,
+			UserID:   action.UserId,
+		})
+	}
+
+	return result
+}
+
+func convertAutomationRuleToProto(rule *models.AutomationRule) *pb.AutomationRule {
+	pbRule := &pb.AutomationRule{
+		Id:             rule.ID,
+		ProjectId:      rule.ProjectID,
+		UserId:         rule.UserID,
+		Name:           rule.Name,
+		Enabled:        rule.Enabled,
+		Trigger:        string(rule.Trigger),
+		ToStatus:       string(rule.ToStatus),
+		Tag:            rule.Tag,
+		DueWithinHours: int32(rule.DueWithinHours),
+		CreatedAt:      timestamppb.New(rule.CreatedAt),
+		UpdatedAt:      timestamppb.New(rule.UpdatedAt),
+	}
+	for _, condition := range rule.Conditions {
+		pbRule.Conditions = append(pbRule.Conditions, &pb.AutomationCondition{
+			Field:    condition.Field,
+			Operator: string(condition.Operator),
+			Value:    condition.Value,
+		})
+	}
+	for _, action := range rule.Actions {
+		pbRule.Actions = append(pbRule.Actions, &pb.AutomationAction{
+			Type:     string(action.Type),
+			Field:    action.Field,
+			Value:    action.Value,
+			Title:    action.Title,
+			Message:  action.Message,
+			Priority: string(action.Priority),
+			Tags:     action.Tags,
+			UserId:   action.UserID,
+		})
+	}
+
+	return pbRule
+}
@@ -0,0 +1,65 @@
+package grpc
+
+import (
+	"context"
+
+	"github.com/todo/pkg/auth"
+	pb "github.com/todo/proto/task"
+	"github.com/todo/services/task-service/internal/models"
+)
+
+func (s *TaskServer) MoveTask(ctx context.Context, req *pb.MoveTaskRequest) (*pb.MoveTaskResponse, error) {
+	if err := s.authz.Task(ctx, req.Id, auth.ScopeTasksWrite); err != nil {
+		return &pb.MoveTaskResponse{
+			Error: err.Error(),
+		}, nil
+	}
+
+	move := models.TaskMove{
+		Scope:    models.MoveScope(req.Scope),
+		AfterID:  req.AfterId,
+		BeforeID: req.BeforeId,
+	}
+	if req.Status != nil {
+		status := convertStatusFromProto(*req.Status)
+		move.Status = &status
+	}
+
+	task, err := s.repo.MoveTask(req.Id, move)
+	if err != nil {
+		return &pb.MoveTaskResponse{
+			Error: err.Error(),
+		}, nil
+	}
+
+	return &pb.MoveTaskResponse{
+		Task: convertTaskToProto(task),
+	}, nil
+}
+
+func (s *TaskServer) GetBoard(ctx context.Context, req *pb.GetBoardRequest) (*pb.GetBoardResponse, error) {
+	userID, err := auth.ScopeUser(ctx, req.UserId, auth.ScopeTasksRead)
+	if err != nil {
+		return &pb.GetBoardResponse{
+			Error: err.Error(),
+		}, nil
+	}
+
+	columns, err := s.repo.Board(userID, req.ProjectId)
+	if err != nil {
+		return &pb.GetBoardResponse{
+			Error: err.Error(),
+		}, nil
+	}
+
+	response := &pb.GetBoardResponse{}
+	for _, column := range columns {
+		pbColumn := &pb.BoardColumn{Status: convertStatusToProto(column.Status)}
+		for _, task := range column.Tasks {
+			pbColumn.Tasks = append(pbColumn.Tasks, convertTaskToProto(task))
+		}
+		response.Columns = append(response.Columns, pbColumn)
+	}
+
+	return response, nil
+}
@@ -0,0 +1,75 @@
+package grpc
+
+import (
+	"context"
+
+	"github.com/todo/pkg/auth"
+	pb "github.com/todo/proto/task"
+	"github.com/todo/services/task-service/internal/models"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func (s *TaskServer) AddComment(ctx context.Context, req *pb.AddCommentRequest) (*pb.CommentResponse, error) {
+	if err := s.authz.Task(ctx, req.TaskId, auth.ScopeTasksWrite); err != nil {
+		return &pb.CommentResponse{
+			Error: err.Error(),
+		}, nil
+	}
+
+	userID, err := auth.ScopeUser(ctx, req.UserId, auth.ScopeTasksWrite)
+	if err != nil {
+		return &pb.CommentResponse{
+			Error: err.Error(),
+		}, nil
+	}
+
+	if req.Body == "" {
+		return &pb.CommentResponse{
+			Error: "body is required",
+		}, nil
+	}
+
+	comment, err := s.repo.AddComment(req.TaskId, userID, req.Body)
+	if err != nil {
+		return &pb.CommentResponse{
+			Error: err.Error(),
+		}, nil
+	}
+
+	return &pb.CommentResponse{
+		Comment: convertCommentToProto(comment),
+	}, nil
+}
+
+func (s *TaskServer) ListComments(ctx context.Context, req *pb.ListCommentsRequest) (*pb.ListCommentsResponse, error) {
+	if err := s.authz.Task(ctx, req.TaskId, auth.ScopeTasksRead); err != nil {
+		return &pb.ListCommentsResponse{
+			Error: err.Error(),
+		}, nil
+	}
+
+	comments, err := s.repo.ListComments(req.TaskId)
+	if err != nil {
+		return &pb.ListCommentsResponse{
+			Error: err.Error(),
+		}, nil
+	}
+
+	response := &pb.ListCommentsResponse{}
+	for _, comment := range comments {
+		response.Comments = append(response.Comments, convertCommentToProto(comment))
+	}
+
+	return response, nil
+}
+
+func convertCommentToProto(comment *models.Comment) *pb.Comment {
+	return &pb.Comment{
+		Id:        comment.ID,
+		TaskId:    comment.TaskID,
+		UserId:    comment.UserID,
+		RuleId:    comment.RuleID,
+		Body:      comment.Body,
+		CreatedAt: timestamppb.New(comment.CreatedAt),
+	}
+}
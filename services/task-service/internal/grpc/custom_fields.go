@@ -0,0 +1,167 @@
+package grpc
+
+import (
+	"context"
+
+	"github.com/todo/pkg/auth"
+	pb "github.com/todo/proto/task"
+	"github.com/todo/services/task-service/internal/models"
+	"google.golang.org/protobuf/types/known/structpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func (s *TaskServer) CreateCustomField(ctx context.Context, req *pb.CreateCustomFieldRequest) (*pb.CustomFieldResponse, error) {
+	if err := s.authz.Project(ctx, req.ProjectId, auth.ScopeTasksWrite); err != nil {
+		return &pb.CustomFieldResponse{
+			Error: err.Error(),
+		}, nil
+	}
+
+	field := &models.CustomFieldDefinition{
+		ProjectID: req.ProjectId,
+		Key:       req.Key,
+		Name:      req.Name,
+		Type:      models.FieldType(req.Type),
+		Options:   req.Options,
+	}
+	if err := field.Validate(); err != nil {
+		return &pb.CustomFieldResponse{
+			Error: err.Error(),
+		}, nil
+	}
+
+	created, err := s.repo.CreateCustomField(field)
+	if err != nil {
+		return &pb.CustomFieldResponse{
+			Error: err.Error(),
+		}, nil
+	}
+
+	return &pb.CustomFieldResponse{
+		Field: convertCustomFieldToProto(created),
+	}, nil
+}
+
+func (s *TaskServer) GetCustomField(ctx context.Context, req *pb.GetCustomFieldRequest) (*pb.CustomFieldResponse, error) {
+	if err := s.authz.CustomField(ctx, req.Id, auth.ScopeTasksRead); err != nil {
+		return &pb.CustomFieldResponse{
+			Error: err.Error(),
+		}, nil
+	}
+
+	field, err := s.repo.GetCustomField(req.Id)
+	if err != nil {
+		return &pb.CustomFieldResponse{
+			Error: err.Error(),
+		}, nil
+	}
+
+	return &pb.CustomFieldResponse{
+		Field: convertCustomFieldToProto(field),
+	}, nil
+}
+
+func (s *TaskServer) UpdateCustomField(ctx context.Context, req *pb.UpdateCustomFieldRequest) (*pb.CustomFieldResponse, error) {
+	if err := s.authz.CustomField(ctx, req.Id, auth.ScopeTasksWrite); err != nil {
+		return &pb.CustomFieldResponse{
+			Error: err.Error(),
+		}, nil
+	}
+
+	field, err := s.repo.UpdateCustomField(req.Id, req.Name, req.Options)
+	if err != nil {
+		return &pb.CustomFieldResponse{
+			Error: err.Error(),
+		}, nil
+	}
+
+	return &pb.CustomFieldResponse{
+		Field: convertCustomFieldToProto(field),
+	}, nil
+}
+
+func (s *TaskServer) DeleteCustomField(ctx context.Context, req *pb.DeleteCustomFieldRequest) (*pb.DeleteCustomFieldResponse, error) {
+	if err := s.authz.CustomField(ctx, req.Id, auth.ScopeTasksWrite); err != nil {
+		return &pb.DeleteCustomFieldResponse{
+			Success: false,
+			Error:   err.Error(),
+		}, nil
+	}
+
+	err := s.repo.DeleteCustomField(req.Id)
+	if err != nil {
+		return &pb.DeleteCustomFieldResponse{
+			Success: false,
+			Error:   err.Error(),
+		}, nil
+	}
+
+	return &pb.DeleteCustomFieldResponse{
+		Success: true,
+	}, nil
+}
+
+func (s *TaskServer) ListCustomFields(ctx context.Context, req *pb.ListCustomFieldsRequest) (*pb.ListCustomFieldsResponse, error) {
+	if err := s.authz.Project(ctx, req.ProjectId, auth.ScopeTasksRead); err != nil {
+		return &pb.ListCustomFieldsResponse{
+			Error: err.Error(),
+		}, nil
+	}
+
+	fields, err := s.repo.ListCustomFields(req.ProjectId)
+	if err != nil {
+		return &pb.ListCustomFieldsResponse{
+			Error: err.Error(),
+		}, nil
+	}
+
+	pbFields := make([]*pb.CustomFieldDefinition, len(fields))
+	for i, field := range fields {
+		pbFields[i] = convertCustomFieldToProto(field)
+	}
+
+	return &pb.ListCustomFieldsResponse{
+		Fields: pbFields,
+	}, nil
+}
+
+func convertCustomFieldToProto(field *models.CustomFieldDefinition) *pb.CustomFieldDefinition {
+	return &pb.CustomFieldDefinition{
+		Id:        field.ID,
+		ProjectId: field.ProjectID,
+		Key:       field.Key,
+		Name:      field.Name,
+		Type:      string(field.Type),
+		Options:   field.Options,
+		CreatedAt: timestamppb.New(field.CreatedAt),
+		UpdatedAt: timestamppb.New(field.UpdatedAt),
+	}
+}
+
+// convertCustomFieldValuesToProto skips values protobuf cannot represent;
+// stored values are always plain JSON so none are expected.
+func convertCustomFieldValuesToProto(values map[string]interface{}) map[string]*structpb.Value {
+	if len(values) == 0 {
+		return nil
+	}
+
+	pbValues := make(map[string]*structpb.Value, len(values))
+	for key, value := range values {
+		if pbValue, err := structpb.NewValue(value); err == nil {
+			pbValues[key] = pbValue
+		}
+	}
+	return pbValues
+}
+
+func convertCustomFieldValuesFromProto(values map[string]*structpb.Value) map[string]interface{} {
+	if len(values) == 0 {
+		return nil
+	}
+
+	converted := make(map[string]interface{}, len(values))
+	for key, value := range values {
+		converted[key] = value.AsInterface()
+	}
+	return converted
+}
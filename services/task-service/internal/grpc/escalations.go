@@ -0,0 +1,293 @@
+package grpc
+
+import (
+	"context"
+
+	"github.com/todo/pkg/auth"
+	pb "github.com/todo/proto/task"
+	"github.com/todo/services/task-service/internal/models"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func (s *TaskServer) CreateEscalationRule(ctx context.Context, req *pb.CreateEscalationRuleRequest) (*pb.EscalationRuleResponse, error) {
+	userID, err := auth.ScopeUser(ctx, req.UserId, auth.ScopeTasksWrite)
+	if err != nil {
+		return &pb.EscalationRuleResponse{
+			Error: err.Error(),
+		}, nil
+	}
+
+	rule := &models.EscalationRule{
+		UserID:       userID,
+		ProjectID:    req.ProjectId,
+		Priority:     models.TaskPriority(req.Priority),
+		AfterHours:   int(req.AfterHours),
+		Action:       models.EscalationAction(req.Action),
+		NotifyUserID: req.NotifyUserId,
+	}
+	if err := rule.Validate(); err != nil {
+		return &pb.EscalationRuleResponse{
+			Error: err.Error(),
+		}, nil
+	}
+
+	created, err := s.repo.CreateEscalationRule(rule)
+	if err != nil {
+		return &pb.EscalationRuleResponse{
+			Error: err.Error(),
+		}, nil
+	}
+
+	return &pb.EscalationRuleResponse{
+		Rule: convertEscalationRuleToProto(created),
+	}, nil
+}
+
+func (s *TaskServer) GetEscalationRule(ctx context.Context, req *pb.GetEscalationRuleRequest) (*pb.EscalationRuleResponse, error) {
+	if err := s.authz.EscalationRule(ctx, req.Id, auth.ScopeTasksRead); err != nil {
+		return &pb.EscalationRuleResponse{
+			Error: err.Error(),
+		}, nil
+	}
+
+	rule, err := s.repo.GetEscalationRule(req.Id)
+	if err != nil {
+		return &pb.EscalationRuleResponse{
+			Error: err.Error(),
+		}, nil
+	}
+
+	return &pb.EscalationRuleResponse{
+		Rule: convertEscalationRuleToProto(rule),
+	}, nil
+}
+
+func (s *TaskServer) UpdateEscalationRule(ctx context.Context, req *pb.UpdateEscalationRuleRequest) (*pb.EscalationRuleResponse, error) {
+	if err := s.authz.EscalationRule(ctx, req.Id, auth.ScopeTasksWrite); err != nil {
+		return &pb.EscalationRuleResponse{
+			Error: err.Error(),
+		}, nil
+	}
+
+	rule, err := s.repo.GetEscalationRule(req.Id)
+	if err != nil {
+		return &pb.EscalationRuleResponse{
+			Error: err.Error(),
+		}, nil
+	}
+
+	rule.ProjectID = req.ProjectId
+	rule.Priority = models.TaskPriority(req.Priority)
+	rule.AfterHours = int(req.AfterHours)
+	rule.Action = models.EscalationAction(req.Action)
+	rule.NotifyUserID = req.NotifyUserId
+	if err := rule.Validate(); err != nil {
+		return &pb.EscalationRuleResponse{
+			Error: err.Error(),
+		}, nil
+	}
+
+	updated, err := s.repo.UpdateEscalationRule(rule)
+	if err != nil {
+		return &pb.EscalationRuleResponse{
+			Error: err.Error(),
+		}, nil
+	}
+
+	return &pb.EscalationRuleResponse{
+		Rule: convertEscalationRuleToProto(updated),
+	}, nil
+}
+
+func (s *TaskServer) DeleteEscalationRule(ctx context.Context, req *pb.DeleteEscalationRuleRequest) (*pb.DeleteEscalationRuleResponse, error) {
+	if err := s.authz.EscalationRule(ctx, req.Id, auth.ScopeTasksWrite); err != nil {
+		return &pb.DeleteEscalationRuleResponse{
+			Success: false,
+			Error:   err.Error(),
+		}, nil
+	}
+
+	err := s.repo.DeleteEscalationRule(req.Id)
+	if err != nil {
+		return &pb.DeleteEscalationRuleResponse{
+			Success: false,
+			Error:   err.Error(),
+		}, nil
+	}
+
+	return &pb.DeleteEscalationRuleResponse{
+		Success: true,
+	}, nil
+}
+
+func (s *TaskServer) ListEscalationRules(ctx context.Context, req *pb.ListEscalationRulesRequest) (*pb.ListEscalationRulesResponse, error) {
+	userID, err := auth.ScopeUser(ctx, req.UserId, auth.ScopeTasksRead)
+	if err != nil {
+		return &pb.ListEscalationRulesResponse{
+			Error: err.Error(),
+		}, nil
+	}
+
+	rules, err := s.repo.ListEscalationRules(userID)
+	if err != nil {
+		return &pb.ListEscalationRulesResponse{
+			Error: err.Error(),
+		}, nil
+	}
+
+	response := &pb.ListEscalationRulesResponse{}
+	for _, rule := range rules {
+		response.Rules = append(response.Rules, convertEscalationRuleToProto(rule))
+	}
+
+	return response, nil
+}
+
+func (s *TaskServer) ListTaskEscalations(ctx context.Context, req *pb.ListTaskEscalationsRequest) (*pb.ListTaskEscalationsResponse, error) {
+	if err := s.authz.Task(ctx, req.TaskId, auth.ScopeTasksRead); err != nil {
+		return &pb.ListTaskEscalationsResponse{
+			Error: err.Error(),
+		}, nil
+	}
+
+	escalations, err := s.repo.ListTaskEscalations(req.TaskId)
+	if err != nil {
+		return &pb.ListTaskEscalationsResponse{
+			Error: err.Error(),
+		}, nil
+	}
+
+	response := &pb.ListTaskEscalationsResponse{}
+	for _, escalation := range escalations {
+		response.Escalations = append(response.Escalations, &pb.Escalation{
+			Id:              escalation.ID,
+			RuleId:          escalation.RuleID,
+			TaskId:          escalation.TaskID,
+			DueDate:         timestamppb.New(escalation.DueDate),
+			Action:          string(escalation.Action),
+			FromPriority:    string(escalation.FromPriority),
+			ToPriority:      string(escalation.ToPriority),
+			NotifiedUserIds: escalation.NotifiedUserIDs,
+			CreatedAt:       timestamppb.New(escalation.CreatedAt),
+		})
+	}
+
+	return response, nil
+}
+
+func (s *TaskServer) AddWatcher(ctx context.Context, req *pb.WatcherRequest) (*pb.WatchersResponse, error) {
+	if err := s.authz.Task(ctx, req.TaskId, auth.ScopeTasksWrite); err != nil {
+		return &pb.WatchersResponse{
+			Error: err.Error(),
+		}, nil
+	}
+
+	if err := s.repo.AddWatcher(req.TaskId, req.UserId); err != nil {
+		return &pb.WatchersResponse{
+			Error: err.Error(),
+		}, nil
+	}
+
+	return s.ListWatchers(ctx, &pb.ListWatchersRequest{TaskId: req.TaskId})
+}
+
+func (s *TaskServer) RemoveWatcher(ctx context.Context, req *pb.WatcherRequest) (*pb.WatchersResponse, error) {
+	// Watchers may remove themselves; anyone else needs to own the task
+	if auth.Authorize(ctx, req.UserId, auth.ScopeTasksWrite) != nil {
+		if err := s.authz.Task(ctx, req.TaskId, auth.ScopeTasksWrite); err != nil {
+			return &pb.WatchersResponse{
+				Error: err.Error(),
+			}, nil
+		}
+	}
+
+	if err := s.repo.RemoveWatcher(req.TaskId, req.UserId); err != nil {
+		return &pb.WatchersResponse{
+			Error: err.Error(),
+		}, nil
+	}
+
+	return s.ListWatchers(ctx, &pb.ListWatchersRequest{TaskId: req.TaskId})
+}
+
+func (s *TaskServer) ListWatchers(ctx context.Context, req *pb.ListWatchersRequest) (*pb.WatchersResponse, error) {
+	if err := s.authz.Task(ctx, req.TaskId, auth.ScopeTasksRead); err != nil {
+		return &pb.WatchersResponse{
+			Error: err.Error(),
+		}, nil
+	}
+
+	userIDs, err := s.repo.ListWatchers(req.TaskId)
+	if err != nil {
+		return &pb.WatchersResponse{
+			Error: err.Error(),
+		}, nil
+	}
+
+	return &pb.WatchersResponse{
+		UserIds: userIDs,
+	}, nil
+}
+
+func (s *TaskServer) GetEscalationSettings(ctx context.Context, req *pb.GetEscalationSettingsRequest) (*pb.EscalationSettingsResponse, error) {
+	if err := s.authz.Project(ctx, req.ProjectId, auth.ScopeTasksRead); err != nil {
+		return &pb.EscalationSettingsResponse{
+			Error: err.Error(),
+		}, nil
+	}
+
+	settings, err := s.repo.GetProjectEscalationSettings(req.ProjectId)
+	if err != nil {
+		return &pb.EscalationSettingsResponse{
+			Error: err.Error(),
+		}, nil
+	}
+
+	return &pb.EscalationSettingsResponse{
+		Settings: convertEscalationSettingsToProto(settings),
+	}, nil
+}
+
+func (s *TaskServer) UpdateEscalationSettings(ctx context.Context, req *pb.UpdateEscalationSettingsRequest) (*pb.EscalationSettingsResponse, error) {
+	if err := s.authz.Project(ctx, req.ProjectId, auth.ScopeTasksWrite); err != nil {
+		return &pb.EscalationSettingsResponse{
+			Error: err.Error(),
+		}, nil
+	}
+
+	settings, err := s.repo.SetProjectEscalationOptOut(req.ProjectId, req.OptedOut)
+	if err != nil {
+		return &pb.EscalationSettingsResponse{
+			Error: err.Error(),
+		}, nil
+	}
+
+	return &pb.EscalationSettingsResponse{
+		Settings: convertEscalationSettingsToProto(settings),
+	}, nil
+}
+
+func convertEscalationRuleToProto(rule *models.EscalationRule) *pb.EscalationRule {
+	return &pb.EscalationRule{
+		Id:           rule.ID,
+		UserId:       rule.UserID,
+		ProjectId:    rule.ProjectID,
+		Priority:     string(rule.Priority),
+		AfterHours:   int32(rule.AfterHours),
+		Action:       string(rule.Action),
+		NotifyUserId: rule.NotifyUserID,
+		CreatedAt:    timestamppb.New(rule.CreatedAt),
+		UpdatedAt:    timestamppb.New(rule.UpdatedAt),
+	}
+}
+
+func convertEscalationSettingsToProto(settings *models.ProjectEscalationSettings) *pb.EscalationSettings {
+	pbSettings := &pb.EscalationSettings{
+		ProjectId: settings.ProjectID,
+		OptedOut:  settings.OptedOut,
+	}
+	if settings.UpdatedAt != nil {
+		pbSettings.UpdatedAt = timestamppb.New(*settings.UpdatedAt)
+	}
+	return pbSettings
+}
@@ -0,0 +1,87 @@
+package grpc
+
+import (
+	"context"
+	"time"
+
+	"github.com/todo/pkg/auth"
+	pb "github.com/todo/proto/task"
+	"github.com/todo/services/task-service/internal/models"
+	"github.com/todo/services/task-service/internal/quickadd"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// QuickAddTask parses a natural-language phrase into a task and creates it,
+// returning both the interpretation and the stored task.
+func (s *TaskServer) QuickAddTask(ctx context.Context, req *pb.QuickAddTaskRequest) (*pb.QuickAddTaskResponse, error) {
+	userID, err := auth.ScopeUser(ctx, req.UserId, auth.ScopeTasksWrite)
+	if err != nil {
+		return &pb.QuickAddTaskResponse{
+			Error: err.Error(),
+		}, nil
+	}
+
+	if err := s.authz.JoinProject(ctx, req.ProjectId); err != nil {
+		return &pb.QuickAddTaskResponse{
+			Error: err.Error(),
+		}, nil
+	}
+
+	timezone, err := s.repo.UserTimezone(userID, req.Timezone)
+	if err != nil {
+		return &pb.QuickAddTaskResponse{
+			Error: err.Error(),
+		}, nil
+	}
+	loc, err := models.LoadTimezone(timezone)
+	if err != nil {
+		return &pb.QuickAddTaskResponse{
+			Error: err.Error(),
+		}, nil
+	}
+
+	parsed, err := quickadd.Parse(req.Text, time.Now(), loc)
+	if err != nil {
+		return &pb.QuickAddTaskResponse{
+			Error: err.Error(),
+		}, nil
+	}
+
+	task, err := s.repo.CreateTask(&models.Task{
+		Title:      parsed.Title,
+		Priority:   parsed.Priority,
+		UserID:     userID,
+		ProjectID:  req.ProjectId,
+		DueDate:    parsed.DueDate,
+		DueAllDay:  parsed.DueDate != nil && !parsed.HasTime,
+		Timezone:   loc.String(),
+		Tags:       parsed.Tags,
+		Recurrence: parsed.Recurrence,
+	})
+	if err != nil {
+		return &pb.QuickAddTaskResponse{
+			Error: err.Error(),
+		}, nil
+	}
+
+	return &pb.QuickAddTaskResponse{
+		Parsed: convertParsedTaskToProto(parsed),
+		Task:   convertTaskToProto(task),
+	}, nil
+}
+
+func convertParsedTaskToProto(parsed *quickadd.Result) *pb.ParsedTask {
+	pbParsed := &pb.ParsedTask{
+		Title:      parsed.Title,
+		HasTime:    parsed.HasTime,
+		Recurrence: parsed.Recurrence,
+		Priority:   convertPriorityToProto(parsed.Priority),
+		Tags:       parsed.Tags,
+	}
+
+	if parsed.DueDate != nil {
+		pbParsed.DueDate = timestamppb.New(*parsed.DueDate)
+	}
+
+	return pbParsed
+}
@@ -0,0 +1,169 @@
+package grpc
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	pb "github.com/todo/proto/task"
+	"github.com/todo/services/task-service/internal/models"
+)
+
+func (s *TaskServer) GetReportSummary(ctx context.Context, req *pb.GetReportSummaryRequest) (*pb.GetReportSummaryResponse, error) {
+	filter, err := convertReportFilterFromProto(req.Filter)
+	if err != nil {
+		return &pb.GetReportSummaryResponse{
+			Error: err.Error(),
+		}, nil
+	}
+
+	if err := s.authz.Report(ctx, filter); err != nil {
+		return &pb.GetReportSummaryResponse{
+			Error: err.Error(),
+		}, nil
+	}
+
+	summary, err := s.repo.ReportSummary(filter, time.Now())
+	if err != nil {
+		return &pb.GetReportSummaryResponse{
+			Error: err.Error(),
+		}, nil
+	}
+
+	return &pb.GetReportSummaryResponse{
+		Summary: &pb.ReportSummary{
+			Created:                 int32(summary.Created),
+			Completed:               int32(summary.Completed),
+			AverageCycleTimeSeconds: summary.AverageCycleTimeSeconds,
+			MedianCycleTimeSeconds:  summary.MedianCycleTimeSeconds,
+			Due:                     int32(summary.Due),
+			Overdue:                 int32(summary.Overdue),
+			OverdueRate:             summary.OverdueRate,
+		},
+	}, nil
+}
+
+func (s *TaskServer) GetThroughputReport(ctx context.Context, req *pb.GetThroughputReportRequest) (*pb.GetThroughputReportResponse, error) {
+	filter, err := convertReportFilterFromProto(req.Filter)
+	if err != nil {
+		return &pb.GetThroughputReportResponse{
+			Error: err.Error(),
+		}, nil
+	}
+
+	if err := s.authz.Report(ctx, filter); err != nil {
+		return &pb.GetThroughputReportResponse{
+			Error: err.Error(),
+		}, nil
+	}
+
+	interval := models.ReportInterval(req.Interval)
+	if interval == "" {
+		interval = models.IntervalWeek
+	}
+
+	points, err := s.repo.Throughput(filter, interval)
+	if err != nil {
+		return &pb.GetThroughputReportResponse{
+			Error: err.Error(),
+		}, nil
+	}
+
+	pbPoints := make([]*pb.ThroughputPoint, len(points))
+	for i, point := range points {
+		pbPoints[i] = &pb.ThroughputPoint{
+			Period:    point.Period,
+			Created:   int32(point.Created),
+			Completed: int32(point.Completed),
+		}
+	}
+
+	return &pb.GetThroughputReportResponse{
+		Points: pbPoints,
+	}, nil
+}
+
+func (s *TaskServer) GetDistributionReport(ctx context.Context, req *pb.GetDistributionReportRequest) (*pb.GetDistributionReportResponse, error) {
+	filter, err := convertReportFilterFromProto(req.Filter)
+	if err != nil {
+		return &pb.GetDistributionReportResponse{
+			Error: err.Error(),
+		}, nil
+	}
+
+	if err := s.authz.Report(ctx, filter); err != nil {
+		return &pb.GetDistributionReportResponse{
+			Error: err.Error(),
+		}, nil
+	}
+
+	buckets, err := s.repo.Distribution(filter, models.DistributionDimension(req.By))
+	if err != nil {
+		return &pb.GetDistributionReportResponse{
+			Error: err.Error(),
+		}, nil
+	}
+
+	pbBuckets := make([]*pb.DistributionBucket, len(buckets))
+	for i, bucket := range buckets {
+		pbBuckets[i] = &pb.DistributionBucket{
+			Key:   bucket.Key,
+			Count: int32(bucket.Count),
+		}
+	}
+
+	return &pb.GetDistributionReportResponse{
+		Buckets: pbBuckets,
+	}, nil
+}
+
+func (s *TaskServer) GetBurndownReport(ctx context.Context, req *pb.GetBurndownReportRequest) (*pb.GetBurndownReportResponse, error) {
+	filter, err := convertReportFilterFromProto(req.Filter)
+	if err != nil {
+		return &pb.GetBurndownReportResponse{
+			Error: err.Error(),
+		}, nil
+	}
+
+	if err := s.authz.Report(ctx, filter); err != nil {
+		return &pb.GetBurndownReportResponse{
+			Error: err.Error(),
+		}, nil
+	}
+
+	points, err := s.repo.Burndown(filter)
+	if err != nil {
+		return &pb.GetBurndownReportResponse{
+			Error: err.Error(),
+		}, nil
+	}
+
+	pbPoints := make([]*pb.BurndownPoint, len(points))
+	for i, point := range points {
+		pbPoints[i] = &pb.BurndownPoint{
+			Date:      point.Date,
+			Open:      int32(point.Open),
+			Completed: int32(point.Completed),
+		}
+	}
+
+	return &pb.GetBurndownReportResponse{
+		Points: pbPoints,
+	}, nil
+}
+
+func convertReportFilterFromProto(filter *pb.ReportFilter) (models.ReportFilter, error) {
+	if filter == nil {
+		filter = &pb.ReportFilter{}
+	}
+
+	loc := time.UTC
+	if filter.Timezone != "" {
+		var err error
+		if loc, err = time.LoadLocation(filter.Timezone); err != nil {
+			return models.ReportFilter{}, fmt.Errorf("invalid timezone: %s", filter.Timezone)
+		}
+	}
+
+	return models.NewReportFilter(filter.UserId, filter.ProjectId, timeOrNil(filter.From), timeOrNil(filter.To), loc, time.Now())
+}
@@ -2,9 +2,12 @@ package grpc
 
 import (
 	"context"
+	"encoding/json"
 	"time"
 
+	"github.com/todo/pkg/auth"
 	pb "github.com/todo/proto/task"
+	"github.com/todo/services/task-service/internal/authz"
 	"github.com/todo/services/task-service/internal/models"
 	"github.com/todo/services/task-service/internal/repository"
 	"google.golang.org/protobuf/types/known/timestamppb"
@@ -12,14 +15,31 @@ import (
 
 type TaskServer struct {
 	pb.UnimplementedTaskServiceServer
-	repo *repository.PostgresRepository
+	repo  *repository.PostgresRepository
+	authz *authz.Authorizer
 }
 
 func NewTaskServer(repo *repository.PostgresRepository) *TaskServer {
-	return &TaskServer{repo: repo}
+	return &TaskServer{
+		repo:  repo,
+		authz: authz.NewAuthorizer(repo),
+	}
 }
 
 func (s *TaskServer) CreateTask(ctx context.Context, req *pb.CreateTaskRequest) (*pb.CreateTaskResponse, error) {
+	userID, err := auth.ScopeUser(ctx, req.UserId, auth.ScopeTasksWrite)
+	if err != nil {
+		return &pb.CreateTaskResponse{
+			Error: err.Error(),
+		}, nil
+	}
+
+	if err := s.authz.JoinProject(ctx, req.ProjectId); err != nil {
+		return &pb.CreateTaskResponse{
+			Error: err.Error(),
+		}, nil
+	}
+
 	priority := convertPriorityFromProto(req.Priority)
 
 	var dueDate *time.Time
@@ -28,7 +48,20 @@ func (s *TaskServer) CreateTask(ctx context.Context, req *pb.CreateTaskRequest)
 		dueDate = &t
 	}
 
-	task, err := s.repo.CreateTask(req.Title, req.Description, req.UserId, priority, dueDate)
+	task, err := s.repo.CreateTask(&models.Task{
+		Title:        req.Title,
+		Description:  req.Description,
+		Priority:     priority,
+		UserID:       userID,
+		ProjectID:    req.ProjectId,
+		DueDate:      dueDate,
+		DueAllDay:    req.DueAllDay,
+		Timezone:     req.Timezone,
+		StartDate:    timeOrNil(req.StartDate),
+		Tags:         req.Tags,
+		Recurrence:   req.Recurrence,
+		CustomFields: convertCustomFieldValuesFromProto(req.CustomFields),
+	})
 	if err != nil {
 		return &pb.CreateTaskResponse{
 			Error: err.Error(),
@@ -41,6 +74,12 @@ func (s *TaskServer) CreateTask(ctx context.Context, req *pb.CreateTaskRequest)
 }
 
 func (s *TaskServer) GetTask(ctx context.Context, req *pb.GetTaskRequest) (*pb.GetTaskResponse, error) {
+	if err := s.authz.TaskScope(ctx, req.Id, auth.ScopeTasksRead); err != nil {
+		return &pb.GetTaskResponse{
+			Error: err.Error(),
+		}, nil
+	}
+
 	task, err := s.repo.GetTaskByID(req.Id)
 	if err != nil {
 		return &pb.GetTaskResponse{
@@ -54,6 +93,17 @@ func (s *TaskServer) GetTask(ctx context.Context, req *pb.GetTaskRequest) (*pb.G
 }
 
 func (s *TaskServer) UpdateTask(ctx context.Context, req *pb.UpdateTaskRequest) (*pb.UpdateTaskResponse, error) {
+	if err := s.authz.TaskScope(ctx, req.Id, auth.ScopeTasksWrite); err != nil {
+		return &pb.UpdateTaskResponse{
+			Error: err.Error(),
+		}, nil
+	}
+	if err := s.authz.TaskProject(ctx, req.Id, req.ProjectId); err != nil {
+		return &pb.UpdateTaskResponse{
+			Error: err.Error(),
+		}, nil
+	}
+
 	status := convertStatusFromProto(req.Status)
 	priority := convertPriorityFromProto(req.Priority)
 
@@ -63,7 +113,21 @@ func (s *TaskServer) UpdateTask(ctx context.Context, req *pb.UpdateTaskRequest)
 		dueDate = &t
 	}
 
-	task, err := s.repo.UpdateTask(req.Id, req.Title, req.Description, status, priority, dueDate)
+	task, err := s.repo.UpdateTask(&models.Task{
+		ID:           req.Id,
+		Title:        req.Title,
+		Description:  req.Description,
+		Status:       status,
+		Priority:     priority,
+		ProjectID:    req.ProjectId,
+		DueDate:      dueDate,
+		DueAllDay:    req.DueAllDay,
+		Timezone:     req.Timezone,
+		StartDate:    timeOrNil(req.StartDate),
+		Tags:         req.Tags,
+		Recurrence:   req.Recurrence,
+		CustomFields: convertCustomFieldValuesFromProto(req.CustomFields),
+	})
 	if err != nil {
 		return &pb.UpdateTaskResponse{
 			Error: err.Error(),
@@ -76,6 +140,13 @@ func (s *TaskServer) UpdateTask(ctx context.Context, req *pb.UpdateTaskRequest)
 }
 
 func (s *TaskServer) DeleteTask(ctx context.Context, req *pb.DeleteTaskRequest) (*pb.DeleteTaskResponse, error) {
+	if err := s.authz.TaskScope(ctx, req.Id, auth.ScopeTasksDelete); err != nil {
+		return &pb.DeleteTaskResponse{
+			Success: false,
+			Error:   err.Error(),
+		}, nil
+	}
+
 	err := s.repo.DeleteTask(req.Id)
 	if err != nil {
 		return &pb.DeleteTaskResponse{
@@ -90,6 +161,12 @@ func (s *TaskServer) DeleteTask(ctx context.Context, req *pb.DeleteTaskRequest)
 }
 
 func (s *TaskServer) ListTasks(ctx context.Context, req *pb.ListTasksRequest) (*pb.ListTasksResponse, error) {
+	if err := auth.RequireScope(ctx, auth.ScopeTasksRead); err != nil {
+		return &pb.ListTasksResponse{
+			Error: err.Error(),
+		}, nil
+	}
+
 	tasks, total, err := s.repo.ListTasks(int(req.Page), int(req.PageSize))
 	if err != nil {
 		return &pb.ListTasksResponse{
@@ -109,8 +186,49 @@ func (s *TaskServer) ListTasks(ctx context.Context, req *pb.ListTasksRequest) (*
 }
 
 func (s *TaskServer) ListUserTasks(ctx context.Context, req *pb.ListUserTasksRequest) (*pb.ListUserTasksResponse, error) {
-	status := convertStatusFromProto(req.Status)
-	tasks, total, err := s.repo.ListUserTasks(req.UserId, int(req.Page), int(req.PageSize), status)
+	userID, err := auth.ScopeUserWith(ctx, req.UserId, auth.ScopeTasksRead)
+	if err != nil {
+		return &pb.ListUserTasksResponse{
+			Error: err.Error(),
+		}, nil
+	}
+
+	filter := models.TaskFilter{
+		Statuses:        []models.TaskStatus{convertStatusFromProto(req.Status)},
+		CustomFields:    req.CustomFields,
+		Due:             models.DueFilter(req.Due),
+		Snoozed:         models.SnoozedFilter(req.Snoozed),
+		IncludeArchived: req.IncludeArchived,
+	}
+	if err := filter.Validate(); err != nil {
+		return &pb.ListUserTasksResponse{
+			Error: err.Error(),
+		}, nil
+	}
+
+	sort := convertTaskSortFromProto(req.Sort)
+	if err := sort.Validate(); err != nil {
+		return &pb.ListUserTasksResponse{
+			Error: err.Error(),
+		}, nil
+	}
+
+	page, pageSize := int(req.Page), int(req.PageSize)
+	if page < 1 {
+		page = 1
+	}
+	if pageSize < 1 {
+		pageSize = 10
+	}
+
+	loc, err := s.repo.FilterTimezone(userID, req.Timezone)
+	if err != nil {
+		return &pb.ListUserTasksResponse{
+			Error: err.Error(),
+		}, nil
+	}
+
+	tasks, total, err := s.repo.QueryUserTasks(userID, filter, sort, loc, page, pageSize)
 	if err != nil {
 		return &pb.ListUserTasksResponse{
 			Error: err.Error(),
@@ -128,22 +246,91 @@ func (s *TaskServer) ListUserTasks(ctx context.Context, req *pb.ListUserTasksReq
 	}, nil
 }
 
+func (s *TaskServer) DeleteUserTasks(ctx context.Context, req *pb.DeleteUserTasksRequest) (*pb.DeleteUserTasksResponse, error) {
+	if err := auth.AuthorizeScope(ctx, req.UserId, auth.ScopeTasksDelete); err != nil {
+		return &pb.DeleteUserTasksResponse{
+			Error: err.Error(),
+		}, nil
+	}
+
+	deleted, err := s.repo.DeleteUserTasks(req.UserId)
+	if err != nil {
+		return &pb.DeleteUserTasksResponse{
+			Error: err.Error(),
+		}, nil
+	}
+
+	return &pb.DeleteUserTasksResponse{
+		Deleted: int32(deleted),
+	}, nil
+}
+
+// ExportUserData returns everything kept about the user as a JSON document
+// for data access requests.
+func (s *TaskServer) ExportUserData(ctx context.Context, req *pb.ExportUserDataRequest) (*pb.ExportUserDataResponse, error) {
+	if err := auth.Authorize(ctx, req.UserId, auth.ScopeTasksRead); err != nil {
+		return &pb.ExportUserDataResponse{
+			Error: err.Error(),
+		}, nil
+	}
+
+	userData, err := s.repo.ExportUserData(req.UserId)
+	if err != nil {
+		return &pb.ExportUserDataResponse{
+			Error: err.Error(),
+		}, nil
+	}
+
+	data, err := json.Marshal(userData)
+	if err != nil {
+		return &pb.ExportUserDataResponse{
+			Error: err.Error(),
+		}, nil
+	}
+
+	return &pb.ExportUserDataResponse{
+		Data: data,
+	}, nil
+}
+
 func convertTaskToProto(task *models.Task) *pb.Task {
 	pbTask := &pb.Task{
-		Id:          task.ID,
-		Title:       task.Title,
-		Description: task.Description,
-		Status:      convertStatusToProto(task.Status),
-		Priority:    convertPriorityToProto(task.Priority),
-		UserId:      task.UserID,
-		CreatedAt:   timestamppb.New(task.CreatedAt),
-		UpdatedAt:   timestamppb.New(task.UpdatedAt),
+		Id:           task.ID,
+		Title:        task.Title,
+		Description:  task.Description,
+		Status:       convertStatusToProto(task.Status),
+		Priority:     convertPriorityToProto(task.Priority),
+		UserId:       task.UserID,
+		ProjectId:    task.ProjectID,
+		DueAllDay:    task.DueAllDay,
+		Timezone:     task.Timezone,
+		Tags:         task.Tags,
+		Recurrence:   task.Recurrence,
+		ListRank:     task.ListRank,
+		BoardRank:    task.BoardRank,
+		CustomFields: convertCustomFieldValuesToProto(task.CustomFields),
+		CreatedAt:    timestamppb.New(task.CreatedAt),
+		UpdatedAt:    timestamppb.New(task.UpdatedAt),
 	}
 
 	if task.DueDate != nil {
 		pbTask.DueDate = timestamppb.New(*task.DueDate)
 	}
 
+	if task.StartDate != nil {
+		pbTask.StartDate = timestamppb.New(*task.StartDate)
+	}
+	if task.CompletedAt != nil {
+		pbTask.CompletedAt = timestamppb.New(*task.CompletedAt)
+	}
+	if task.ArchivedAt != nil {
+		pbTask.ArchivedAt = timestamppb.New(*task.ArchivedAt)
+	}
+
+	for _, item := range task.Checklist {
+		pbTask.Checklist = append(pbTask.Checklist, convertChecklistItemToProto(item))
+	}
+
 	return pbTask
 }
 
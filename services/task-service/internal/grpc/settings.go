@@ -0,0 +1,59 @@
+package grpc
+
+import (
+	"context"
+
+	"github.com/todo/pkg/auth"
+	pb "github.com/todo/proto/task"
+	"github.com/todo/services/task-service/internal/models"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func (s *TaskServer) GetUserSettings(ctx context.Context, req *pb.GetUserSettingsRequest) (*pb.UserSettingsResponse, error) {
+	if err := auth.Authorize(ctx, req.UserId, auth.ScopeTasksRead); err != nil {
+		return &pb.UserSettingsResponse{
+			Error: err.Error(),
+		}, nil
+	}
+
+	settings, err := s.repo.GetUserSettings(req.UserId)
+	if err != nil {
+		return &pb.UserSettingsResponse{
+			Error: err.Error(),
+		}, nil
+	}
+
+	return &pb.UserSettingsResponse{
+		Settings: convertUserSettingsToProto(settings),
+	}, nil
+}
+
+func (s *TaskServer) UpdateUserSettings(ctx context.Context, req *pb.UpdateUserSettingsRequest) (*pb.UserSettingsResponse, error) {
+	if err := auth.Authorize(ctx, req.UserId, auth.ScopeTasksWrite); err != nil {
+		return &pb.UserSettingsResponse{
+			Error: err.Error(),
+		}, nil
+	}
+
+	settings, err := s.repo.UpdateUserSettings(req.UserId, req.Timezone)
+	if err != nil {
+		return &pb.UserSettingsResponse{
+			Error: err.Error(),
+		}, nil
+	}
+
+	return &pb.UserSettingsResponse{
+		Settings: convertUserSettingsToProto(settings),
+	}, nil
+}
+
+func convertUserSettingsToProto(settings *models.UserSettings) *pb.UserSettings {
+	pbSettings := &pb.UserSettings{
+		UserId:   settings.UserID,
+		Timezone: settings.Timezone,
+	}
+	if !settings.UpdatedAt.IsZero() {
+		pbSettings.UpdatedAt = timestamppb.New(settings.UpdatedAt)
+	}
+	return pbSettings
+}
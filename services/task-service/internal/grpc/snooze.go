@@ -0,0 +1,50 @@
+package grpc
+
+import (
+	"context"
+	"time"
+
+	"github.com/todo/pkg/auth"
+	pb "github.com/todo/proto/task"
+	"github.com/todo/services/task-service/internal/models"
+)
+
+// SnoozeTask hides a task until a preset or explicit time without changing
+// its due date.
+func (s *TaskServer) SnoozeTask(ctx context.Context, req *pb.SnoozeTaskRequest) (*pb.SnoozeTaskResponse, error) {
+	if err := s.authz.Task(ctx, req.Id, auth.ScopeTasksWrite); err != nil {
+		return &pb.SnoozeTaskResponse{
+			Error: err.Error(),
+		}, nil
+	}
+
+	task, err := s.repo.SnoozeTask(req.Id, models.SnoozePreset(req.Preset), timeOrNil(req.Until), time.Now())
+	if err != nil {
+		return &pb.SnoozeTaskResponse{
+			Error: err.Error(),
+		}, nil
+	}
+
+	return &pb.SnoozeTaskResponse{
+		Task: convertTaskToProto(task),
+	}, nil
+}
+
+func (s *TaskServer) UnsnoozeTask(ctx context.Context, req *pb.UnsnoozeTaskRequest) (*pb.SnoozeTaskResponse, error) {
+	if err := s.authz.Task(ctx, req.Id, auth.ScopeTasksWrite); err != nil {
+		return &pb.SnoozeTaskResponse{
+			Error: err.Error(),
+		}, nil
+	}
+
+	task, err := s.repo.UnsnoozeTask(req.Id)
+	if err != nil {
+		return &pb.SnoozeTaskResponse{
+			Error: err.Error(),
+		}, nil
+	}
+
+	return &pb.SnoozeTaskResponse{
+		Task: convertTaskToProto(task),
+	}, nil
+}
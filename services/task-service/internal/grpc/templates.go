@@ -0,0 +1,320 @@
+package grpc
+
+import (
+	"context"
+	"time"
+
+	"github.com/todo/pkg/auth"
+	pb "github.com/todo/proto/task"
+	"github.com/todo/services/task-service/internal/models"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func (s *TaskServer) AddChecklistItem(ctx context.Context, req *pb.AddChecklistItemRequest) (*pb.ChecklistItemResponse, error) {
+	if err := s.authz.Task(ctx, req.TaskId, auth.ScopeTasksWrite); err != nil {
+		return &pb.ChecklistItemResponse{
+			Error: err.Error(),
+		}, nil
+	}
+
+	item, err := s.repo.AddChecklistItem(req.TaskId, req.Title)
+	if err != nil {
+		return &pb.ChecklistItemResponse{
+			Error: err.Error(),
+		}, nil
+	}
+
+	return &pb.ChecklistItemResponse{
+		Item: convertChecklistItemToProto(item),
+	}, nil
+}
+
+func (s *TaskServer) UpdateChecklistItem(ctx context.Context, req *pb.UpdateChecklistItemRequest) (*pb.ChecklistItemResponse, error) {
+	if err := s.authz.ChecklistItem(ctx, req.Id, auth.ScopeTasksWrite); err != nil {
+		return &pb.ChecklistItemResponse{
+			Error: err.Error(),
+		}, nil
+	}
+
+	item, err := s.repo.UpdateChecklistItem(req.Id, req.Title, req.Completed)
+	if err != nil {
+		return &pb.ChecklistItemResponse{
+			Error: err.Error(),
+		}, nil
+	}
+
+	return &pb.ChecklistItemResponse{
+		Item: convertChecklistItemToProto(item),
+	}, nil
+}
+
+func (s *TaskServer) DeleteChecklistItem(ctx context.Context, req *pb.DeleteChecklistItemRequest) (*pb.DeleteChecklistItemResponse, error) {
+	if err := s.authz.ChecklistItem(ctx, req.Id, auth.ScopeTasksWrite); err != nil {
+		return &pb.DeleteChecklistItemResponse{
+			Success: false,
+			Error:   err.Error(),
+		}, nil
+	}
+
+	err := s.repo.DeleteChecklistItem(req.Id)
+	if err != nil {
+		return &pb.DeleteChecklistItemResponse{
+			Success: false,
+			Error:   err.Error(),
+		}, nil
+	}
+
+	return &pb.DeleteChecklistItemResponse{
+		Success: true,
+	}, nil
+}
+
+func (s *TaskServer) CreateTemplate(ctx context.Context, req *pb.CreateTemplateRequest) (*pb.TemplateResponse, error) {
+	userID, err := auth.ScopeUser(ctx, req.UserId, auth.ScopeTasksWrite)
+	if err != nil {
+		return &pb.TemplateResponse{
+			Error: err.Error(),
+		}, nil
+	}
+
+	template := &models.TaskTemplate{
+		UserID:          userID,
+		Name:            req.Name,
+		Description:     req.Description,
+		DefaultPriority: convertPriorityFromProto(req.DefaultPriority),
+		Tasks:           convertTemplateTasksFromProto(req.Tasks),
+	}
+	if err := template.Validate(); err != nil {
+		return &pb.TemplateResponse{
+			Error: err.Error(),
+		}, nil
+	}
+
+	created, err := s.repo.CreateTemplate(template)
+	if err != nil {
+		return &pb.TemplateResponse{
+			Error: err.Error(),
+		}, nil
+	}
+
+	return &pb.TemplateResponse{
+		Template: convertTemplateToProto(created),
+	}, nil
+}
+
+func (s *TaskServer) GetTemplate(ctx context.Context, req *pb.GetTemplateRequest) (*pb.TemplateResponse, error) {
+	if err := s.authz.Template(ctx, req.Id, auth.ScopeTasksRead); err != nil {
+		return &pb.TemplateResponse{
+			Error: err.Error(),
+		}, nil
+	}
+
+	template, err := s.repo.GetTemplate(req.Id)
+	if err != nil {
+		return &pb.TemplateResponse{
+			Error: err.Error(),
+		}, nil
+	}
+
+	return &pb.TemplateResponse{
+		Template: convertTemplateToProto(template),
+	}, nil
+}
+
+func (s *TaskServer) UpdateTemplate(ctx context.Context, req *pb.UpdateTemplateRequest) (*pb.TemplateResponse, error) {
+	if err := s.authz.Template(ctx, req.Id, auth.ScopeTasksWrite); err != nil {
+		return &pb.TemplateResponse{
+			Error: err.Error(),
+		}, nil
+	}
+
+	template := &models.TaskTemplate{
+		ID:              req.Id,
+		Name:            req.Name,
+		Description:     req.Description,
+		DefaultPriority: convertPriorityFromProto(req.DefaultPriority),
+		Tasks:           convertTemplateTasksFromProto(req.Tasks),
+	}
+	if err := template.Validate(); err != nil {
+		return &pb.TemplateResponse{
+			Error: err.Error(),
+		}, nil
+	}
+
+	updated, err := s.repo.UpdateTemplate(template)
+	if err != nil {
+		return &pb.TemplateResponse{
+			Error: err.Error(),
+		}, nil
+	}
+
+	return &pb.TemplateResponse{
+		Template: convertTemplateToProto(updated),
+	}, nil
+}
+
+func (s *TaskServer) DeleteTemplate(ctx context.Context, req *pb.DeleteTemplateRequest) (*pb.DeleteTemplateResponse, error) {
+	if err := s.authz.Template(ctx, req.Id, auth.ScopeTasksWrite); err != nil {
+		return &pb.DeleteTemplateResponse{
+			Success: false,
+			Error:   err.Error(),
+		}, nil
+	}
+
+	err := s.repo.DeleteTemplate(req.Id)
+	if err != nil {
+		return &pb.DeleteTemplateResponse{
+			Success: false,
+			Error:   err.Error(),
+		}, nil
+	}
+
+	return &pb.DeleteTemplateResponse{
+		Success: true,
+	}, nil
+}
+
+func (s *TaskServer) ListTemplates(ctx context.Context, req *pb.ListTemplatesRequest) (*pb.ListTemplatesResponse, error) {
+	userID, err := auth.ScopeUser(ctx, req.UserId, auth.ScopeTasksRead)
+	if err != nil {
+		return &pb.ListTemplatesResponse{
+			Error: err.Error(),
+		}, nil
+	}
+
+	templates, err := s.repo.ListTemplates(userID)
+	if err != nil {
+		return &pb.ListTemplatesResponse{
+			Error: err.Error(),
+		}, nil
+	}
+
+	response := &pb.ListTemplatesResponse{}
+	for _, template := range templates {
+		response.Templates = append(response.Templates, convertTemplateToProto(template))
+	}
+
+	return response, nil
+}
+
+// InstantiateTemplate creates a template's tasks and checklists for a user,
+// with due dates offset from the start date.
+func (s *TaskServer) InstantiateTemplate(ctx context.Context, req *pb.InstantiateTemplateRequest) (*pb.InstantiateTemplateResponse, error) {
+	if err := s.authz.Template(ctx, req.TemplateId, auth.ScopeTasksWrite); err != nil {
+		return &pb.InstantiateTemplateResponse{
+			Error: err.Error(),
+		}, nil
+	}
+
+	userID, err := auth.ScopeUser(ctx, req.UserId, auth.ScopeTasksWrite)
+	if err != nil {
+		return &pb.InstantiateTemplateResponse{
+			Error: err.Error(),
+		}, nil
+	}
+	if err := s.authz.JoinProject(ctx, req.ProjectId); err != nil {
+		return &pb.InstantiateTemplateResponse{
+			Error: err.Error(),
+		}, nil
+	}
+
+	timezone, err := s.repo.UserTimezone(userID, req.Timezone)
+	if err != nil {
+		return &pb.InstantiateTemplateResponse{
+			Error: err.Error(),
+		}, nil
+	}
+	loc, err := models.LoadTimezone(timezone)
+	if err != nil {
+		return &pb.InstantiateTemplateResponse{
+			Error: err.Error(),
+		}, nil
+	}
+
+	start, allDay, err := models.TemplateStart(req.StartDate, loc, time.Now())
+	if err != nil {
+		return &pb.InstantiateTemplateResponse{
+			Error: err.Error(),
+		}, nil
+	}
+
+	tasks, err := s.repo.InstantiateTemplate(req.TemplateId, userID, req.ProjectId, start, allDay)
+	if err != nil {
+		return &pb.InstantiateTemplateResponse{
+			Error: err.Error(),
+		}, nil
+	}
+
+	pbTasks := make([]*pb.Task, len(tasks))
+	for i, task := range tasks {
+		pbTasks[i] = convertTaskToProto(task)
+	}
+
+	return &pb.InstantiateTemplateResponse{
+		Tasks: pbTasks,
+	}, nil
+}
+
+func convertChecklistItemToProto(item *models.ChecklistItem) *pb.ChecklistItem {
+	return &pb.ChecklistItem{
+		Id:        item.ID,
+		TaskId:    item.TaskID,
+		Title:     item.Title,
+		Completed: item.Completed,
+		Position:  int32(item.Position),
+		CreatedAt: timestamppb.New(item.CreatedAt),
+		UpdatedAt: timestamppb.New(item.UpdatedAt),
+	}
+}
+
+func convertTemplateTasksFromProto(tasks []*pb.TemplateTask) []models.TemplateTask {
+	result := make([]models.TemplateTask, len(tasks))
+	for i, task := range tasks {
+		result[i] = models.TemplateTask{
+			Title:       task.Title,
+			Description: task.Description,
+			Tags:        task.Tags,
+			Checklist:   task.Checklist,
+		}
+		if task.Priority != nil {
+			result[i].Priority = convertPriorityFromProto(*task.Priority)
+		}
+		if task.DueOffsetDays != nil {
+			offset := int(*task.DueOffsetDays)
+			result[i].DueOffsetDays = &offset
+		}
+	}
+	return result
+}
+
+func convertTemplateToProto(template *models.TaskTemplate) *pb.TaskTemplate {
+	pbTemplate := &pb.TaskTemplate{
+		Id:              template.ID,
+		UserId:          template.UserID,
+		Name:            template.Name,
+		Description:     template.Description,
+		DefaultPriority: convertPriorityToProto(template.DefaultPriority),
+		CreatedAt:       timestamppb.New(template.CreatedAt),
+		UpdatedAt:       timestamppb.New(template.UpdatedAt),
+	}
+
+	for _, task := range template.Tasks {
+		pbTask := &pb.TemplateTask{
+			Title:       task.Title,
+			Description: task.Description,
+			Tags:        task.Tags,
+			Checklist:   task.Checklist,
+		}
+		if task.Priority != "" {
+			priority := convertPriorityToProto(task.Priority)
+			pbTask.Priority = &priority
+		}
+		if task.DueOffsetDays != nil {
+			offset := int32(*task.DueOffsetDays)
+			pbTask.DueOffsetDays = &offset
+		}
+		pbTemplate.Tasks = append(pbTemplate.Tasks, pbTask)
+	}
+
+	return pbTemplate
+}
@@ -0,0 +1,249 @@
+package grpc
+
+import (
+	"context"
+	"time"
+
+	"github.com/todo/pkg/auth"
+	pb "github.com/todo/proto/task"
+	"github.com/todo/services/task-service/internal/models"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func (s *TaskServer) StartTimer(ctx context.Context, req *pb.StartTimerRequest) (*pb.TimeEntryResponse, error) {
+	if err := s.authz.Task(ctx, req.TaskId, auth.ScopeTasksWrite); err != nil {
+		return &pb.TimeEntryResponse{
+			Error: err.Error(),
+		}, nil
+	}
+
+	userID, err := auth.ScopeUser(ctx, req.UserId, auth.ScopeTasksWrite)
+	if err != nil {
+		return &pb.TimeEntryResponse{
+			Error: err.Error(),
+		}, nil
+	}
+
+	entry, err := s.repo.StartTimer(req.TaskId, userID, req.Note)
+	if err != nil {
+		return &pb.TimeEntryResponse{
+			Error: err.Error(),
+		}, nil
+	}
+
+	return &pb.TimeEntryResponse{
+		Entry: convertTimeEntryToProto(entry),
+	}, nil
+}
+
+func (s *TaskServer) StopTimer(ctx context.Context, req *pb.StopTimerRequest) (*pb.TimeEntryResponse, error) {
+	userID, err := auth.ScopeUser(ctx, req.UserId, auth.ScopeTasksWrite)
+	if err != nil {
+		return &pb.TimeEntryResponse{
+			Error: err.Error(),
+		}, nil
+	}
+
+	entry, err := s.repo.StopTimer(userID)
+	if err != nil {
+		return &pb.TimeEntryResponse{
+			Error: err.Error(),
+		}, nil
+	}
+
+	return &pb.TimeEntryResponse{
+		Entry: convertTimeEntryToProto(entry),
+	}, nil
+}
+
+func (s *TaskServer) GetRunningTimer(ctx context.Context, req *pb.GetRunningTimerRequest) (*pb.TimeEntryResponse, error) {
+	userID, err := auth.ScopeUser(ctx, req.UserId, auth.ScopeTasksRead)
+	if err != nil {
+		return &pb.TimeEntryResponse{
+			Error: err.Error(),
+		}, nil
+	}
+
+	entry, err := s.repo.GetRunningTimer(userID)
+	if err != nil {
+		return &pb.TimeEntryResponse{
+			Error: err.Error(),
+		}, nil
+	}
+
+	return &pb.TimeEntryResponse{
+		Entry: convertTimeEntryToProto(entry),
+	}, nil
+}
+
+func (s *TaskServer) CreateTimeEntry(ctx context.Context, req *pb.CreateTimeEntryRequest) (*pb.TimeEntryResponse, error) {
+	if err := s.authz.Task(ctx, req.TaskId, auth.ScopeTasksWrite); err != nil {
+		return &pb.TimeEntryResponse{
+			Error: err.Error(),
+		}, nil
+	}
+
+	userID, err := auth.ScopeUser(ctx, req.UserId, auth.ScopeTasksWrite)
+	if err != nil {
+		return &pb.TimeEntryResponse{
+			Error: err.Error(),
+		}, nil
+	}
+
+	if req.StartedAt == nil || req.EndedAt == nil {
+		return &pb.TimeEntryResponse{
+			Error: "started_at and ended_at are required",
+		}, nil
+	}
+
+	entry, err := s.repo.CreateTimeEntry(req.TaskId, userID, req.StartedAt.AsTime(), req.EndedAt.AsTime(), req.Note)
+	if err != nil {
+		return &pb.TimeEntryResponse{
+			Error: err.Error(),
+		}, nil
+	}
+
+	return &pb.TimeEntryResponse{
+		Entry: convertTimeEntryToProto(entry),
+	}, nil
+}
+
+func (s *TaskServer) DeleteTimeEntry(ctx context.Context, req *pb.DeleteTimeEntryRequest) (*pb.DeleteTimeEntryResponse, error) {
+	if err := s.authz.TimeEntry(ctx, req.Id, auth.ScopeTasksWrite); err != nil {
+		return &pb.DeleteTimeEntryResponse{
+			Success: false,
+			Error:   err.Error(),
+		}, nil
+	}
+
+	err := s.repo.DeleteTimeEntry(req.Id)
+	if err != nil {
+		return &pb.DeleteTimeEntryResponse{
+			Success: false,
+			Error:   err.Error(),
+		}, nil
+	}
+
+	return &pb.DeleteTimeEntryResponse{
+		Success: true,
+	}, nil
+}
+
+func (s *TaskServer) ListTimeEntries(ctx context.Context, req *pb.ListTimeEntriesRequest) (*pb.ListTimeEntriesResponse, error) {
+	filter, err := s.scopeTimeFilter(ctx, convertTimeFilterFromProto(req.Filter))
+	if err != nil {
+		return &pb.ListTimeEntriesResponse{
+			Error: err.Error(),
+		}, nil
+	}
+
+	entries, err := s.repo.ListTimeEntries(filter)
+	if err != nil {
+		return &pb.ListTimeEntriesResponse{
+			Error: err.Error(),
+		}, nil
+	}
+
+	pbEntries := make([]*pb.TimeEntry, len(entries))
+	for i, entry := range entries {
+		pbEntries[i] = convertTimeEntryToProto(entry)
+	}
+
+	return &pb.ListTimeEntriesResponse{
+		Entries: pbEntries,
+	}, nil
+}
+
+func (s *TaskServer) GetTimeSummary(ctx context.Context, req *pb.GetTimeSummaryRequest) (*pb.GetTimeSummaryResponse, error) {
+	filter, err := s.scopeTimeFilter(ctx, convertTimeFilterFromProto(req.Filter))
+	if err != nil {
+		return &pb.GetTimeSummaryResponse{
+			Error: err.Error(),
+		}, nil
+	}
+
+	totals, err := s.repo.SummarizeTime(filter, models.TimeGrouping(req.GroupBy), req.Timezone)
+	if err != nil {
+		return &pb.GetTimeSummaryResponse{
+			Error: err.Error(),
+		}, nil
+	}
+
+	var totalSeconds int64
+	pbTotals := make([]*pb.TimeTotal, len(totals))
+	for i, total := range totals {
+		pbTotals[i] = &pb.TimeTotal{
+			Key:             total.Key,
+			Label:           total.Label,
+			DurationSeconds: total.DurationSeconds,
+			Entries:         int32(total.Entries),
+		}
+		totalSeconds += total.DurationSeconds
+	}
+
+	return &pb.GetTimeSummaryResponse{
+		Totals:       pbTotals,
+		TotalSeconds: totalSeconds,
+	}, nil
+}
+
+// scopeTimeFilter limits a time filter to the caller's own entries unless it
+// names a user the caller may act for. A filter naming only a project covers
+// every user's entries in it, for the project's members.
+func (s *TaskServer) scopeTimeFilter(ctx context.Context, filter models.TimeFilter) (models.TimeFilter, error) {
+	if filter.UserID == "" && filter.ProjectID != "" {
+		if err := s.authz.Project(ctx, filter.ProjectID, auth.ScopeTasksRead); err != nil {
+			return models.TimeFilter{}, err
+		}
+		return filter, nil
+	}
+
+	userID, err := auth.ScopeUser(ctx, filter.UserID, auth.ScopeTasksRead)
+	if err != nil {
+		return models.TimeFilter{}, err
+	}
+	filter.UserID = userID
+	return filter, nil
+}
+
+func convertTimeFilterFromProto(filter *pb.TimeFilter) models.TimeFilter {
+	if filter == nil {
+		return models.TimeFilter{}
+	}
+
+	return models.TimeFilter{
+		UserID:    filter.UserId,
+		TaskID:    filter.TaskId,
+		ProjectID: filter.ProjectId,
+		From:      timeOrNil(filter.From),
+		To:        timeOrNil(filter.To),
+	}
+}
+
+func convertTimeEntryToProto(entry *models.TimeEntry) *pb.TimeEntry {
+	pbEntry := &pb.TimeEntry{
+		Id:              entry.ID,
+		TaskId:          entry.TaskID,
+		UserId:          entry.UserID,
+		StartedAt:       timestamppb.New(entry.StartedAt),
+		DurationSeconds: entry.DurationSeconds,
+		Note:            entry.Note,
+		Manual:          entry.Manual,
+		CreatedAt:       timestamppb.New(entry.CreatedAt),
+	}
+
+	if entry.EndedAt != nil {
+		pbEntry.EndedAt = timestamppb.New(*entry.EndedAt)
+	}
+
+	return pbEntry
+}
+
+// timeOrNil converts an optional proto timestamp.
+func timeOrNil(ts *timestamppb.Timestamp) *time.Time {
+	if ts == nil {
+		return nil
+	}
+	t := ts.AsTime()
+	return &t
+}
@@ -0,0 +1,290 @@
+package grpc
+
+import (
+	"context"
+
+	"github.com/todo/pkg/auth"
+	pb "github.com/todo/proto/task"
+	"github.com/todo/services/task-service/internal/models"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func (s *TaskServer) CreateView(ctx context.Context, req *pb.CreateViewRequest) (*pb.ViewResponse, error) {
+	userID, err := auth.ScopeUser(ctx, req.UserId, auth.ScopeTasksWrite)
+	if err != nil {
+		return &pb.ViewResponse{
+			Error: err.Error(),
+		}, nil
+	}
+
+	view := &models.SavedView{
+		Name:   req.Name,
+		Filter: convertTaskFilterFromProto(req.Filter),
+		Sort:   convertTaskSortFromProto(req.Sort),
+	}
+	if err := view.Validate(); err != nil {
+		return &pb.ViewResponse{
+			Error: err.Error(),
+		}, nil
+	}
+
+	created, err := s.repo.CreateView(userID, view.Name, view.Filter, view.Sort)
+	if err != nil {
+		return &pb.ViewResponse{
+			Error: err.Error(),
+		}, nil
+	}
+
+	return &pb.ViewResponse{
+		View: convertViewToProto(created),
+	}, nil
+}
+
+func (s *TaskServer) GetView(ctx context.Context, req *pb.GetViewRequest) (*pb.ViewResponse, error) {
+	if err := s.authz.View(ctx, req.Id, auth.ScopeTasksRead); err != nil {
+		return &pb.ViewResponse{
+			Error: err.Error(),
+		}, nil
+	}
+
+	view := models.BuiltInView(req.Id)
+	if view == nil {
+		var err error
+		view, err = s.repo.GetView(req.Id)
+		if err != nil {
+			return &pb.ViewResponse{
+				Error: err.Error(),
+			}, nil
+		}
+	}
+
+	return &pb.ViewResponse{
+		View: convertViewToProto(view),
+	}, nil
+}
+
+func (s *TaskServer) UpdateView(ctx context.Context, req *pb.UpdateViewRequest) (*pb.ViewResponse, error) {
+	if err := s.authz.View(ctx, req.Id, auth.ScopeTasksWrite); err != nil {
+		return &pb.ViewResponse{
+			Error: err.Error(),
+		}, nil
+	}
+
+	view := &models.SavedView{
+		Name:   req.Name,
+		Filter: convertTaskFilterFromProto(req.Filter),
+		Sort:   convertTaskSortFromProto(req.Sort),
+	}
+	if err := view.Validate(); err != nil {
+		return &pb.ViewResponse{
+			Error: err.Error(),
+		}, nil
+	}
+
+	updated, err := s.repo.UpdateView(req.Id, view.Name, view.Filter, view.Sort)
+	if err != nil {
+		return &pb.ViewResponse{
+			Error: err.Error(),
+		}, nil
+	}
+
+	return &pb.ViewResponse{
+		View: convertViewToProto(updated),
+	}, nil
+}
+
+func (s *TaskServer) DeleteView(ctx context.Context, req *pb.DeleteViewRequest) (*pb.DeleteViewResponse, error) {
+	if err := s.authz.View(ctx, req.Id, auth.ScopeTasksWrite); err != nil {
+		return &pb.DeleteViewResponse{
+			Success: false,
+			Error:   err.Error(),
+		}, nil
+	}
+
+	err := s.repo.DeleteView(req.Id)
+	if err != nil {
+		return &pb.DeleteViewResponse{
+			Success: false,
+			Error:   err.Error(),
+		}, nil
+	}
+
+	return &pb.DeleteViewResponse{
+		Success: true,
+	}, nil
+}
+
+func (s *TaskServer) ListViews(ctx context.Context, req *pb.ListViewsRequest) (*pb.ListViewsResponse, error) {
+	userID, err := auth.ScopeUser(ctx, req.UserId, auth.ScopeTasksRead)
+	if err != nil {
+		return &pb.ListViewsResponse{
+			Error: err.Error(),
+		}, nil
+	}
+
+	views, err := s.repo.ListViews(userID)
+	if err != nil {
+		return &pb.ListViewsResponse{
+			Error: err.Error(),
+		}, nil
+	}
+
+	response := &pb.ListViewsResponse{}
+	for _, view := range views {
+		response.Views = append(response.Views, convertViewToProto(view))
+	}
+
+	if req.IncludeCounts {
+		loc, err := s.repo.FilterTimezone(userID, req.Timezone)
+		if err != nil {
+			return &pb.ListViewsResponse{
+				Error: err.Error(),
+			}, nil
+		}
+
+		for _, view := range views {
+			count, err := s.repo.CountUserTasks(userID, view.Filter, loc)
+			if err != nil {
+				return &pb.ListViewsResponse{
+					Error: err.Error(),
+				}, nil
+			}
+			response.Counts = append(response.Counts, &pb.ViewCount{
+				ViewId: view.ID,
+				Count:  int32(count),
+			})
+		}
+	}
+
+	return response, nil
+}
+
+func (s *TaskServer) ExecuteView(ctx context.Context, req *pb.ExecuteViewRequest) (*pb.ExecuteViewResponse, error) {
+	userID, err := auth.ScopeUser(ctx, req.UserId, auth.ScopeTasksRead)
+	if err != nil {
+		return &pb.ExecuteViewResponse{
+			Error: err.Error(),
+		}, nil
+	}
+
+	if err := s.authz.View(ctx, req.ViewId, auth.ScopeTasksRead); err != nil {
+		return &pb.ExecuteViewResponse{
+			Error: err.Error(),
+		}, nil
+	}
+
+	view, err := s.repo.ResolveView(userID, req.ViewId)
+	if err != nil {
+		return &pb.ExecuteViewResponse{
+			Error: err.Error(),
+		}, nil
+	}
+
+	loc, err := s.repo.FilterTimezone(userID, req.Timezone)
+	if err != nil {
+		return &pb.ExecuteViewResponse{
+			Error: err.Error(),
+		}, nil
+	}
+
+	page, pageSize := int(req.Page), int(req.PageSize)
+	if page < 1 {
+		page = 1
+	}
+	if pageSize < 1 {
+		pageSize = 10
+	}
+
+	tasks, total, err := s.repo.QueryUserTasks(userID, view.Filter, view.Sort, loc, page, pageSize)
+	if err != nil {
+		return &pb.ExecuteViewResponse{
+			Error: err.Error(),
+		}, nil
+	}
+
+	pbTasks := make([]*pb.Task, len(tasks))
+	for i, task := range tasks {
+		pbTasks[i] = convertTaskToProto(task)
+	}
+
+	return &pb.ExecuteViewResponse{
+		View:  convertViewToProto(view),
+		Tasks: pbTasks,
+		Total: int32(total),
+	}, nil
+}
+
+func convertTaskFilterFromProto(filter *pb.TaskFilter) models.TaskFilter {
+	if filter == nil {
+		return models.TaskFilter{}
+	}
+
+	result := models.TaskFilter{
+		ProjectID:       filter.ProjectId,
+		Due:             models.DueFilter(filter.Due),
+		UpcomingDays:    int(filter.UpcomingDays),
+		Search:          filter.Search,
+		Tags:            filter.Tags,
+		CustomFields:    filter.CustomFields,
+		Snoozed:         models.SnoozedFilter(filter.Snoozed),
+		IncludeArchived: filter.IncludeArchived,
+	}
+	for _, status := range filter.Statuses {
+		result.Statuses = append(result.Statuses, convertStatusFromProto(status))
+	}
+	for _, priority := range filter.Priorities {
+		result.Priorities = append(result.Priorities, convertPriorityFromProto(priority))
+	}
+
+	return result
+}
+
+func convertTaskSortFromProto(sort *pb.TaskSort) models.TaskSort {
+	if sort == nil {
+		return models.TaskSort{}
+	}
+
+	return models.TaskSort{
+		Field:       models.SortField(sort.Field),
+		CustomField: sort.CustomField,
+		Descending:  sort.Descending,
+	}
+}
+
+func convertViewToProto(view *models.SavedView) *pb.SavedView {
+	pbView := &pb.SavedView{
+		Id:      view.ID,
+		UserId:  view.UserID,
+		Name:    view.Name,
+		BuiltIn: view.BuiltIn,
+		Filter: &pb.TaskFilter{
+			ProjectId:       view.Filter.ProjectID,
+			Due:             string(view.Filter.Due),
+			UpcomingDays:    int32(view.Filter.UpcomingDays),
+			Search:          view.Filter.Search,
+			Tags:            view.Filter.Tags,
+			CustomFields:    view.Filter.CustomFields,
+			Snoozed:         string(view.Filter.Snoozed),
+			IncludeArchived: view.Filter.IncludeArchived,
+		},
+		Sort: &pb.TaskSort{
+			Field:       string(view.Sort.Field),
+			CustomField: view.Sort.CustomField,
+			Descending:  view.Sort.Descending,
+		},
+	}
+
+	for _, status := range view.Filter.Statuses {
+		pbView.Filter.Statuses = append(pbView.Filter.Statuses, convertStatusToProto(status))
+	}
+	for _, priority := range view.Filter.Priorities {
+		pbView.Filter.Priorities = append(pbView.Filter.Priorities, convertPriorityToProto(priority))
+	}
+
+	if !view.BuiltIn {
+		pbView.CreatedAt = timestamppb.New(view.CreatedAt)
+		pbView.UpdatedAt = timestamppb.New(view.UpdatedAt)
+	}
+
+	return pbView
+}
@@ -0,0 +1,28 @@
+package http
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"github.com/todo/pkg/auth"
+)
+
+func (h *Handler) UnarchiveTask(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	if err := h.authz.Task(r.Context(), id, auth.ScopeTasksWrite); err != nil {
+		auth.HTTPError(w, err, http.StatusNotFound)
+		return
+	}
+
+	task, err := h.repo.UnarchiveTask(id)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(task)
+}
@@ -0,0 +1,202 @@
+package http
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"github.com/todo/pkg/auth"
+	"github.com/todo/services/task-service/internal/models"
+)
+
+// AutomationRuleRequest defines a rule. Enabled defaults to true, and UserID,
+// whose tasks the rule acts on, to the caller. UserID is fixed once created.
+type AutomationRuleRequest struct {
+	UserID         string                       `json:"user_id,omitempty"`
+	Name           string                       `json:"name"`
+	Enabled        *bool                        `json:"enabled,omitempty"`
+	Trigger        models.AutomationTrigger     `json:"trigger"`
+	ToStatus       models.TaskStatus            `json:"to_status,omitempty"`
+	Tag            string                       `json:"tag,omitempty"`
+	DueWithinHours int                          `json:"due_within_hours,omitempty"`
+	Conditions     []models.AutomationCondition `json:"conditions,omitempty"`
+	Actions        []models.AutomationAction    `json:"actions"`
+}
+
+func (req *AutomationRuleRequest) rule(id, projectID, userID string) *models.AutomationRule {
+	rule := &models.AutomationRule{
+		ID:             id,
+		ProjectID:      projectID,
+		UserID:         userID,
+		Name:           req.Name,
+		Enabled:        true,
+		Trigger:        req.Trigger,
+		ToStatus:       req.ToStatus,
+		Tag:            req.Tag,
+		DueWithinHours: req.DueWithinHours,
+		Conditions:     req.Conditions,
+		Actions:        req.Actions,
+	}
+	if req.Enabled != nil {
+		rule.Enabled = *req.Enabled
+	}
+	return rule
+}
+
+func (h *Handler) CreateAutomationRule(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	projectID := vars["project_id"]
+
+	if err := h.authz.Project(r.Context(), projectID, auth.ScopeTasksWrite); err != nil {
+		auth.HTTPError(w, err, http.StatusInternalServerError)
+		return
+	}
+
+	var req AutomationRuleRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	userID, err := auth.ScopeUser(r.Context(), req.UserID, auth.ScopeTasksWrite)
+	if err != nil {
+		auth.HTTPError(w, err, http.StatusBadRequest)
+		return
+	}
+
+	rule := req.rule("", projectID, userID)
+	if err := rule.Validate(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	created, err := h.repo.CreateAutomationRule(rule)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(created)
+}
+
+func (h *Handler) ListAutomationRules(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	projectID := vars["project_id"]
+
+	if err := h.authz.Project(r.Context(), projectID, auth.ScopeTasksRead); err != nil {
+		auth.HTTPError(w, err, http.StatusInternalServerError)
+		return
+	}
+
+	rules, err := h.repo.ListAutomationRules(projectID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	response := map[string]interface{}{
+		"rules": rules,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+func (h *Handler) GetAutomationRule(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	if err := h.authz.AutomationRule(r.Context(), id, auth.ScopeTasksRead); err != nil {
+		auth.HTTPError(w, err, http.StatusNotFound)
+		return
+	}
+
+	rule, err := h.repo.GetAutomationRule(id)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(rule)
+}
+
+func (h *Handler) UpdateAutomationRule(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	if err := h.authz.AutomationRuleOwner(r.Context(), id, auth.ScopeTasksWrite); err != nil {
+		auth.HTTPError(w, err, http.StatusNotFound)
+		return
+	}
+
+	var req AutomationRuleRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	existing, err := h.repo.GetAutomationRule(id)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	rule := req.rule(id, existing.ProjectID, existing.UserID)
+	if err := rule.Validate(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	updated, err := h.repo.UpdateAutomationRule(rule)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(updated)
+}
+
+func (h *Handler) DeleteAutomationRule(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	if err := h.authz.AutomationRuleOwner(r.Context(), id, auth.ScopeTasksWrite); err != nil {
+		auth.HTTPError(w, err, http.StatusNotFound)
+		return
+	}
+
+	err := h.repo.DeleteAutomationRule(id)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *Handler) ListAutomationRuns(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	if err := h.authz.AutomationRule(r.Context(), id, auth.ScopeTasksRead); err != nil {
+		auth.HTTPError(w, err, http.StatusNotFound)
+		return
+	}
+
+	runs, err := h.repo.ListAutomationRuns(id)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	response := map[string]interface{}{
+		"runs": runs,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
@@ -0,0 +1,60 @@
+package http
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"github.com/todo/pkg/auth"
+	"github.com/todo/services/task-service/internal/models"
+)
+
+// MoveTask places a task between two neighbours in its list or, with
+// scope "board", in a status column, optionally changing its status.
+func (h *Handler) MoveTask(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	if err := h.authz.Task(r.Context(), id, auth.ScopeTasksWrite); err != nil {
+		auth.HTTPError(w, err, http.StatusNotFound)
+		return
+	}
+
+	var move models.TaskMove
+	if err := json.NewDecoder(r.Body).Decode(&move); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	task, err := h.repo.MoveTask(id, move)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(task)
+}
+
+func (h *Handler) GetBoard(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	userID := vars["user_id"]
+
+	if err := auth.Authorize(r.Context(), userID, auth.ScopeTasksRead); err != nil {
+		auth.HTTPError(w, err, http.StatusForbidden)
+		return
+	}
+
+	columns, err := h.repo.Board(userID, r.URL.Query().Get("project_id"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	response := map[string]interface{}{
+		"columns": columns,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
@@ -0,0 +1,74 @@
+package http
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"github.com/todo/pkg/auth"
+)
+
+type CommentRequest struct {
+	UserID string `json:"user_id"`
+	Body   string `json:"body"`
+}
+
+func (h *Handler) AddComment(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	if err := h.authz.Task(r.Context(), id, auth.ScopeTasksWrite); err != nil {
+		auth.HTTPError(w, err, http.StatusNotFound)
+		return
+	}
+
+	var req CommentRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	userID, err := auth.ScopeUser(r.Context(), req.UserID, auth.ScopeTasksWrite)
+	if err != nil {
+		auth.HTTPError(w, err, http.StatusForbidden)
+		return
+	}
+
+	if req.Body == "" {
+		http.Error(w, "body is required", http.StatusBadRequest)
+		return
+	}
+
+	comment, err := h.repo.AddComment(id, userID, req.Body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(comment)
+}
+
+func (h *Handler) ListComments(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	if err := h.authz.Task(r.Context(), id, auth.ScopeTasksRead); err != nil {
+		auth.HTTPError(w, err, http.StatusNotFound)
+		return
+	}
+
+	comments, err := h.repo.ListComments(id)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	response := map[string]interface{}{
+		"comments": comments,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
@@ -0,0 +1,142 @@
+package http
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"github.com/todo/pkg/auth"
+	"github.com/todo/services/task-service/internal/models"
+)
+
+type CustomFieldRequest struct {
+	Key     string           `json:"key"`
+	Name    string           `json:"name"`
+	Type    models.FieldType `json:"type"`
+	Options []string         `json:"options,omitempty"`
+}
+
+func (h *Handler) CreateCustomField(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	projectID := vars["project_id"]
+
+	if err := h.authz.Project(r.Context(), projectID, auth.ScopeTasksWrite); err != nil {
+		auth.HTTPError(w, err, http.StatusInternalServerError)
+		return
+	}
+
+	var req CustomFieldRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	field := &models.CustomFieldDefinition{
+		ProjectID: projectID,
+		Key:       req.Key,
+		Name:      req.Name,
+		Type:      req.Type,
+		Options:   req.Options,
+	}
+	if err := field.Validate(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	created, err := h.repo.CreateCustomField(field)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusConflict)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(created)
+}
+
+func (h *Handler) ListCustomFields(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	projectID := vars["project_id"]
+
+	if err := h.authz.Project(r.Context(), projectID, auth.ScopeTasksRead); err != nil {
+		auth.HTTPError(w, err, http.StatusInternalServerError)
+		return
+	}
+
+	fields, err := h.repo.ListCustomFields(projectID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	response := map[string]interface{}{
+		"fields": fields,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+func (h *Handler) GetCustomField(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	if err := h.authz.CustomField(r.Context(), id, auth.ScopeTasksRead); err != nil {
+		auth.HTTPError(w, err, http.StatusNotFound)
+		return
+	}
+
+	field, err := h.repo.GetCustomField(id)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(field)
+}
+
+// UpdateCustomField changes a field's name and options; key and type in the
+// body are ignored.
+func (h *Handler) UpdateCustomField(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	if err := h.authz.CustomField(r.Context(), id, auth.ScopeTasksWrite); err != nil {
+		auth.HTTPError(w, err, http.StatusNotFound)
+		return
+	}
+
+	var req CustomFieldRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	field, err := h.repo.UpdateCustomField(id, req.Name, req.Options)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(field)
+}
+
+func (h *Handler) DeleteCustomField(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	if err := h.authz.CustomField(r.Context(), id, auth.ScopeTasksWrite); err != nil {
+		auth.HTTPError(w, err, http.StatusNotFound)
+		return
+	}
+
+	err := h.repo.DeleteCustomField(id)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
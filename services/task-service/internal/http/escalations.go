@@ -0,0 +1,305 @@
+package http
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"github.com/todo/pkg/auth"
+	"github.com/todo/services/task-service/internal/models"
+)
+
+type EscalationRuleRequest struct {
+	ProjectID    string                  `json:"project_id,omitempty"`
+	Priority     models.TaskPriority     `json:"priority,omitempty"`
+	AfterHours   int                     `json:"after_hours"`
+	Action       models.EscalationAction `json:"action"`
+	NotifyUserID string                  `json:"notify_user_id,omitempty"`
+}
+
+type WatcherRequest struct {
+	UserID string `json:"user_id"`
+}
+
+type EscalationSettingsRequest struct {
+	OptedOut bool `json:"opted_out"`
+}
+
+func (h *Handler) CreateEscalationRule(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	userID := vars["user_id"]
+
+	if err := auth.Authorize(r.Context(), userID, auth.ScopeTasksWrite); err != nil {
+		auth.HTTPError(w, err, http.StatusForbidden)
+		return
+	}
+
+	var req EscalationRuleRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	rule := &models.EscalationRule{
+		UserID:       userID,
+		ProjectID:    req.ProjectID,
+		Priority:     req.Priority,
+		AfterHours:   req.AfterHours,
+		Action:       req.Action,
+		NotifyUserID: req.NotifyUserID,
+	}
+	if err := rule.Validate(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	created, err := h.repo.CreateEscalationRule(rule)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(created)
+}
+
+func (h *Handler) ListEscalationRules(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	userID := vars["user_id"]
+
+	if err := auth.Authorize(r.Context(), userID, auth.ScopeTasksRead); err != nil {
+		auth.HTTPError(w, err, http.StatusForbidden)
+		return
+	}
+
+	rules, err := h.repo.ListEscalationRules(userID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	response := map[string]interface{}{
+		"rules": rules,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+func (h *Handler) GetEscalationRule(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	if err := h.authz.EscalationRule(r.Context(), id, auth.ScopeTasksRead); err != nil {
+		auth.HTTPError(w, err, http.StatusNotFound)
+		return
+	}
+
+	rule, err := h.repo.GetEscalationRule(id)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(rule)
+}
+
+func (h *Handler) UpdateEscalationRule(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	if err := h.authz.EscalationRule(r.Context(), id, auth.ScopeTasksWrite); err != nil {
+		auth.HTTPError(w, err, http.StatusNotFound)
+		return
+	}
+
+	var req EscalationRuleRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	rule, err := h.repo.GetEscalationRule(id)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	rule.ProjectID = req.ProjectID
+	rule.Priority = req.Priority
+	rule.AfterHours = req.AfterHours
+	rule.Action = req.Action
+	rule.NotifyUserID = req.NotifyUserID
+	if err := rule.Validate(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	updated, err := h.repo.UpdateEscalationRule(rule)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(updated)
+}
+
+func (h *Handler) DeleteEscalationRule(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	if err := h.authz.EscalationRule(r.Context(), id, auth.ScopeTasksWrite); err != nil {
+		auth.HTTPError(w, err, http.StatusNotFound)
+		return
+	}
+
+	err := h.repo.DeleteEscalationRule(id)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *Handler) ListTaskEscalations(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	if err := h.authz.Task(r.Context(), id, auth.ScopeTasksRead); err != nil {
+		auth.HTTPError(w, err, http.StatusNotFound)
+		return
+	}
+
+	escalations, err := h.repo.ListTaskEscalations(id)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	response := map[string]interface{}{
+		"escalations": escalations,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+func (h *Handler) AddWatcher(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	if err := h.authz.Task(r.Context(), id, auth.ScopeTasksWrite); err != nil {
+		auth.HTTPError(w, err, http.StatusNotFound)
+		return
+	}
+
+	var req WatcherRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if req.UserID == "" {
+		http.Error(w, "user_id is required", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.repo.AddWatcher(id, req.UserID); err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *Handler) RemoveWatcher(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+	userID := vars["user_id"]
+
+	// Watchers may remove themselves; anyone else needs to own the task
+	if auth.Authorize(r.Context(), userID, auth.ScopeTasksWrite) != nil {
+		if err := h.authz.Task(r.Context(), id, auth.ScopeTasksWrite); err != nil {
+			auth.HTTPError(w, err, http.StatusNotFound)
+			return
+		}
+	}
+
+	if err := h.repo.RemoveWatcher(id, userID); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *Handler) ListWatchers(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	if err := h.authz.Task(r.Context(), id, auth.ScopeTasksRead); err != nil {
+		auth.HTTPError(w, err, http.StatusNotFound)
+		return
+	}
+
+	userIDs, err := h.repo.ListWatchers(id)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	response := map[string]interface{}{
+		"user_ids": userIDs,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+func (h *Handler) GetEscalationSettings(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	projectID := vars["project_id"]
+
+	if err := h.authz.Project(r.Context(), projectID, auth.ScopeTasksRead); err != nil {
+		auth.HTTPError(w, err, http.StatusInternalServerError)
+		return
+	}
+
+	settings, err := h.repo.GetProjectEscalationSettings(projectID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(settings)
+}
+
+// UpdateEscalationSettings opts a project out of escalation, or back in.
+func (h *Handler) UpdateEscalationSettings(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	projectID := vars["project_id"]
+
+	if err := h.authz.Project(r.Context(), projectID, auth.ScopeTasksWrite); err != nil {
+		auth.HTTPError(w, err, http.StatusInternalServerError)
+		return
+	}
+
+	var req EscalationSettingsRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	settings, err := h.repo.SetProjectEscalationOptOut(projectID, req.OptedOut)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(settings)
+}
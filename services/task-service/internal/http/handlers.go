@@ -4,35 +4,53 @@ import (
 	"encoding/json"
 	"net/http"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/gorilla/mux"
+	"github.com/todo/pkg/auth"
+	"github.com/todo/services/task-service/internal/authz"
 	"github.com/todo/services/task-service/internal/models"
 	"github.com/todo/services/task-service/internal/repository"
 )
 
 type Handler struct {
-	repo *repository.PostgresRepository
+	repo  *repository.PostgresRepository
+	authz *authz.Authorizer
 }
 
 func NewHandler(repo *repository.PostgresRepository) *Handler {
-	return &Handler{repo: repo}
+	return &Handler{repo: repo, authz: authz.NewAuthorizer(repo)}
 }
 
 type CreateTaskRequest struct {
-	Title       string  `json:"title"`
-	Description string  `json:"description"`
-	Priority    string  `json:"priority"`
-	UserID      string  `json:"user_id"`
-	DueDate     *string `json:"due_date,omitempty"`
+	Title        string                 `json:"title"`
+	Description  string                 `json:"description"`
+	Priority     string                 `json:"priority"`
+	UserID       string                 `json:"user_id"`
+	ProjectID    string                 `json:"project_id,omitempty"`
+	DueDate      *string                `json:"due_date,omitempty"`
+	DueAllDay    bool                   `json:"due_all_day,omitempty"`
+	Timezone     string                 `json:"timezone,omitempty"`
+	StartDate    *string                `json:"start_date,omitempty"`
+	Tags         []string               `json:"tags,omitempty"`
+	Recurrence   string                 `json:"recurrence,omitempty"`
+	CustomFields map[string]interface{} `json:"custom_fields,omitempty"`
 }
 
 type UpdateTaskRequest struct {
-	Title       string  `json:"title"`
-	Description string  `json:"description"`
-	Status      string  `json:"status"`
-	Priority    string  `json:"priority"`
-	DueDate     *string `json:"due_date,omitempty"`
+	Title        string                 `json:"title"`
+	Description  string                 `json:"description"`
+	Status       string                 `json:"status"`
+	Priority     string                 `json:"priority"`
+	ProjectID    string                 `json:"project_id,omitempty"`
+	DueDate      *string                `json:"due_date,omitempty"`
+	DueAllDay    bool                   `json:"due_all_day,omitempty"`
+	Timezone     string                 `json:"timezone,omitempty"`
+	StartDate    *string                `json:"start_date,omitempty"`
+	Tags         []string               `json:"tags,omitempty"`
+	Recurrence   string                 `json:"recurrence,omitempty"`
+	CustomFields map[string]interface{} `json:"custom_fields,omitempty"`
 }
 
 func (h *Handler) CreateTask(w http.ResponseWriter, r *http.Request) {
@@ -42,16 +60,48 @@ func (h *Handler) CreateTask(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	userID, err := auth.ScopeUser(r.Context(), req.UserID, auth.ScopeTasksWrite)
+	if err != nil {
+		auth.HTTPError(w, err, http.StatusForbidden)
+		return
+	}
+	if err := h.authz.JoinProject(r.Context(), req.ProjectID); err != nil {
+		auth.HTTPError(w, err, http.StatusInternalServerError)
+		return
+	}
+
+	timezone, err := h.repo.UserTimezone(userID, req.Timezone)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
 	priority := models.TaskPriority(req.Priority)
-	var dueDate *time.Time
-	if req.DueDate != nil {
-		parsedDate, err := time.Parse(time.RFC3339, *req.DueDate)
-		if err == nil {
-			dueDate = &parsedDate
-		}
+	dueDate, allDay, err := parseDueDate(req.DueDate, req.DueAllDay, timezone)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	startDate, err := parseStartDate(req.StartDate, timezone)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
 	}
 
-	task, err := h.repo.CreateTask(req.Title, req.Description, req.UserID, priority, dueDate)
+	task, err := h.repo.CreateTask(&models.Task{
+		Title:        req.Title,
+		Description:  req.Description,
+		Priority:     priority,
+		UserID:       userID,
+		ProjectID:    req.ProjectID,
+		DueDate:      dueDate,
+		DueAllDay:    allDay,
+		Timezone:     timezone,
+		StartDate:    startDate,
+		Tags:         req.Tags,
+		Recurrence:   req.Recurrence,
+		CustomFields: req.CustomFields,
+	})
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
@@ -65,6 +115,11 @@ func (h *Handler) GetTask(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	id := vars["id"]
 
+	if err := h.authz.TaskScope(r.Context(), id, auth.ScopeTasksRead); err != nil {
+		auth.HTTPError(w, err, http.StatusNotFound)
+		return
+	}
+
 	task, err := h.repo.GetTaskByID(id)
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusNotFound)
@@ -79,23 +134,56 @@ func (h *Handler) UpdateTask(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	id := vars["id"]
 
+	if err := h.authz.TaskScope(r.Context(), id, auth.ScopeTasksWrite); err != nil {
+		auth.HTTPError(w, err, http.StatusNotFound)
+		return
+	}
+
 	var req UpdateTaskRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
 
+	if err := h.authz.TaskProject(r.Context(), id, req.ProjectID); err != nil {
+		auth.HTTPError(w, err, http.StatusInternalServerError)
+		return
+	}
+
+	timezone, err := h.repo.TaskTimezone(id, req.Timezone)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
 	status := models.TaskStatus(req.Status)
 	priority := models.TaskPriority(req.Priority)
-	var dueDate *time.Time
-	if req.DueDate != nil {
-		parsedDate, err := time.Parse(time.RFC3339, *req.DueDate)
-		if err == nil {
-			dueDate = &parsedDate
-		}
+	dueDate, allDay, err := parseDueDate(req.DueDate, req.DueAllDay, timezone)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	startDate, err := parseStartDate(req.StartDate, timezone)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
 	}
 
-	task, err := h.repo.UpdateTask(id, req.Title, req.Description, status, priority, dueDate)
+	task, err := h.repo.UpdateTask(&models.Task{
+		ID:           id,
+		Title:        req.Title,
+		Description:  req.Description,
+		Status:       status,
+		Priority:     priority,
+		ProjectID:    req.ProjectID,
+		DueDate:      dueDate,
+		DueAllDay:    allDay,
+		Timezone:     timezone,
+		StartDate:    startDate,
+		Tags:         req.Tags,
+		Recurrence:   req.Recurrence,
+		CustomFields: req.CustomFields,
+	})
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
@@ -109,6 +197,11 @@ func (h *Handler) DeleteTask(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	id := vars["id"]
 
+	if err := h.authz.TaskScope(r.Context(), id, auth.ScopeTasksDelete); err != nil {
+		auth.HTTPError(w, err, http.StatusNotFound)
+		return
+	}
+
 	err := h.repo.DeleteTask(id)
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
@@ -119,6 +212,11 @@ func (h *Handler) DeleteTask(w http.ResponseWriter, r *http.Request) {
 }
 
 func (h *Handler) ListTasks(w http.ResponseWriter, r *http.Request) {
+	if err := auth.RequireScope(r.Context(), auth.ScopeTasksRead); err != nil {
+		auth.HTTPError(w, err, http.StatusForbidden)
+		return
+	}
+
 	pageStr := r.URL.Query().Get("page")
 	pageSizeStr := r.URL.Query().Get("page_size")
 
@@ -157,6 +255,11 @@ func (h *Handler) ListUserTasks(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	userID := vars["user_id"]
 
+	if err := auth.AuthorizeScope(r.Context(), userID, auth.ScopeTasksRead); err != nil {
+		auth.HTTPError(w, err, http.StatusForbidden)
+		return
+	}
+
 	pageStr := r.URL.Query().Get("page")
 	pageSizeStr := r.URL.Query().Get("page_size")
 	statusStr := r.URL.Query().Get("status")
@@ -180,7 +283,50 @@ func (h *Handler) ListUserTasks(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
-	tasks, total, err := h.repo.ListUserTasks(userID, page, pageSize, status)
+	filter := models.TaskFilter{
+		Due:             models.DueFilter(r.URL.Query().Get("due")),
+		Snoozed:         models.SnoozedFilter(r.URL.Query().Get("snoozed")),
+		IncludeArchived: r.URL.Query().Get("include_archived") == "true",
+	}
+	if status != "" {
+		filter.Statuses = []models.TaskStatus{status}
+	}
+
+	// Custom fields are filtered with cf.<key>=value
+	for name, values := range r.URL.Query() {
+		if key, ok := strings.CutPrefix(name, "cf."); ok {
+			if filter.CustomFields == nil {
+				filter.CustomFields = make(map[string]string)
+			}
+			filter.CustomFields[key] = values[0]
+		}
+	}
+
+	if err := filter.Validate(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	sort := models.TaskSort{
+		Field:      models.SortField(r.URL.Query().Get("sort")),
+		Descending: r.URL.Query().Get("order") == "desc",
+	}
+	if key, ok := strings.CutPrefix(string(sort.Field), "cf."); ok {
+		sort.Field = models.SortCustomField
+		sort.CustomField = key
+	}
+	if err := sort.Validate(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	loc, err := h.repo.FilterTimezone(userID, r.URL.Query().Get("tz"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	tasks, total, err := h.repo.QueryUserTasks(userID, filter, sort, loc, page, pageSize)
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
@@ -196,11 +342,158 @@ func (h *Handler) ListUserTasks(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(response)
 }
 
+// parseDueDate reads a due_date given as an RFC3339 timestamp or, for an
+// all-day task, a YYYY-MM-DD day in timezone.
+func parseDueDate(value *string, allDay bool, timezone string) (*time.Time, bool, error) {
+	if value == nil || *value == "" {
+		return nil, false, nil
+	}
+
+	loc, err := models.LoadTimezone(timezone)
+	if err != nil {
+		return nil, false, err
+	}
+
+	dueDate, dateOnly, err := models.ParseDueDate(*value, loc)
+	if err != nil {
+		return nil, false, err
+	}
+
+	return &dueDate, allDay || dateOnly, nil
+}
+
+// parseStartDate reads a start_date given as an RFC3339 timestamp or a
+// YYYY-MM-DD day starting at midnight in timezone.
+func parseStartDate(value *string, timezone string) (*time.Time, error) {
+	if value == nil || *value == "" {
+		return nil, nil
+	}
+
+	loc, err := models.LoadTimezone(timezone)
+	if err != nil {
+		return nil, err
+	}
+
+	startDate, err := models.ParseStartDate(*value, loc)
+	if err != nil {
+		return nil, err
+	}
+
+	return &startDate, nil
+}
+
+// parsePagination reads the page and page_size query parameters, falling
+// back to the first page of ten.
+func parsePagination(r *http.Request) (int, int) {
+	page := 1
+	pageSize := 10
+
+	if p, err := strconv.Atoi(r.URL.Query().Get("page")); err == nil && p > 0 {
+		page = p
+	}
+
+	if ps, err := strconv.Atoi(r.URL.Query().Get("page_size")); err == nil && ps > 0 {
+		pageSize = ps
+	}
+
+	return page, pageSize
+}
+
 func (h *Handler) RegisterRoutes(router *mux.Router) {
 	router.HandleFunc("/api/tasks", h.CreateTask).Methods("POST")
 	router.HandleFunc("/api/tasks", h.ListTasks).Methods("GET")
+	router.HandleFunc("/api/tasks/quick", h.QuickAddTask).Methods("POST")
 	router.HandleFunc("/api/tasks/{id}", h.GetTask).Methods("GET")
 	router.HandleFunc("/api/tasks/{id}", h.UpdateTask).Methods("PUT")
 	router.HandleFunc("/api/tasks/{id}", h.DeleteTask).Methods("DELETE")
 	router.HandleFunc("/api/users/{user_id}/tasks", h.ListUserTasks).Methods("GET")
+
+	// Settings
+	router.HandleFunc("/api/users/{user_id}/settings", h.GetUserSettings).Methods("GET")
+	router.HandleFunc("/api/users/{user_id}/settings", h.UpdateUserSettings).Methods("PUT")
+
+	// Ordering and board
+	router.HandleFunc("/api/tasks/{id}/move", h.MoveTask).Methods("POST")
+	router.HandleFunc("/api/users/{user_id}/board", h.GetBoard).Methods("GET")
+
+	// Snooze
+	router.HandleFunc("/api/tasks/{id}/snooze", h.SnoozeTask).Methods("POST")
+	router.HandleFunc("/api/tasks/{id}/snooze", h.UnsnoozeTask).Methods("DELETE")
+
+	// Archive
+	router.HandleFunc("/api/tasks/{id}/unarchive", h.UnarchiveTask).Methods("POST")
+
+	// Time tracking
+	router.HandleFunc("/api/tasks/{id}/timer/start", h.StartTimer).Methods("POST")
+	router.HandleFunc("/api/tasks/{id}/time-entries", h.CreateTimeEntry).Methods("POST")
+	router.HandleFunc("/api/tasks/{id}/time-entries", h.ListTaskTimeEntries).Methods("GET")
+	router.HandleFunc("/api/time-entries/{id}", h.DeleteTimeEntry).Methods("DELETE")
+	router.HandleFunc("/api/users/{user_id}/timer", h.GetRunningTimer).Methods("GET")
+	router.HandleFunc("/api/users/{user_id}/timer/stop", h.StopTimer).Methods("POST")
+	router.HandleFunc("/api/users/{user_id}/time-summary", h.GetTimeSummary).Methods("GET")
+	router.HandleFunc("/api/users/{user_id}/timesheet", h.ExportTimesheet).Methods("GET")
+	router.HandleFunc("/api/projects/{project_id}/time-summary", h.GetProjectTimeSummary).Methods("GET")
+
+	// Saved views
+	router.HandleFunc("/api/users/{user_id}/views", h.CreateView).Methods("POST")
+	router.HandleFunc("/api/users/{user_id}/views", h.ListViews).Methods("GET")
+	router.HandleFunc("/api/users/{user_id}/views/{view_id}/tasks", h.ExecuteView).Methods("GET")
+	router.HandleFunc("/api/views/{id}", h.GetView).Methods("GET")
+	router.HandleFunc("/api/views/{id}", h.UpdateView).Methods("PUT")
+	router.HandleFunc("/api/views/{id}", h.DeleteView).Methods("DELETE")
+
+	// Checklists and templates
+	router.HandleFunc("/api/tasks/{id}/checklist", h.AddChecklistItem).Methods("POST")
+	router.HandleFunc("/api/tasks/{id}/checklist", h.ListChecklistItems).Methods("GET")
+	router.HandleFunc("/api/checklist-items/{id}", h.UpdateChecklistItem).Methods("PUT")
+	router.HandleFunc("/api/checklist-items/{id}", h.DeleteChecklistItem).Methods("DELETE")
+	router.HandleFunc("/api/users/{user_id}/templates", h.CreateTemplate).Methods("POST")
+	router.HandleFunc("/api/users/{user_id}/templates", h.ListTemplates).Methods("GET")
+	router.HandleFunc("/api/templates/{id}", h.GetTemplate).Methods("GET")
+	router.HandleFunc("/api/templates/{id}", h.UpdateTemplate).Methods("PUT")
+	router.HandleFunc("/api/templates/{id}", h.DeleteTemplate).Methods("DELETE")
+	router.HandleFunc("/api/templates/{id}/instantiate", h.InstantiateTemplate).Methods("POST")
+
+	// Projects
+	router.HandleFunc("/api/projects/{project_id}/members", h.ListProjectMembers).Methods("GET")
+	router.HandleFunc("/api/projects/{project_id}/members/{user_id}", h.AddProjectMember).Methods("PUT")
+	router.HandleFunc("/api/projects/{project_id}/members/{user_id}", h.RemoveProjectMember).Methods("DELETE")
+
+	// Custom fields
+	router.HandleFunc("/api/projects/{project_id}/custom-fields", h.CreateCustomField).Methods("POST")
+	router.HandleFunc("/api/projects/{project_id}/custom-fields", h.ListCustomFields).Methods("GET")
+	router.HandleFunc("/api/custom-fields/{id}", h.GetCustomField).Methods("GET")
+	router.HandleFunc("/api/custom-fields/{id}", h.UpdateCustomField).Methods("PUT")
+	router.HandleFunc("/api/custom-fields/{id}", h.DeleteCustomField).Methods("DELETE")
+
+	// Reports
+	router.HandleFunc("/api/reports/summary", h.GetReportSummary).Methods("GET")
+	router.HandleFunc("/api/reports/throughput", h.GetThroughputReport).Methods("GET")
+	router.HandleFunc("/api/reports/distribution", h.GetDistributionReport).Methods("GET")
+	router.HandleFunc("/api/reports/burndown", h.GetBurndownReport).Methods("GET")
+
+	// Escalation
+	router.HandleFunc("/api/users/{user_id}/escalation-rules", h.CreateEscalationRule).Methods("POST")
+	router.HandleFunc("/api/users/{user_id}/escalation-rules", h.ListEscalationRules).Methods("GET")
+	router.HandleFunc("/api/escalation-rules/{id}", h.GetEscalationRule).Methods("GET")
+	router.HandleFunc("/api/escalation-rules/{id}", h.UpdateEscalationRule).Methods("PUT")
+	router.HandleFunc("/api/escalation-rules/{id}", h.DeleteEscalationRule).Methods("DELETE")
+	router.HandleFunc("/api/tasks/{id}/escalations", h.ListTaskEscalations).Methods("GET")
+	router.HandleFunc("/api/tasks/{id}/watchers", h.AddWatcher).Methods("POST")
+	router.HandleFunc("/api/tasks/{id}/watchers", h.ListWatchers).Methods("GET")
+	router.HandleFunc("/api/tasks/{id}/watchers/{user_id}", h.RemoveWatcher).Methods("DELETE")
+	router.HandleFunc("/api/projects/{project_id}/escalation-settings", h.GetEscalationSettings).Methods("GET")
+	router.HandleFunc("/api/projects/{project_id}/escalation-settings", h.UpdateEscalationSettings).Methods("PUT")
+
+	// Comments
+	router.HandleFunc("/api/tasks/{id}/comments", h.AddComment).Methods("POST")
+	router.HandleFunc("/api/tasks/{id}/comments", h.ListComments).Methods("GET")
+
+	// Automation
+	router.HandleFunc("/api/projects/{project_id}/automations", h.CreateAutomationRule).Methods("POST")
+	router.HandleFunc("/api/projects/{project_id}/automations", h.ListAutomationRules).Methods("GET")
+	router.HandleFunc("/api/automations/{id}", h.GetAutomationRule).Methods("GET")
+	router.HandleFunc("/api/automations/{id}", h.UpdateAutomationRule).Methods("PUT")
+	router.HandleFunc("/api/automations/{id}", h.DeleteAutomationRule).Methods("DELETE")
+	router.HandleFunc("/api/automations/{id}/runs", h.ListAutomationRuns).Methods("GET")
 }
@@ -0,0 +1,58 @@
+package http
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/todo/pkg/auth"
+)
+
+// TestPersonalAccessTokenScopes checks that requests with a personal access
+// token lacking the scope they need are refused before anything is read or
+// written, so the handler runs without a repository.
+func TestPersonalAccessTokenScopes(t *testing.T) {
+	const owner = "owner-id"
+	const other = "other-id"
+
+	readOwn := &auth.Identity{UserID: owner, Scopes: []string{auth.ScopeOwnTasksRead}, PersonalAccessToken: true}
+	writeOwn := &auth.Identity{UserID: owner, Scopes: []string{auth.ScopeOwnTasksWrite}, PersonalAccessToken: true}
+	readAny := &auth.Identity{UserID: owner, Roles: []string{auth.RoleAdmin}, Scopes: []string{auth.ScopeTasksRead}, PersonalAccessToken: true}
+
+	tests := []struct {
+		name     string
+		identity *auth.Identity
+		method   string
+		path     string
+		body     string
+	}{
+		{name: "read scope doesn't create tasks", identity: readOwn, method: "POST", path: "/api/tasks", body: `{"title": "Task", "user_id": "owner-id"}`},
+		{name: "read scope doesn't quick add", identity: readOwn, method: "POST", path: "/api/tasks/quick", body: `{"text": "Task tomorrow"}`},
+		{name: "read scope doesn't create views", identity: readOwn, method: "POST", path: "/api/users/owner-id/views", body: `{"name": "Mine"}`},
+		{name: "read scope doesn't change settings", identity: readOwn, method: "PUT", path: "/api/users/owner-id/settings", body: `{"timezone": "Europe/Berlin"}`},
+		{name: "owner scope doesn't list other users' tasks", identity: readOwn, method: "GET", path: "/api/users/" + other + "/tasks"},
+		{name: "write scope doesn't list views", identity: writeOwn, method: "GET", path: "/api/users/owner-id/views"},
+		{name: "write scope doesn't read settings", identity: writeOwn, method: "GET", path: "/api/users/owner-id/settings"},
+		{name: "write scope doesn't read project time totals", identity: writeOwn, method: "GET", path: "/api/projects/project-id/time-summary"},
+		{name: "role scope doesn't cover own views", identity: readAny, method: "GET", path: "/api/users/owner-id/views"},
+	}
+
+	router := mux.NewRouter()
+	NewHandler(nil).RegisterRoutes(router)
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(tt.method, tt.path, strings.NewReader(tt.body))
+			r = r.WithContext(auth.NewContext(r.Context(), tt.identity))
+			w := httptest.NewRecorder()
+
+			router.ServeHTTP(w, r)
+
+			if w.Code != http.StatusForbidden {
+				t.Errorf("%s %s: got status %d, want %d: %s", tt.method, tt.path, w.Code, http.StatusForbidden, w.Body.String())
+			}
+		})
+	}
+}
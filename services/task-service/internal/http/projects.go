@@ -0,0 +1,70 @@
+package http
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"github.com/todo/pkg/auth"
+)
+
+func (h *Handler) ListProjectMembers(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	projectID := vars["project_id"]
+
+	if err := h.authz.Project(r.Context(), projectID, auth.ScopeTasksRead); err != nil {
+		auth.HTTPError(w, err, http.StatusInternalServerError)
+		return
+	}
+
+	members, err := h.repo.ListProjectMembers(projectID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"members": members,
+	})
+}
+
+// AddProjectMember lets the project's owner add a user to it.
+func (h *Handler) AddProjectMember(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	projectID := vars["project_id"]
+
+	if err := h.authz.ProjectOwner(r.Context(), projectID, auth.ScopeTasksWrite); err != nil {
+		auth.HTTPError(w, err, http.StatusNotFound)
+		return
+	}
+
+	if err := h.repo.AddProjectMember(projectID, vars["user_id"]); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// RemoveProjectMember lets the project's owner remove a member, and members
+// leave.
+func (h *Handler) RemoveProjectMember(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	projectID := vars["project_id"]
+	userID := vars["user_id"]
+
+	if auth.Authorize(r.Context(), userID, auth.ScopeTasksWrite) != nil {
+		if err := h.authz.ProjectOwner(r.Context(), projectID, auth.ScopeTasksWrite); err != nil {
+			auth.HTTPError(w, err, http.StatusNotFound)
+			return
+		}
+	}
+
+	if err := h.repo.RemoveProjectMember(projectID, userID); err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,80 @@
+package http
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/todo/pkg/auth"
+	"github.com/todo/services/task-service/internal/models"
+	"github.com/todo/services/task-service/internal/quickadd"
+)
+
+type QuickAddTaskRequest struct {
+	UserID    string `json:"user_id"`
+	Text      string `json:"text"`
+	Timezone  string `json:"timezone,omitempty"`
+	ProjectID string `json:"project_id,omitempty"`
+}
+
+// QuickAddTask creates a task from a phrase such as "Pay rent every 1st of
+// the month at 9am !high #home" and returns how it was interpreted.
+func (h *Handler) QuickAddTask(w http.ResponseWriter, r *http.Request) {
+	var req QuickAddTaskRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	userID, err := auth.ScopeUser(r.Context(), req.UserID, auth.ScopeTasksWrite)
+	if err != nil {
+		auth.HTTPError(w, err, http.StatusForbidden)
+		return
+	}
+	if err := h.authz.JoinProject(r.Context(), req.ProjectID); err != nil {
+		auth.HTTPError(w, err, http.StatusInternalServerError)
+		return
+	}
+
+	timezone, err := h.repo.UserTimezone(userID, req.Timezone)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	loc, err := models.LoadTimezone(timezone)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	parsed, err := quickadd.Parse(req.Text, time.Now(), loc)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	task, err := h.repo.CreateTask(&models.Task{
+		Title:      parsed.Title,
+		Priority:   parsed.Priority,
+		UserID:     userID,
+		ProjectID:  req.ProjectID,
+		DueDate:    parsed.DueDate,
+		DueAllDay:  parsed.DueDate != nil && !parsed.HasTime,
+		Timezone:   loc.String(),
+		Tags:       parsed.Tags,
+		Recurrence: parsed.Recurrence,
+	})
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	response := map[string]interface{}{
+		"parsed": parsed,
+		"task":   task,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(response)
+}
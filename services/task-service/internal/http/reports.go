@@ -0,0 +1,192 @@
+package http
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/todo/pkg/auth"
+	"github.com/todo/services/task-service/internal/models"
+)
+
+func (h *Handler) GetReportSummary(w http.ResponseWriter, r *http.Request) {
+	filter, err := parseReportFilter(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := h.authz.Report(r.Context(), filter); err != nil {
+		auth.HTTPError(w, err, http.StatusForbidden)
+		return
+	}
+
+	summary, err := h.repo.ReportSummary(filter, time.Now())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	writeReport(w, r, "summary", summary,
+		[]string{"created", "completed", "average_cycle_time_seconds", "median_cycle_time_seconds", "due", "overdue", "overdue_rate"},
+		[][]string{{
+			strconv.Itoa(summary.Created),
+			strconv.Itoa(summary.Completed),
+			strconv.FormatInt(summary.AverageCycleTimeSeconds, 10),
+			strconv.FormatInt(summary.MedianCycleTimeSeconds, 10),
+			strconv.Itoa(summary.Due),
+			strconv.Itoa(summary.Overdue),
+			strconv.FormatFloat(summary.OverdueRate, 'f', 4, 64),
+		}},
+	)
+}
+
+func (h *Handler) GetThroughputReport(w http.ResponseWriter, r *http.Request) {
+	filter, err := parseReportFilter(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := h.authz.Report(r.Context(), filter); err != nil {
+		auth.HTTPError(w, err, http.StatusForbidden)
+		return
+	}
+
+	interval := models.ReportInterval(r.URL.Query().Get("interval"))
+	if interval == "" {
+		interval = models.IntervalWeek
+	}
+
+	points, err := h.repo.Throughput(filter, interval)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	rows := make([][]string, len(points))
+	for i, point := range points {
+		rows[i] = []string{point.Period, strconv.Itoa(point.Created), strconv.Itoa(point.Completed)}
+	}
+
+	writeReport(w, r, "throughput", map[string]interface{}{
+		"interval": interval,
+		"points":   points,
+	}, []string{"period", "created", "completed"}, rows)
+}
+
+func (h *Handler) GetDistributionReport(w http.ResponseWriter, r *http.Request) {
+	filter, err := parseReportFilter(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := h.authz.Report(r.Context(), filter); err != nil {
+		auth.HTTPError(w, err, http.StatusForbidden)
+		return
+	}
+
+	by := models.DistributionDimension(r.URL.Query().Get("by"))
+	if by == "" {
+		by = models.DistributionStatus
+	}
+
+	buckets, err := h.repo.Distribution(filter, by)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	rows := make([][]string, len(buckets))
+	for i, bucket := range buckets {
+		rows[i] = []string{bucket.Key, strconv.Itoa(bucket.Count)}
+	}
+
+	writeReport(w, r, "distribution-"+string(by), map[string]interface{}{
+		"by":      by,
+		"buckets": buckets,
+	}, []string{string(by), "count"}, rows)
+}
+
+func (h *Handler) GetBurndownReport(w http.ResponseWriter, r *http.Request) {
+	filter, err := parseReportFilter(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := h.authz.Report(r.Context(), filter); err != nil {
+		auth.HTTPError(w, err, http.StatusForbidden)
+		return
+	}
+
+	points, err := h.repo.Burndown(filter)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	rows := make([][]string, len(points))
+	for i, point := range points {
+		rows[i] = []string{point.Date, strconv.Itoa(point.Open), strconv.Itoa(point.Completed)}
+	}
+
+	writeReport(w, r, "burndown", map[string]interface{}{
+		"points": points,
+	}, []string{"date", "open", "completed"}, rows)
+}
+
+// parseReportFilter reads user_id, project_id, from, to and tz. Bounds follow
+// parseTimeFilter: a YYYY-MM-DD day given as "to" is inclusive.
+func parseReportFilter(r *http.Request) (models.ReportFilter, error) {
+	query := r.URL.Query()
+
+	loc := time.UTC
+	if tz := query.Get("tz"); tz != "" {
+		var err error
+		if loc, err = time.LoadLocation(tz); err != nil {
+			return models.ReportFilter{}, fmt.Errorf("invalid tz: %s", tz)
+		}
+	}
+
+	var from, to *time.Time
+	if value := query.Get("from"); value != "" {
+		t, err := parseRangeBound(value, loc, false)
+		if err != nil {
+			return models.ReportFilter{}, fmt.Errorf("invalid from: %s", value)
+		}
+		from = &t
+	}
+	if value := query.Get("to"); value != "" {
+		t, err := parseRangeBound(value, loc, true)
+		if err != nil {
+			return models.ReportFilter{}, fmt.Errorf("invalid to: %s", value)
+		}
+		to = &t
+	}
+
+	return models.NewReportFilter(query.Get("user_id"), query.Get("project_id"), from, to, loc, time.Now())
+}
+
+// writeReport encodes body as JSON or, with format=csv, writes the header
+// and rows as a CSV download.
+func writeReport(w http.ResponseWriter, r *http.Request, name string, body interface{}, header []string, rows [][]string) {
+	switch r.URL.Query().Get("format") {
+	case "", "json":
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(body)
+	case "csv":
+		w.Header().Set("Content-Type", "text/csv")
+		w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=\"%s.csv\"", name))
+
+		cw := csv.NewWriter(w)
+		cw.Write(header)
+		cw.WriteAll(rows)
+	default:
+		http.Error(w, "invalid format: expected json or csv", http.StatusBadRequest)
+	}
+}
@@ -0,0 +1,65 @@
+package http
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"github.com/todo/pkg/auth"
+	"github.com/todo/services/task-service/internal/models"
+)
+
+type UserSettingsRequest struct {
+	Timezone string `json:"timezone"`
+}
+
+func (h *Handler) GetUserSettings(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	userID := vars["user_id"]
+
+	if err := auth.Authorize(r.Context(), userID, auth.ScopeTasksRead); err != nil {
+		auth.HTTPError(w, err, http.StatusForbidden)
+		return
+	}
+
+	settings, err := h.repo.GetUserSettings(userID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(settings)
+}
+
+// UpdateUserSettings sets the timezone used for the user's tasks and due
+// filters when a request names none.
+func (h *Handler) UpdateUserSettings(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	userID := vars["user_id"]
+
+	if err := auth.Authorize(r.Context(), userID, auth.ScopeTasksWrite); err != nil {
+		auth.HTTPError(w, err, http.StatusForbidden)
+		return
+	}
+
+	var req UserSettingsRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if _, err := models.LoadTimezone(req.Timezone); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	settings, err := h.repo.UpdateUserSettings(userID, req.Timezone)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(settings)
+}
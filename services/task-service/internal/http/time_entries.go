@@ -0,0 +1,365 @@
+package http
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/todo/pkg/auth"
+	"github.com/todo/services/task-service/internal/models"
+)
+
+type StartTimerRequest struct {
+	UserID string `json:"user_id"`
+	Note   string `json:"note,omitempty"`
+}
+
+type CreateTimeEntryRequest struct {
+	UserID    string `json:"user_id"`
+	StartedAt string `json:"started_at"`
+	EndedAt   string `json:"ended_at"`
+	Note      string `json:"note,omitempty"`
+}
+
+func (h *Handler) StartTimer(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	taskID := vars["id"]
+
+	if err := h.authz.Task(r.Context(), taskID, auth.ScopeTasksWrite); err != nil {
+		auth.HTTPError(w, err, http.StatusNotFound)
+		return
+	}
+
+	var req StartTimerRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	userID, err := auth.ScopeUser(r.Context(), req.UserID, auth.ScopeTasksWrite)
+	if err != nil {
+		auth.HTTPError(w, err, http.StatusForbidden)
+		return
+	}
+
+	entry, err := h.repo.StartTimer(taskID, userID, req.Note)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusConflict)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(entry)
+}
+
+func (h *Handler) StopTimer(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	userID := vars["user_id"]
+
+	if err := auth.Authorize(r.Context(), userID, auth.ScopeTasksWrite); err != nil {
+		auth.HTTPError(w, err, http.StatusForbidden)
+		return
+	}
+
+	entry, err := h.repo.StopTimer(userID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(entry)
+}
+
+func (h *Handler) GetRunningTimer(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	userID := vars["user_id"]
+
+	if err := auth.Authorize(r.Context(), userID, auth.ScopeTasksRead); err != nil {
+		auth.HTTPError(w, err, http.StatusForbidden)
+		return
+	}
+
+	entry, err := h.repo.GetRunningTimer(userID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(entry)
+}
+
+func (h *Handler) CreateTimeEntry(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	taskID := vars["id"]
+
+	if err := h.authz.Task(r.Context(), taskID, auth.ScopeTasksWrite); err != nil {
+		auth.HTTPError(w, err, http.StatusNotFound)
+		return
+	}
+
+	var req CreateTimeEntryRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	userID, err := auth.ScopeUser(r.Context(), req.UserID, auth.ScopeTasksWrite)
+	if err != nil {
+		auth.HTTPError(w, err, http.StatusForbidden)
+		return
+	}
+
+	startedAt, err := time.Parse(time.RFC3339, req.StartedAt)
+	if err != nil {
+		http.Error(w, "invalid started_at: expected RFC3339", http.StatusBadRequest)
+		return
+	}
+
+	endedAt, err := time.Parse(time.RFC3339, req.EndedAt)
+	if err != nil {
+		http.Error(w, "invalid ended_at: expected RFC3339", http.StatusBadRequest)
+		return
+	}
+
+	entry, err := h.repo.CreateTimeEntry(taskID, userID, startedAt, endedAt, req.Note)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(entry)
+}
+
+func (h *Handler) ListTaskTimeEntries(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+
+	filter, err := parseTimeFilter(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	filter.TaskID = vars["id"]
+
+	if err := h.authz.Task(r.Context(), filter.TaskID, auth.ScopeTasksRead); err != nil {
+		auth.HTTPError(w, err, http.StatusNotFound)
+		return
+	}
+
+	entries, err := h.repo.ListTimeEntries(filter)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	var totalSeconds int64
+	for _, entry := range entries {
+		totalSeconds += entry.DurationSeconds
+	}
+
+	response := map[string]interface{}{
+		"entries":       entries,
+		"total_seconds": totalSeconds,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+func (h *Handler) DeleteTimeEntry(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	if err := h.authz.TimeEntry(r.Context(), id, auth.ScopeTasksWrite); err != nil {
+		auth.HTTPError(w, err, http.StatusNotFound)
+		return
+	}
+
+	err := h.repo.DeleteTimeEntry(id)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *Handler) GetTimeSummary(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+
+	filter, err := parseTimeFilter(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	filter.UserID = vars["user_id"]
+
+	if err := auth.Authorize(r.Context(), filter.UserID, auth.ScopeTasksRead); err != nil {
+		auth.HTTPError(w, err, http.StatusForbidden)
+		return
+	}
+
+	h.writeTimeSummary(w, r, filter)
+}
+
+// GetProjectTimeSummary totals the time every user tracked on a project's
+// tasks. Only the project's members may see it.
+func (h *Handler) GetProjectTimeSummary(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+
+	filter, err := parseTimeFilter(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	filter.UserID = ""
+	filter.ProjectID = vars["project_id"]
+
+	if err := h.authz.Project(r.Context(), filter.ProjectID, auth.ScopeTasksRead); err != nil {
+		auth.HTTPError(w, err, http.StatusForbidden)
+		return
+	}
+
+	h.writeTimeSummary(w, r, filter)
+}
+
+func (h *Handler) writeTimeSummary(w http.ResponseWriter, r *http.Request, filter models.TimeFilter) {
+	groupBy := models.TimeGrouping(r.URL.Query().Get("group_by"))
+	if groupBy == "" {
+		groupBy = models.GroupByTask
+	}
+
+	totals, err := h.repo.SummarizeTime(filter, groupBy, r.URL.Query().Get("tz"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	var totalSeconds int64
+	for _, total := range totals {
+		totalSeconds += total.DurationSeconds
+	}
+
+	response := map[string]interface{}{
+		"group_by":      groupBy,
+		"totals":        totals,
+		"total_seconds": totalSeconds,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+// ExportTimesheet returns the user's time entries for a date range as JSON
+// or, with format=csv, as a CSV download.
+func (h *Handler) ExportTimesheet(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+
+	filter, err := parseTimeFilter(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	filter.UserID = vars["user_id"]
+
+	if err := auth.Authorize(r.Context(), filter.UserID, auth.ScopeTasksRead); err != nil {
+		auth.HTTPError(w, err, http.StatusForbidden)
+		return
+	}
+
+	sheet, err := h.repo.Timesheet(filter)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	switch r.URL.Query().Get("format") {
+	case "", "json":
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"entries": sheet,
+		})
+	case "csv":
+		w.Header().Set("Content-Type", "text/csv")
+		w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=\"timesheet-%s.csv\"", filter.UserID))
+
+		cw := csv.NewWriter(w)
+		cw.Write([]string{"started_at", "ended_at", "duration_seconds", "duration_hours", "task_id", "task_title", "project_id", "note", "manual"})
+		for _, row := range sheet {
+			endedAt := ""
+			if row.EndedAt != nil {
+				endedAt = row.EndedAt.Format(time.RFC3339)
+			}
+			cw.Write([]string{
+				row.StartedAt.Format(time.RFC3339),
+				endedAt,
+				strconv.FormatInt(row.DurationSeconds, 10),
+				strconv.FormatFloat(float64(row.DurationSeconds)/3600, 'f', 2, 64),
+				row.TaskID,
+				row.TaskTitle,
+				row.ProjectID,
+				row.Note,
+				strconv.FormatBool(row.Manual),
+			})
+		}
+		cw.Flush()
+	default:
+		http.Error(w, "invalid format: expected json or csv", http.StatusBadRequest)
+	}
+}
+
+// parseTimeFilter reads the project_id, from and to query parameters. Dates
+// may be RFC3339 timestamps or YYYY-MM-DD days in the tz timezone; a day
+// given as "to" is inclusive.
+func parseTimeFilter(r *http.Request) (models.TimeFilter, error) {
+	query := r.URL.Query()
+	filter := models.TimeFilter{ProjectID: query.Get("project_id")}
+
+	loc := time.UTC
+	if tz := query.Get("tz"); tz != "" {
+		var err error
+		if loc, err = time.LoadLocation(tz); err != nil {
+			return filter, fmt.Errorf("invalid tz: %s", tz)
+		}
+	}
+
+	if from := query.Get("from"); from != "" {
+		t, err := parseRangeBound(from, loc, false)
+		if err != nil {
+			return filter, fmt.Errorf("invalid from: %s", from)
+		}
+		filter.From = &t
+	}
+
+	if to := query.Get("to"); to != "" {
+		t, err := parseRangeBound(to, loc, true)
+		if err != nil {
+			return filter, fmt.Errorf("invalid to: %s", to)
+		}
+		filter.To = &t
+	}
+
+	return filter, nil
+}
+
+func parseRangeBound(value string, loc *time.Location, end bool) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+
+	day, err := time.ParseInLocation("2006-01-02", value, loc)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if end {
+		day = day.AddDate(0, 0, 1)
+	}
+	return day, nil
+}
@@ -0,0 +1,85 @@
+package jobs
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/todo/services/task-service/internal/repository"
+)
+
+// Worker periodically runs a batch job until ctx is done. Batches repeat
+// while they come back full so a backlog drains quickly.
+type Worker struct {
+	name      string
+	interval  time.Duration
+	batchSize int
+	batch     func(now time.Time, limit int) (int, error)
+}
+
+// NewSnoozeWaker wakes snoozed tasks whose start date has passed, which
+// notifies their owners through the outbox.
+func NewSnoozeWaker(repo *repository.PostgresRepository, interval time.Duration) *Worker {
+	return &Worker{
+		name:      "Snooze waker",
+		interval:  interval,
+		batchSize: 100,
+		batch:     repo.WakeSnoozedTasks,
+	}
+}
+
+// NewEscalationWorker fires escalation rules for overdue tasks.
+func NewEscalationWorker(repo *repository.PostgresRepository, interval time.Duration) *Worker {
+	return &Worker{
+		name:      "Escalation worker",
+		interval:  interval,
+		batchSize: 100,
+		batch:     repo.EscalateOverdueTasks,
+	}
+}
+
+// NewAutomationWorker fires due_soon automation rules.
+func NewAutomationWorker(repo *repository.PostgresRepository, interval time.Duration) *Worker {
+	return &Worker{
+		name:      "Automation worker",
+		interval:  interval,
+		batchSize: 100,
+		batch:     repo.RunDueSoonAutomations,
+	}
+}
+
+// NewArchiver moves tasks completed more than after ago into the archive.
+func NewArchiver(repo *repository.PostgresRepository, interval, after time.Duration) *Worker {
+	return &Worker{
+		name:      "Archiver",
+		interval:  interval,
+		batchSize: 500,
+		batch: func(now time.Time, limit int) (int, error) {
+			return repo.ArchiveCompletedTasks(now.Add(-after), limit)
+		},
+	}
+}
+
+func (w *Worker) Run(ctx context.Context) {
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	for {
+		for {
+			done, err := w.batch(time.Now(), w.batchSize)
+			if err != nil {
+				log.Printf("%s: %v", w.name, err)
+				break
+			}
+			if done < w.batchSize {
+				break
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
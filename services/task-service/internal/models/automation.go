@@ -0,0 +1,321 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+type AutomationTrigger string
+
+const (
+	TriggerTaskCreated   AutomationTrigger = "task_created"
+	TriggerStatusChanged AutomationTrigger = "status_changed"
+	TriggerDueSoon       AutomationTrigger = "due_soon"
+	TriggerTagAdded      AutomationTrigger = "tag_added"
+)
+
+type ConditionOperator string
+
+const (
+	OperatorEquals      ConditionOperator = "equals"
+	OperatorNotEquals   ConditionOperator = "not_equals"
+	OperatorContains    ConditionOperator = "contains"
+	OperatorNotContains ConditionOperator = "not_contains"
+)
+
+type AutomationActionType string
+
+const (
+	ActionSetField         AutomationActionType = "set_field"
+	ActionCreateTask       AutomationActionType = "create_task"
+	ActionAddComment       AutomationActionType = "add_comment"
+	ActionSendNotification AutomationActionType = "send_notification"
+)
+
+type AutomationRunStatus string
+
+const (
+	RunSucceeded AutomationRunStatus = "succeeded"
+	RunFailed    AutomationRunStatus = "failed"
+	RunSkipped   AutomationRunStatus = "skipped"
+)
+
+// MaxAutomationDepth bounds how many rules can chain off each other's
+// changes from a single user action.
+const MaxAutomationDepth = 5
+
+// AutomationRule runs its actions on a project's task when the trigger fires
+// and every condition holds. Rules only act on the tasks of the user who
+// owns them. ToStatus narrows status_changed, Tag narrows tag_added and
+// DueWithinHours sets how early due_soon fires.
+type AutomationRule struct {
+	ID             string                `json:"id"`
+	ProjectID      string                `json:"project_id"`
+	UserID         string                `json:"user_id"`
+	Name           string                `json:"name"`
+	Enabled        bool                  `json:"enabled"`
+	Trigger        AutomationTrigger     `json:"trigger"`
+	ToStatus       TaskStatus            `json:"to_status,omitempty"`
+	Tag            string                `json:"tag,omitempty"`
+	DueWithinHours int                   `json:"due_within_hours,omitempty"`
+	Conditions     []AutomationCondition `json:"conditions"`
+	Actions        []AutomationAction    `json:"actions"`
+	CreatedAt      time.Time             `json:"created_at"`
+	UpdatedAt      time.Time             `json:"updated_at"`
+}
+
+// AutomationCondition compares a task field with a value. Fields are title,
+// description, status, priority, tags and custom fields as cf.<key>;
+// contains matches a substring, or a tag for tags.
+type AutomationCondition struct {
+	Field    string            `json:"field"`
+	Operator ConditionOperator `json:"operator"`
+	Value    string            `json:"value"`
+}
+
+// AutomationAction is one step of a rule. set_field uses Field and Value,
+// create_task uses Title, Message as the description, Priority and Tags,
+// add_comment posts Message, and send_notification sends Message to UserID,
+// who must be a project member, or the task owner. Title and Message may use {{title}}, {{id}},
+// {{status}} and {{priority}} of the triggering task.
+type AutomationAction struct {
+	Type     AutomationActionType `json:"type"`
+	Field    string               `json:"field,omitempty"`
+	Value    string               `json:"value,omitempty"`
+	Title    string               `json:"title,omitempty"`
+	Message  string               `json:"message,omitempty"`
+	Priority TaskPriority         `json:"priority,omitempty"`
+	Tags     []string             `json:"tags,omitempty"`
+	UserID   string               `json:"user_id,omitempty"`
+}
+
+// AutomationEvent is what happened to a task, matched against triggers.
+type AutomationEvent struct {
+	Trigger        AutomationTrigger
+	PreviousStatus TaskStatus
+	Tag            string
+}
+
+// AutomationRun is an execution log entry for one rule on one task.
+type AutomationRun struct {
+	ID        string              `json:"id"`
+	RuleID    string              `json:"rule_id"`
+	TaskID    string              `json:"task_id"`
+	Trigger   AutomationTrigger   `json:"trigger"`
+	Status    AutomationRunStatus `json:"status"`
+	Error     string              `json:"error,omitempty"`
+	Depth     int                 `json:"depth"`
+	DueDate   *time.Time          `json:"due_date,omitempty"`
+	CreatedAt time.Time           `json:"created_at"`
+}
+
+// settableFields are the task fields set_field can change.
+var settableFields = map[string]bool{
+	"status":      true,
+	"priority":    true,
+	"title":       true,
+	"description": true,
+}
+
+func validStatus(status TaskStatus) bool {
+	switch status {
+	case StatusPending, StatusInProgress, StatusCompleted, StatusCancelled:
+		return true
+	default:
+		return false
+	}
+}
+
+func (r *AutomationRule) Validate() error {
+	if r.ProjectID == "" {
+		return fmt.Errorf("project_id is required")
+	}
+	if r.UserID == "" {
+		return fmt.Errorf("user_id is required")
+	}
+	if r.Name == "" {
+		return fmt.Errorf("name is required")
+	}
+
+	switch r.Trigger {
+	case TriggerTaskCreated, TriggerTagAdded:
+	case TriggerStatusChanged:
+		if r.ToStatus != "" && !validStatus(r.ToStatus) {
+			return fmt.Errorf("invalid to_status: %s", r.ToStatus)
+		}
+	case TriggerDueSoon:
+		if r.DueWithinHours <= 0 {
+			return fmt.Errorf("due_within_hours must be positive for %s", r.Trigger)
+		}
+	default:
+		return fmt.Errorf("invalid trigger: %s", r.Trigger)
+	}
+
+	for _, condition := range r.Conditions {
+		if err := condition.Validate(); err != nil {
+			return err
+		}
+	}
+
+	if len(r.Actions) == 0 {
+		return fmt.Errorf("at least one action is required")
+	}
+	for _, action := range r.Actions {
+		if err := action.Validate(); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (c *AutomationCondition) Validate() error {
+	if !settableFields[c.Field] && c.Field != "tags" && !strings.HasPrefix(c.Field, "cf.") {
+		return fmt.Errorf("invalid condition field: %s", c.Field)
+	}
+
+	switch c.Operator {
+	case OperatorEquals, OperatorNotEquals, OperatorContains, OperatorNotContains:
+	default:
+		return fmt.Errorf("invalid condition operator: %s", c.Operator)
+	}
+
+	return nil
+}
+
+func (a *AutomationAction) Validate() error {
+	switch a.Type {
+	case ActionSetField:
+		if !settableFields[a.Field] {
+			return fmt.Errorf("invalid set_field field: %s", a.Field)
+		}
+		if a.Field == "status" && !validStatus(TaskStatus(a.Value)) {
+			return fmt.Errorf("invalid status: %s", a.Value)
+		}
+		if a.Field == "priority" && !validPriority(TaskPriority(a.Value)) {
+			return fmt.Errorf("invalid priority: %s", a.Value)
+		}
+		if a.Field == "title" && a.Value == "" {
+			return fmt.Errorf("title must not be empty")
+		}
+	case ActionCreateTask:
+		if a.Title == "" {
+			return fmt.Errorf("create_task needs a title")
+		}
+		if a.Priority != "" && !validPriority(a.Priority) {
+			return fmt.Errorf("invalid priority: %s", a.Priority)
+		}
+	case ActionAddComment, ActionSendNotification:
+		if a.Message == "" {
+			return fmt.Errorf("%s needs a message", a.Type)
+		}
+	default:
+		return fmt.Errorf("invalid action: %s", a.Type)
+	}
+
+	return nil
+}
+
+// Matches reports whether the rule fires for the event on the task.
+func (r *AutomationRule) Matches(task *Task, event AutomationEvent) bool {
+	if !r.Enabled || r.Trigger != event.Trigger {
+		return false
+	}
+
+	switch r.Trigger {
+	case TriggerStatusChanged:
+		if r.ToStatus != "" && task.Status != r.ToStatus {
+			return false
+		}
+	case TriggerTagAdded:
+		if r.Tag != "" && !strings.EqualFold(strings.TrimPrefix(r.Tag, "#"), event.Tag) {
+			return false
+		}
+	}
+
+	for _, condition := range r.Conditions {
+		if !condition.Matches(task) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func (c *AutomationCondition) Matches(task *Task) bool {
+	var value string
+	switch {
+	case c.Field == "tags":
+		hasTag := false
+		for _, tag := range task.Tags {
+			if strings.EqualFold(tag, strings.TrimPrefix(c.Value, "#")) {
+				hasTag = true
+			}
+		}
+		switch c.Operator {
+		case OperatorContains, OperatorEquals:
+			return hasTag
+		default:
+			return !hasTag
+		}
+	case strings.HasPrefix(c.Field, "cf."):
+		if v, ok := task.CustomFields[strings.TrimPrefix(c.Field, "cf.")]; ok {
+			value = fmt.Sprint(v)
+		}
+	default:
+		value = taskField(task, c.Field)
+	}
+
+	switch c.Operator {
+	case OperatorEquals:
+		return strings.EqualFold(value, c.Value)
+	case OperatorNotEquals:
+		return !strings.EqualFold(value, c.Value)
+	case OperatorContains:
+		return strings.Contains(strings.ToLower(value), strings.ToLower(c.Value))
+	default:
+		return !strings.Contains(strings.ToLower(value), strings.ToLower(c.Value))
+	}
+}
+
+func taskField(task *Task, field string) string {
+	switch field {
+	case "title":
+		return task.Title
+	case "description":
+		return task.Description
+	case "status":
+		return string(task.Status)
+	case "priority":
+		return string(task.Priority)
+	}
+	return ""
+}
+
+// ExpandAutomationText fills in the {{...}} placeholders an action's text may
+// use.
+func ExpandAutomationText(text string, task *Task) string {
+	return strings.NewReplacer(
+		"{{title}}", task.Title,
+		"{{id}}", task.ID,
+		"{{status}}", string(task.Status),
+		"{{priority}}", string(task.Priority),
+	).Replace(text)
+}
+
+// AddedTags returns the tags in after that were not in before.
+func AddedTags(before, after []string) []string {
+	existing := make(map[string]bool)
+	for _, tag := range before {
+		existing[tag] = true
+	}
+
+	added := []string{}
+	for _, tag := range after {
+		if !existing[tag] {
+			added = append(added, tag)
+		}
+	}
+	return added
+}
@@ -0,0 +1,49 @@
+package models
+
+import "fmt"
+
+// MoveScope selects which ordering a move changes: the task's position in
+// its list (the user's tasks within one project) or in its status column on
+// the board.
+type MoveScope string
+
+const (
+	ScopeList  MoveScope = "list"
+	ScopeBoard MoveScope = "board"
+)
+
+// TaskMove places a task between two neighbours. AfterID is the task that
+// should end up directly above it and BeforeID the one directly below; with
+// neither, the task goes to the end.
+type TaskMove struct {
+	Scope    MoveScope   `json:"scope,omitempty"`
+	Status   *TaskStatus `json:"status,omitempty"`
+	AfterID  string      `json:"after_id,omitempty"`
+	BeforeID string      `json:"before_id,omitempty"`
+}
+
+func (m *TaskMove) Validate() error {
+	switch m.Scope {
+	case ScopeList, ScopeBoard:
+	default:
+		return fmt.Errorf("invalid scope: %s", m.Scope)
+	}
+
+	if m.Status != nil {
+		switch *m.Status {
+		case StatusPending, StatusInProgress, StatusCompleted, StatusCancelled:
+		default:
+			return fmt.Errorf("invalid status: %s", *m.Status)
+		}
+	}
+
+	return nil
+}
+
+// BoardStatuses are the board's columns, left to right.
+var BoardStatuses = []TaskStatus{StatusPending, StatusInProgress, StatusCompleted, StatusCancelled}
+
+type BoardColumn struct {
+	Status TaskStatus `json:"status"`
+	Tasks  []*Task    `json:"tasks"`
+}
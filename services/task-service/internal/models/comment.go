@@ -0,0 +1,14 @@
+package models
+
+import "time"
+
+// Comment is a note on a task, left by a user or by the automation rule in
+// RuleID.
+type Comment struct {
+	ID        string    `json:"id"`
+	TaskID    string    `json:"task_id"`
+	UserID    string    `json:"user_id,omitempty"`
+	RuleID    string    `json:"rule_id,omitempty"`
+	Body      string    `json:"body"`
+	CreatedAt time.Time `json:"created_at"`
+}
@@ -0,0 +1,134 @@
+package models
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"time"
+)
+
+type FieldType string
+
+const (
+	FieldText        FieldType = "text"
+	FieldNumber      FieldType = "number"
+	FieldDate        FieldType = "date"
+	FieldSelect      FieldType = "select"
+	FieldMultiSelect FieldType = "multi_select"
+	FieldURL         FieldType = "url"
+)
+
+// fieldKeyPattern keeps keys safe to use as JSON keys in SQL and in query
+// parameters such as cf.story_points.
+var fieldKeyPattern = regexp.MustCompile(`^[a-z][a-z0-9_]{0,62}$`)
+
+// CustomFieldDefinition describes an extra attribute tasks in a project can
+// carry. Values are stored on the task under Key.
+type CustomFieldDefinition struct {
+	ID        string    `json:"id"`
+	ProjectID string    `json:"project_id"`
+	Key       string    `json:"key"`
+	Name      string    `json:"name"`
+	Type      FieldType `json:"type"`
+	Options   []string  `json:"options,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+func ValidFieldKey(key string) bool {
+	return fieldKeyPattern.MatchString(key)
+}
+
+func (d *CustomFieldDefinition) Validate() error {
+	if d.ProjectID == "" {
+		return fmt.Errorf("project_id is required")
+	}
+	if !ValidFieldKey(d.Key) {
+		return fmt.Errorf("invalid key: must be lowercase letters, digits and underscores")
+	}
+	if d.Name == "" {
+		return fmt.Errorf("name is required")
+	}
+
+	switch d.Type {
+	case FieldSelect, FieldMultiSelect:
+		if len(d.Options) == 0 {
+			return fmt.Errorf("%s fields need at least one option", d.Type)
+		}
+		seen := make(map[string]bool)
+		for _, option := range d.Options {
+			if option == "" || seen[option] {
+				return fmt.Errorf("options must be unique and non-empty")
+			}
+			seen[option] = true
+		}
+	case FieldText, FieldNumber, FieldDate, FieldURL:
+		if len(d.Options) > 0 {
+			return fmt.Errorf("only select fields have options")
+		}
+	default:
+		return fmt.Errorf("invalid type: %s", d.Type)
+	}
+
+	return nil
+}
+
+func (d *CustomFieldDefinition) hasOption(value string) bool {
+	for _, option := range d.Options {
+		if option == value {
+			return true
+		}
+	}
+	return false
+}
+
+// ValidateValue checks a JSON-decoded value against the field's type and
+// returns it in its stored form.
+func (d *CustomFieldDefinition) ValidateValue(value interface{}) (interface{}, error) {
+	switch d.Type {
+	case FieldText:
+		if s, ok := value.(string); ok {
+			return s, nil
+		}
+	case FieldNumber:
+		if n, ok := value.(float64); ok {
+			return n, nil
+		}
+	case FieldDate:
+		if s, ok := value.(string); ok {
+			if _, err := time.Parse("2006-01-02", s); err == nil {
+				return s, nil
+			}
+			return nil, fmt.Errorf("%s: expected a YYYY-MM-DD date", d.Key)
+		}
+	case FieldURL:
+		if s, ok := value.(string); ok {
+			u, err := url.Parse(s)
+			if err == nil && (u.Scheme == "http" || u.Scheme == "https") && u.Host != "" {
+				return s, nil
+			}
+			return nil, fmt.Errorf("%s: expected an http or https URL", d.Key)
+		}
+	case FieldSelect:
+		if s, ok := value.(string); ok {
+			if d.hasOption(s) {
+				return s, nil
+			}
+			return nil, fmt.Errorf("%s: %q is not an option", d.Key, s)
+		}
+	case FieldMultiSelect:
+		if values, ok := value.([]interface{}); ok {
+			selected := make([]interface{}, 0, len(values))
+			for _, v := range values {
+				s, ok := v.(string)
+				if !ok || !d.hasOption(s) {
+					return nil, fmt.Errorf("%s: %v is not an option", d.Key, v)
+				}
+				selected = append(selected, s)
+			}
+			return selected, nil
+		}
+	}
+
+	return nil, fmt.Errorf("%s: expected a %s value", d.Key, d.Type)
+}
@@ -0,0 +1,87 @@
+package models
+
+import (
+	"fmt"
+	"time"
+)
+
+type EscalationAction string
+
+const (
+	EscalateRaisePriority  EscalationAction = "raise_priority"
+	EscalateNotifyWatchers EscalationAction = "notify_watchers"
+	EscalateNotifyUser     EscalationAction = "notify_user"
+)
+
+// EscalationRule acts on a user's open tasks once they have been overdue for
+// AfterHours. An empty ProjectID or Priority matches every project or
+// priority; NotifyUserID is the manager notify_user escalates to.
+type EscalationRule struct {
+	ID           string           `json:"id"`
+	UserID       string           `json:"user_id"`
+	ProjectID    string           `json:"project_id,omitempty"`
+	Priority     TaskPriority     `json:"priority,omitempty"`
+	AfterHours   int              `json:"after_hours"`
+	Action       EscalationAction `json:"action"`
+	NotifyUserID string           `json:"notify_user_id,omitempty"`
+	CreatedAt    time.Time        `json:"created_at"`
+	UpdatedAt    time.Time        `json:"updated_at"`
+}
+
+func (r *EscalationRule) Validate() error {
+	if r.UserID == "" {
+		return fmt.Errorf("user_id is required")
+	}
+	if r.Priority != "" && !validPriority(r.Priority) {
+		return fmt.Errorf("invalid priority: %s", r.Priority)
+	}
+	if r.AfterHours < 0 {
+		return fmt.Errorf("after_hours must not be negative")
+	}
+
+	switch r.Action {
+	case EscalateRaisePriority, EscalateNotifyWatchers:
+	case EscalateNotifyUser:
+		if r.NotifyUserID == "" {
+			return fmt.Errorf("notify_user_id is required for %s", r.Action)
+		}
+	default:
+		return fmt.Errorf("invalid action: %s", r.Action)
+	}
+
+	return nil
+}
+
+// Escalation records one rule firing for a task, so a rule fires once per
+// task and due date.
+type Escalation struct {
+	ID              string           `json:"id"`
+	RuleID          string           `json:"rule_id"`
+	TaskID          string           `json:"task_id"`
+	DueDate         time.Time        `json:"due_date"`
+	Action          EscalationAction `json:"action"`
+	FromPriority    TaskPriority     `json:"from_priority"`
+	ToPriority      TaskPriority     `json:"to_priority"`
+	NotifiedUserIDs []string         `json:"notified_user_ids"`
+	CreatedAt       time.Time        `json:"created_at"`
+}
+
+// ProjectEscalationSettings lets a project opt out of escalation entirely.
+// UpdatedAt is nil for projects that never changed their settings.
+type ProjectEscalationSettings struct {
+	ProjectID string     `json:"project_id"`
+	OptedOut  bool       `json:"opted_out"`
+	UpdatedAt *time.Time `json:"updated_at,omitempty"`
+}
+
+// RaisePriority returns the next priority up, or URGENT once there.
+func RaisePriority(priority TaskPriority) TaskPriority {
+	switch priority {
+	case PriorityLow:
+		return PriorityMedium
+	case PriorityMedium:
+		return PriorityHigh
+	default:
+		return PriorityUrgent
+	}
+}
@@ -0,0 +1,34 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// TaskEvent is an event published about a task, as kept in the outbox.
+type TaskEvent struct {
+	ID         string          `json:"id"`
+	Type       string          `json:"type"`
+	TaskID     string          `json:"task_id"`
+	Payload    json.RawMessage `json:"payload"`
+	OccurredAt time.Time       `json:"occurred_at"`
+}
+
+// UserData is everything Task Service keeps about a user, for data access
+// requests. Comments and time entries include those the user added to other
+// users' tasks.
+type UserData struct {
+	Settings        *UserSettings     `json:"settings"`
+	Tasks           []*Task           `json:"tasks"`
+	ChecklistItems  []*ChecklistItem  `json:"checklist_items"`
+	Comments        []*Comment        `json:"comments"`
+	TimeEntries     []*TimeEntry      `json:"time_entries"`
+	WatchedTaskIDs  []string          `json:"watched_task_ids"`
+	History         []*TaskEvent      `json:"history"`
+	Escalations     []*Escalation     `json:"escalations"`
+	AutomationRuns  []*AutomationRun  `json:"automation_runs"`
+	SavedViews      []*SavedView      `json:"saved_views"`
+	Templates       []*TaskTemplate   `json:"templates"`
+	EscalationRules []*EscalationRule `json:"escalation_rules"`
+	AutomationRules []*AutomationRule `json:"automation_rules"`
+}
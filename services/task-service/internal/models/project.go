@@ -0,0 +1,21 @@
+package models
+
+import "time"
+
+// ProjectRole is what a member may do in a project.
+type ProjectRole string
+
+const (
+	// ProjectRoleOwner manages the project's members.
+	ProjectRoleOwner  ProjectRole = "owner"
+	ProjectRoleMember ProjectRole = "member"
+)
+
+// ProjectMember is a user who may put tasks in a project and see and manage
+// its settings, reports and automation.
+type ProjectMember struct {
+	ProjectID string      `json:"project_id"`
+	UserID    string      `json:"user_id"`
+	Role      ProjectRole `json:"role"`
+	CreatedAt time.Time   `json:"created_at"`
+}
@@ -0,0 +1,157 @@
+package models
+
+import (
+	"fmt"
+	"time"
+)
+
+type ReportInterval string
+
+const (
+	IntervalDay   ReportInterval = "day"
+	IntervalWeek  ReportInterval = "week"
+	IntervalMonth ReportInterval = "month"
+)
+
+type DistributionDimension string
+
+const (
+	DistributionPriority DistributionDimension = "priority"
+	DistributionStatus   DistributionDimension = "status"
+	DistributionProject  DistributionDimension = "project"
+)
+
+const (
+	defaultReportDays = 30
+	maxReportPeriods  = 1000
+)
+
+// ReportFilter selects the tasks and date range a report covers. From is
+// inclusive and To exclusive; periods and days are aligned in Location.
+type ReportFilter struct {
+	UserID    string
+	ProjectID string
+	From      time.Time
+	To        time.Time
+	Location  *time.Location
+}
+
+// NewReportFilter fills in a default range of the last 30 days, ending at
+// the end of today in loc, for bounds that are not given.
+func NewReportFilter(userID, projectID string, from, to *time.Time, loc *time.Location, now time.Time) (ReportFilter, error) {
+	if loc == nil {
+		loc = time.UTC
+	}
+
+	filter := ReportFilter{UserID: userID, ProjectID: projectID, Location: loc}
+
+	if to != nil {
+		filter.To = *to
+	} else {
+		local := now.In(loc)
+		filter.To = time.Date(local.Year(), local.Month(), local.Day()+1, 0, 0, 0, 0, loc)
+	}
+
+	if from != nil {
+		filter.From = *from
+	} else {
+		filter.From = filter.To.AddDate(0, 0, -defaultReportDays)
+	}
+
+	if err := filter.Validate(); err != nil {
+		return filter, err
+	}
+
+	return filter, nil
+}
+
+func (f *ReportFilter) Validate() error {
+	if f.UserID == "" && f.ProjectID == "" {
+		return fmt.Errorf("user_id or project_id is required")
+	}
+	if !f.From.Before(f.To) {
+		return fmt.Errorf("from must be before to")
+	}
+	return nil
+}
+
+// ReportPeriod is one bucket of a time series, from Start up to End.
+type ReportPeriod struct {
+	Label string
+	Start time.Time
+	End   time.Time
+}
+
+// Periods splits the range into calendar days, ISO weeks or months in the
+// filter's location. The first and last periods are clipped to the range.
+func (f *ReportFilter) Periods(interval ReportInterval) ([]ReportPeriod, error) {
+	local := f.From.In(f.Location)
+	start := time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, f.Location)
+
+	var next func(time.Time) time.Time
+	switch interval {
+	case IntervalDay:
+		next = func(t time.Time) time.Time { return t.AddDate(0, 0, 1) }
+	case IntervalWeek:
+		start = start.AddDate(0, 0, -(int(start.Weekday())+6)%7)
+		next = func(t time.Time) time.Time { return t.AddDate(0, 0, 7) }
+	case IntervalMonth:
+		start = time.Date(start.Year(), start.Month(), 1, 0, 0, 0, 0, f.Location)
+		next = func(t time.Time) time.Time { return t.AddDate(0, 1, 0) }
+	default:
+		return nil, fmt.Errorf("invalid interval: %s", interval)
+	}
+
+	var periods []ReportPeriod
+	for ; start.Before(f.To); start = next(start) {
+		if len(periods) == maxReportPeriods {
+			return nil, fmt.Errorf("date range is too long for %s intervals", interval)
+		}
+
+		period := ReportPeriod{Label: start.Format("2006-01-02"), Start: start, End: next(start)}
+		if period.Start.Before(f.From) {
+			period.Start = f.From
+		}
+		if period.End.After(f.To) {
+			period.End = f.To
+		}
+		periods = append(periods, period)
+	}
+
+	return periods, nil
+}
+
+// ThroughputPoint counts tasks created and completed in one period.
+type ThroughputPoint struct {
+	Period    string `json:"period"`
+	Created   int    `json:"created"`
+	Completed int    `json:"completed"`
+}
+
+// ReportSummary aggregates a range. Cycle time runs from creation to
+// completion for tasks completed in the range. The overdue rate is the share
+// of tasks due in the range, up to now, that were not completed by their due
+// date.
+type ReportSummary struct {
+	Created                 int     `json:"created"`
+	Completed               int     `json:"completed"`
+	AverageCycleTimeSeconds int64   `json:"average_cycle_time_seconds"`
+	MedianCycleTimeSeconds  int64   `json:"median_cycle_time_seconds"`
+	Due                     int     `json:"due"`
+	Overdue                 int     `json:"overdue"`
+	OverdueRate             float64 `json:"overdue_rate"`
+}
+
+// DistributionBucket counts the tasks with one priority, status or project.
+type DistributionBucket struct {
+	Key   string `json:"key"`
+	Count int    `json:"count"`
+}
+
+// BurndownPoint is the number of tasks still open at the end of a day, and
+// those completed by then.
+type BurndownPoint struct {
+	Date      string `json:"date"`
+	Open      int    `json:"open"`
+	Completed int    `json:"completed"`
+}
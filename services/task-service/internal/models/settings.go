@@ -0,0 +1,11 @@
+package models
+
+import "time"
+
+// UserSettings holds a user's preferences for interpreting task dates.
+type UserSettings struct {
+	UserID string `json:"user_id"`
+	// Timezone is the IANA timezone used when a task or request names none.
+	Timezone  string    `json:"timezone"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
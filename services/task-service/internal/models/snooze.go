@@ -0,0 +1,66 @@
+package models
+
+import (
+	"fmt"
+	"time"
+)
+
+type SnoozePreset string
+
+const (
+	SnoozeLaterToday  SnoozePreset = "later_today"
+	SnoozeTomorrow    SnoozePreset = "tomorrow"
+	SnoozeThisWeekend SnoozePreset = "this_weekend"
+	SnoozeNextWeek    SnoozePreset = "next_week"
+)
+
+// snoozeHour is the local time presets that land on another day wake at.
+const snoozeHour = 9
+
+// SnoozeUntil resolves a preset against now in loc: later_today is three
+// hours from now, tomorrow is 9am tomorrow, this_weekend is 9am on the coming
+// Saturday and next_week is 9am on the coming Monday.
+func SnoozeUntil(preset SnoozePreset, now time.Time, loc *time.Location) (time.Time, error) {
+	local := now.In(loc)
+	morning := func(days int) time.Time {
+		return time.Date(local.Year(), local.Month(), local.Day()+days, snoozeHour, 0, 0, 0, loc)
+	}
+
+	switch preset {
+	case SnoozeLaterToday:
+		return now.Add(3 * time.Hour), nil
+	case SnoozeTomorrow:
+		return morning(1), nil
+	case SnoozeThisWeekend:
+		return morning(daysUntil(local.Weekday(), time.Saturday)), nil
+	case SnoozeNextWeek:
+		return morning(daysUntil(local.Weekday(), time.Monday)), nil
+	default:
+		return time.Time{}, fmt.Errorf("invalid preset: %s", preset)
+	}
+}
+
+// daysUntil counts the days from one weekday to the next occurrence of
+// another, a full week when they are the same.
+func daysUntil(from, to time.Weekday) int {
+	days := (int(to) - int(from) + 7) % 7
+	if days == 0 {
+		days = 7
+	}
+	return days
+}
+
+// ParseStartDate reads an RFC3339 timestamp or a YYYY-MM-DD day, which starts
+// at midnight in loc.
+func ParseStartDate(value string, loc *time.Location) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+
+	day, err := time.ParseInLocation("2006-01-02", value, loc)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid start_date %q: expected RFC3339 or YYYY-MM-DD", value)
+	}
+
+	return day, nil
+}
@@ -1,6 +1,8 @@
 package models
 
 import (
+	"fmt"
+	"strings"
 	"time"
 )
 
@@ -23,13 +25,65 @@ const (
 )
 
 type Task struct {
-	ID          string       `json:"id"`
-	Title       string       `json:"title"`
-	Description string       `json:"description"`
-	Status      TaskStatus   `json:"status"`
-	Priority    TaskPriority `json:"priority"`
-	UserID      string       `json:"user_id"`
-	DueDate     *time.Time   `json:"due_date,omitempty"`
-	CreatedAt   time.Time    `json:"created_at"`
-	UpdatedAt   time.Time    `json:"updated_at"`
+	ID           string                 `json:"id"`
+	Title        string                 `json:"title"`
+	Description  string                 `json:"description"`
+	Status       TaskStatus             `json:"status"`
+	Priority     TaskPriority           `json:"priority"`
+	UserID       string                 `json:"user_id"`
+	ProjectID    string                 `json:"project_id,omitempty"`
+	DueDate      *time.Time             `json:"due_date,omitempty"`
+	DueAllDay    bool                   `json:"due_all_day"`
+	StartDate    *time.Time             `json:"start_date,omitempty"`
+	Timezone     string                 `json:"timezone"`
+	Tags         []string               `json:"tags"`
+	Recurrence   string                 `json:"recurrence,omitempty"`
+	ListRank     string                 `json:"list_rank"`
+	BoardRank    string                 `json:"board_rank"`
+	Checklist    []*ChecklistItem       `json:"checklist,omitempty"`
+	CustomFields map[string]interface{} `json:"custom_fields,omitempty"`
+	CompletedAt  *time.Time             `json:"completed_at,omitempty"`
+	ArchivedAt   *time.Time             `json:"archived_at,omitempty"`
+	CreatedAt    time.Time              `json:"created_at"`
+	UpdatedAt    time.Time              `json:"updated_at"`
+}
+
+// NormalizeTags lowercases tags, strips a leading '#' and drops blanks and
+// duplicates. The result is never nil.
+func NormalizeTags(tags []string) []string {
+	normalized := []string{}
+	seen := make(map[string]bool)
+
+	for _, tag := range tags {
+		tag = strings.ToLower(strings.TrimPrefix(strings.TrimSpace(tag), "#"))
+		if tag == "" || seen[tag] {
+			continue
+		}
+		seen[tag] = true
+		normalized = append(normalized, tag)
+	}
+
+	return normalized
+}
+
+// ParseDueDate reads an RFC3339 timestamp or, for an all-day due date, a
+// YYYY-MM-DD day in loc.
+func ParseDueDate(value string, loc *time.Location) (time.Time, bool, error) {
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, false, nil
+	}
+
+	day, err := time.ParseInLocation("2006-01-02", value, loc)
+	if err != nil {
+		return time.Time{}, false, fmt.Errorf("invalid due_date %q: expected RFC3339 or YYYY-MM-DD", value)
+	}
+
+	return EndOfDay(day, loc), true, nil
+}
+
+// EndOfDay is the last second of t's calendar day in loc, when an all-day
+// task falls due.
+func EndOfDay(t time.Time, loc *time.Location) time.Time {
+	t = t.In(loc)
+	return time.Date(t.Year(), t.Month(), t.Day(), 23, 59, 59, 0, loc)
 }
@@ -0,0 +1,104 @@
+package models
+
+import (
+	"fmt"
+	"time"
+)
+
+// ChecklistItem is a step within a task that can be ticked off on its own.
+type ChecklistItem struct {
+	ID        string    `json:"id"`
+	TaskID    string    `json:"task_id"`
+	Title     string    `json:"title"`
+	Completed bool      `json:"completed"`
+	Position  int       `json:"position"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// TemplateTask describes one task a template creates.
+type TemplateTask struct {
+	Title       string       `json:"title"`
+	Description string       `json:"description,omitempty"`
+	Priority    TaskPriority `json:"priority,omitempty"`
+	// DueOffsetDays is the due date in days relative to the start date and
+	// may be negative; tasks without it get no due date.
+	DueOffsetDays *int     `json:"due_offset_days,omitempty"`
+	Tags          []string `json:"tags,omitempty"`
+	Checklist     []string `json:"checklist,omitempty"`
+}
+
+// TaskTemplate is a reusable set of tasks, such as an onboarding or release
+// process.
+type TaskTemplate struct {
+	ID              string         `json:"id"`
+	UserID          string         `json:"user_id"`
+	Name            string         `json:"name"`
+	Description     string         `json:"description"`
+	DefaultPriority TaskPriority   `json:"default_priority"`
+	Tasks           []TemplateTask `json:"tasks"`
+	CreatedAt       time.Time      `json:"created_at"`
+	UpdatedAt       time.Time      `json:"updated_at"`
+}
+
+func validPriority(priority TaskPriority) bool {
+	switch priority {
+	case PriorityLow, PriorityMedium, PriorityHigh, PriorityUrgent:
+		return true
+	default:
+		return false
+	}
+}
+
+func (t *TaskTemplate) Validate() error {
+	if t.Name == "" {
+		return fmt.Errorf("name is required")
+	}
+
+	if t.DefaultPriority == "" {
+		t.DefaultPriority = PriorityMedium
+	}
+	if !validPriority(t.DefaultPriority) {
+		return fmt.Errorf("invalid default_priority: %s", t.DefaultPriority)
+	}
+
+	if len(t.Tasks) == 0 {
+		return fmt.Errorf("template must contain at least one task")
+	}
+
+	for i, task := range t.Tasks {
+		if task.Title == "" {
+			return fmt.Errorf("task %d: title is required", i+1)
+		}
+		if task.Priority != "" && !validPriority(task.Priority) {
+			return fmt.Errorf("task %d: invalid priority: %s", i+1, task.Priority)
+		}
+		for _, item := range task.Checklist {
+			if item == "" {
+				return fmt.Errorf("task %d: checklist items need a title", i+1)
+			}
+		}
+	}
+
+	return nil
+}
+
+// TemplateStart reads the start date a template is instantiated from: an
+// RFC3339 timestamp, or a YYYY-MM-DD day in loc, in which case the tasks are
+// due all day. An empty value means today.
+func TemplateStart(value string, loc *time.Location, now time.Time) (time.Time, bool, error) {
+	if value == "" {
+		return EndOfDay(now, loc), true, nil
+	}
+
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t.In(loc), false, nil
+	}
+
+	day, err := time.ParseInLocation("2006-01-02", value, loc)
+	if err != nil {
+		return time.Time{}, false, fmt.Errorf("invalid start_date: expected RFC3339 or YYYY-MM-DD")
+	}
+
+	return EndOfDay(day, loc), true, nil
+}
@@ -0,0 +1,53 @@
+package models
+
+import "time"
+
+// TimeEntry is a span of time a user spent on a task. A running timer is an
+// entry without an end time.
+type TimeEntry struct {
+	ID              string     `json:"id"`
+	TaskID          string     `json:"task_id"`
+	UserID          string     `json:"user_id"`
+	StartedAt       time.Time  `json:"started_at"`
+	EndedAt         *time.Time `json:"ended_at,omitempty"`
+	DurationSeconds int64      `json:"duration_seconds"`
+	Note            string     `json:"note,omitempty"`
+	Manual          bool       `json:"manual"`
+	CreatedAt       time.Time  `json:"created_at"`
+}
+
+func (e *TimeEntry) Running() bool {
+	return e.EndedAt == nil
+}
+
+type TimeGrouping string
+
+const (
+	GroupByTask    TimeGrouping = "task"
+	GroupByProject TimeGrouping = "project"
+	GroupByDay     TimeGrouping = "day"
+)
+
+// TimeFilter narrows time entry queries. Zero values are ignored.
+type TimeFilter struct {
+	UserID    string
+	TaskID    string
+	ProjectID string
+	From      *time.Time
+	To        *time.Time
+}
+
+// TimeTotal is the tracked time rolled up for one task, project or day.
+type TimeTotal struct {
+	Key             string `json:"key"`
+	Label           string `json:"label,omitempty"`
+	DurationSeconds int64  `json:"duration_seconds"`
+	Entries         int    `json:"entries"`
+}
+
+// TimesheetRow is a time entry joined with its task for timesheet exports.
+type TimesheetRow struct {
+	TimeEntry
+	TaskTitle string `json:"task_title"`
+	ProjectID string `json:"project_id,omitempty"`
+}
@@ -0,0 +1,215 @@
+package models
+
+import (
+	"fmt"
+	"time"
+)
+
+type DueFilter string
+
+const (
+	DueAny      DueFilter = ""
+	DueOverdue  DueFilter = "overdue"
+	DueToday    DueFilter = "today"
+	DueUpcoming DueFilter = "upcoming"
+	DueNone     DueFilter = "none"
+)
+
+type SnoozedFilter string
+
+const (
+	SnoozedExclude SnoozedFilter = ""
+	SnoozedInclude SnoozedFilter = "include"
+	SnoozedOnly    SnoozedFilter = "only"
+)
+
+// TaskFilter selects a user's tasks. Empty fields match everything.
+type TaskFilter struct {
+	Statuses   []TaskStatus   `json:"statuses,omitempty"`
+	Priorities []TaskPriority `json:"priorities,omitempty"`
+	ProjectID  string         `json:"project_id,omitempty"`
+	Due        DueFilter      `json:"due,omitempty"`
+	// UpcomingDays is the window used by DueUpcoming, starting tomorrow.
+	UpcomingDays int    `json:"upcoming_days,omitempty"`
+	Search       string `json:"search,omitempty"`
+	// Tags matches tasks that carry every listed tag.
+	Tags []string `json:"tags,omitempty"`
+	// CustomFields matches tasks whose custom field equals the value, or for
+	// multi-select fields includes it.
+	CustomFields map[string]string `json:"custom_fields,omitempty"`
+	// Snoozed tasks, whose start date is still ahead, are hidden by default.
+	Snoozed SnoozedFilter `json:"snoozed,omitempty"`
+	// IncludeArchived adds tasks that were moved to the archive.
+	IncludeArchived bool `json:"include_archived,omitempty"`
+}
+
+func (f *TaskFilter) Validate() error {
+	for _, status := range f.Statuses {
+		switch status {
+		case StatusPending, StatusInProgress, StatusCompleted, StatusCancelled:
+		default:
+			return fmt.Errorf("invalid status: %s", status)
+		}
+	}
+
+	for _, priority := range f.Priorities {
+		switch priority {
+		case PriorityLow, PriorityMedium, PriorityHigh, PriorityUrgent:
+		default:
+			return fmt.Errorf("invalid priority: %s", priority)
+		}
+	}
+
+	switch f.Due {
+	case DueAny, DueOverdue, DueToday, DueUpcoming, DueNone:
+	default:
+		return fmt.Errorf("invalid due filter: %s", f.Due)
+	}
+
+	switch f.Snoozed {
+	case SnoozedExclude, SnoozedInclude, SnoozedOnly:
+	default:
+		return fmt.Errorf("invalid snoozed filter: %s", f.Snoozed)
+	}
+
+	if f.UpcomingDays < 0 {
+		return fmt.Errorf("upcoming_days must not be negative")
+	}
+
+	for key := range f.CustomFields {
+		if !ValidFieldKey(key) {
+			return fmt.Errorf("invalid custom field: %s", key)
+		}
+	}
+
+	return nil
+}
+
+type SortField string
+
+const (
+	SortCreatedAt SortField = "created_at"
+	SortUpdatedAt SortField = "updated_at"
+	SortDueDate   SortField = "due_date"
+	SortPriority  SortField = "priority"
+	SortTitle     SortField = "title"
+	// SortPosition is the manual order set with MoveTask.
+	SortPosition SortField = "position"
+	// SortCustomField orders by the custom field named in TaskSort.CustomField.
+	SortCustomField SortField = "custom_field"
+)
+
+type TaskSort struct {
+	Field       SortField `json:"field,omitempty"`
+	CustomField string    `json:"custom_field,omitempty"`
+	Descending  bool      `json:"descending,omitempty"`
+}
+
+func (s *TaskSort) Validate() error {
+	switch s.Field {
+	case "", SortCreatedAt, SortUpdatedAt, SortDueDate, SortPriority, SortTitle, SortPosition:
+		return nil
+	case SortCustomField:
+		if !ValidFieldKey(s.CustomField) {
+			return fmt.Errorf("invalid custom field: %s", s.CustomField)
+		}
+		return nil
+	default:
+		return fmt.Errorf("invalid sort field: %s", s.Field)
+	}
+}
+
+// SavedView is a named filter and sort a user can run repeatedly.
+type SavedView struct {
+	ID        string     `json:"id"`
+	UserID    string     `json:"user_id,omitempty"`
+	Name      string     `json:"name"`
+	Filter    TaskFilter `json:"filter"`
+	Sort      TaskSort   `json:"sort"`
+	BuiltIn   bool       `json:"built_in"`
+	CreatedAt time.Time  `json:"created_at,omitempty"`
+	UpdatedAt time.Time  `json:"updated_at,omitempty"`
+}
+
+func (v *SavedView) Validate() error {
+	if v.Name == "" {
+		return fmt.Errorf("name is required")
+	}
+	if err := v.Filter.Validate(); err != nil {
+		return err
+	}
+	return v.Sort.Validate()
+}
+
+var openStatuses = []TaskStatus{StatusPending, StatusInProgress}
+
+// BuiltInViews are available to every user and are not stored.
+var BuiltInViews = []*SavedView{
+	{
+		ID:      "today",
+		Name:    "Today",
+		Filter:  TaskFilter{Statuses: openStatuses, Due: DueToday},
+		Sort:    TaskSort{Field: SortDueDate},
+		BuiltIn: true,
+	},
+	{
+		ID:      "upcoming",
+		Name:    "Upcoming",
+		Filter:  TaskFilter{Statuses: openStatuses, Due: DueUpcoming, UpcomingDays: 7},
+		Sort:    TaskSort{Field: SortDueDate},
+		BuiltIn: true,
+	},
+	{
+		ID:      "overdue",
+		Name:    "Overdue",
+		Filter:  TaskFilter{Statuses: openStatuses, Due: DueOverdue},
+		Sort:    TaskSort{Field: SortDueDate},
+		BuiltIn: true,
+	},
+	{
+		ID:      "snoozed",
+		Name:    "Snoozed",
+		Filter:  TaskFilter{Statuses: openStatuses, Snoozed: SnoozedOnly},
+		Sort:    TaskSort{Field: SortDueDate},
+		BuiltIn: true,
+	},
+}
+
+func BuiltInView(id string) *SavedView {
+	for _, view := range BuiltInViews {
+		if view.ID == id {
+			return view
+		}
+	}
+	return nil
+}
+
+// ViewCount is the number of tasks a view currently matches.
+type ViewCount struct {
+	ViewID string `json:"view_id"`
+	Name   string `json:"name"`
+	Count  int    `json:"count"`
+}
+
+// FilterTimezone resolves the timezone due filters are evaluated in. Without
+// a name it returns nil, meaning each task's own timezone.
+func FilterTimezone(name string) (*time.Location, error) {
+	if name == "" {
+		return nil, nil
+	}
+	return LoadTimezone(name)
+}
+
+// LoadTimezone resolves an IANA timezone name, defaulting to UTC.
+func LoadTimezone(name string) (*time.Location, error) {
+	if name == "" {
+		return time.UTC, nil
+	}
+
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		return nil, fmt.Errorf("invalid timezone: %s", name)
+	}
+
+	return loc, nil
+}
@@ -0,0 +1,411 @@
+package quickadd
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/todo/services/task-service/internal/models"
+	"github.com/todo/services/task-service/internal/recurrence"
+)
+
+// Result is the interpretation of a quick-add phrase.
+type Result struct {
+	Title      string              `json:"title"`
+	DueDate    *time.Time          `json:"due_date,omitempty"`
+	HasTime    bool                `json:"has_time"`
+	Recurrence string              `json:"recurrence,omitempty"`
+	Priority   models.TaskPriority `json:"priority"`
+	Tags       []string            `json:"tags"`
+}
+
+const (
+	weekdayPattern = `(monday|tuesday|wednesday|thursday|friday|saturday|sunday|mon|tues|tue|wed|thurs|thur|thu|fri|sat|sun)`
+	monthPattern   = `(january|february|march|april|may|june|july|august|september|october|november|december|jan|feb|mar|apr|jun|jul|aug|sept|sep|oct|nov|dec)`
+	numberPattern  = `(\d+|a|an|one|two|three|four|five|six|seven|eight|nine|ten)`
+	datePrefix     = `(?:(?:on|by|due)\s+)?`
+)
+
+var (
+	tagRe      = regexp.MustCompile(`(?:^|\s)#([\p{L}\p{N}_/-]+)`)
+	priorityRe = regexp.MustCompile(`(?i)(?:^|\s)!(low|medium|med|high|urgent|[1-4])\b`)
+
+	everyMonthDayRe = regexp.MustCompile(`(?i)\bevery\s+(\d{1,2})(?:(?:st|nd|rd|th)(?:\s+(?:day\s+)?of\s+(?:the|each|every)\s+month)?|\s+(?:day\s+)?of\s+(?:the|each|every)\s+month)\b`)
+	everyWeekdayRe  = regexp.MustCompile(`(?i)\bevery\s+(weekday|weekend)\b`)
+	everyDaysRe     = regexp.MustCompile(`(?i)\bevery\s+(` + weekdayPattern + `(?:\s*(?:,|and|&)\s*` + weekdayPattern + `)*)\b`)
+	everyIntervalRe = regexp.MustCompile(`(?i)\bevery\s+(?:(\d+|other)\s+)?(day|week|month|year)s?\b`)
+	repeatWordRe    = regexp.MustCompile(`(?i)\b(daily|weekly|monthly|yearly|annually)\b`)
+	weekdayNameRe   = regexp.MustCompile(`(?i)` + weekdayPattern)
+
+	time12Re    = regexp.MustCompile(`(?i)(?:\bat\s+|@\s*|\b)(\d{1,2})(?::([0-5]\d))?\s*(am|pm)\b`)
+	time24Re    = regexp.MustCompile(`(?i)(?:\bat\s+|@\s*|\b)([01]?\d|2[0-3]):([0-5]\d)\b`)
+	namedTimeRe = regexp.MustCompile(`(?i)\b(?:at\s+)?(noon|midnight)\b`)
+
+	isoDateRe     = regexp.MustCompile(`(?i)\b` + datePrefix + `(\d{4})-(\d{2})-(\d{2})\b`)
+	relativeDayRe = regexp.MustCompile(`(?i)\b` + datePrefix + `(today|tonight|tomorrow|tmrw|tmr)\b`)
+	inDurationRe  = regexp.MustCompile(`(?i)\b(?:due\s+)?in\s+` + numberPattern + `\s+(minute|min|hour|hr|day|week|month|year)s?\b`)
+	nextPeriodRe  = regexp.MustCompile(`(?i)\b` + datePrefix + `next\s+(week|month|year)\b`)
+	weekdayRe     = regexp.MustCompile(`(?i)\b` + datePrefix + `(?:(?:next|this)\s+)?` + weekdayPattern + `\b`)
+	monthDayRe    = regexp.MustCompile(`(?i)\b` + datePrefix + monthPattern + `\.?\s+(\d{1,2})(?:st|nd|rd|th)?(?:,?\s+(\d{4}))?\b`)
+	dayMonthRe    = regexp.MustCompile(`(?i)\b` + datePrefix + `(\d{1,2})(?:st|nd|rd|th)?\s+(?:of\s+)?` + monthPattern + `(?:,?\s+(\d{4}))?\b`)
+)
+
+var weekdays = map[string]time.Weekday{
+	"sun": time.Sunday,
+	"mon": time.Monday,
+	"tue": time.Tuesday,
+	"wed": time.Wednesday,
+	"thu": time.Thursday,
+	"fri": time.Friday,
+	"sat": time.Saturday,
+}
+
+var months = map[string]time.Month{
+	"jan": time.January,
+	"feb": time.February,
+	"mar": time.March,
+	"apr": time.April,
+	"may": time.May,
+	"jun": time.June,
+	"jul": time.July,
+	"aug": time.August,
+	"sep": time.September,
+	"oct": time.October,
+	"nov": time.November,
+	"dec": time.December,
+}
+
+var numberWords = map[string]int{
+	"a": 1, "an": 1, "one": 1, "two": 2, "three": 3, "four": 4, "five": 5,
+	"six": 6, "seven": 7, "eight": 8, "nine": 9, "ten": 10,
+}
+
+var priorities = map[string]models.TaskPriority{
+	"low":    models.PriorityLow,
+	"medium": models.PriorityMedium,
+	"med":    models.PriorityMedium,
+	"high":   models.PriorityHigh,
+	"urgent": models.PriorityUrgent,
+	"1":      models.PriorityUrgent,
+	"2":      models.PriorityHigh,
+	"3":      models.PriorityMedium,
+	"4":      models.PriorityLow,
+}
+
+// eveningHour is the time "tonight" means when no time is given.
+const eveningHour = 20
+
+// parser consumes recognised phrases from text; whatever remains is the title.
+type parser struct {
+	text string
+	now  time.Time
+	loc  *time.Location
+
+	date    *time.Time // local midnight of the due day
+	exact   *time.Time // a due instant from "in 2 hours"
+	hour    int
+	minute  int
+	hasTime bool
+	rule    *recurrence.Rule
+}
+
+// take removes the first match of re from the remaining text and returns its
+// submatches, or nil when re does not match.
+func (p *parser) take(re *regexp.Regexp) []string {
+	loc := re.FindStringSubmatchIndex(p.text)
+	if loc == nil {
+		return nil
+	}
+
+	match := make([]string, len(loc)/2)
+	for i := range match {
+		if loc[2*i] >= 0 {
+			match[i] = strings.ToLower(p.text[loc[2*i]:loc[2*i+1]])
+		}
+	}
+
+	p.text = p.text[:loc[0]] + " " + p.text[loc[1]:]
+	return match
+}
+
+// Parse interprets a phrase such as "Pay rent every 1st of the month at 9am
+// !high #home". Dates and times are read in loc relative to now.
+func Parse(text string, now time.Time, loc *time.Location) (*Result, error) {
+	p := &parser{text: text, now: now.In(loc), loc: loc}
+	result := &Result{Priority: models.PriorityMedium}
+
+	var tags []string
+	for match := p.take(tagRe); match != nil; match = p.take(tagRe) {
+		tags = append(tags, match[1])
+	}
+	result.Tags = models.NormalizeTags(tags)
+
+	if match := p.take(priorityRe); match != nil {
+		result.Priority = priorities[match[1]]
+	}
+
+	p.parseRecurrence()
+
+	if err := p.parseTime(); err != nil {
+		return nil, err
+	}
+
+	if err := p.parseDate(); err != nil {
+		return nil, err
+	}
+
+	result.Title = strings.Trim(strings.Join(strings.Fields(p.text), " "), " ,;:-")
+	if result.Title == "" {
+		return nil, fmt.Errorf("title is required")
+	}
+
+	if p.rule != nil {
+		result.Recurrence = p.rule.String()
+	}
+	result.DueDate = p.dueDate()
+	result.HasTime = p.hasTime || p.exact != nil
+
+	return result, nil
+}
+
+func (p *parser) parseRecurrence() {
+	if match := p.take(everyMonthDayRe); match != nil {
+		day, _ := strconv.Atoi(match[1])
+		if day >= 1 && day <= 31 {
+			p.rule = &recurrence.Rule{Freq: recurrence.Monthly, Interval: 1, ByMonthDay: day}
+			return
+		}
+	}
+
+	if match := p.take(everyWeekdayRe); match != nil {
+		days := []time.Weekday{time.Monday, time.Tuesday, time.Wednesday, time.Thursday, time.Friday}
+		if match[1] == "weekend" {
+			days = []time.Weekday{time.Saturday, time.Sunday}
+		}
+		p.rule = &recurrence.Rule{Freq: recurrence.Weekly, Interval: 1, ByDay: days}
+		return
+	}
+
+	if match := p.take(everyDaysRe); match != nil {
+		rule := &recurrence.Rule{Freq: recurrence.Weekly, Interval: 1}
+		for _, name := range weekdayNameRe.FindAllString(match[1], -1) {
+			rule.ByDay = append(rule.ByDay, weekdays[name[:3]])
+		}
+		p.rule = rule
+		return
+	}
+
+	if match := p.take(everyIntervalRe); match != nil {
+		interval := 1
+		if match[1] == "other" {
+			interval = 2
+		} else if match[1] != "" {
+			interval, _ = strconv.Atoi(match[1])
+		}
+		if interval < 1 {
+			interval = 1
+		}
+		freqs := map[string]recurrence.Frequency{"day": recurrence.Daily, "week": recurrence.Weekly, "month": recurrence.Monthly, "year": recurrence.Yearly}
+		p.rule = &recurrence.Rule{Freq: freqs[match[2]], Interval: interval}
+		return
+	}
+
+	if match := p.take(repeatWordRe); match != nil {
+		freqs := map[string]recurrence.Frequency{"daily": recurrence.Daily, "weekly": recurrence.Weekly, "monthly": recurrence.Monthly, "yearly": recurrence.Yearly, "annually": recurrence.Yearly}
+		p.rule = &recurrence.Rule{Freq: freqs[match[1]], Interval: 1}
+	}
+}
+
+func (p *parser) parseTime() error {
+	if match := p.take(time12Re); match != nil {
+		hour, _ := strconv.Atoi(match[1])
+		if hour < 1 || hour > 12 {
+			return fmt.Errorf("invalid time: %s%s", match[1], match[3])
+		}
+		hour %= 12
+		if match[3] == "pm" {
+			hour += 12
+		}
+		p.setTime(hour, match[2])
+		return nil
+	}
+
+	if match := p.take(time24Re); match != nil {
+		hour, _ := strconv.Atoi(match[1])
+		p.setTime(hour, match[2])
+		return nil
+	}
+
+	if match := p.take(namedTimeRe); match != nil {
+		if match[1] == "noon" {
+			p.setTime(12, "")
+		} else {
+			p.setTime(0, "")
+		}
+	}
+
+	return nil
+}
+
+func (p *parser) setTime(hour int, minute string) {
+	p.hour = hour
+	p.minute, _ = strconv.Atoi(minute)
+	p.hasTime = true
+}
+
+func (p *parser) parseDate() error {
+	today := time.Date(p.now.Year(), p.now.Month(), p.now.Day(), 0, 0, 0, 0, p.loc)
+
+	if match := p.take(isoDateRe); match != nil {
+		year, _ := strconv.Atoi(match[1])
+		month, _ := strconv.Atoi(match[2])
+		day, _ := strconv.Atoi(match[3])
+		return p.setDate(year, time.Month(month), day)
+	}
+
+	if match := p.take(relativeDayRe); match != nil {
+		date := today
+		switch match[1] {
+		case "tonight":
+			if !p.hasTime {
+				p.setTime(eveningHour, "")
+			}
+		case "tomorrow", "tmrw", "tmr":
+			date = today.AddDate(0, 0, 1)
+		}
+		p.date = &date
+		return nil
+	}
+
+	if match := p.take(inDurationRe); match != nil {
+		n, ok := numberWords[match[1]]
+		if !ok {
+			n, _ = strconv.Atoi(match[1])
+		}
+
+		var date time.Time
+		switch match[2] {
+		case "minute", "min":
+			exact := p.now.Add(time.Duration(n) * time.Minute)
+			p.exact = &exact
+			return nil
+		case "hour", "hr":
+			exact := p.now.Add(time.Duration(n) * time.Hour)
+			p.exact = &exact
+			return nil
+		case "day":
+			date = today.AddDate(0, 0, n)
+		case "week":
+			date = today.AddDate(0, 0, 7*n)
+		case "month":
+			date = today.AddDate(0, n, 0)
+		case "year":
+			date = today.AddDate(n, 0, 0)
+		}
+		p.date = &date
+		return nil
+	}
+
+	if match := p.take(nextPeriodRe); match != nil {
+		var date time.Time
+		switch match[1] {
+		case "week":
+			date = nextWeekday(today, time.Monday)
+		case "month":
+			date = time.Date(today.Year(), today.Month()+1, 1, 0, 0, 0, 0, p.loc)
+		case "year":
+			date = time.Date(today.Year()+1, time.January, 1, 0, 0, 0, 0, p.loc)
+		}
+		p.date = &date
+		return nil
+	}
+
+	if match := p.take(monthDayRe); match != nil {
+		day, _ := strconv.Atoi(match[2])
+		return p.setMonthDay(months[match[1][:3]], day, match[3])
+	}
+
+	if match := p.take(dayMonthRe); match != nil {
+		day, _ := strconv.Atoi(match[1])
+		return p.setMonthDay(months[match[2][:3]], day, match[3])
+	}
+
+	if match := p.take(weekdayRe); match != nil {
+		date := nextWeekday(today, weekdays[match[1][:3]])
+		p.date = &date
+	}
+
+	return nil
+}
+
+// setMonthDay sets a date given without a year to its next occurrence.
+func (p *parser) setMonthDay(month time.Month, day int, year string) error {
+	if year != "" {
+		y, _ := strconv.Atoi(year)
+		return p.setDate(y, month, day)
+	}
+
+	y := p.now.Year()
+	if time.Date(y, month, day, 0, 0, 0, 0, p.loc).Before(time.Date(p.now.Year(), p.now.Month(), p.now.Day(), 0, 0, 0, 0, p.loc)) {
+		y++
+	}
+	return p.setDate(y, month, day)
+}
+
+// setDate rejects dates that don't exist, such as February 30th, rather than
+// letting them roll over into the next month.
+func (p *parser) setDate(year int, month time.Month, day int) error {
+	date := time.Date(year, month, day, 0, 0, 0, 0, p.loc)
+	if date.Year() != year || date.Month() != month || date.Day() != day {
+		return fmt.Errorf("invalid date: %04d-%02d-%02d", year, int(month), day)
+	}
+
+	p.date = &date
+	return nil
+}
+
+// nextWeekday returns the first given weekday strictly after day.
+func nextWeekday(day time.Time, weekday time.Weekday) time.Time {
+	offset := (int(weekday) - int(day.Weekday()) + 7) % 7
+	if offset == 0 {
+		offset = 7
+	}
+	return day.AddDate(0, 0, offset)
+}
+
+// dueDate combines the parsed date, time and recurrence. A time without a
+// date means its next occurrence, and a date without a time means the end
+// of that day.
+func (p *parser) dueDate() *time.Time {
+	if p.exact != nil {
+		return p.exact
+	}
+
+	if p.date == nil && !p.hasTime && p.rule == nil {
+		return nil
+	}
+
+	var due time.Time
+	switch {
+	case p.date != nil && p.hasTime:
+		due = time.Date(p.date.Year(), p.date.Month(), p.date.Day(), p.hour, p.minute, 0, 0, p.loc)
+	case p.date != nil:
+		due = time.Date(p.date.Year(), p.date.Month(), p.date.Day(), 23, 59, 59, 0, p.loc)
+	case p.hasTime:
+		due = time.Date(p.now.Year(), p.now.Month(), p.now.Day(), p.hour, p.minute, 0, 0, p.loc)
+		if due.Before(p.now) {
+			due = due.AddDate(0, 0, 1)
+		}
+	default:
+		due = time.Date(p.now.Year(), p.now.Month(), p.now.Day(), 23, 59, 59, 0, p.loc)
+	}
+
+	// The first occurrence of a series starts on or after the parsed date
+	if p.rule != nil {
+		due = p.rule.First(due)
+	}
+
+	return &due
+}
@@ -0,0 +1,288 @@
+package quickadd
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/todo/services/task-service/internal/models"
+)
+
+func loadLocation(t *testing.T, name string) *time.Location {
+	t.Helper()
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		t.Fatalf("load location %s: %v", name, err)
+	}
+	return loc
+}
+
+func TestParse(t *testing.T) {
+	loc := loadLocation(t, "America/New_York")
+	// A Wednesday morning
+	now := time.Date(2024, time.March, 13, 10, 0, 0, 0, loc)
+
+	tests := []struct {
+		name       string
+		text       string
+		title      string
+		due        string // in loc; empty for no due date
+		hasTime    bool
+		recurrence string
+		priority   models.TaskPriority
+		tags       []string
+	}{
+		{
+			name:       "request example",
+			text:       "pay rent every 1st of the month at 9am !high #home",
+			title:      "pay rent",
+			due:        "2024-04-01 09:00:00",
+			hasTime:    true,
+			recurrence: "FREQ=MONTHLY;BYMONTHDAY=1",
+			priority:   models.PriorityHigh,
+			tags:       []string{"home"},
+		},
+		{
+			name:     "no markers",
+			text:     "buy milk",
+			title:    "buy milk",
+			priority: models.PriorityMedium,
+			tags:     []string{},
+		},
+		{
+			name:     "numbers that are not times",
+			text:     "read chapter 12 of 30",
+			title:    "read chapter 12 of 30",
+			priority: models.PriorityMedium,
+			tags:     []string{},
+		},
+		{
+			name:     "hash inside a word is not a tag",
+			text:     "fix issue#42",
+			title:    "fix issue#42",
+			priority: models.PriorityMedium,
+			tags:     []string{},
+		},
+		{
+			name:     "weekday later this week",
+			text:     "call mom friday",
+			title:    "call mom",
+			due:      "2024-03-15 23:59:59",
+			priority: models.PriorityMedium,
+			tags:     []string{},
+		},
+		{
+			name:     "same weekday means next week",
+			text:     "team lunch on wednesday",
+			title:    "team lunch",
+			due:      "2024-03-20 23:59:59",
+			priority: models.PriorityMedium,
+			tags:     []string{},
+		},
+		{
+			name:     "abbreviated weekday with time",
+			text:     "dentist next tue at 3:30pm",
+			title:    "dentist",
+			due:      "2024-03-19 15:30:00",
+			hasTime:  true,
+			priority: models.PriorityMedium,
+			tags:     []string{},
+		},
+		{
+			name:     "tomorrow with 24-hour time",
+			text:     "standup tomorrow at 14:30",
+			title:    "standup",
+			due:      "2024-03-14 14:30:00",
+			hasTime:  true,
+			priority: models.PriorityMedium,
+			tags:     []string{},
+		},
+		{
+			name:     "tonight defaults to the evening",
+			text:     "take out trash tonight",
+			title:    "take out trash",
+			due:      "2024-03-13 20:00:00",
+			hasTime:  true,
+			priority: models.PriorityMedium,
+			tags:     []string{},
+		},
+		{
+			name:     "time still ahead today",
+			text:     "lunch at noon",
+			title:    "lunch",
+			due:      "2024-03-13 12:00:00",
+			hasTime:  true,
+			priority: models.PriorityMedium,
+			tags:     []string{},
+		},
+		{
+			name:     "time already past today",
+			text:     "coffee @ 8am",
+			title:    "coffee",
+			due:      "2024-03-14 08:00:00",
+			hasTime:  true,
+			priority: models.PriorityMedium,
+			tags:     []string{},
+		},
+		{
+			name:     "duration in hours",
+			text:     "check oven in 2 hours",
+			title:    "check oven",
+			due:      "2024-03-13 12:00:00",
+			hasTime:  true,
+			priority: models.PriorityMedium,
+			tags:     []string{},
+		},
+		{
+			name:     "duration in days",
+			text:     "follow up in three days",
+			title:    "follow up",
+			due:      "2024-03-16 23:59:59",
+			priority: models.PriorityMedium,
+			tags:     []string{},
+		},
+		{
+			name:     "month and day",
+			text:     "renew passport by Jan 5th",
+			title:    "renew passport",
+			due:      "2025-01-05 23:59:59",
+			priority: models.PriorityMedium,
+			tags:     []string{},
+		},
+		{
+			name:     "iso date",
+			text:     "file taxes 2024-04-15 9:00",
+			title:    "file taxes",
+			due:      "2024-04-15 09:00:00",
+			hasTime:  true,
+			priority: models.PriorityMedium,
+			tags:     []string{},
+		},
+		{
+			name:       "weekday recurrence",
+			text:       "gym every mon, wed and fri at 7am",
+			title:      "gym",
+			due:        "2024-03-15 07:00:00",
+			hasTime:    true,
+			recurrence: "FREQ=WEEKLY;BYDAY=MO,WE,FR",
+			priority:   models.PriorityMedium,
+			tags:       []string{},
+		},
+		{
+			name:       "interval recurrence",
+			text:       "water plants every other day",
+			title:      "water plants",
+			due:        "2024-03-13 23:59:59",
+			recurrence: "FREQ=DAILY;INTERVAL=2",
+			priority:   models.PriorityMedium,
+			tags:       []string{},
+		},
+		{
+			name:     "tags are normalized and deduplicated",
+			text:     "plan trip #Travel #family #travel",
+			title:    "plan trip",
+			priority: models.PriorityMedium,
+			tags:     []string{"travel", "family"},
+		},
+		{
+			name:     "numeric priority",
+			text:     "ship release !1",
+			title:    "ship release",
+			priority: models.PriorityUrgent,
+			tags:     []string{},
+		},
+		{
+			name:     "named priority in any case",
+			text:     "!LOW tidy desk",
+			title:    "tidy desk",
+			priority: models.PriorityLow,
+			tags:     []string{},
+		},
+		{
+			name:     "unknown priority stays in the title",
+			text:     "celebrate !yay",
+			title:    "celebrate !yay",
+			priority: models.PriorityMedium,
+			tags:     []string{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := Parse(tt.text, now, loc)
+			if err != nil {
+				t.Fatalf("Parse(%q) returned error: %v", tt.text, err)
+			}
+
+			if result.Title != tt.title {
+				t.Errorf("title = %q, want %q", result.Title, tt.title)
+			}
+			if result.HasTime != tt.hasTime {
+				t.Errorf("has_time = %v, want %v", result.HasTime, tt.hasTime)
+			}
+			if result.Recurrence != tt.recurrence {
+				t.Errorf("recurrence = %q, want %q", result.Recurrence, tt.recurrence)
+			}
+			if result.Priority != tt.priority {
+				t.Errorf("priority = %q, want %q", result.Priority, tt.priority)
+			}
+			if !reflect.DeepEqual(result.Tags, tt.tags) {
+				t.Errorf("tags = %q, want %q", result.Tags, tt.tags)
+			}
+
+			switch {
+			case tt.due == "" && result.DueDate != nil:
+				t.Errorf("due = %v, want none", result.DueDate)
+			case tt.due != "" && result.DueDate == nil:
+				t.Errorf("due = none, want %s", tt.due)
+			case tt.due != "":
+				want, err := time.ParseInLocation(time.DateTime, tt.due, loc)
+				if err != nil {
+					t.Fatalf("bad expected due date %q: %v", tt.due, err)
+				}
+				if !result.DueDate.Equal(want) {
+					t.Errorf("due = %v, want %v", result.DueDate.In(loc), want)
+				}
+			}
+		})
+	}
+}
+
+func TestParseUsesTimezone(t *testing.T) {
+	tokyo := loadLocation(t, "Asia/Tokyo")
+	// Still Wednesday in UTC, already Thursday in Tokyo
+	now := time.Date(2024, time.March, 13, 20, 0, 0, 0, time.UTC)
+
+	result, err := Parse("review tomorrow at 9am", now, tokyo)
+	if err != nil {
+		t.Fatalf("Parse returned error: %v", err)
+	}
+
+	want := time.Date(2024, time.March, 15, 9, 0, 0, 0, tokyo)
+	if result.DueDate == nil || !result.DueDate.Equal(want) {
+		t.Errorf("due = %v, want %v", result.DueDate, want)
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	loc := loadLocation(t, "America/New_York")
+	now := time.Date(2024, time.March, 13, 10, 0, 0, 0, loc)
+
+	tests := []struct {
+		name string
+		text string
+	}{
+		{name: "only markers", text: "#home !high tomorrow"},
+		{name: "empty", text: "   "},
+		{name: "date that does not exist", text: "party on Feb 30"},
+		{name: "hour out of range", text: "wake up at 13pm"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if result, err := Parse(tt.text, now, loc); err == nil {
+				t.Errorf("Parse(%q) = %+v, want an error", tt.text, result)
+			}
+		})
+	}
+}
@@ -0,0 +1,57 @@
+package rank
+
+import "strings"
+
+// alphabet is in ASCII order so ranks compare correctly as plain strings
+// under the "C" collation.
+const alphabet = "0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz"
+
+const base = len(alphabet)
+
+func digit(s string, i int) int {
+	return strings.IndexByte(alphabet, s[i])
+}
+
+// Between returns a rank that sorts strictly after lower and before upper.
+// An empty lower means the start of the list and an empty upper its end.
+// It reports false when no rank fits, in which case the list needs to be
+// renumbered. Generated ranks never end in the lowest digit, so there is
+// always room before them.
+func Between(lower, upper string) (string, bool) {
+	upper = strings.TrimRight(upper, alphabet[:1])
+	if upper != "" && lower >= upper {
+		return "", false
+	}
+
+	var result []byte
+	bounded := upper != ""
+
+	for i := 0; ; i++ {
+		lo := 0
+		if i < len(lower) {
+			lo = digit(lower, i)
+		}
+
+		hi := base
+		if bounded {
+			hi = digit(upper, i)
+		}
+
+		switch {
+		case hi-lo > 1:
+			return string(append(result, alphabet[(lo+hi)/2])), true
+		case hi-lo == 1:
+			// Keep lower's digit; everything after it is already below upper
+			result = append(result, alphabet[lo])
+			bounded = false
+		default:
+			result = append(result, alphabet[lo])
+		}
+	}
+}
+
+// After returns a rank that sorts after lower.
+func After(lower string) string {
+	r, _ := Between(lower, "")
+	return r
+}
@@ -0,0 +1,123 @@
+package rank
+
+import (
+	"strings"
+	"testing"
+)
+
+// checkBetween fails unless r sorts strictly between lower and upper and
+// leaves room before it.
+func checkBetween(t *testing.T, lower, upper, r string) {
+	t.Helper()
+	if r <= lower {
+		t.Errorf("Between(%q, %q) = %q, not after lower", lower, upper, r)
+	}
+	if upper != "" && r >= upper {
+		t.Errorf("Between(%q, %q) = %q, not before upper", lower, upper, r)
+	}
+	if strings.HasSuffix(r, alphabet[:1]) {
+		t.Errorf("Between(%q, %q) = %q, ends in the lowest digit", lower, upper, r)
+	}
+}
+
+func TestBetween(t *testing.T) {
+	tests := []struct {
+		name  string
+		lower string
+		upper string
+		want  string
+	}{
+		{name: "empty list", lower: "", upper: "", want: "V"},
+		{name: "start of list", lower: "", upper: "V", want: "F"},
+		{name: "end of list", lower: "V", upper: "", want: "k"},
+		{name: "room between", lower: "A", upper: "C", want: "B"},
+		{name: "adjacent keys", lower: "a", upper: "b", want: "aV"},
+		{name: "adjacent keys at the start", lower: "", upper: "1", want: "0V"},
+		{name: "adjacent multi-digit keys", lower: "a1", upper: "a2", want: "a1V"},
+		{name: "lower is a prefix of upper", lower: "a", upper: "aV", want: "aF"},
+		{name: "upper is a prefix of lower", lower: "aV", upper: "b", want: "ak"},
+		{name: "lower at the top digit", lower: "z", upper: "", want: "zV"},
+		{name: "trailing lowest digits in upper are ignored", lower: "a", upper: "b00", want: "aV"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := Between(tt.lower, tt.upper)
+			if !ok {
+				t.Fatalf("Between(%q, %q) found no room", tt.lower, tt.upper)
+			}
+			if got != tt.want {
+				t.Errorf("Between(%q, %q) = %q, want %q", tt.lower, tt.upper, got, tt.want)
+			}
+			checkBetween(t, tt.lower, tt.upper, got)
+		})
+	}
+}
+
+func TestBetweenNoRoom(t *testing.T) {
+	tests := []struct {
+		name  string
+		lower string
+		upper string
+	}{
+		{name: "equal keys", lower: "a", upper: "a"},
+		{name: "bounds reversed", lower: "b", upper: "a"},
+		{name: "upper only adds lowest digits", lower: "a", upper: "a0"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got, ok := Between(tt.lower, tt.upper); ok {
+				t.Errorf("Between(%q, %q) = %q, want no room", tt.lower, tt.upper, got)
+			}
+		})
+	}
+}
+
+func TestBetweenRepeatedInserts(t *testing.T) {
+	tests := []struct {
+		name  string
+		lower string
+		upper string
+		// newAbove places each task above the previous one rather than
+		// below it
+		newAbove bool
+	}{
+		{name: "each right after the same task", lower: "a", upper: "b", newAbove: true},
+		{name: "each right before the same task", lower: "a", upper: "b"},
+		{name: "each at the start of the list", lower: "", upper: "V", newAbove: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			lower, upper := tt.lower, tt.upper
+			for i := 0; i < 1000; i++ {
+				r, ok := Between(lower, upper)
+				if !ok {
+					t.Fatalf("insert %d: Between(%q, %q) found no room", i, lower, upper)
+				}
+				checkBetween(t, lower, upper, r)
+				if t.Failed() {
+					t.FailNow()
+				}
+
+				if tt.newAbove {
+					upper = r
+				} else {
+					lower = r
+				}
+			}
+		})
+	}
+}
+
+func TestAfter(t *testing.T) {
+	prev := ""
+	for i := 0; i < 200; i++ {
+		r := After(prev)
+		if r <= prev {
+			t.Fatalf("After(%q) = %q, not after it", prev, r)
+		}
+		prev = r
+	}
+}
@@ -0,0 +1,204 @@
+package recurrence
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type Frequency string
+
+const (
+	Daily   Frequency = "DAILY"
+	Weekly  Frequency = "WEEKLY"
+	Monthly Frequency = "MONTHLY"
+	Yearly  Frequency = "YEARLY"
+)
+
+// Rule is the subset of an iCalendar RRULE that tasks support, e.g.
+// "FREQ=WEEKLY;INTERVAL=2;BYDAY=MO,TH" or "FREQ=MONTHLY;BYMONTHDAY=1".
+type Rule struct {
+	Freq       Frequency
+	Interval   int
+	ByDay      []time.Weekday
+	ByMonthDay int
+}
+
+var weekdayCodes = map[string]time.Weekday{
+	"SU": time.Sunday,
+	"MO": time.Monday,
+	"TU": time.Tuesday,
+	"WE": time.Wednesday,
+	"TH": time.Thursday,
+	"FR": time.Friday,
+	"SA": time.Saturday,
+}
+
+var weekdayNames = [...]string{"SU", "MO", "TU", "WE", "TH", "FR", "SA"}
+
+func Parse(value string) (*Rule, error) {
+	rule := &Rule{Interval: 1}
+
+	for _, part := range strings.Split(strings.TrimPrefix(strings.ToUpper(value), "RRULE:"), ";") {
+		if part == "" {
+			continue
+		}
+
+		key, val, ok := strings.Cut(part, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid recurrence part: %s", part)
+		}
+
+		switch key {
+		case "FREQ":
+			switch Frequency(val) {
+			case Daily, Weekly, Monthly, Yearly:
+				rule.Freq = Frequency(val)
+			default:
+				return nil, fmt.Errorf("unsupported recurrence frequency: %s", val)
+			}
+		case "INTERVAL":
+			interval, err := strconv.Atoi(val)
+			if err != nil || interval < 1 {
+				return nil, fmt.Errorf("invalid recurrence interval: %s", val)
+			}
+			rule.Interval = interval
+		case "BYDAY":
+			for _, code := range strings.Split(val, ",") {
+				day, ok := weekdayCodes[code]
+				if !ok {
+					return nil, fmt.Errorf("invalid recurrence day: %s", code)
+				}
+				rule.ByDay = append(rule.ByDay, day)
+			}
+		case "BYMONTHDAY":
+			day, err := strconv.Atoi(val)
+			if err != nil || day < 1 || day > 31 {
+				return nil, fmt.Errorf("invalid recurrence month day: %s", val)
+			}
+			rule.ByMonthDay = day
+		default:
+			return nil, fmt.Errorf("unsupported recurrence part: %s", key)
+		}
+	}
+
+	if rule.Freq == "" {
+		return nil, fmt.Errorf("recurrence requires FREQ")
+	}
+
+	return rule, nil
+}
+
+func (r *Rule) String() string {
+	parts := []string{"FREQ=" + string(r.Freq)}
+
+	if r.Interval > 1 {
+		parts = append(parts, "INTERVAL="+strconv.Itoa(r.Interval))
+	}
+
+	if len(r.ByDay) > 0 {
+		codes := make([]string, len(r.ByDay))
+		for i, day := range r.ByDay {
+			codes[i] = weekdayNames[day]
+		}
+		parts = append(parts, "BYDAY="+strings.Join(codes, ","))
+	}
+
+	if r.ByMonthDay > 0 {
+		parts = append(parts, "BYMONTHDAY="+strconv.Itoa(r.ByMonthDay))
+	}
+
+	return strings.Join(parts, ";")
+}
+
+// Next returns the first occurrence strictly after prev, keeping prev's
+// time of day and location.
+func (r *Rule) Next(prev time.Time) time.Time {
+	interval := r.Interval
+	if interval < 1 {
+		interval = 1
+	}
+
+	switch r.Freq {
+	case Daily:
+		return prev.AddDate(0, 0, interval)
+	case Weekly:
+		if len(r.ByDay) == 0 {
+			return prev.AddDate(0, 0, 7*interval)
+		}
+		for day := prev.AddDate(0, 0, 1); ; day = day.AddDate(0, 0, 1) {
+			weeks := int(weekStart(day).Sub(weekStart(prev)).Hours()/24) / 7
+			if weeks%interval == 0 && r.hasDay(day.Weekday()) {
+				return day
+			}
+		}
+	case Monthly:
+		dayOfMonth := r.ByMonthDay
+		if dayOfMonth == 0 {
+			dayOfMonth = prev.Day()
+		}
+		// Monthly rules on the given day that land before prev's day in the
+		// same month still belong to this month
+		if r.ByMonthDay > 0 && clampDay(prev.Year(), prev.Month(), dayOfMonth) > prev.Day() {
+			return onDay(prev, prev.Year(), prev.Month(), dayOfMonth)
+		}
+		month := time.Date(prev.Year(), prev.Month()+time.Month(interval), 1, 0, 0, 0, 0, prev.Location())
+		return onDay(prev, month.Year(), month.Month(), dayOfMonth)
+	case Yearly:
+		return onDay(prev, prev.Year()+interval, prev.Month(), prev.Day())
+	}
+
+	return prev
+}
+
+// First returns the earliest occurrence at or after start, keeping start's
+// time of day.
+func (r *Rule) First(start time.Time) time.Time {
+	for day, i := start, 0; i < 366; day, i = day.AddDate(0, 0, 1), i+1 {
+		if r.matches(day) {
+			return day
+		}
+	}
+	return start
+}
+
+func (r *Rule) matches(t time.Time) bool {
+	switch r.Freq {
+	case Weekly:
+		return len(r.ByDay) == 0 || r.hasDay(t.Weekday())
+	case Monthly:
+		return r.ByMonthDay == 0 || t.Day() == clampDay(t.Year(), t.Month(), r.ByMonthDay)
+	default:
+		return true
+	}
+}
+
+func (r *Rule) hasDay(day time.Weekday) bool {
+	for _, d := range r.ByDay {
+		if d == day {
+			return true
+		}
+	}
+	return false
+}
+
+// weekStart returns midnight of the Monday starting t's week.
+func weekStart(t time.Time) time.Time {
+	offset := (int(t.Weekday()) + 6) % 7
+	return time.Date(t.Year(), t.Month(), t.Day()-offset, 0, 0, 0, 0, t.Location())
+}
+
+// clampDay caps day to the length of the month, so the 31st means the last
+// day in shorter months.
+func clampDay(year int, month time.Month, day int) int {
+	last := time.Date(year, month+1, 0, 0, 0, 0, 0, time.UTC).Day()
+	if day > last {
+		return last
+	}
+	return day
+}
+
+func onDay(clock time.Time, year int, month time.Month, day int) time.Time {
+	return time.Date(year, month, clampDay(year, month, day), clock.Hour(), clock.Minute(), clock.Second(), 0, clock.Location())
+}
@@ -0,0 +1,160 @@
+package recurrence
+
+import (
+	"testing"
+	"time"
+)
+
+const layout = "2006-01-02 15:04:05 MST"
+
+func mustParse(t *testing.T, value string) *Rule {
+	t.Helper()
+	rule, err := Parse(value)
+	if err != nil {
+		t.Fatalf("Parse(%q): %v", value, err)
+	}
+	return rule
+}
+
+func mustTime(t *testing.T, value string, loc *time.Location) time.Time {
+	t.Helper()
+	parsed, err := time.ParseInLocation("2006-01-02 15:04:05", value, loc)
+	if err != nil {
+		t.Fatalf("bad time %q: %v", value, err)
+	}
+	return parsed
+}
+
+func TestNextMonthEndAndLeapDay(t *testing.T) {
+	tests := []struct {
+		name string
+		rule string
+		prev string
+		want string
+	}{
+		{name: "daily into a leap day", rule: "FREQ=DAILY", prev: "2024-02-28 09:00:00", want: "2024-02-29 09:00:00"},
+		{name: "daily across the year end", rule: "FREQ=DAILY", prev: "2024-12-31 09:00:00", want: "2025-01-01 09:00:00"},
+		{name: "monthly from the 31st into a leap February", rule: "FREQ=MONTHLY", prev: "2024-01-31 09:00:00", want: "2024-02-29 09:00:00"},
+		{name: "monthly from the 31st into a common February", rule: "FREQ=MONTHLY", prev: "2023-01-31 09:00:00", want: "2023-02-28 09:00:00"},
+		{name: "monthly from the 31st into a 30-day month", rule: "FREQ=MONTHLY", prev: "2024-03-31 09:00:00", want: "2024-04-30 09:00:00"},
+		{name: "monthly across the year end", rule: "FREQ=MONTHLY", prev: "2024-12-15 09:00:00", want: "2025-01-15 09:00:00"},
+		{name: "every third month onto a leap day", rule: "FREQ=MONTHLY;INTERVAL=3", prev: "2023-11-30 09:00:00", want: "2024-02-29 09:00:00"},
+		{name: "month day 31 clamps to a leap February", rule: "FREQ=MONTHLY;BYMONTHDAY=31", prev: "2024-01-31 09:00:00", want: "2024-02-29 09:00:00"},
+		{name: "month day 31 returns after February", rule: "FREQ=MONTHLY;BYMONTHDAY=31", prev: "2024-02-29 09:00:00", want: "2024-03-31 09:00:00"},
+		{name: "month day 31 returns after a 30-day month", rule: "FREQ=MONTHLY;BYMONTHDAY=31", prev: "2024-04-30 09:00:00", want: "2024-05-31 09:00:00"},
+		{name: "month day 30 clamps to a common February", rule: "FREQ=MONTHLY;BYMONTHDAY=30", prev: "2023-01-30 09:00:00", want: "2023-02-28 09:00:00"},
+		{name: "month day later in the same month", rule: "FREQ=MONTHLY;BYMONTHDAY=15", prev: "2024-03-10 09:00:00", want: "2024-03-15 09:00:00"},
+		{name: "month day earlier than prev", rule: "FREQ=MONTHLY;BYMONTHDAY=1", prev: "2024-03-10 09:00:00", want: "2024-04-01 09:00:00"},
+		{name: "yearly from a leap day", rule: "FREQ=YEARLY", prev: "2024-02-29 09:00:00", want: "2025-02-28 09:00:00"},
+		{name: "every four years from a leap day", rule: "FREQ=YEARLY;INTERVAL=4", prev: "2024-02-29 09:00:00", want: "2028-02-29 09:00:00"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rule := mustParse(t, tt.rule)
+			prev := mustTime(t, tt.prev, time.UTC)
+			want := mustTime(t, tt.want, time.UTC)
+
+			if got := rule.Next(prev); !got.Equal(want) {
+				t.Errorf("Next(%s) = %s, want %s", prev.Format(layout), got.Format(layout), want.Format(layout))
+			}
+		})
+	}
+}
+
+func TestNextWeekly(t *testing.T) {
+	tests := []struct {
+		name string
+		rule string
+		prev string
+		want string
+	}{
+		{name: "plain weekly", rule: "FREQ=WEEKLY", prev: "2024-03-13 09:00:00", want: "2024-03-20 09:00:00"},
+		{name: "next listed day this week", rule: "FREQ=WEEKLY;BYDAY=MO,WE,FR", prev: "2024-03-13 09:00:00", want: "2024-03-15 09:00:00"},
+		{name: "listed day wraps to next week", rule: "FREQ=WEEKLY;BYDAY=MO,WE,FR", prev: "2024-03-15 09:00:00", want: "2024-03-18 09:00:00"},
+		{name: "fortnightly skips a week", rule: "FREQ=WEEKLY;INTERVAL=2;BYDAY=MO", prev: "2024-03-11 09:00:00", want: "2024-03-25 09:00:00"},
+		{name: "fortnightly stays in its week", rule: "FREQ=WEEKLY;INTERVAL=2;BYDAY=MO,FR", prev: "2024-03-11 09:00:00", want: "2024-03-15 09:00:00"},
+		{name: "sunday ends the week", rule: "FREQ=WEEKLY;INTERVAL=2;BYDAY=MO,SU", prev: "2024-03-11 09:00:00", want: "2024-03-17 09:00:00"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rule := mustParse(t, tt.rule)
+			prev := mustTime(t, tt.prev, time.UTC)
+			want := mustTime(t, tt.want, time.UTC)
+
+			if got := rule.Next(prev); !got.Equal(want) {
+				t.Errorf("Next(%s) = %s, want %s", prev.Format(layout), got.Format(layout), want.Format(layout))
+			}
+		})
+	}
+}
+
+func TestNextAcrossDST(t *testing.T) {
+	loc, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Fatalf("load location: %v", err)
+	}
+
+	// Clocks went forward on 2024-03-10 and back on 2024-11-03
+	tests := []struct {
+		name    string
+		rule    string
+		prev    string
+		want    string
+		elapsed time.Duration
+	}{
+		{name: "daily into summer time", rule: "FREQ=DAILY", prev: "2024-03-09 09:00:00", want: "2024-03-10 09:00:00", elapsed: 23 * time.Hour},
+		{name: "daily out of summer time", rule: "FREQ=DAILY", prev: "2024-11-02 09:00:00", want: "2024-11-03 09:00:00", elapsed: 25 * time.Hour},
+		{name: "weekly into summer time", rule: "FREQ=WEEKLY", prev: "2024-03-06 18:30:00", want: "2024-03-13 18:30:00", elapsed: 7*24*time.Hour - time.Hour},
+		{name: "weekday list out of summer time", rule: "FREQ=WEEKLY;BYDAY=TU,SA", prev: "2024-11-02 07:00:00", want: "2024-11-05 07:00:00", elapsed: 3*24*time.Hour + time.Hour},
+		{name: "all-day monthly into summer time", rule: "FREQ=MONTHLY", prev: "2024-02-10 23:59:59", want: "2024-03-10 23:59:59", elapsed: 29*24*time.Hour - time.Hour},
+		{name: "all-day yearly keeps its wall clock", rule: "FREQ=YEARLY", prev: "2024-01-15 23:59:59", want: "2025-01-15 23:59:59", elapsed: 366 * 24 * time.Hour},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rule := mustParse(t, tt.rule)
+			prev := mustTime(t, tt.prev, loc)
+			want := mustTime(t, tt.want, loc)
+
+			got := rule.Next(prev)
+			if !got.Equal(want) {
+				t.Errorf("Next(%s) = %s, want %s", prev.Format(layout), got.Format(layout), want.Format(layout))
+			}
+			if got.Location() != loc {
+				t.Errorf("Next(%s) is in %s, want %s", prev.Format(layout), got.Location(), loc)
+			}
+			if elapsed := got.Sub(prev); elapsed != tt.elapsed {
+				t.Errorf("Next(%s) is %s later, want %s", prev.Format(layout), elapsed, tt.elapsed)
+			}
+		})
+	}
+}
+
+func TestFirst(t *testing.T) {
+	tests := []struct {
+		name  string
+		rule  string
+		start string
+		want  string
+	}{
+		{name: "daily starts at once", rule: "FREQ=DAILY", start: "2024-03-13 09:00:00", want: "2024-03-13 09:00:00"},
+		{name: "start on a listed day", rule: "FREQ=WEEKLY;BYDAY=WE", start: "2024-03-13 09:00:00", want: "2024-03-13 09:00:00"},
+		{name: "next listed day", rule: "FREQ=WEEKLY;BYDAY=SA", start: "2024-03-13 09:00:00", want: "2024-03-16 09:00:00"},
+		{name: "month day 31 in a leap February", rule: "FREQ=MONTHLY;BYMONTHDAY=31", start: "2024-02-10 09:00:00", want: "2024-02-29 09:00:00"},
+		{name: "month day already passed", rule: "FREQ=MONTHLY;BYMONTHDAY=1", start: "2024-12-02 09:00:00", want: "2025-01-01 09:00:00"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rule := mustParse(t, tt.rule)
+			start := mustTime(t, tt.start, time.UTC)
+			want := mustTime(t, tt.want, time.UTC)
+
+			if got := rule.First(start); !got.Equal(want) {
+				t.Errorf("First(%s) = %s, want %s", start.Format(layout), got.Format(layout), want.Format(layout))
+			}
+		})
+	}
+}
@@ -0,0 +1,200 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/todo/services/task-service/internal/models"
+)
+
+// archived_tasks mirrors tasks; archived_at is only ever set there, so tasks
+// only holds live tasks. all_tasks spans both tables for reads that include
+// archived tasks.
+//
+// The tables that hang off a task keep their rows while it is archived, so
+// they can't reference tasks with a foreign key. Triggers stand in for one:
+// rows may only name a task in either table, and deleting a task deletes
+// them unless it is just moving to the other table. Columns added to tasks
+// later are added to archived_tasks as well.
+//
+// Earlier versions kept archived tasks in tasks with foreign keys; those
+// tasks are moved out once the keys are gone.
+const archiveSchema = `
+	ALTER TABLE tasks ADD COLUMN IF NOT EXISTS archived_at TIMESTAMP;
+	DROP INDEX IF EXISTS idx_tasks_archived_at;
+	CREATE TABLE IF NOT EXISTS archived_tasks (LIKE tasks INCLUDING DEFAULTS INCLUDING INDEXES);
+	CREATE INDEX IF NOT EXISTS idx_archived_tasks_archived_at ON archived_tasks(user_id, archived_at);
+
+	DO $$
+	DECLARE
+		col RECORD;
+	BEGIN
+		FOR col IN
+			SELECT a.attname, format_type(a.atttypid, a.atttypmod) AS type FROM pg_attribute a
+			WHERE a.attrelid = 'tasks'::regclass AND a.attnum > 0 AND NOT a.attisdropped
+			AND NOT EXISTS (
+				SELECT 1 FROM pg_attribute b
+				WHERE b.attrelid = 'archived_tasks'::regclass AND b.attname = a.attname AND NOT b.attisdropped
+			)
+		LOOP
+			EXECUTE format('ALTER TABLE archived_tasks ADD COLUMN %I %s', col.attname, col.type);
+		END LOOP;
+	END $$;
+
+	DROP VIEW IF EXISTS all_tasks;
+	CREATE VIEW all_tasks AS
+		SELECT ` + taskColumns + ` FROM tasks
+		UNION ALL
+		SELECT ` + taskColumns + ` FROM archived_tasks;
+
+	CREATE OR REPLACE FUNCTION check_task_reference() RETURNS trigger AS $fn$
+	BEGIN
+		PERFORM 1 FROM tasks WHERE id = NEW.task_id FOR KEY SHARE;
+		IF NOT FOUND THEN
+			PERFORM 1 FROM archived_tasks WHERE id = NEW.task_id FOR KEY SHARE;
+		END IF;
+		IF NOT FOUND THEN
+			RAISE EXCEPTION 'task % not found', NEW.task_id USING ERRCODE = 'foreign_key_violation';
+		END IF;
+		RETURN NEW;
+	END $fn$ LANGUAGE plpgsql;
+
+	CREATE OR REPLACE FUNCTION delete_task_data() RETURNS trigger AS $fn$
+	DECLARE
+		child TEXT;
+	BEGIN
+		IF EXISTS (SELECT 1 FROM all_tasks WHERE id = OLD.id) THEN
+			RETURN OLD;
+		END IF;
+		FOREACH child IN ARRAY TG_ARGV LOOP
+			EXECUTE format('DELETE FROM %I WHERE task_id = $1', child) USING OLD.id;
+		END LOOP;
+		RETURN OLD;
+	END $fn$ LANGUAGE plpgsql;
+
+	DO $$
+	DECLARE
+		children TEXT[] := ARRAY['time_entries', 'checklist_items', 'task_escalations', 'task_watchers', 'task_comments', 'automation_runs'];
+		child TEXT;
+		parent TEXT;
+	BEGIN
+		FOREACH child IN ARRAY children LOOP
+			EXECUTE format('ALTER TABLE %I DROP CONSTRAINT IF EXISTS %I', child, child || '_task_id_fkey');
+			EXECUTE format('DROP TRIGGER IF EXISTS check_task_reference ON %I', child);
+			EXECUTE format('CREATE TRIGGER check_task_reference BEFORE INSERT OR UPDATE OF task_id ON %I FOR EACH ROW EXECUTE FUNCTION check_task_reference()', child);
+		END LOOP;
+		FOREACH parent IN ARRAY ARRAY['tasks', 'archived_tasks'] LOOP
+			EXECUTE format('DROP TRIGGER IF EXISTS delete_task_data ON %I', parent);
+			EXECUTE format('CREATE TRIGGER delete_task_data AFTER DELETE ON %I FOR EACH ROW EXECUTE FUNCTION delete_task_data(%s)', parent, array_to_string(children, ', '));
+		END LOOP;
+	END $$;
+
+	INSERT INTO archived_tasks (` + taskColumns + `)
+	SELECT ` + taskColumns + ` FROM tasks WHERE archived_at IS NOT NULL
+	ON CONFLICT (id) DO NOTHING;
+	DELETE FROM tasks WHERE archived_at IS NOT NULL;
+`
+
+// ArchiveCompletedTasks moves up to limit tasks completed before cutoff into
+// archived_tasks and returns how many it moved.
+func (r *PostgresRepository) ArchiveCompletedTasks(cutoff time.Time, limit int) (int, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	// SKIP LOCKED lets several replicas archive without contending
+	rows, err := tx.Query(
+		"UPDATE tasks SET archived_at = $1 WHERE id IN ("+
+			"SELECT id FROM tasks WHERE status = $2 AND completed_at < $3 ORDER BY completed_at LIMIT $4 FOR UPDATE SKIP LOCKED"+
+			") RETURNING id",
+		time.Now().UTC(), models.StatusCompleted, cutoff.UTC(), limit,
+	)
+	if err != nil {
+		return 0, err
+	}
+
+	var ids []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			rows.Close()
+			return 0, err
+		}
+		ids = append(ids, id)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, err
+	}
+	if len(ids) == 0 {
+		return 0, nil
+	}
+
+	if err := moveTasks(tx, "tasks", "archived_tasks", ids); err != nil {
+		return 0, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, err
+	}
+
+	return len(ids), nil
+}
+
+// UnarchiveTask moves an archived task back into tasks, at the end of its
+// list and status column.
+func (r *PostgresRepository) UnarchiveTask(id string) (*models.Task, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	result, err := tx.Exec("UPDATE archived_tasks SET archived_at = NULL WHERE id = $1", id)
+	if err != nil {
+		return nil, err
+	}
+	if rows, err := result.RowsAffected(); err != nil {
+		return nil, err
+	} else if rows == 0 {
+		return nil, fmt.Errorf("archived task not found")
+	}
+
+	if err := moveTasks(tx, "archived_tasks", "tasks", []string{id}); err != nil {
+		return nil, err
+	}
+
+	task, err := getTask(tx, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := moveToListEnd(tx, task); err != nil {
+		return nil, err
+	}
+	if err := moveToColumnEnd(tx, task); err != nil {
+		return nil, err
+	}
+	if task, err = getTask(tx, id); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return task, nil
+}
+
+func moveTasks(tx *sql.Tx, from, to string, ids []string) error {
+	_, err := tx.Exec("INSERT INTO "+to+" ("+taskColumns+") SELECT "+taskColumns+" FROM "+from+" WHERE id = ANY($1)", pq.Array(ids))
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec("DELETE FROM "+from+" WHERE id = ANY($1)", pq.Array(ids))
+	return err
+}
@@ -0,0 +1,529 @@
+package repository
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/todo/pkg/events"
+	"github.com/todo/services/task-service/internal/models"
+)
+
+// due_date on a run records which due date a due_soon run was for, so the
+// rule fires again once the task is rescheduled.
+const automationsSchema = `
+	CREATE TABLE IF NOT EXISTS automation_rules (
+		id VARCHAR(36) PRIMARY KEY,
+		project_id VARCHAR(36) NOT NULL,
+		name VARCHAR(255) NOT NULL,
+		enabled BOOLEAN NOT NULL DEFAULT TRUE,
+		trigger VARCHAR(30) NOT NULL,
+		to_status VARCHAR(50) NOT NULL DEFAULT '',
+		tag VARCHAR(255) NOT NULL DEFAULT '',
+		due_within_hours INTEGER NOT NULL DEFAULT 0,
+		conditions JSONB NOT NULL DEFAULT '[]',
+		actions JSONB NOT NULL DEFAULT '[]',
+		created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
+		updated_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP
+	);
+	CREATE INDEX IF NOT EXISTS idx_automation_rules_project ON automation_rules(project_id, trigger);
+	ALTER TABLE automation_rules ADD COLUMN IF NOT EXISTS user_id VARCHAR(36) NOT NULL DEFAULT '';
+
+	CREATE TABLE IF NOT EXISTS automation_runs (
+		id VARCHAR(36) PRIMARY KEY,
+		rule_id VARCHAR(36) NOT NULL REFERENCES automation_rules(id) ON DELETE CASCADE,
+		task_id VARCHAR(36) NOT NULL,
+		trigger VARCHAR(30) NOT NULL,
+		status VARCHAR(20) NOT NULL,
+		error TEXT NOT NULL DEFAULT '',
+		depth INTEGER NOT NULL DEFAULT 0,
+		due_date TIMESTAMPTZ,
+		created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP
+	);
+	CREATE INDEX IF NOT EXISTS idx_automation_runs_rule ON automation_runs(rule_id, created_at);
+	CREATE INDEX IF NOT EXISTS idx_automation_runs_task ON automation_runs(task_id, rule_id);
+`
+
+const automationRuleColumns = "id, project_id, user_id, name, enabled, trigger, to_status, tag, due_within_hours, conditions, actions, created_at, updated_at"
+
+const automationRunColumns = "id, rule_id, task_id, trigger, status, error, depth, due_date, created_at"
+
+const maxAutomationRuns = 100
+
+// automationColumns maps the fields set_field can change to their columns.
+var automationColumns = map[string]string{
+	"status":      "status",
+	"priority":    "priority",
+	"title":       "title",
+	"description": "description",
+}
+
+func scanAutomationRule(row rowScanner) (*models.AutomationRule, error) {
+	rule := &models.AutomationRule{}
+	var conditions, actions []byte
+	err := row.Scan(&rule.ID, &rule.ProjectID, &rule.UserID, &rule.Name, &rule.Enabled, &rule.Trigger, &rule.ToStatus, &rule.Tag, &rule.DueWithinHours, &conditions, &actions, &rule.CreatedAt, &rule.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal(conditions, &rule.Conditions); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(actions, &rule.Actions); err != nil {
+		return nil, err
+	}
+
+	return rule, nil
+}
+
+func marshalAutomation(rule *models.AutomationRule) (conditions, actions []byte, err error) {
+	if rule.Conditions == nil {
+		rule.Conditions = []models.AutomationCondition{}
+	}
+	if conditions, err = json.Marshal(rule.Conditions); err != nil {
+		return nil, nil, err
+	}
+	if actions, err = json.Marshal(rule.Actions); err != nil {
+		return nil, nil, err
+	}
+	return conditions, actions, nil
+}
+
+func getAutomationRule(db queryRower, id string) (*models.AutomationRule, error) {
+	rule, err := scanAutomationRule(db.QueryRow("SELECT "+automationRuleColumns+" FROM automation_rules WHERE id = $1", id))
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("automation rule not found")
+	}
+	if err != nil {
+		return nil, err
+	}
+	return rule, nil
+}
+
+func (r *PostgresRepository) CreateAutomationRule(input *models.AutomationRule) (*models.AutomationRule, error) {
+	rule := *input
+	rule.ID = uuid.New().String()
+	rule.CreatedAt = time.Now()
+	rule.UpdatedAt = time.Now()
+
+	conditions, actions, err := marshalAutomation(&rule)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = r.db.Exec(
+		"INSERT INTO automation_rules ("+automationRuleColumns+") VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)",
+		rule.ID, rule.ProjectID, rule.UserID, rule.Name, rule.Enabled, rule.Trigger, rule.ToStatus, rule.Tag, rule.DueWithinHours, conditions, actions, rule.CreatedAt, rule.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &rule, nil
+}
+
+func (r *PostgresRepository) GetAutomationRule(id string) (*models.AutomationRule, error) {
+	return getAutomationRule(r.db, id)
+}
+
+// UpdateAutomationRule replaces a rule's definition. The project and owner
+// are fixed once created.
+func (r *PostgresRepository) UpdateAutomationRule(input *models.AutomationRule) (*models.AutomationRule, error) {
+	rule := *input
+	conditions, actions, err := marshalAutomation(&rule)
+	if err != nil {
+		return nil, err
+	}
+
+	updated, err := scanAutomationRule(r.db.QueryRow(
+		"UPDATE automation_rules SET name = $2, enabled = $3, trigger = $4, to_status = $5, tag = $6, due_within_hours = $7, conditions = $8, actions = $9, updated_at = $10 WHERE id = $1 RETURNING "+automationRuleColumns,
+		rule.ID, rule.Name, rule.Enabled, rule.Trigger, rule.ToStatus, rule.Tag, rule.DueWithinHours, conditions, actions, time.Now(),
+	))
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("automation rule not found")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return updated, nil
+}
+
+func (r *PostgresRepository) DeleteAutomationRule(id string) error {
+	result, err := r.db.Exec("DELETE FROM automation_rules WHERE id = $1", id)
+	if err != nil {
+		return err
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return fmt.Errorf("automation rule not found")
+	}
+
+	return nil
+}
+
+func (r *PostgresRepository) ListAutomationRules(projectID string) ([]*models.AutomationRule, error) {
+	rows, err := r.db.Query("SELECT "+automationRuleColumns+" FROM automation_rules WHERE project_id = $1 ORDER BY created_at", projectID)
+	if err != nil {
+		return nil, err
+	}
+	return scanAutomationRules(rows)
+}
+
+func scanAutomationRules(rows *sql.Rows) ([]*models.AutomationRule, error) {
+	defer rows.Close()
+
+	rules := []*models.AutomationRule{}
+	for rows.Next() {
+		rule, err := scanAutomationRule(rows)
+		if err != nil {
+			return nil, err
+		}
+		rules = append(rules, rule)
+	}
+
+	return rules, rows.Err()
+}
+
+// ListAutomationRuns returns a rule's most recent executions, newest first.
+func (r *PostgresRepository) ListAutomationRuns(ruleID string) ([]*models.AutomationRun, error) {
+	rows, err := r.db.Query(
+		"SELECT "+automationRunColumns+" FROM automation_runs WHERE rule_id = $1 ORDER BY created_at DESC LIMIT $2",
+		ruleID, maxAutomationRuns,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return scanAutomationRuns(rows)
+}
+
+func scanAutomationRuns(rows *sql.Rows) ([]*models.AutomationRun, error) {
+	defer rows.Close()
+
+	runs := []*models.AutomationRun{}
+	for rows.Next() {
+		run := &models.AutomationRun{}
+		if err := rows.Scan(&run.ID, &run.RuleID, &run.TaskID, &run.Trigger, &run.Status, &run.Error, &run.Depth, &run.DueDate, &run.CreatedAt); err != nil {
+			return nil, err
+		}
+		runs = append(runs, run)
+	}
+
+	return runs, rows.Err()
+}
+
+// RunDueSoonAutomations fires up to limit due_soon rules for their owners'
+// open tasks due within the rule's hours that the rule has not yet run for at their current
+// due date. It returns how many it evaluated.
+func (r *PostgresRepository) RunDueSoonAutomations(now time.Time, limit int) (int, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	// SKIP LOCKED lets several replicas run automations without firing twice
+	rows, err := tx.Query(
+		"SELECT r.id, t.id FROM automation_rules r JOIN tasks t ON t.project_id = r.project_id AND t.user_id = r.user_id"+
+			" WHERE r.enabled AND r.trigger = $1 AND t.status IN ($2, $3)"+
+			" AND t.due_date > $4 AND t.due_date <= $4 + r.due_within_hours * INTERVAL '1 hour'"+
+			" AND NOT EXISTS (SELECT 1 FROM automation_runs a WHERE a.rule_id = r.id AND a.task_id = t.id AND a.due_date = t.due_date)"+
+			" ORDER BY t.due_date, r.created_at LIMIT $5 FOR UPDATE OF t SKIP LOCKED",
+		models.TriggerDueSoon, models.StatusPending, models.StatusInProgress, now, limit,
+	)
+	if err != nil {
+		return 0, err
+	}
+
+	type pending struct{ ruleID, taskID string }
+	var due []pending
+	for rows.Next() {
+		var p pending
+		if err := rows.Scan(&p.ruleID, &p.taskID); err != nil {
+			rows.Close()
+			return 0, err
+		}
+		due = append(due, p)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, err
+	}
+
+	event := models.AutomationEvent{Trigger: models.TriggerDueSoon}
+	for _, p := range due {
+		rule, err := getAutomationRule(tx, p.ruleID)
+		if err != nil {
+			return 0, err
+		}
+		task, err := getTask(tx, p.taskID)
+		if err != nil {
+			return 0, err
+		}
+
+		// Record unmatched tasks too, or they would be selected again forever
+		if !rule.Matches(task, event) {
+			if err := logAutomationRun(tx, rule, task, event, models.RunSkipped, "conditions not met", 0); err != nil {
+				return 0, err
+			}
+			continue
+		}
+
+		if err := newAutomationChain().fire(tx, rule, task, event); err != nil {
+			return 0, err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, err
+	}
+
+	return len(due), nil
+}
+
+// automationChain follows the rules set off by one change, including those
+// triggered by other rules' actions. Each rule runs at most once per task in
+// a chain and chains stop at MaxAutomationDepth, so rules cannot loop.
+type automationChain struct {
+	depth int
+	fired map[string]bool
+}
+
+func newAutomationChain() *automationChain {
+	return &automationChain{fired: make(map[string]bool)}
+}
+
+// taskCreated fires task_created for a new task, and tag_added for each of
+// its tags.
+func (c *automationChain) taskCreated(tx *sql.Tx, task *models.Task) error {
+	if err := c.run(tx, task, models.AutomationEvent{Trigger: models.TriggerTaskCreated}); err != nil {
+		return err
+	}
+	return c.tagsAdded(tx, task, task.Tags)
+}
+
+// taskUpdated fires status_changed and tag_added for what an update changed.
+func (c *automationChain) taskUpdated(tx *sql.Tx, task *models.Task, previousStatus models.TaskStatus, previousTags []string) error {
+	added := models.AddedTags(previousTags, task.Tags)
+
+	if task.Status != previousStatus {
+		event := models.AutomationEvent{Trigger: models.TriggerStatusChanged, PreviousStatus: previousStatus}
+		if err := c.run(tx, task, event); err != nil {
+			return err
+		}
+	}
+	return c.tagsAdded(tx, task, added)
+}
+
+func (c *automationChain) tagsAdded(tx *sql.Tx, task *models.Task, tags []string) error {
+	for _, tag := range tags {
+		if err := c.run(tx, task, models.AutomationEvent{Trigger: models.TriggerTagAdded, Tag: tag}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// run fires the rules the task's owner has in its project that match the
+// event. Rules never act on other users' tasks. The task is refreshed in
+// place with whatever the rules changed.
+func (c *automationChain) run(tx *sql.Tx, task *models.Task, event models.AutomationEvent) error {
+	if task.ProjectID == "" {
+		return nil
+	}
+
+	rows, err := tx.Query(
+		"SELECT "+automationRuleColumns+" FROM automation_rules WHERE project_id = $1 AND user_id = $2 AND trigger = $3 AND enabled ORDER BY created_at",
+		task.ProjectID, task.UserID, event.Trigger,
+	)
+	if err != nil {
+		return err
+	}
+	rules, err := scanAutomationRules(rows)
+	if err != nil {
+		return err
+	}
+
+	for _, rule := range rules {
+		if !rule.Matches(task, event) {
+			continue
+		}
+		if err := c.fire(tx, rule, task, event); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// fire runs a rule's actions inside a savepoint, so a failing rule is rolled
+// back and logged without undoing the change that triggered it.
+func (c *automationChain) fire(tx *sql.Tx, rule *models.AutomationRule, task *models.Task, event models.AutomationEvent) error {
+	key := rule.ID + "/" + task.ID
+	if c.fired[key] {
+		return logAutomationRun(tx, rule, task, event, models.RunSkipped, "rule already ran for this task in the chain", c.depth)
+	}
+	if c.depth >= models.MaxAutomationDepth {
+		return logAutomationRun(tx, rule, task, event, models.RunSkipped, "automation chain is too deep", c.depth)
+	}
+	c.fired[key] = true
+
+	if _, err := tx.Exec("SAVEPOINT automation"); err != nil {
+		return err
+	}
+
+	c.depth++
+	err := c.apply(tx, rule, task)
+	c.depth--
+
+	status, message := models.RunSucceeded, ""
+	if err != nil {
+		if _, err := tx.Exec("ROLLBACK TO SAVEPOINT automation"); err != nil {
+			return err
+		}
+		status, message = models.RunFailed, err.Error()
+	}
+	if _, err := tx.Exec("RELEASE SAVEPOINT automation"); err != nil {
+		return err
+	}
+
+	refreshed, err := getTask(tx, task.ID)
+	if err != nil {
+		return err
+	}
+	*task = *refreshed
+
+	return logAutomationRun(tx, rule, task, event, status, message, c.depth)
+}
+
+func (c *automationChain) apply(tx *sql.Tx, rule *models.AutomationRule, task *models.Task) error {
+	for _, action := range rule.Actions {
+		var err error
+		switch action.Type {
+		case models.ActionSetField:
+			err = c.setField(tx, task, action)
+		case models.ActionCreateTask:
+			err = c.createTask(tx, task, action)
+		case models.ActionAddComment:
+			_, err = insertComment(tx, &models.Comment{
+				TaskID: task.ID,
+				RuleID: rule.ID,
+				Body:   models.ExpandAutomationText(action.Message, task),
+			})
+		case models.ActionSendNotification:
+			recipient := action.UserID
+			if recipient == "" {
+				recipient = task.UserID
+			}
+			if err = checkRecipient(tx, task, recipient); err != nil {
+				break
+			}
+			err = events.Append(tx, events.TaskNotified, task.ID, events.TaskNotificationPayload{
+				Task:         taskPayload(task),
+				Subject:      rule.Name,
+				Message:      models.ExpandAutomationText(action.Message, task),
+				RecipientIDs: []string{recipient},
+			})
+		default:
+			err = fmt.Errorf("invalid action: %s", action.Type)
+		}
+		if err != nil {
+			return fmt.Errorf("%s: %v", action.Type, err)
+		}
+	}
+	return nil
+}
+
+// checkRecipient checks that a notification about a task goes to its owner
+// or to a member of its project.
+func checkRecipient(tx *sql.Tx, task *models.Task, recipient string) error {
+	if recipient == task.UserID {
+		return nil
+	}
+
+	member, err := isProjectMember(tx, task.ProjectID, recipient)
+	if err != nil {
+		return err
+	}
+	if !member {
+		return fmt.Errorf("user %s is not a member of the project", recipient)
+	}
+	return nil
+}
+
+func (c *automationChain) setField(tx *sql.Tx, task *models.Task, action models.AutomationAction) error {
+	column, ok := automationColumns[action.Field]
+	if !ok {
+		return fmt.Errorf("invalid field: %s", action.Field)
+	}
+
+	previousStatus := task.Status
+	_, err := tx.Exec("UPDATE tasks SET "+column+" = $2, updated_at = $3 WHERE id = $1", task.ID, action.Value, time.Now())
+	if err != nil {
+		return err
+	}
+
+	updated, err := getTask(tx, task.ID)
+	if err != nil {
+		return err
+	}
+	if updated.Status != previousStatus {
+		if err := moveToColumnEnd(tx, updated); err != nil {
+			return err
+		}
+		if updated, err = getTask(tx, task.ID); err != nil {
+			return err
+		}
+	}
+
+	if err := recordTaskUpdate(tx, updated, previousStatus); err != nil {
+		return err
+	}
+	*task = *updated
+
+	return c.taskUpdated(tx, task, previousStatus, task.Tags)
+}
+
+func (c *automationChain) createTask(tx *sql.Tx, task *models.Task, action models.AutomationAction) error {
+	created := &models.Task{
+		ID:          uuid.New().String(),
+		Title:       models.ExpandAutomationText(action.Title, task),
+		Description: models.ExpandAutomationText(action.Message, task),
+		Status:      models.StatusPending,
+		Priority:    action.Priority,
+		UserID:      task.UserID,
+		ProjectID:   task.ProjectID,
+		Timezone:    task.Timezone,
+		Tags:        models.NormalizeTags(action.Tags),
+		CreatedAt:   time.Now(),
+		UpdatedAt:   time.Now(),
+	}
+	if created.Priority == "" {
+		created.Priority = models.PriorityMedium
+	}
+
+	if err := insertTask(tx, created); err != nil {
+		return err
+	}
+
+	return c.taskCreated(tx, created)
+}
+
+func logAutomationRun(tx *sql.Tx, rule *models.AutomationRule, task *models.Task, event models.AutomationEvent, status models.AutomationRunStatus, message string, depth int) error {
+	var dueDate *time.Time
+	if event.Trigger == models.TriggerDueSoon {
+		dueDate = task.DueDate
+	}
+
+	_, err := tx.Exec(
+		"INSERT INTO automation_runs ("+automationRunColumns+") VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)",
+		uuid.New().String(), rule.ID, task.ID, event.Trigger, status, message, depth, dueDate, time.Now(),
+	)
+	return err
+}
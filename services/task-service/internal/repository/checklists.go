@@ -0,0 +1,139 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/todo/services/task-service/internal/models"
+)
+
+const checklistSchema = `
+	CREATE TABLE IF NOT EXISTS checklist_items (
+		id VARCHAR(36) PRIMARY KEY,
+		task_id VARCHAR(36) NOT NULL,
+		title VARCHAR(255) NOT NULL,
+		completed BOOLEAN NOT NULL DEFAULT FALSE,
+		position INTEGER NOT NULL,
+		created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
+		updated_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP
+	);
+	CREATE INDEX IF NOT EXISTS idx_checklist_items_task ON checklist_items(task_id, position);
+`
+
+const checklistColumns = "id, task_id, title, completed, position, created_at, updated_at"
+
+func scanChecklistItem(row rowScanner) (*models.ChecklistItem, error) {
+	item := &models.ChecklistItem{}
+	err := row.Scan(&item.ID, &item.TaskID, &item.Title, &item.Completed, &item.Position, &item.CreatedAt, &item.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return item, nil
+}
+
+// execer is satisfied by both *sql.DB and *sql.Tx.
+type execer interface {
+	Exec(query string, args ...interface{}) (sql.Result, error)
+}
+
+func insertChecklistItem(e execer, taskID, title string, position int) (*models.ChecklistItem, error) {
+	item := &models.ChecklistItem{
+		ID:        uuid.New().String(),
+		TaskID:    taskID,
+		Title:     title,
+		Position:  position,
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+	}
+
+	_, err := e.Exec(
+		"INSERT INTO checklist_items (id, task_id, title, completed, position, created_at, updated_at) VALUES ($1, $2, $3, FALSE, $4, $5, $6)",
+		item.ID, item.TaskID, item.Title, item.Position, item.CreatedAt, item.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return item, nil
+}
+
+// AddChecklistItem appends an item to the end of a task's checklist.
+func (r *PostgresRepository) AddChecklistItem(taskID, title string) (*models.ChecklistItem, error) {
+	if title == "" {
+		return nil, fmt.Errorf("title is required")
+	}
+
+	if _, err := r.GetTaskByID(taskID); err != nil {
+		return nil, err
+	}
+
+	var position int
+	err := r.db.QueryRow("SELECT COALESCE(MAX(position), 0) + 1 FROM checklist_items WHERE task_id = $1", taskID).Scan(&position)
+	if err != nil {
+		return nil, err
+	}
+
+	return insertChecklistItem(r.db, taskID, title, position)
+}
+
+func (r *PostgresRepository) UpdateChecklistItem(id, title string, completed bool) (*models.ChecklistItem, error) {
+	if title == "" {
+		return nil, fmt.Errorf("title is required")
+	}
+
+	item, err := scanChecklistItem(r.db.QueryRow(
+		"UPDATE checklist_items SET title = $2, completed = $3, updated_at = $4 WHERE id = $1 RETURNING "+checklistColumns,
+		id, title, completed, time.Now(),
+	))
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("checklist item not found")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return item, nil
+}
+
+func (r *PostgresRepository) DeleteChecklistItem(id string) error {
+	result, err := r.db.Exec("DELETE FROM checklist_items WHERE id = $1", id)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("checklist item not found")
+	}
+
+	return nil
+}
+
+func (r *PostgresRepository) ListChecklistItems(taskID string) ([]*models.ChecklistItem, error) {
+	rows, err := r.db.Query("SELECT "+checklistColumns+" FROM checklist_items WHERE task_id = $1 ORDER BY position", taskID)
+	if err != nil {
+		return nil, err
+	}
+	return scanChecklistItems(rows)
+}
+
+func scanChecklistItems(rows *sql.Rows) ([]*models.ChecklistItem, error) {
+	defer rows.Close()
+
+	items := []*models.ChecklistItem{}
+	for rows.Next() {
+		item, err := scanChecklistItem(rows)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+	}
+
+	return items, rows.Err()
+}
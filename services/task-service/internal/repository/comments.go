@@ -0,0 +1,71 @@
+package repository
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/todo/services/task-service/internal/models"
+)
+
+const commentsSchema = `
+	CREATE TABLE IF NOT EXISTS task_comments (
+		id VARCHAR(36) PRIMARY KEY,
+		task_id VARCHAR(36) NOT NULL,
+		user_id VARCHAR(36) NOT NULL DEFAULT '',
+		rule_id VARCHAR(36) NOT NULL DEFAULT '',
+		body TEXT NOT NULL,
+		created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP
+	);
+	CREATE INDEX IF NOT EXISTS idx_task_comments_task_id ON task_comments(task_id, created_at);
+`
+
+func insertComment(db execer, input *models.Comment) (*models.Comment, error) {
+	comment := *input
+	comment.ID = uuid.New().String()
+	comment.CreatedAt = time.Now()
+
+	_, err := db.Exec(
+		"INSERT INTO task_comments (id, task_id, user_id, rule_id, body, created_at) VALUES ($1, $2, $3, $4, $5, $6)",
+		comment.ID, comment.TaskID, comment.UserID, comment.RuleID, comment.Body, comment.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &comment, nil
+}
+
+func (r *PostgresRepository) AddComment(taskID, userID, body string) (*models.Comment, error) {
+	if _, err := r.GetTaskByID(taskID); err != nil {
+		return nil, err
+	}
+
+	return insertComment(r.db, &models.Comment{TaskID: taskID, UserID: userID, Body: body})
+}
+
+func (r *PostgresRepository) ListComments(taskID string) ([]*models.Comment, error) {
+	rows, err := r.db.Query(
+		"SELECT id, task_id, user_id, rule_id, body, created_at FROM task_comments WHERE task_id = $1 ORDER BY created_at",
+		taskID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return scanComments(rows)
+}
+
+func scanComments(rows *sql.Rows) ([]*models.Comment, error) {
+	defer rows.Close()
+
+	comments := []*models.Comment{}
+	for rows.Next() {
+		comment := &models.Comment{}
+		if err := rows.Scan(&comment.ID, &comment.TaskID, &comment.UserID, &comment.RuleID, &comment.Body, &comment.CreatedAt); err != nil {
+			return nil, err
+		}
+		comments = append(comments, comment)
+	}
+
+	return comments, rows.Err()
+}
@@ -0,0 +1,180 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"github.com/todo/services/task-service/internal/models"
+)
+
+const customFieldsSchema = `
+	CREATE TABLE IF NOT EXISTS custom_field_definitions (
+		id VARCHAR(36) PRIMARY KEY,
+		project_id VARCHAR(36) NOT NULL,
+		key VARCHAR(63) NOT NULL,
+		name VARCHAR(255) NOT NULL,
+		type VARCHAR(20) NOT NULL,
+		options TEXT[] NOT NULL DEFAULT '{}',
+		created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
+		updated_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
+		UNIQUE (project_id, key)
+	);
+	ALTER TABLE tasks ADD COLUMN IF NOT EXISTS custom_fields JSONB NOT NULL DEFAULT '{}';
+`
+
+const customFieldColumns = "id, project_id, key, name, type, options, created_at, updated_at"
+
+func scanCustomField(row rowScanner) (*models.CustomFieldDefinition, error) {
+	field := &models.CustomFieldDefinition{}
+	err := row.Scan(&field.ID, &field.ProjectID, &field.Key, &field.Name, &field.Type, pq.Array(&field.Options), &field.CreatedAt, &field.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return field, nil
+}
+
+func (r *PostgresRepository) CreateCustomField(input *models.CustomFieldDefinition) (*models.CustomFieldDefinition, error) {
+	field := *input
+	field.ID = uuid.New().String()
+	field.CreatedAt = time.Now()
+	field.UpdatedAt = time.Now()
+	if field.Options == nil {
+		field.Options = []string{}
+	}
+
+	_, err := r.db.Exec(
+		"INSERT INTO custom_field_definitions (id, project_id, key, name, type, options, created_at, updated_at) VALUES ($1, $2, $3, $4, $5, $6, $7, $8)",
+		field.ID, field.ProjectID, field.Key, field.Name, field.Type, pq.Array(field.Options), field.CreatedAt, field.UpdatedAt,
+	)
+	if pqErr, ok := err.(*pq.Error); ok && pqErr.Code == uniqueViolation {
+		return nil, fmt.Errorf("custom field %s already exists in this project", field.Key)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &field, nil
+}
+
+func (r *PostgresRepository) GetCustomField(id string) (*models.CustomFieldDefinition, error) {
+	field, err := scanCustomField(r.db.QueryRow("SELECT "+customFieldColumns+" FROM custom_field_definitions WHERE id = $1", id))
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("custom field not found")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return field, nil
+}
+
+// UpdateCustomField renames a field or changes its options. The key and type
+// are fixed once created so stored values stay valid.
+func (r *PostgresRepository) UpdateCustomField(id, name string, options []string) (*models.CustomFieldDefinition, error) {
+	field, err := r.GetCustomField(id)
+	if err != nil {
+		return nil, err
+	}
+
+	field.Name = name
+	field.Options = options
+	if field.Options == nil {
+		field.Options = []string{}
+	}
+	if err := field.Validate(); err != nil {
+		return nil, err
+	}
+
+	return scanCustomField(r.db.QueryRow(
+		"UPDATE custom_field_definitions SET name = $2, options = $3, updated_at = $4 WHERE id = $1 RETURNING "+customFieldColumns,
+		id, field.Name, pq.Array(field.Options), time.Now(),
+	))
+}
+
+// DeleteCustomField removes a field and its values from the project's tasks.
+func (r *PostgresRepository) DeleteCustomField(id string) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var projectID, key string
+	err = tx.QueryRow("DELETE FROM custom_field_definitions WHERE id = $1 RETURNING project_id, key", id).Scan(&projectID, &key)
+	if err == sql.ErrNoRows {
+		return fmt.Errorf("custom field not found")
+	}
+	if err != nil {
+		return err
+	}
+
+	for _, table := range []string{"tasks", "archived_tasks"} {
+		_, err = tx.Exec("UPDATE "+table+" SET custom_fields = custom_fields - $2 WHERE project_id = $1 AND custom_fields ? $2", projectID, key)
+		if err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+func (r *PostgresRepository) ListCustomFields(projectID string) ([]*models.CustomFieldDefinition, error) {
+	rows, err := r.db.Query("SELECT "+customFieldColumns+" FROM custom_field_definitions WHERE project_id = $1 ORDER BY name", projectID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	fields := []*models.CustomFieldDefinition{}
+	for rows.Next() {
+		field, err := scanCustomField(rows)
+		if err != nil {
+			return nil, err
+		}
+		fields = append(fields, field)
+	}
+
+	return fields, rows.Err()
+}
+
+// validateCustomFields checks a task's values against its project's field
+// definitions and returns them in stored form. A nil value clears a field.
+func (r *PostgresRepository) validateCustomFields(projectID string, values map[string]interface{}) (map[string]interface{}, error) {
+	validated := make(map[string]interface{})
+	if len(values) == 0 {
+		return validated, nil
+	}
+
+	if projectID == "" {
+		return nil, fmt.Errorf("custom fields require a project")
+	}
+
+	fields, err := r.ListCustomFields(projectID)
+	if err != nil {
+		return nil, err
+	}
+
+	byKey := make(map[string]*models.CustomFieldDefinition, len(fields))
+	for _, field := range fields {
+		byKey[field.Key] = field
+	}
+
+	for key, value := range values {
+		field, ok := byKey[key]
+		if !ok {
+			return nil, fmt.Errorf("unknown custom field: %s", key)
+		}
+		if value == nil {
+			continue
+		}
+
+		if validated[key], err = field.ValidateValue(value); err != nil {
+			return nil, err
+		}
+	}
+
+	return validated, nil
+}
@@ -0,0 +1,62 @@
+package repository
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/todo/services/task-service/internal/models"
+)
+
+// due_date was originally a TIMESTAMP holding UTC wall-clock times. It is
+// converted once; the check keeps the migration from shifting values again.
+const dueDatesSchema = `
+	DO $$
+	BEGIN
+		IF (SELECT data_type FROM information_schema.columns
+			WHERE table_schema = current_schema() AND table_name = 'tasks' AND column_name = 'due_date') = 'timestamp without time zone' THEN
+			ALTER TABLE tasks ALTER COLUMN due_date TYPE TIMESTAMPTZ USING due_date AT TIME ZONE 'UTC';
+		END IF;
+	END $$;
+	ALTER TABLE tasks ADD COLUMN IF NOT EXISTS due_all_day BOOLEAN NOT NULL DEFAULT FALSE;
+	ALTER TABLE tasks ADD COLUMN IF NOT EXISTS timezone VARCHAR(64) NOT NULL DEFAULT 'UTC';
+`
+
+// normalizeDueDate validates the task's timezone, defaulting to UTC, and
+// moves an all-day due date to the end of its day in that timezone.
+func normalizeDueDate(task *models.Task) error {
+	loc, err := models.LoadTimezone(task.Timezone)
+	if err != nil {
+		return err
+	}
+	task.Timezone = loc.String()
+
+	if task.DueDate == nil {
+		task.DueAllDay = false
+	} else if task.DueAllDay {
+		dueDate := models.EndOfDay(*task.DueDate, loc)
+		task.DueDate = &dueDate
+	}
+
+	return nil
+}
+
+// addDueDays matches due dates from the start of the day fromDays after
+// today up to the start of the day toDays after it. Days are computed in loc,
+// or in each task's own timezone when loc is nil.
+func (q *taskQuery) addDueDays(loc *time.Location, now time.Time, fromDays, toDays int) {
+	if loc == nil {
+		q.add("due_date >= "+localDayStart(fromDays)+" AND due_date < "+localDayStart(toDays), now)
+		return
+	}
+
+	localNow := now.In(loc)
+	startOfToday := time.Date(localNow.Year(), localNow.Month(), localNow.Day(), 0, 0, 0, 0, loc)
+	q.add("due_date >= $%d AND due_date < $%d", startOfToday.AddDate(0, 0, fromDays), startOfToday.AddDate(0, 0, toDays))
+}
+
+// localDayStart is SQL for the start of the day the given number of days
+// after the time in the condition's first placeholder, in the task's
+// timezone.
+func localDayStart(days int) string {
+	return "((DATE_TRUNC('day', $%[1]d::timestamptz AT TIME ZONE timezone) + INTERVAL '" + strconv.Itoa(days) + " days') AT TIME ZONE timezone)"
+}
@@ -0,0 +1,361 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"github.com/todo/pkg/events"
+	"github.com/todo/services/task-service/internal/models"
+)
+
+// Escalations keep no foreign key to their rule so the audit trail outlives
+// deleted rules. A rule fires once per task and due date, so rescheduling an
+// overdue task lets it escalate again.
+const escalationsSchema = `
+	CREATE TABLE IF NOT EXISTS escalation_rules (
+		id VARCHAR(36) PRIMARY KEY,
+		user_id VARCHAR(36) NOT NULL,
+		project_id VARCHAR(36) NOT NULL DEFAULT '',
+		priority VARCHAR(50) NOT NULL DEFAULT '',
+		after_hours INTEGER NOT NULL,
+		action VARCHAR(30) NOT NULL,
+		notify_user_id VARCHAR(36) NOT NULL DEFAULT '',
+		created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
+		updated_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP
+	);
+	CREATE INDEX IF NOT EXISTS idx_escalation_rules_user_id ON escalation_rules(user_id);
+
+	CREATE TABLE IF NOT EXISTS task_escalations (
+		id VARCHAR(36) PRIMARY KEY,
+		rule_id VARCHAR(36) NOT NULL,
+		task_id VARCHAR(36) NOT NULL,
+		due_date TIMESTAMPTZ NOT NULL,
+		action VARCHAR(30) NOT NULL,
+		from_priority VARCHAR(50) NOT NULL,
+		to_priority VARCHAR(50) NOT NULL,
+		notified_user_ids TEXT[] NOT NULL DEFAULT '{}',
+		created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
+		UNIQUE (rule_id, task_id, due_date)
+	);
+	CREATE INDEX IF NOT EXISTS idx_task_escalations_task_id ON task_escalations(task_id);
+
+	CREATE TABLE IF NOT EXISTS task_watchers (
+		task_id VARCHAR(36) NOT NULL,
+		user_id VARCHAR(36) NOT NULL,
+		created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
+		PRIMARY KEY (task_id, user_id)
+	);
+
+	CREATE TABLE IF NOT EXISTS project_escalation_settings (
+		project_id VARCHAR(36) PRIMARY KEY,
+		opted_out BOOLEAN NOT NULL DEFAULT FALSE,
+		updated_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP
+	);
+`
+
+const escalationRuleColumns = "id, user_id, project_id, priority, after_hours, action, notify_user_id, created_at, updated_at"
+
+const escalationColumns = "id, rule_id, task_id, due_date, action, from_priority, to_priority, notified_user_ids, created_at"
+
+func scanEscalationRule(row rowScanner) (*models.EscalationRule, error) {
+	rule := &models.EscalationRule{}
+	err := row.Scan(&rule.ID, &rule.UserID, &rule.ProjectID, &rule.Priority, &rule.AfterHours, &rule.Action, &rule.NotifyUserID, &rule.CreatedAt, &rule.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return rule, nil
+}
+
+func getEscalationRule(db queryRower, id string) (*models.EscalationRule, error) {
+	rule, err := scanEscalationRule(db.QueryRow("SELECT "+escalationRuleColumns+" FROM escalation_rules WHERE id = $1", id))
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("escalation rule not found")
+	}
+	if err != nil {
+		return nil, err
+	}
+	return rule, nil
+}
+
+func (r *PostgresRepository) CreateEscalationRule(input *models.EscalationRule) (*models.EscalationRule, error) {
+	rule := *input
+	rule.ID = uuid.New().String()
+	rule.CreatedAt = time.Now()
+	rule.UpdatedAt = time.Now()
+
+	_, err := r.db.Exec(
+		"INSERT INTO escalation_rules ("+escalationRuleColumns+") VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)",
+		rule.ID, rule.UserID, rule.ProjectID, rule.Priority, rule.AfterHours, rule.Action, rule.NotifyUserID, rule.CreatedAt, rule.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &rule, nil
+}
+
+func (r *PostgresRepository) GetEscalationRule(id string) (*models.EscalationRule, error) {
+	return getEscalationRule(r.db, id)
+}
+
+// UpdateEscalationRule replaces a rule's conditions and action. The owner is
+// fixed once created.
+func (r *PostgresRepository) UpdateEscalationRule(input *models.EscalationRule) (*models.EscalationRule, error) {
+	rule, err := scanEscalationRule(r.db.QueryRow(
+		"UPDATE escalation_rules SET project_id = $2, priority = $3, after_hours = $4, action = $5, notify_user_id = $6, updated_at = $7 WHERE id = $1 RETURNING "+escalationRuleColumns,
+		input.ID, input.ProjectID, input.Priority, input.AfterHours, input.Action, input.NotifyUserID, time.Now(),
+	))
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("escalation rule not found")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return rule, nil
+}
+
+func (r *PostgresRepository) DeleteEscalationRule(id string) error {
+	result, err := r.db.Exec("DELETE FROM escalation_rules WHERE id = $1", id)
+	if err != nil {
+		return err
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return fmt.Errorf("escalation rule not found")
+	}
+
+	return nil
+}
+
+func (r *PostgresRepository) ListEscalationRules(userID string) ([]*models.EscalationRule, error) {
+	rows, err := r.db.Query("SELECT "+escalationRuleColumns+" FROM escalation_rules WHERE user_id = $1 ORDER BY created_at", userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	rules := []*models.EscalationRule{}
+	for rows.Next() {
+		rule, err := scanEscalationRule(rows)
+		if err != nil {
+			return nil, err
+		}
+		rules = append(rules, rule)
+	}
+
+	return rules, rows.Err()
+}
+
+// ListTaskEscalations returns a task's escalation audit trail, oldest first.
+func (r *PostgresRepository) ListTaskEscalations(taskID string) ([]*models.Escalation, error) {
+	rows, err := r.db.Query("SELECT "+escalationColumns+" FROM task_escalations WHERE task_id = $1 ORDER BY created_at", taskID)
+	if err != nil {
+		return nil, err
+	}
+	return scanEscalations(rows)
+}
+
+func scanEscalations(rows *sql.Rows) ([]*models.Escalation, error) {
+	defer rows.Close()
+
+	escalations := []*models.Escalation{}
+	for rows.Next() {
+		escalation := &models.Escalation{}
+		err := rows.Scan(&escalation.ID, &escalation.RuleID, &escalation.TaskID, &escalation.DueDate, &escalation.Action,
+			&escalation.FromPriority, &escalation.ToPriority, pq.Array(&escalation.NotifiedUserIDs), &escalation.CreatedAt)
+		if err != nil {
+			return nil, err
+		}
+		escalations = append(escalations, escalation)
+	}
+
+	return escalations, rows.Err()
+}
+
+func (r *PostgresRepository) AddWatcher(taskID, userID string) error {
+	if _, err := r.GetTaskByID(taskID); err != nil {
+		return err
+	}
+
+	_, err := r.db.Exec(
+		"INSERT INTO task_watchers (task_id, user_id, created_at) VALUES ($1, $2, $3) ON CONFLICT DO NOTHING",
+		taskID, userID, time.Now(),
+	)
+	return err
+}
+
+func (r *PostgresRepository) RemoveWatcher(taskID, userID string) error {
+	_, err := r.db.Exec("DELETE FROM task_watchers WHERE task_id = $1 AND user_id = $2", taskID, userID)
+	return err
+}
+
+func (r *PostgresRepository) ListWatchers(taskID string) ([]string, error) {
+	return listWatchers(r.db, taskID)
+}
+
+func listWatchers(db queryRower, taskID string) ([]string, error) {
+	var userIDs []string
+	err := db.QueryRow(
+		"SELECT COALESCE(array_agg(user_id ORDER BY created_at), '{}') FROM task_watchers WHERE task_id = $1",
+		taskID,
+	).Scan(pq.Array(&userIDs))
+	if err != nil {
+		return nil, err
+	}
+	return userIDs, nil
+}
+
+// GetProjectEscalationSettings returns a project's settings. Projects that
+// never changed them take part in escalation.
+func (r *PostgresRepository) GetProjectEscalationSettings(projectID string) (*models.ProjectEscalationSettings, error) {
+	settings := &models.ProjectEscalationSettings{ProjectID: projectID}
+	err := r.db.QueryRow(
+		"SELECT opted_out, updated_at FROM project_escalation_settings WHERE project_id = $1",
+		projectID,
+	).Scan(&settings.OptedOut, &settings.UpdatedAt)
+	if err != nil && err != sql.ErrNoRows {
+		return nil, err
+	}
+
+	return settings, nil
+}
+
+func (r *PostgresRepository) SetProjectEscalationOptOut(projectID string, optedOut bool) (*models.ProjectEscalationSettings, error) {
+	now := time.Now()
+	settings := &models.ProjectEscalationSettings{ProjectID: projectID, OptedOut: optedOut, UpdatedAt: &now}
+	_, err := r.db.Exec(
+		"INSERT INTO project_escalation_settings (project_id, opted_out, updated_at) VALUES ($1, $2, $3) ON CONFLICT (project_id) DO UPDATE SET opted_out = $2, updated_at = $3",
+		settings.ProjectID, settings.OptedOut, now,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return settings, nil
+}
+
+// EscalateOverdueTasks fires up to limit pending escalations: open, awake
+// tasks overdue for at least a matching rule's hours that the rule has not
+// fired for at their current due date. It returns how many it fired.
+func (r *PostgresRepository) EscalateOverdueTasks(now time.Time, limit int) (int, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	// SKIP LOCKED lets several replicas escalate without firing a rule twice
+	rows, err := tx.Query(
+		"SELECT r.id, t.id FROM escalation_rules r JOIN tasks t ON t.user_id = r.user_id"+
+			" AND (r.project_id = '' OR t.project_id = r.project_id)"+
+			" AND (r.priority = '' OR t.priority = r.priority)"+
+			" WHERE t.status IN ($1, $2) AND t.due_date + r.after_hours * INTERVAL '1 hour' <= $3"+
+			" AND (t.start_date IS NULL OR t.start_date <= $3)"+
+			" AND NOT EXISTS (SELECT 1 FROM task_escalations e WHERE e.rule_id = r.id AND e.task_id = t.id AND e.due_date = t.due_date)"+
+			" AND NOT EXISTS (SELECT 1 FROM project_escalation_settings s WHERE s.project_id = t.project_id AND s.opted_out)"+
+			" ORDER BY t.due_date, r.created_at LIMIT $4 FOR UPDATE OF t SKIP LOCKED",
+		models.StatusPending, models.StatusInProgress, now, limit,
+	)
+	if err != nil {
+		return 0, err
+	}
+
+	type pending struct{ ruleID, taskID string }
+	var fired []pending
+	for rows.Next() {
+		var p pending
+		if err := rows.Scan(&p.ruleID, &p.taskID); err != nil {
+			rows.Close()
+			return 0, err
+		}
+		fired = append(fired, p)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, err
+	}
+
+	for _, p := range fired {
+		rule, err := getEscalationRule(tx, p.ruleID)
+		if err != nil {
+			return 0, err
+		}
+		if err := escalateTask(tx, rule, p.taskID, now); err != nil {
+			return 0, err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, err
+	}
+
+	return len(fired), nil
+}
+
+// escalateTask applies a rule's action to a task, records it in the audit
+// trail and publishes a TaskEscalated event naming who to notify.
+func escalateTask(tx *sql.Tx, rule *models.EscalationRule, taskID string, now time.Time) error {
+	task, err := getTask(tx, taskID)
+	if err != nil {
+		return err
+	}
+
+	escalation := &models.Escalation{
+		ID:              uuid.New().String(),
+		RuleID:          rule.ID,
+		TaskID:          task.ID,
+		DueDate:         *task.DueDate,
+		Action:          rule.Action,
+		FromPriority:    task.Priority,
+		ToPriority:      task.Priority,
+		NotifiedUserIDs: []string{},
+		CreatedAt:       now,
+	}
+
+	switch rule.Action {
+	case models.EscalateRaisePriority:
+		escalation.ToPriority = models.RaisePriority(task.Priority)
+		if escalation.ToPriority != task.Priority {
+			_, err := tx.Exec("UPDATE tasks SET priority = $2, updated_at = $3 WHERE id = $1", task.ID, escalation.ToPriority, time.Now())
+			if err != nil {
+				return err
+			}
+			if task, err = getTask(tx, taskID); err != nil {
+				return err
+			}
+			if err := recordTaskUpdate(tx, task, task.Status); err != nil {
+				return err
+			}
+		}
+	case models.EscalateNotifyWatchers:
+		if escalation.NotifiedUserIDs, err = listWatchers(tx, task.ID); err != nil {
+			return err
+		}
+	case models.EscalateNotifyUser:
+		escalation.NotifiedUserIDs = []string{rule.NotifyUserID}
+	}
+
+	_, err = tx.Exec(
+		"INSERT INTO task_escalations ("+escalationColumns+") VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)",
+		escalation.ID, escalation.RuleID, escalation.TaskID, escalation.DueDate, escalation.Action,
+		escalation.FromPriority, escalation.ToPriority, pq.Array(escalation.NotifiedUserIDs), escalation.CreatedAt,
+	)
+	if err != nil {
+		return err
+	}
+
+	return events.Append(tx, events.TaskEscalated, task.ID, events.TaskEscalatedPayload{
+		Task:         taskPayload(task),
+		Action:       string(rule.Action),
+		HoursOverdue: int(now.Sub(*task.DueDate).Hours()),
+		RecipientIDs: escalation.NotifiedUserIDs,
+	})
+}
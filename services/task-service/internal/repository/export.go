@@ -0,0 +1,172 @@
+package repository
+
+import (
+	"github.com/todo/services/task-service/internal/models"
+)
+
+// userTaskIDs selects the IDs of every task owned by the user in $1,
+// archived ones included.
+const userTaskIDs = "SELECT id FROM all_tasks WHERE user_id = $1"
+
+// ExportUserData collects everything kept about a user: their tasks and
+// everything attached to them, what they added to other users' tasks, their
+// settings, views, templates and rules, and the events published about their
+// tasks.
+func (r *PostgresRepository) ExportUserData(userID string) (*models.UserData, error) {
+	data := &models.UserData{}
+	var err error
+
+	if data.Tasks, err = r.ListAllUserTasks(userID); err != nil {
+		return nil, err
+	}
+
+	rows, err := r.db.Query("SELECT "+checklistColumns+" FROM checklist_items WHERE task_id IN ("+userTaskIDs+") ORDER BY task_id, position", userID)
+	if err != nil {
+		return nil, err
+	}
+	if data.ChecklistItems, err = scanChecklistItems(rows); err != nil {
+		return nil, err
+	}
+
+	rows, err = r.db.Query(
+		"SELECT id, task_id, user_id, rule_id, body, created_at FROM task_comments WHERE user_id = $1 OR task_id IN ("+userTaskIDs+") ORDER BY created_at",
+		userID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if data.Comments, err = scanComments(rows); err != nil {
+		return nil, err
+	}
+
+	if data.TimeEntries, err = r.exportTimeEntries(userID); err != nil {
+		return nil, err
+	}
+
+	if data.WatchedTaskIDs, err = r.exportWatchedTaskIDs(userID); err != nil {
+		return nil, err
+	}
+
+	if data.History, err = r.exportTaskEvents(userID); err != nil {
+		return nil, err
+	}
+
+	rows, err = r.db.Query("SELECT "+escalationColumns+" FROM task_escalations WHERE task_id IN ("+userTaskIDs+") ORDER BY created_at", userID)
+	if err != nil {
+		return nil, err
+	}
+	if data.Escalations, err = scanEscalations(rows); err != nil {
+		return nil, err
+	}
+
+	rows, err = r.db.Query("SELECT "+automationRunColumns+" FROM automation_runs WHERE task_id IN ("+userTaskIDs+") ORDER BY created_at", userID)
+	if err != nil {
+		return nil, err
+	}
+	if data.AutomationRuns, err = scanAutomationRuns(rows); err != nil {
+		return nil, err
+	}
+
+	if data.Settings, err = r.GetUserSettings(userID); err != nil {
+		return nil, err
+	}
+
+	views, err := r.ListViews(userID)
+	if err != nil {
+		return nil, err
+	}
+	data.SavedViews = []*models.SavedView{}
+	for _, view := range views {
+		if view.UserID == userID {
+			data.SavedViews = append(data.SavedViews, view)
+		}
+	}
+
+	if data.Templates, err = r.ListTemplates(userID); err != nil {
+		return nil, err
+	}
+
+	if data.EscalationRules, err = r.ListEscalationRules(userID); err != nil {
+		return nil, err
+	}
+
+	rows, err = r.db.Query("SELECT "+automationRuleColumns+" FROM automation_rules WHERE user_id = $1 ORDER BY created_at", userID)
+	if err != nil {
+		return nil, err
+	}
+	if data.AutomationRules, err = scanAutomationRules(rows); err != nil {
+		return nil, err
+	}
+
+	return data, nil
+}
+
+// exportTimeEntries returns the time tracked on the user's tasks and by the
+// user on other users' tasks.
+func (r *PostgresRepository) exportTimeEntries(userID string) ([]*models.TimeEntry, error) {
+	rows, err := r.db.Query(
+		"SELECT "+timeEntryColumns+" FROM time_entries te WHERE te.user_id = $1 OR te.task_id IN ("+userTaskIDs+") ORDER BY te.started_at",
+		userID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	entries := []*models.TimeEntry{}
+	for rows.Next() {
+		entry, err := scanTimeEntry(rows)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, entry)
+	}
+
+	return entries, rows.Err()
+}
+
+func (r *PostgresRepository) exportWatchedTaskIDs(userID string) ([]string, error) {
+	rows, err := r.db.Query("SELECT task_id FROM task_watchers WHERE user_id = $1 ORDER BY created_at", userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	ids := []string{}
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+
+	return ids, rows.Err()
+}
+
+// exportTaskEvents returns the events published about the user's tasks,
+// deleted ones included, oldest first. Task events carry the task's owner
+// either at the top of their payload or in its task.
+func (r *PostgresRepository) exportTaskEvents(userID string) ([]*models.TaskEvent, error) {
+	rows, err := r.db.Query(
+		`SELECT id, event_type, aggregate_id, payload, created_at FROM outbox_events
+		 WHERE COALESCE(payload->'task'->>'user_id', payload->>'user_id') = $1
+		 ORDER BY created_at`,
+		userID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	history := []*models.TaskEvent{}
+	for rows.Next() {
+		event := &models.TaskEvent{}
+		if err := rows.Scan(&event.ID, &event.Type, &event.TaskID, &event.Payload, &event.OccurredAt); err != nil {
+			return nil, err
+		}
+		history = append(history, event)
+	}
+
+	return history, rows.Err()
+}
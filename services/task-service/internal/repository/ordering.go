@@ -0,0 +1,296 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/todo/services/task-service/internal/models"
+	"github.com/todo/services/task-service/internal/rank"
+)
+
+// Ranks use the "C" collation so they sort byte-wise, matching rank.Between.
+// Existing tasks are numbered in creation order the first time this runs.
+const orderingSchema = `
+	ALTER TABLE tasks ADD COLUMN IF NOT EXISTS list_rank VARCHAR(255) COLLATE "C" NOT NULL DEFAULT '';
+	ALTER TABLE tasks ADD COLUMN IF NOT EXISTS board_rank VARCHAR(255) COLLATE "C" NOT NULL DEFAULT '';
+	CREATE INDEX IF NOT EXISTS idx_tasks_list_rank ON tasks(user_id, project_id, list_rank);
+	CREATE INDEX IF NOT EXISTS idx_tasks_board_rank ON tasks(user_id, project_id, status, board_rank);
+	UPDATE tasks t SET list_rank = r.rank FROM (
+		SELECT id, LPAD(TO_HEX(ROW_NUMBER() OVER (PARTITION BY user_id, project_id ORDER BY created_at, id) * 16 + 8), 8, '0') AS rank
+		FROM tasks WHERE list_rank = ''
+	) r WHERE t.id = r.id;
+	UPDATE tasks t SET board_rank = r.rank FROM (
+		SELECT id, LPAD(TO_HEX(ROW_NUMBER() OVER (PARTITION BY user_id, project_id, status ORDER BY created_at, id) * 16 + 8), 8, '0') AS rank
+		FROM tasks WHERE board_rank = ''
+	) r WHERE t.id = r.id;
+`
+
+// rankList is the set of tasks a rank orders: a user's tasks in one project
+// for list ranks, narrowed to one status for board ranks.
+type rankList struct {
+	tx     *sql.Tx
+	column string
+	task   *models.Task
+	board  bool
+}
+
+func newRankList(tx *sql.Tx, scope models.MoveScope, task *models.Task) *rankList {
+	if scope == models.ScopeBoard {
+		return &rankList{tx: tx, column: "board_rank", task: task, board: true}
+	}
+	return &rankList{tx: tx, column: "list_rank", task: task}
+}
+
+// all selects every task in the list.
+func (l *rankList) all() *taskQuery {
+	q := &taskQuery{}
+	q.add("user_id = $%d", l.task.UserID)
+	if l.task.ProjectID == "" {
+		q.add("project_id IS NULL")
+	} else {
+		q.add("project_id = $%d", l.task.ProjectID)
+	}
+	if l.board {
+		q.add("status = $%d", l.task.Status)
+	}
+	return q
+}
+
+// members selects the list's tasks other than the one being placed.
+func (l *rankList) members() *taskQuery {
+	q := l.all()
+	q.add("id <> $%d", l.task.ID)
+	return q
+}
+
+func (l *rankList) aggregate(fn string, condition string, args ...interface{}) (string, error) {
+	q := l.members()
+	if condition != "" {
+		q.add(condition, args...)
+	}
+
+	var value string
+	err := l.tx.QueryRow("SELECT COALESCE("+fn+"("+l.column+"), '') FROM tasks"+q.where(), q.args...).Scan(&value)
+	return value, err
+}
+
+func (l *rankList) rankOf(id string) (string, error) {
+	if id == l.task.ID {
+		return "", fmt.Errorf("cannot move a task relative to itself")
+	}
+
+	q := l.members()
+	q.add("id = $%d", id)
+
+	var value string
+	err := l.tx.QueryRow("SELECT "+l.column+" FROM tasks"+q.where(), q.args...).Scan(&value)
+	if err == sql.ErrNoRows {
+		return "", fmt.Errorf("task %s is not in the same list", id)
+	}
+	return value, err
+}
+
+// end returns a rank after every other task in the list.
+func (l *rankList) end() (string, error) {
+	last, err := l.aggregate("MAX", "")
+	if err != nil {
+		return "", err
+	}
+	return rank.After(last), nil
+}
+
+// between returns a rank after afterID and before beforeID. Either may be
+// empty, in which case the task's other neighbour is looked up.
+func (l *rankList) between(afterID, beforeID string) (string, bool, error) {
+	var lower, upper string
+	var err error
+
+	if afterID != "" {
+		if lower, err = l.rankOf(afterID); err != nil {
+			return "", false, err
+		}
+	}
+	if beforeID != "" {
+		if upper, err = l.rankOf(beforeID); err != nil {
+			return "", false, err
+		}
+	}
+
+	switch {
+	case afterID == "" && beforeID == "":
+		lower, err = l.aggregate("MAX", "")
+	case beforeID == "":
+		upper, err = l.aggregate("MIN", l.column+" > $%d", lower)
+	case afterID == "":
+		lower, err = l.aggregate("MAX", l.column+" < $%d", upper)
+	}
+	if err != nil {
+		return "", false, err
+	}
+
+	r, ok := rank.Between(lower, upper)
+	return r, ok, nil
+}
+
+// renumber spreads the list's ranks out evenly, keeping their order, when
+// there is no room left between two neighbours.
+func (l *rankList) renumber() error {
+	q := l.all()
+
+	_, err := l.tx.Exec(
+		"UPDATE tasks t SET "+l.column+" = r.rank FROM ("+
+			"SELECT id, LPAD(TO_HEX(ROW_NUMBER() OVER (ORDER BY "+l.column+", created_at, id) * 16 + 8), 8, '0') AS rank FROM tasks"+q.where()+
+			") r WHERE t.id = r.id",
+		q.args...,
+	)
+	return err
+}
+
+// assignRanks puts a new task at the end of its list and status column.
+func assignRanks(tx *sql.Tx, task *models.Task) error {
+	var err error
+	if task.ListRank, err = newRankList(tx, models.ScopeList, task).end(); err != nil {
+		return err
+	}
+	task.BoardRank, err = newRankList(tx, models.ScopeBoard, task).end()
+	return err
+}
+
+// MoveTask repositions a task in its list or status column and optionally
+// changes its status in the same transaction.
+func (r *PostgresRepository) MoveTask(id string, move models.TaskMove) (*models.Task, error) {
+	if move.Scope == "" {
+		move.Scope = models.ScopeList
+	}
+	if err := move.Validate(); err != nil {
+		return nil, err
+	}
+
+	tx, err := r.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	task, err := scanTask(tx.QueryRow("SELECT "+taskColumns+" FROM tasks WHERE id = $1 FOR UPDATE", id))
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("task not found")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	previousStatus := task.Status
+	if move.Status != nil {
+		task.Status = *move.Status
+	}
+
+	list := newRankList(tx, move.Scope, task)
+	position, ok, err := list.between(move.AfterID, move.BeforeID)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		if err := list.renumber(); err != nil {
+			return nil, err
+		}
+		if position, ok, err = list.between(move.AfterID, move.BeforeID); err != nil {
+			return nil, err
+		}
+		if !ok {
+			return nil, fmt.Errorf("neighbours are out of order")
+		}
+	}
+
+	_, err = tx.Exec(
+		"UPDATE tasks SET status = $2, "+list.column+" = $3, updated_at = $4 WHERE id = $1",
+		task.ID, task.Status, position, time.Now(),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	// A status change from a list view still lands at the end of the new column
+	if move.Scope == models.ScopeList && task.Status != previousStatus {
+		if err := moveToColumnEnd(tx, task); err != nil {
+			return nil, err
+		}
+	}
+
+	task, err = getTask(tx, task.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := recordTaskUpdate(tx, task, previousStatus); err != nil {
+		return nil, err
+	}
+
+	if err := newAutomationChain().taskUpdated(tx, task, previousStatus, task.Tags); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return task, nil
+}
+
+func moveToColumnEnd(tx *sql.Tx, task *models.Task) error {
+	boardRank, err := newRankList(tx, models.ScopeBoard, task).end()
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec("UPDATE tasks SET board_rank = $2 WHERE id = $1", task.ID, boardRank)
+	return err
+}
+
+func moveToListEnd(tx *sql.Tx, task *models.Task) error {
+	listRank, err := newRankList(tx, models.ScopeList, task).end()
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec("UPDATE tasks SET list_rank = $2 WHERE id = $1", task.ID, listRank)
+	return err
+}
+
+// Board returns a user's tasks in one project, or those without a project
+// when projectID is empty, grouped into status columns in rank order.
+func (r *PostgresRepository) Board(userID, projectID string) ([]*models.BoardColumn, error) {
+	q := &taskQuery{}
+	q.add("user_id = $%d", userID)
+	if projectID == "" {
+		q.add("project_id IS NULL")
+	} else {
+		q.add("project_id = $%d", projectID)
+	}
+
+	rows, err := r.db.Query("SELECT "+taskColumns+" FROM tasks"+q.where()+" ORDER BY board_rank, id", q.args...)
+	if err != nil {
+		return nil, err
+	}
+
+	tasks, err := scanTasks(rows)
+	if err != nil {
+		return nil, err
+	}
+
+	columns := make([]*models.BoardColumn, len(models.BoardStatuses))
+	byStatus := make(map[models.TaskStatus]*models.BoardColumn)
+	for i, status := range models.BoardStatuses {
+		columns[i] = &models.BoardColumn{Status: status, Tasks: []*models.Task{}}
+		byStatus[status] = columns[i]
+	}
+
+	for _, task := range tasks {
+		if column, ok := byStatus[task.Status]; ok {
+			column.Tasks = append(column.Tasks, task)
+		}
+	}
+
+	return columns, nil
+}
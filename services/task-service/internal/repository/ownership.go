@@ -0,0 +1,32 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+)
+
+// TimeEntryOwner returns the ID of the user who logged a time entry.
+func (r *PostgresRepository) TimeEntryOwner(id string) (string, error) {
+	var userID string
+	err := r.db.QueryRow("SELECT user_id FROM time_entries WHERE id = $1", id).Scan(&userID)
+	if err == sql.ErrNoRows {
+		return "", fmt.Errorf("time entry not found")
+	}
+	return userID, err
+}
+
+// ChecklistItemOwner returns the ID of the user who owns the task a checklist
+// item belongs to.
+func (r *PostgresRepository) ChecklistItemOwner(id string) (string, error) {
+	var userID string
+	err := r.db.QueryRow(
+		`SELECT t.user_id FROM checklist_items c
+		 JOIN all_tasks t ON t.id = c.task_id
+		 WHERE c.id = $1`,
+		id,
+	).Scan(&userID)
+	if err == sql.ErrNoRows {
+		return "", fmt.Errorf("checklist item not found")
+	}
+	return userID, err
+}
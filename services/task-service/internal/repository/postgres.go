@@ -2,18 +2,24 @@ package repository
 
 import (
 	"database/sql"
+	"encoding/json"
 	"fmt"
 	"time"
 
 	"github.com/google/uuid"
-	_ "github.com/lib/pq"
+	"github.com/lib/pq"
+	"github.com/todo/pkg/events"
 	"github.com/todo/services/task-service/internal/models"
+	"github.com/todo/services/task-service/internal/recurrence"
 )
 
 type PostgresRepository struct {
 	db *sql.DB
 }
 
+// taskColumns is the column list every task query selects, in scanTask order.
+const taskColumns = "id, title, description, status, priority, user_id, project_id, due_date, due_all_day, timezone, start_date, tags, recurrence, list_rank, board_rank, custom_fields, completed_at, created_at, updated_at, archived_at"
+
 func NewPostgresRepository(connStr string) (*PostgresRepository, error) {
 	db, err := sql.Open("postgres", connStr)
 	if err != nil {
@@ -33,53 +39,196 @@ func NewPostgresRepository(connStr string) (*PostgresRepository, error) {
 			status VARCHAR(50) NOT NULL DEFAULT 'PENDING',
 			priority VARCHAR(50) NOT NULL DEFAULT 'MEDIUM',
 			user_id VARCHAR(36) NOT NULL,
-			due_date TIMESTAMP,
+			due_date TIMESTAMPTZ,
 			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
 			updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
 		);
 		CREATE INDEX IF NOT EXISTS idx_tasks_user_id ON tasks(user_id);
 		CREATE INDEX IF NOT EXISTS idx_tasks_status ON tasks(status);
+		ALTER TABLE tasks ADD COLUMN IF NOT EXISTS project_id VARCHAR(36);
+		CREATE INDEX IF NOT EXISTS idx_tasks_project_id ON tasks(project_id);
+		ALTER TABLE tasks ADD COLUMN IF NOT EXISTS tags TEXT[] NOT NULL DEFAULT '{}';
+		ALTER TABLE tasks ADD COLUMN IF NOT EXISTS recurrence VARCHAR(255) NOT NULL DEFAULT '';
+		CREATE INDEX IF NOT EXISTS idx_tasks_tags ON tasks USING GIN(tags);
 	`)
 	if err != nil {
 		return nil, err
 	}
 
+	// Feature schemas that build on tasks
+	for _, schema := range []string{orderingSchema, timeEntriesSchema, savedViewsSchema, checklistSchema, taskTemplatesSchema, customFieldsSchema, reportsSchema, dueDatesSchema, snoozeSchema, escalationsSchema, commentsSchema, automationsSchema, archiveSchema, projectsSchema, userSettingsSchema} {
+		if _, err := db.Exec(schema); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := events.CreateOutboxTable(db); err != nil {
+		return nil, err
+	}
+
 	return &PostgresRepository{db: db}, nil
 }
 
-func (r *PostgresRepository) CreateTask(title, description, userID string, priority models.TaskPriority, dueDate *time.Time) (*models.Task, error) {
-	task := &models.Task{
-		ID:          uuid.New().String(),
-		Title:       title,
-		Description: description,
-		Status:      models.StatusPending,
-		Priority:    priority,
-		UserID:      userID,
-		DueDate:     dueDate,
-		CreatedAt:   time.Now(),
-		UpdatedAt:   time.Now(),
-	}
+// DB exposes the connection pool for the outbox relay and event consumer.
+func (r *PostgresRepository) DB() *sql.DB {
+	return r.db
+}
 
-	_, err := r.db.Exec(
-		"INSERT INTO tasks (id, title, description, status, priority, user_id, due_date, created_at, updated_at) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)",
-		task.ID, task.Title, task.Description, task.Status, task.Priority, task.UserID, task.DueDate, task.CreatedAt, task.UpdatedAt,
-	)
+// rowScanner is satisfied by both *sql.Row and *sql.Rows.
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+// queryRower is satisfied by both *sql.DB and *sql.Tx.
+type queryRower interface {
+	QueryRow(query string, args ...interface{}) *sql.Row
+}
+
+func scanTask(row rowScanner) (*models.Task, error) {
+	task := &models.Task{}
+	var projectID sql.NullString
+	var dueDate, startDate, completedAt, archivedAt sql.NullTime
+	var customFields []byte
+
+	err := row.Scan(&task.ID, &task.Title, &task.Description, &task.Status, &task.Priority, &task.UserID, &projectID, &dueDate, &task.DueAllDay, &task.Timezone, &startDate, pq.Array(&task.Tags), &task.Recurrence, &task.ListRank, &task.BoardRank, &customFields, &completedAt, &task.CreatedAt, &task.UpdatedAt, &archivedAt)
 	if err != nil {
 		return nil, err
 	}
 
+	if err := json.Unmarshal(customFields, &task.CustomFields); err != nil {
+		return nil, err
+	}
+
+	task.ProjectID = projectID.String
+	if dueDate.Valid {
+		task.DueDate = &dueDate.Time
+	}
+	if startDate.Valid {
+		task.StartDate = &startDate.Time
+	}
+	if completedAt.Valid {
+		task.CompletedAt = &completedAt.Time
+	}
+	if archivedAt.Valid {
+		task.ArchivedAt = &archivedAt.Time
+	}
+
 	return task, nil
 }
 
-func (r *PostgresRepository) GetTaskByID(id string) (*models.Task, error) {
-	task := &models.Task{}
-	var dueDate sql.NullTime
+func scanTasks(rows *sql.Rows) ([]*models.Task, error) {
+	defer rows.Close()
+
+	var tasks []*models.Task
+	for rows.Next() {
+		task, err := scanTask(rows)
+		if err != nil {
+			return nil, err
+		}
+		tasks = append(tasks, task)
+	}
+
+	return tasks, rows.Err()
+}
+
+// customFieldsOrEmpty keeps the custom_fields column a JSON object rather
+// than null.
+func customFieldsOrEmpty(values map[string]interface{}) map[string]interface{} {
+	if values == nil {
+		return map[string]interface{}{}
+	}
+	return values
+}
+
+// nullString stores empty optional identifiers as NULL.
+func nullString(s string) sql.NullString {
+	return sql.NullString{String: s, Valid: s != ""}
+}
+
+// normalizeRecurrence validates an RRULE and returns its canonical form.
+func normalizeRecurrence(value string) (string, error) {
+	if value == "" {
+		return "", nil
+	}
 
-	err := r.db.QueryRow(
-		"SELECT id, title, description, status, priority, user_id, due_date, created_at, updated_at FROM tasks WHERE id = $1",
-		id,
-	).Scan(&task.ID, &task.Title, &task.Description, &task.Status, &task.Priority, &task.UserID, &dueDate, &task.CreatedAt, &task.UpdatedAt)
+	rule, err := recurrence.Parse(value)
+	if err != nil {
+		return "", err
+	}
+
+	return rule.String(), nil
+}
 
+func (r *PostgresRepository) CreateTask(input *models.Task) (*models.Task, error) {
+	task := *input
+	task.ID = uuid.New().String()
+	task.Status = models.StatusPending
+	task.Tags = models.NormalizeTags(task.Tags)
+	task.CreatedAt = time.Now()
+	task.UpdatedAt = time.Now()
+
+	var err error
+	if task.Recurrence, err = normalizeRecurrence(task.Recurrence); err != nil {
+		return nil, err
+	}
+	if task.Timezone, err = r.UserTimezone(task.UserID, task.Timezone); err != nil {
+		return nil, err
+	}
+	if err := normalizeDueDate(&task); err != nil {
+		return nil, err
+	}
+	if task.CustomFields, err = r.validateCustomFields(task.ProjectID, task.CustomFields); err != nil {
+		return nil, err
+	}
+
+	tx, err := r.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	if err := insertTask(tx, &task); err != nil {
+		return nil, err
+	}
+
+	if err := newAutomationChain().taskCreated(tx, &task); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return &task, nil
+}
+
+// insertTask writes a new task at the end of its list and status column,
+// along with its TaskCreated event.
+func insertTask(tx *sql.Tx, task *models.Task) error {
+	if err := assignRanks(tx, task); err != nil {
+		return err
+	}
+
+	customFields, err := json.Marshal(customFieldsOrEmpty(task.CustomFields))
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(
+		"INSERT INTO tasks (id, title, description, status, priority, user_id, project_id, due_date, due_all_day, timezone, start_date, tags, recurrence, list_rank, board_rank, custom_fields, created_at, updated_at) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18)",
+		task.ID, task.Title, task.Description, task.Status, task.Priority, task.UserID, nullString(task.ProjectID), task.DueDate, task.DueAllDay, task.Timezone, task.StartDate, pq.Array(task.Tags), task.Recurrence, task.ListRank, task.BoardRank, customFields, task.CreatedAt, task.UpdatedAt,
+	)
+	if err != nil {
+		return err
+	}
+
+	return events.Append(tx, events.TaskCreated, task.ID, taskPayload(task))
+}
+
+// GetTaskByID returns a task along with its checklist, whether or not it is
+// archived.
+func (r *PostgresRepository) GetTaskByID(id string) (*models.Task, error) {
+	task, err := scanTask(r.db.QueryRow("SELECT "+taskColumns+" FROM all_tasks WHERE id = $1", id))
 	if err == sql.ErrNoRows {
 		return nil, fmt.Errorf("task not found")
 	}
@@ -87,123 +236,287 @@ func (r *PostgresRepository) GetTaskByID(id string) (*models.Task, error) {
 		return nil, err
 	}
 
-	if dueDate.Valid {
-		task.DueDate = &dueDate.Time
+	if task.Checklist, err = r.ListChecklistItems(id); err != nil {
+		return nil, err
 	}
 
 	return task, nil
 }
 
-func (r *PostgresRepository) UpdateTask(id, title, description string, status models.TaskStatus, priority models.TaskPriority, dueDate *time.Time) (*models.Task, error) {
-	_, err := r.db.Exec(
-		"UPDATE tasks SET title = $2, description = $3, status = $4, priority = $5, due_date = $6, updated_at = $7 WHERE id = $1",
-		id, title, description, status, priority, dueDate, time.Now(),
-	)
+func getTask(q queryRower, id string) (*models.Task, error) {
+	task, err := scanTask(q.QueryRow("SELECT "+taskColumns+" FROM tasks WHERE id = $1", id))
+
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("task not found")
+	}
 	if err != nil {
 		return nil, err
 	}
 
-	return r.GetTaskByID(id)
+	return task, nil
 }
 
-func (r *PostgresRepository) DeleteTask(id string) error {
-	result, err := r.db.Exec("DELETE FROM tasks WHERE id = $1", id)
+// UpdateTask replaces the editable fields of the task identified by input.ID.
+// Completing a recurring task schedules its next occurrence as a new task.
+func (r *PostgresRepository) UpdateTask(input *models.Task) (*models.Task, error) {
+	rule, err := normalizeRecurrence(input.Recurrence)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	rowsAffected, err := result.RowsAffected()
+	timezone, err := r.TaskTimezone(input.ID, input.Timezone)
 	if err != nil {
-		return err
+		return nil, err
+	}
+	due := models.Task{DueDate: input.DueDate, DueAllDay: input.DueAllDay, Timezone: timezone}
+	if err := normalizeDueDate(&due); err != nil {
+		return nil, err
 	}
 
-	if rowsAffected == 0 {
-		return fmt.Errorf("task not found")
+	customFields, err := r.validateCustomFields(input.ProjectID, input.CustomFields)
+	if err != nil {
+		return nil, err
+	}
+	customFieldsJSON, err := json.Marshal(customFields)
+	if err != nil {
+		return nil, err
 	}
 
-	return nil
-}
+	tx, err := r.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
 
-func (r *PostgresRepository) ListTasks(page, pageSize int) ([]*models.Task, int, error) {
-	offset := (page - 1) * pageSize
+	var previousStatus models.TaskStatus
+	var previousProject sql.NullString
+	var previousTags []string
+	err = tx.QueryRow("SELECT status, project_id, tags FROM tasks WHERE id = $1 FOR UPDATE", input.ID).Scan(&previousStatus, &previousProject, pq.Array(&previousTags))
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("task not found")
+	}
+	if err != nil {
+		return nil, err
+	}
 
-	rows, err := r.db.Query(
-		"SELECT id, title, description, status, priority, user_id, due_date, created_at, updated_at FROM tasks ORDER BY created_at DESC LIMIT $1 OFFSET $2",
-		pageSize, offset,
+	_, err = tx.Exec(
+		"UPDATE tasks SET title = $2, description = $3, status = $4, priority = $5, project_id = $6, due_date = $7, due_all_day = $8, timezone = $9, start_date = $10, wake_pending = (wake_pending AND $10::timestamptz IS NOT NULL), tags = $11, recurrence = $12, custom_fields = $13, updated_at = $14 WHERE id = $1",
+		input.ID, input.Title, input.Description, input.Status, input.Priority, nullString(input.ProjectID), due.DueDate, due.DueAllDay, due.Timezone, input.StartDate, pq.Array(models.NormalizeTags(input.Tags)), rule, customFieldsJSON, time.Now(),
 	)
 	if err != nil {
-		return nil, 0, err
+		return nil, err
 	}
-	defer rows.Close()
 
-	var tasks []*models.Task
-	for rows.Next() {
-		task := &models.Task{}
-		var dueDate sql.NullTime
+	task, err := getTask(tx, input.ID)
+	if err != nil {
+		return nil, err
+	}
 
-		err := rows.Scan(&task.ID, &task.Title, &task.Description, &task.Status, &task.Priority, &task.UserID, &dueDate, &task.CreatedAt, &task.UpdatedAt)
-		if err != nil {
-			return nil, 0, err
+	// Tasks that change list or column go to the end of their new one
+	if task.ProjectID != previousProject.String {
+		if err := moveToListEnd(tx, task); err != nil {
+			return nil, err
 		}
-
-		if dueDate.Valid {
-			task.DueDate = &dueDate.Time
+	}
+	if task.ProjectID != previousProject.String || task.Status != previousStatus {
+		if err := moveToColumnEnd(tx, task); err != nil {
+			return nil, err
 		}
+	}
+	if task, err = getTask(tx, input.ID); err != nil {
+		return nil, err
+	}
 
-		tasks = append(tasks, task)
+	if err := recordTaskUpdate(tx, task, previousStatus); err != nil {
+		return nil, err
 	}
 
-	var total int
-	err = r.db.QueryRow("SELECT COUNT(*) FROM tasks").Scan(&total)
+	if err := newAutomationChain().taskUpdated(tx, task, previousStatus, previousTags); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return task, nil
+}
+
+// recordTaskUpdate stamps or clears the completion time, appends the events
+// for an updated task and, when a recurring task has just been completed,
+// schedules its next occurrence.
+func recordTaskUpdate(tx *sql.Tx, task *models.Task, previousStatus models.TaskStatus) error {
+	if err := trackCompletion(tx, task, previousStatus); err != nil {
+		return err
+	}
+
+	if err := events.Append(tx, events.TaskUpdated, task.ID, taskPayload(task)); err != nil {
+		return err
+	}
+
+	if task.Status != models.StatusCompleted || previousStatus == models.StatusCompleted {
+		return nil
+	}
+
+	if err := events.Append(tx, events.TaskCompleted, task.ID, taskPayload(task)); err != nil {
+		return err
+	}
+
+	if task.Recurrence != "" {
+		return scheduleNextOccurrence(tx, task)
+	}
+
+	return nil
+}
+
+// scheduleNextOccurrence creates the next task in a recurring series and
+// moves the recurrence onto it, so the completed task is not repeated again
+// if it is reopened and completed a second time.
+func scheduleNextOccurrence(tx *sql.Tx, completed *models.Task) error {
+	rule, err := recurrence.Parse(completed.Recurrence)
 	if err != nil {
-		return nil, 0, err
+		return err
 	}
 
-	return tasks, total, nil
+	// Step in the task's timezone so all-day and wall-clock times survive DST
+	loc, err := models.LoadTimezone(completed.Timezone)
+	if err != nil {
+		loc = time.UTC
+	}
+
+	previous := time.Now().In(loc)
+	if completed.DueDate != nil {
+		previous = completed.DueDate.In(loc)
+	}
+	dueDate := rule.Next(previous)
+
+	next := *completed
+	next.ID = uuid.New().String()
+	next.Status = models.StatusPending
+	next.DueDate = &dueDate
+	next.CompletedAt = nil
+	next.StartDate = nil
+	next.CreatedAt = time.Now()
+	next.UpdatedAt = time.Now()
+
+	if err := insertTask(tx, &next); err != nil {
+		return err
+	}
+
+	_, err = tx.Exec("UPDATE tasks SET recurrence = '' WHERE id = $1", completed.ID)
+	if err != nil {
+		return err
+	}
+	completed.Recurrence = ""
+
+	return nil
 }
 
-func (r *PostgresRepository) ListUserTasks(userID string, page, pageSize int, status models.TaskStatus) ([]*models.Task, int, error) {
-	offset := (page - 1) * pageSize
+func (r *PostgresRepository) DeleteTask(id string) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
 
-	query := "SELECT id, title, description, status, priority, user_id, due_date, created_at, updated_at FROM tasks WHERE user_id = $1"
-	countQuery := "SELECT COUNT(*) FROM tasks WHERE user_id = $1"
-	args := []interface{}{userID}
+	task, err := getTask(tx, id)
+	if err != nil {
+		return err
+	}
+
+	if _, err := tx.Exec("DELETE FROM tasks WHERE id = $1", id); err != nil {
+		return err
+	}
 
-	if status != "" {
-		query += " AND status = $2"
-		countQuery += " AND status = $2"
-		args = append(args, status)
+	if err := events.Append(tx, events.TaskDeleted, task.ID, taskPayload(task)); err != nil {
+		return err
 	}
 
-	query += " ORDER BY created_at DESC LIMIT $" + fmt.Sprintf("%d", len(args)+1) + " OFFSET $" + fmt.Sprintf("%d", len(args)+2)
-	args = append(args, pageSize, offset)
+	return tx.Commit()
+}
+
+// userDataStatements delete what a user leaves behind besides their tasks:
+// their settings, views, templates and rules, their part in other users'
+// tasks, and the published events about their tasks. Escalation rules that
+// notify the user go too, having nobody left to notify.
+var userDataStatements = []string{
+	"DELETE FROM user_settings WHERE user_id = $1",
+	"DELETE FROM saved_views WHERE user_id = $1",
+	"DELETE FROM task_templates WHERE user_id = $1",
+	"DELETE FROM escalation_rules WHERE user_id = $1 OR notify_user_id = $1",
+	"UPDATE task_escalations SET notified_user_ids = array_remove(notified_user_ids, $1) WHERE $1 = ANY(notified_user_ids)",
+	"DELETE FROM task_watchers WHERE user_id = $1",
+	"DELETE FROM automation_rules WHERE user_id = $1",
+	"DELETE FROM time_entries WHERE user_id = $1",
+	"DELETE FROM task_comments WHERE user_id = $1",
+	"DELETE FROM outbox_events WHERE published_at IS NOT NULL AND COALESCE(payload->'task'->>'user_id', payload->>'user_id') = $1",
+}
 
-	rows, err := r.db.Query(query, args...)
+// DeleteUserTasks removes every task owned by a user, together with
+// everything else kept for them, and returns how many tasks were deleted.
+// The user leaves their projects; see leaveProjects.
+func (r *PostgresRepository) DeleteUserTasks(userID string) (int, error) {
+	tx, err := r.db.Begin()
 	if err != nil {
-		return nil, 0, err
+		return 0, err
 	}
-	defer rows.Close()
+	defer tx.Rollback()
 
-	var tasks []*models.Task
-	for rows.Next() {
-		task := &models.Task{}
-		var dueDate sql.NullTime
+	var deleted []*models.Task
+	for _, table := range []string{"tasks", "archived_tasks"} {
+		rows, err := tx.Query("DELETE FROM "+table+" WHERE user_id = $1 RETURNING "+taskColumns, userID)
+		if err != nil {
+			return 0, err
+		}
 
-		err := rows.Scan(&task.ID, &task.Title, &task.Description, &task.Status, &task.Priority, &task.UserID, &dueDate, &task.CreatedAt, &task.UpdatedAt)
+		tasks, err := scanTasks(rows)
 		if err != nil {
-			return nil, 0, err
+			return 0, err
 		}
+		deleted = append(deleted, tasks...)
+	}
 
-		if dueDate.Valid {
-			task.DueDate = &dueDate.Time
+	for _, task := range deleted {
+		if err := events.Append(tx, events.TaskDeleted, task.ID, taskPayload(task)); err != nil {
+			return 0, err
 		}
+	}
 
-		tasks = append(tasks, task)
+	for _, statement := range userDataStatements {
+		if _, err := tx.Exec(statement, userID); err != nil {
+			return 0, err
+		}
+	}
+
+	if err := leaveProjects(tx, userID); err != nil {
+		return 0, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, err
+	}
+
+	return len(deleted), nil
+}
+
+func (r *PostgresRepository) ListTasks(page, pageSize int) ([]*models.Task, int, error) {
+	offset := (page - 1) * pageSize
+
+	rows, err := r.db.Query(
+		"SELECT "+taskColumns+" FROM tasks ORDER BY created_at DESC LIMIT $1 OFFSET $2",
+		pageSize, offset,
+	)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	tasks, err := scanTasks(rows)
+	if err != nil {
+		return nil, 0, err
 	}
 
 	var total int
-	countArgs := args[:len(args)-2] // Remove limit and offset
-	err = r.db.QueryRow(countQuery, countArgs...).Scan(&total)
+	err = r.db.QueryRow("SELECT COUNT(*) FROM tasks").Scan(&total)
 	if err != nil {
 		return nil, 0, err
 	}
@@ -211,6 +524,28 @@ func (r *PostgresRepository) ListUserTasks(userID string, page, pageSize int, st
 	return tasks, total, nil
 }
 
+// ListAllUserTasks returns every task owned by a user, oldest first.
+func (r *PostgresRepository) ListAllUserTasks(userID string) ([]*models.Task, error) {
+	rows, err := r.db.Query("SELECT "+taskColumns+" FROM all_tasks WHERE user_id = $1 ORDER BY created_at", userID)
+	if err != nil {
+		return nil, err
+	}
+
+	return scanTasks(rows)
+}
+
+func taskPayload(task *models.Task) events.TaskPayload {
+	return events.TaskPayload{
+		ID:        task.ID,
+		Title:     task.Title,
+		Status:    string(task.Status),
+		Priority:  string(task.Priority),
+		UserID:    task.UserID,
+		ProjectID: task.ProjectID,
+		DueDate:   task.DueDate,
+	}
+}
+
 func (r *PostgresRepository) Close() error {
 	return r.db.Close()
 }
@@ -0,0 +1,156 @@
+package repository
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/todo/services/task-service/internal/models"
+)
+
+// newTestRepository connects to the database in TEST_DATABASE_URL, skipping
+// the test when it is not set. Tests keep to rows of their own users so they
+// can share the database.
+func newTestRepository(t *testing.T) *PostgresRepository {
+	t.Helper()
+
+	connStr := os.Getenv("TEST_DATABASE_URL")
+	if connStr == "" {
+		t.Skip("TEST_DATABASE_URL is not set")
+	}
+
+	repo, err := NewPostgresRepository(connStr)
+	if err != nil {
+		t.Fatalf("NewPostgresRepository: %v", err)
+	}
+	t.Cleanup(func() { repo.Close() })
+
+	return repo
+}
+
+func countRows(t *testing.T, repo *PostgresRepository, query string, args ...interface{}) int {
+	t.Helper()
+
+	var n int
+	if err := repo.db.QueryRow(query, args...).Scan(&n); err != nil {
+		t.Fatalf("%s: %v", query, err)
+	}
+	return n
+}
+
+func TestDeleteUserTasks(t *testing.T) {
+	repo := newTestRepository(t)
+
+	deleted := uuid.New().String()
+	other := uuid.New().String()
+	shared := uuid.New().String()
+	solo := uuid.New().String()
+	t.Cleanup(func() { repo.DeleteUserTasks(other) })
+
+	mustJoin := func(projectID, userID string) {
+		t.Helper()
+		if member, err := repo.JoinProject(projectID, userID); err != nil || !member {
+			t.Fatalf("JoinProject(%s, %s) = %v, %v", projectID, userID, member, err)
+		}
+	}
+	mustCreate := func(userID, projectID string) *models.Task {
+		t.Helper()
+		task, err := repo.CreateTask(&models.Task{Title: "Task", UserID: userID, ProjectID: projectID})
+		if err != nil {
+			t.Fatalf("CreateTask: %v", err)
+		}
+		return task
+	}
+
+	// The deleted user owns a shared project and one of their own
+	mustJoin(shared, deleted)
+	if err := repo.AddProjectMember(shared, other); err != nil {
+		t.Fatalf("AddProjectMember: %v", err)
+	}
+	mustJoin(solo, deleted)
+
+	own := mustCreate(deleted, shared)
+	soloTask := mustCreate(deleted, solo)
+	theirs := mustCreate(other, shared)
+
+	// What they added to their own task, and someone else's part in it
+	if _, err := repo.AddChecklistItem(own.ID, "Step"); err != nil {
+		t.Fatalf("AddChecklistItem: %v", err)
+	}
+	if _, err := repo.AddComment(own.ID, other, "On your task"); err != nil {
+		t.Fatalf("AddComment: %v", err)
+	}
+
+	// What they added to the other user's task
+	if _, err := repo.AddComment(theirs.ID, deleted, "On their task"); err != nil {
+		t.Fatalf("AddComment: %v", err)
+	}
+	if err := repo.AddWatcher(theirs.ID, deleted); err != nil {
+		t.Fatalf("AddWatcher: %v", err)
+	}
+	if _, err := repo.CreateTimeEntry(theirs.ID, deleted, time.Now().Add(-time.Hour), time.Now(), "Helping"); err != nil {
+		t.Fatalf("CreateTimeEntry: %v", err)
+	}
+
+	// What they kept for themselves
+	if _, err := repo.CreateView(deleted, "Mine", models.TaskFilter{}, models.TaskSort{}); err != nil {
+		t.Fatalf("CreateView: %v", err)
+	}
+	if _, err := repo.UpdateUserSettings(deleted, "Europe/Berlin"); err != nil {
+		t.Fatalf("UpdateUserSettings: %v", err)
+	}
+
+	count, err := repo.DeleteUserTasks(deleted)
+	if err != nil {
+		t.Fatalf("DeleteUserTasks: %v", err)
+	}
+	if count != 2 {
+		t.Errorf("deleted %d tasks, want 2", count)
+	}
+
+	for _, task := range []*models.Task{own, soloTask} {
+		if _, err := repo.GetTaskByID(task.ID); err == nil {
+			t.Errorf("task %s still exists", task.ID)
+		}
+	}
+	if _, err := repo.GetTaskByID(theirs.ID); err != nil {
+		t.Errorf("the other user's task is gone: %v", err)
+	}
+
+	// Nothing is left hanging off the deleted tasks
+	if n := countRows(t, repo, "SELECT COUNT(*) FROM checklist_items WHERE task_id = $1", own.ID); n != 0 {
+		t.Errorf("%d checklist items left on a deleted task", n)
+	}
+	if n := countRows(t, repo, "SELECT COUNT(*) FROM task_comments WHERE task_id = $1", own.ID); n != 0 {
+		t.Errorf("%d comments left on a deleted task", n)
+	}
+
+	// Nor anything of theirs anywhere else
+	data, err := repo.ExportUserData(deleted)
+	if err != nil {
+		t.Fatalf("ExportUserData: %v", err)
+	}
+	if len(data.Tasks) != 0 || len(data.Comments) != 0 || len(data.TimeEntries) != 0 || len(data.WatchedTaskIDs) != 0 || len(data.SavedViews) != 0 {
+		t.Errorf("data left after deletion: %d tasks, %d comments, %d time entries, %d watches, %d views",
+			len(data.Tasks), len(data.Comments), len(data.TimeEntries), len(data.WatchedTaskIDs), len(data.SavedViews))
+	}
+	if data.Settings.Timezone != "UTC" {
+		t.Errorf("settings left after deletion: timezone %s", data.Settings.Timezone)
+	}
+
+	// The shared project passes to the remaining member; the other goes
+	owner, err := repo.GetProjectOwner(shared)
+	if err != nil {
+		t.Fatalf("GetProjectOwner: %v", err)
+	}
+	if owner != other {
+		t.Errorf("shared project is owned by %s, want %s", owner, other)
+	}
+	if member, err := repo.IsProjectMember(shared, deleted); err != nil || member {
+		t.Errorf("IsProjectMember = %v, %v; want the deleted user gone", member, err)
+	}
+	if _, err := repo.GetProjectOwner(solo); err == nil {
+		t.Error("project without other members still exists")
+	}
+}
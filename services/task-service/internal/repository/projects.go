@@ -0,0 +1,201 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/todo/services/task-service/internal/models"
+)
+
+// Projects are claimed by the first user to put a task in one, who becomes
+// its owner. Projects that predate membership are backfilled once: everyone
+// with a task in one joins it, and the author of its oldest task owns it.
+// Automation rules from before rules had owners go to the project owner.
+const projectsSchema = `
+	CREATE TABLE IF NOT EXISTS projects (
+		id VARCHAR(36) PRIMARY KEY,
+		owner_id VARCHAR(36) NOT NULL,
+		created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+	);
+
+	CREATE TABLE IF NOT EXISTS project_members (
+		project_id VARCHAR(36) NOT NULL REFERENCES projects(id) ON DELETE CASCADE,
+		user_id VARCHAR(36) NOT NULL,
+		created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+		PRIMARY KEY (project_id, user_id)
+	);
+
+	CREATE INDEX IF NOT EXISTS idx_project_members_user_id ON project_members(user_id);
+
+	INSERT INTO projects (id, owner_id, created_at)
+	SELECT DISTINCT ON (project_id) project_id, user_id, created_at FROM all_tasks
+	WHERE project_id IS NOT NULL AND NOT EXISTS (SELECT 1 FROM projects)
+	ORDER BY project_id, created_at;
+
+	INSERT INTO project_members (project_id, user_id, created_at)
+	SELECT project_id, user_id, MIN(created_at) FROM all_tasks
+	WHERE project_id IS NOT NULL AND NOT EXISTS (SELECT 1 FROM project_members)
+	GROUP BY project_id, user_id;
+
+	UPDATE automation_rules r SET user_id = p.owner_id
+	FROM projects p WHERE p.id = r.project_id AND r.user_id = '';
+`
+
+// IsProjectMember reports whether a user is a member of a project.
+func (r *PostgresRepository) IsProjectMember(projectID, userID string) (bool, error) {
+	return isProjectMember(r.db, projectID, userID)
+}
+
+func isProjectMember(db queryRower, projectID, userID string) (bool, error) {
+	var member bool
+	err := db.QueryRow(
+		"SELECT EXISTS(SELECT 1 FROM project_members WHERE project_id = $1 AND user_id = $2)",
+		projectID, userID,
+	).Scan(&member)
+	return member, err
+}
+
+// JoinProject checks that a user may put tasks in a project. A project that
+// nobody has claimed yet is claimed for the user, who becomes its owner.
+func (r *PostgresRepository) JoinProject(projectID, userID string) (bool, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback()
+
+	result, err := tx.Exec(
+		"INSERT INTO projects (id, owner_id) VALUES ($1, $2) ON CONFLICT (id) DO NOTHING",
+		projectID, userID,
+	)
+	if err != nil {
+		return false, err
+	}
+	claimed, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	if claimed == 1 {
+		if _, err := tx.Exec(
+			"INSERT INTO project_members (project_id, user_id) VALUES ($1, $2)",
+			projectID, userID,
+		); err != nil {
+			return false, err
+		}
+		return true, tx.Commit()
+	}
+
+	member, err := isProjectMember(tx, projectID, userID)
+	if err != nil {
+		return false, err
+	}
+	return member, tx.Commit()
+}
+
+// GetProjectOwner returns the ID of the user who owns a project.
+func (r *PostgresRepository) GetProjectOwner(projectID string) (string, error) {
+	var ownerID string
+	err := r.db.QueryRow("SELECT owner_id FROM projects WHERE id = $1", projectID).Scan(&ownerID)
+	if err == sql.ErrNoRows {
+		return "", fmt.Errorf("project not found")
+	}
+	return ownerID, err
+}
+
+// ListProjectMembers returns a project's members, the owner first.
+func (r *PostgresRepository) ListProjectMembers(projectID string) ([]*models.ProjectMember, error) {
+	rows, err := r.db.Query(
+		`SELECT m.project_id, m.user_id, m.user_id = p.owner_id, m.created_at
+		 FROM project_members m JOIN projects p ON p.id = m.project_id
+		 WHERE m.project_id = $1
+		 ORDER BY m.user_id = p.owner_id DESC, m.created_at`,
+		projectID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	members := []*models.ProjectMember{}
+	for rows.Next() {
+		member := &models.ProjectMember{Role: models.ProjectRoleMember}
+		var owner bool
+		if err := rows.Scan(&member.ProjectID, &member.UserID, &owner, &member.CreatedAt); err != nil {
+			return nil, err
+		}
+		if owner {
+			member.Role = models.ProjectRoleOwner
+		}
+		members = append(members, member)
+	}
+
+	return members, rows.Err()
+}
+
+// AddProjectMember adds a user to a project. Adding a member again is a
+// no-op.
+func (r *PostgresRepository) AddProjectMember(projectID, userID string) error {
+	_, err := r.db.Exec(
+		"INSERT INTO project_members (project_id, user_id) VALUES ($1, $2) ON CONFLICT DO NOTHING",
+		projectID, userID,
+	)
+	return err
+}
+
+// RemoveProjectMember removes a user from a project. The owner can't be
+// removed. Their tasks stay in the project.
+func (r *PostgresRepository) RemoveProjectMember(projectID, userID string) error {
+	result, err := r.db.Exec(
+		`DELETE FROM project_members m USING projects p
+		 WHERE p.id = m.project_id AND m.project_id = $1 AND m.user_id = $2 AND p.owner_id <> $2`,
+		projectID, userID,
+	)
+	if err != nil {
+		return err
+	}
+
+	n, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return fmt.Errorf("project member not found")
+	}
+	return nil
+}
+
+// leaveProjects takes a deleted user out of every project. Projects they own
+// pass to the member who joined first after them; those with no other member
+// are deleted along with their custom fields, automation rules and
+// escalation setting.
+func leaveProjects(tx *sql.Tx, userID string) error {
+	_, err := tx.Exec(
+		`UPDATE projects p SET owner_id = m.user_id
+		 FROM (
+			SELECT DISTINCT ON (project_id) project_id, user_id FROM project_members
+			WHERE user_id <> $1 ORDER BY project_id, created_at, user_id
+		 ) m
+		 WHERE m.project_id = p.id AND p.owner_id = $1`,
+		userID,
+	)
+	if err != nil {
+		return err
+	}
+
+	for _, table := range []string{"custom_field_definitions", "automation_rules", "project_escalation_settings"} {
+		if _, err := tx.Exec(
+			"DELETE FROM "+table+" WHERE project_id IN (SELECT id FROM projects WHERE owner_id = $1)",
+			userID,
+		); err != nil {
+			return err
+		}
+	}
+
+	if _, err := tx.Exec("DELETE FROM projects WHERE owner_id = $1", userID); err != nil {
+		return err
+	}
+
+	_, err = tx.Exec("DELETE FROM project_members WHERE user_id = $1", userID)
+	return err
+}
@@ -0,0 +1,222 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/todo/services/task-service/internal/models"
+)
+
+// Tasks completed before completed_at existed are backfilled from their last
+// update, the closest record of when they were closed.
+const reportsSchema = `
+	ALTER TABLE tasks ADD COLUMN IF NOT EXISTS completed_at TIMESTAMP;
+	UPDATE tasks SET completed_at = updated_at WHERE status = 'COMPLETED' AND completed_at IS NULL;
+	CREATE INDEX IF NOT EXISTS idx_tasks_completed_at ON tasks(user_id, completed_at);
+`
+
+// trackCompletion stamps completed_at when a task becomes completed and
+// clears it when the task is reopened.
+func trackCompletion(tx *sql.Tx, task *models.Task, previousStatus models.TaskStatus) error {
+	if (task.Status == models.StatusCompleted) == (previousStatus == models.StatusCompleted) {
+		return nil
+	}
+
+	var completedAt *time.Time
+	if task.Status == models.StatusCompleted {
+		now := time.Now()
+		completedAt = &now
+	}
+
+	if _, err := tx.Exec("UPDATE tasks SET completed_at = $2 WHERE id = $1", task.ID, completedAt); err != nil {
+		return err
+	}
+	task.CompletedAt = completedAt
+
+	return nil
+}
+
+// reportScope selects the user's or project's tasks a report covers,
+// archived ones included.
+func reportScope(filter models.ReportFilter) *taskQuery {
+	q := &taskQuery{}
+	if filter.UserID != "" {
+		q.add("user_id = $%d", filter.UserID)
+	}
+	if filter.ProjectID != "" {
+		q.add("project_id = $%d", filter.ProjectID)
+	}
+	return q
+}
+
+// reportTimestamps formats boundaries for a timestamp[] parameter. Task
+// timestamps are stored as UTC without a zone.
+func reportTimestamps(times []time.Time) pq.StringArray {
+	formatted := make(pq.StringArray, len(times))
+	for i, t := range times {
+		formatted[i] = t.UTC().Format("2006-01-02 15:04:05.999999")
+	}
+	return formatted
+}
+
+func (r *PostgresRepository) ReportSummary(filter models.ReportFilter, now time.Time) (*models.ReportSummary, error) {
+	// Due dates are zoned, unlike the other task timestamps
+	dueBefore := filter.To
+	if now.Before(dueBefore) {
+		dueBefore = now
+	}
+
+	q := reportScope(filter)
+	from, to := q.param(filter.From.UTC()), q.param(filter.To.UTC())
+	dueFrom, dueTo := q.param(filter.From), q.param(dueBefore)
+
+	completedInRange := "completed_at >= " + from + " AND completed_at < " + to
+	cycleTime := "EXTRACT(EPOCH FROM completed_at - created_at)"
+	dueInRange := "status <> 'CANCELLED' AND due_date >= " + dueFrom + "::timestamptz AND due_date < " + dueTo + "::timestamptz"
+
+	summary := &models.ReportSummary{}
+	err := r.db.QueryRow(
+		"SELECT COUNT(*) FILTER (WHERE created_at >= "+from+" AND created_at < "+to+"),"+
+			" COUNT(*) FILTER (WHERE "+completedInRange+"),"+
+			" COALESCE(AVG("+cycleTime+") FILTER (WHERE "+completedInRange+"), 0)::BIGINT,"+
+			" COALESCE(PERCENTILE_CONT(0.5) WITHIN GROUP (ORDER BY "+cycleTime+") FILTER (WHERE "+completedInRange+"), 0)::BIGINT,"+
+			" COUNT(*) FILTER (WHERE "+dueInRange+"),"+
+			" COUNT(*) FILTER (WHERE "+dueInRange+" AND (completed_at IS NULL OR completed_at AT TIME ZONE 'UTC' > due_date))"+
+			" FROM all_tasks"+q.where(),
+		q.args...,
+	).Scan(&summary.Created, &summary.Completed, &summary.AverageCycleTimeSeconds, &summary.MedianCycleTimeSeconds, &summary.Due, &summary.Overdue)
+	if err != nil {
+		return nil, err
+	}
+
+	if summary.Due > 0 {
+		summary.OverdueRate = float64(summary.Overdue) / float64(summary.Due)
+	}
+
+	return summary, nil
+}
+
+// Throughput counts the tasks created and completed in each period.
+func (r *PostgresRepository) Throughput(filter models.ReportFilter, interval models.ReportInterval) ([]*models.ThroughputPoint, error) {
+	periods, err := filter.Periods(interval)
+	if err != nil {
+		return nil, err
+	}
+
+	starts := make([]time.Time, len(periods))
+	ends := make([]time.Time, len(periods))
+	for i, period := range periods {
+		starts[i], ends[i] = period.Start, period.End
+	}
+
+	q := reportScope(filter)
+	where := q.where()
+	startsParam, endsParam := q.param(reportTimestamps(starts)), q.param(reportTimestamps(ends))
+
+	rows, err := r.db.Query(
+		"SELECT (SELECT COUNT(*) FROM all_tasks"+where+" AND created_at >= p.start AND created_at < p.finish),"+
+			" (SELECT COUNT(*) FROM all_tasks"+where+" AND completed_at >= p.start AND completed_at < p.finish)"+
+			" FROM UNNEST("+startsParam+"::timestamp[], "+endsParam+"::timestamp[]) WITH ORDINALITY AS p(start, finish, n) ORDER BY p.n",
+		q.args...,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	points := make([]*models.ThroughputPoint, 0, len(periods))
+	for i := 0; rows.Next(); i++ {
+		point := &models.ThroughputPoint{Period: periods[i].Label}
+		if err := rows.Scan(&point.Created, &point.Completed); err != nil {
+			return nil, err
+		}
+		points = append(points, point)
+	}
+
+	return points, rows.Err()
+}
+
+// Distribution counts the tasks open at some point in the range, that is
+// created before its end and not completed before its start, by priority,
+// status or project.
+func (r *PostgresRepository) Distribution(filter models.ReportFilter, by models.DistributionDimension) ([]*models.DistributionBucket, error) {
+	var key string
+	switch by {
+	case models.DistributionPriority:
+		key = "priority"
+	case models.DistributionStatus:
+		key = "status"
+	case models.DistributionProject:
+		key = "COALESCE(project_id, '')"
+	default:
+		return nil, fmt.Errorf("invalid dimension: %s", by)
+	}
+
+	q := reportScope(filter)
+	q.add("created_at < $%d", filter.To.UTC())
+	q.add("(completed_at IS NULL OR completed_at >= $%d)", filter.From.UTC())
+
+	rows, err := r.db.Query(
+		"SELECT "+key+", COUNT(*) FROM all_tasks"+q.where()+" GROUP BY "+key+" ORDER BY COUNT(*) DESC, "+key,
+		q.args...,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	buckets := []*models.DistributionBucket{}
+	for rows.Next() {
+		bucket := &models.DistributionBucket{}
+		if err := rows.Scan(&bucket.Key, &bucket.Count); err != nil {
+			return nil, err
+		}
+		buckets = append(buckets, bucket)
+	}
+
+	return buckets, rows.Err()
+}
+
+// Burndown samples the open and completed task counts at the end of each
+// day in the range. Cancelled tasks are left out entirely since the time
+// they were cancelled is not recorded.
+func (r *PostgresRepository) Burndown(filter models.ReportFilter) ([]*models.BurndownPoint, error) {
+	days, err := filter.Periods(models.IntervalDay)
+	if err != nil {
+		return nil, err
+	}
+
+	ends := make([]time.Time, len(days))
+	for i, day := range days {
+		ends[i] = day.End
+	}
+
+	q := reportScope(filter)
+	q.add("status <> $%d", models.StatusCancelled)
+	where := q.where()
+	from, endsParam := q.param(filter.From.UTC()), q.param(reportTimestamps(ends))
+
+	rows, err := r.db.Query(
+		"SELECT (SELECT COUNT(*) FROM all_tasks"+where+" AND created_at < p.day_end AND (completed_at IS NULL OR completed_at >= p.day_end)),"+
+			" (SELECT COUNT(*) FROM all_tasks"+where+" AND completed_at >= "+from+" AND completed_at < p.day_end)"+
+			" FROM UNNEST("+endsParam+"::timestamp[]) WITH ORDINALITY AS p(day_end, n) ORDER BY p.n",
+		q.args...,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	points := make([]*models.BurndownPoint, 0, len(days))
+	for i := 0; rows.Next(); i++ {
+		point := &models.BurndownPoint{Date: days[i].Label}
+		if err := rows.Scan(&point.Open, &point.Completed); err != nil {
+			return nil, err
+		}
+		points = append(points, point)
+	}
+
+	return points, rows.Err()
+}
@@ -0,0 +1,95 @@
+package repository
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/todo/services/task-service/internal/models"
+)
+
+const userSettingsSchema = `
+	CREATE TABLE IF NOT EXISTS user_settings (
+		user_id VARCHAR(36) PRIMARY KEY,
+		timezone VARCHAR(64) NOT NULL,
+		updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+	);
+`
+
+// GetUserSettings returns the user's settings, with a UTC timezone when the
+// user has not saved any.
+func (r *PostgresRepository) GetUserSettings(userID string) (*models.UserSettings, error) {
+	settings := &models.UserSettings{UserID: userID}
+	err := r.db.QueryRow(
+		"SELECT timezone, updated_at FROM user_settings WHERE user_id = $1",
+		userID,
+	).Scan(&settings.Timezone, &settings.UpdatedAt)
+	if err == sql.ErrNoRows {
+		settings.Timezone = time.UTC.String()
+		return settings, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return settings, nil
+}
+
+// UpdateUserSettings saves the user's settings after validating the timezone.
+func (r *PostgresRepository) UpdateUserSettings(userID, timezone string) (*models.UserSettings, error) {
+	loc, err := models.LoadTimezone(timezone)
+	if err != nil {
+		return nil, err
+	}
+
+	settings := &models.UserSettings{UserID: userID, Timezone: loc.String(), UpdatedAt: time.Now()}
+	_, err = r.db.Exec(
+		`INSERT INTO user_settings (user_id, timezone, updated_at) VALUES ($1, $2, $3)
+		 ON CONFLICT (user_id) DO UPDATE SET timezone = EXCLUDED.timezone, updated_at = EXCLUDED.updated_at`,
+		settings.UserID, settings.Timezone, settings.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return settings, nil
+}
+
+// UserTimezone returns name, or when it is empty the user's saved timezone.
+// It is empty when neither is set.
+func (r *PostgresRepository) UserTimezone(userID, name string) (string, error) {
+	return savedTimezone(r.db, "SELECT timezone FROM user_settings WHERE user_id = $1", userID, name)
+}
+
+// TaskTimezone returns name, or when it is empty the saved timezone of the
+// task's owner.
+func (r *PostgresRepository) TaskTimezone(taskID, name string) (string, error) {
+	return savedTimezone(r.db,
+		`SELECT s.timezone FROM user_settings s
+		 JOIN all_tasks t ON t.user_id = s.user_id
+		 WHERE t.id = $1`,
+		taskID, name,
+	)
+}
+
+func savedTimezone(db queryRower, query, id, name string) (string, error) {
+	if name != "" {
+		return name, nil
+	}
+
+	var timezone string
+	err := db.QueryRow(query, id).Scan(&timezone)
+	if err == sql.ErrNoRows {
+		return "", nil
+	}
+	return timezone, err
+}
+
+// FilterTimezone resolves the timezone a user's due filters are evaluated in:
+// name, else the user's saved timezone, else each task's own.
+func (r *PostgresRepository) FilterTimezone(userID, name string) (*time.Location, error) {
+	timezone, err := r.UserTimezone(userID, name)
+	if err != nil {
+		return nil, err
+	}
+	return models.FilterTimezone(timezone)
+}
@@ -0,0 +1,141 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/todo/pkg/events"
+	"github.com/todo/services/task-service/internal/models"
+)
+
+// wake_pending marks tasks snoozed with SnoozeTask whose owner has not yet
+// been told they are back.
+const snoozeSchema = `
+	ALTER TABLE tasks ADD COLUMN IF NOT EXISTS start_date TIMESTAMPTZ;
+	ALTER TABLE tasks ADD COLUMN IF NOT EXISTS wake_pending BOOLEAN NOT NULL DEFAULT FALSE;
+	CREATE INDEX IF NOT EXISTS idx_tasks_wake_pending ON tasks(start_date) WHERE wake_pending;
+`
+
+// SnoozeTask hides a task from default listings until the given time, or
+// the preset resolved in the task's timezone, when its owner is notified.
+// The due date is left alone.
+func (r *PostgresRepository) SnoozeTask(id string, preset models.SnoozePreset, until *time.Time, now time.Time) (*models.Task, error) {
+	return r.setStartDate(id, func(task *models.Task) (*time.Time, error) {
+		if until == nil {
+			loc, err := models.LoadTimezone(task.Timezone)
+			if err != nil {
+				return nil, err
+			}
+
+			resolved, err := models.SnoozeUntil(preset, now, loc)
+			if err != nil {
+				return nil, err
+			}
+			until = &resolved
+		}
+
+		if !until.After(now) {
+			return nil, fmt.Errorf("snooze time must be in the future")
+		}
+		return until, nil
+	})
+}
+
+// UnsnoozeTask brings a snoozed task back immediately, without notifying.
+func (r *PostgresRepository) UnsnoozeTask(id string) (*models.Task, error) {
+	return r.setStartDate(id, func(*models.Task) (*time.Time, error) {
+		return nil, nil
+	})
+}
+
+// setStartDate locks the task, asks startDate for its new start date and
+// stores it. A wake-up notification is pending whenever a start date is set.
+func (r *PostgresRepository) setStartDate(id string, startDate func(*models.Task) (*time.Time, error)) (*models.Task, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	task, err := scanTask(tx.QueryRow("SELECT "+taskColumns+" FROM tasks WHERE id = $1 FOR UPDATE", id))
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("task not found")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	start, err := startDate(task)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = tx.Exec(
+		"UPDATE tasks SET start_date = $2, wake_pending = $3, updated_at = $4 WHERE id = $1",
+		id, start, start != nil, time.Now(),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if task, err = getTask(tx, id); err != nil {
+		return nil, err
+	}
+
+	if err := recordTaskUpdate(tx, task, task.Status); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return task, nil
+}
+
+// WakeSnoozedTasks appends a TaskWoken event for up to limit snoozed tasks
+// whose start date has passed and returns how many it woke.
+func (r *PostgresRepository) WakeSnoozedTasks(now time.Time, limit int) (int, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	// SKIP LOCKED lets several replicas wake tasks without notifying twice
+	rows, err := tx.Query(
+		"SELECT "+taskColumns+" FROM tasks WHERE wake_pending AND start_date <= $1 ORDER BY start_date LIMIT $2 FOR UPDATE SKIP LOCKED",
+		now, limit,
+	)
+	if err != nil {
+		return 0, err
+	}
+
+	tasks, err := scanTasks(rows)
+	if err != nil {
+		return 0, err
+	}
+	if len(tasks) == 0 {
+		return 0, nil
+	}
+
+	ids := make([]string, len(tasks))
+	for i, task := range tasks {
+		if err := events.Append(tx, events.TaskWoken, task.ID, taskPayload(task)); err != nil {
+			return 0, err
+		}
+		ids[i] = task.ID
+	}
+
+	if _, err := tx.Exec("UPDATE tasks SET wake_pending = FALSE WHERE id = ANY($1)", pq.Array(ids)); err != nil {
+		return 0, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, err
+	}
+
+	return len(tasks), nil
+}
@@ -0,0 +1,177 @@
+package repository
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/todo/services/task-service/internal/models"
+)
+
+const defaultUpcomingDays = 7
+
+// taskQuery accumulates WHERE conditions and their positional arguments.
+type taskQuery struct {
+	conditions []string
+	args       []interface{}
+}
+
+func (q *taskQuery) add(condition string, args ...interface{}) {
+	placeholders := make([]interface{}, len(args))
+	for i, arg := range args {
+		q.args = append(q.args, arg)
+		placeholders[i] = len(q.args)
+	}
+	q.conditions = append(q.conditions, fmt.Sprintf(condition, placeholders...))
+}
+
+// param appends an argument used outside the WHERE clause and returns its
+// placeholder.
+func (q *taskQuery) param(arg interface{}) string {
+	q.args = append(q.args, arg)
+	return fmt.Sprintf("$%d", len(q.args))
+}
+
+func (q *taskQuery) where() string {
+	if len(q.conditions) == 0 {
+		return ""
+	}
+	return " WHERE " + strings.Join(q.conditions, " AND ")
+}
+
+// buildTaskFilter translates a TaskFilter into SQL conditions on the tasks
+// table. Day boundaries for due filters are computed in loc, or in each
+// task's own timezone when loc is nil.
+func buildTaskFilter(userID string, filter models.TaskFilter, loc *time.Location, now time.Time) *taskQuery {
+	q := &taskQuery{}
+	q.add("user_id = $%d", userID)
+
+	if len(filter.Statuses) > 0 {
+		statuses := make([]string, len(filter.Statuses))
+		for i, status := range filter.Statuses {
+			statuses[i] = string(status)
+		}
+		q.add("status = ANY($%d)", pq.Array(statuses))
+	}
+
+	if len(filter.Priorities) > 0 {
+		priorities := make([]string, len(filter.Priorities))
+		for i, priority := range filter.Priorities {
+			priorities[i] = string(priority)
+		}
+		q.add("priority = ANY($%d)", pq.Array(priorities))
+	}
+
+	if filter.ProjectID != "" {
+		q.add("project_id = $%d", filter.ProjectID)
+	}
+
+	if len(filter.Tags) > 0 {
+		q.add("tags @> $%d", pq.Array(models.NormalizeTags(filter.Tags)))
+	}
+
+	for key, value := range filter.CustomFields {
+		q.add("(custom_fields->>$%d = $%d OR custom_fields->$%[1]d ? $%[2]d)", key, value)
+	}
+
+	if filter.Search != "" {
+		q.add("(title ILIKE $%d OR description ILIKE $%[1]d)", "%"+filter.Search+"%")
+	}
+
+	switch filter.Snoozed {
+	case models.SnoozedExclude:
+		q.add("(start_date IS NULL OR start_date <= $%d)", now)
+	case models.SnoozedOnly:
+		q.add("start_date > $%d", now)
+	}
+
+	switch filter.Due {
+	case models.DueOverdue:
+		q.add("due_date < $%d", now)
+	case models.DueToday:
+		q.addDueDays(loc, now, 0, 1)
+	case models.DueUpcoming:
+		days := filter.UpcomingDays
+		if days == 0 {
+			days = defaultUpcomingDays
+		}
+		q.addDueDays(loc, now, 1, 1+days)
+	case models.DueNone:
+		q.add("due_date IS NULL")
+	}
+
+	return q
+}
+
+func taskOrderBy(sort models.TaskSort) string {
+	direction := "ASC"
+	if sort.Descending {
+		direction = "DESC"
+	}
+
+	switch sort.Field {
+	case models.SortUpdatedAt:
+		return "updated_at " + direction + ", id"
+	case models.SortDueDate:
+		return "due_date " + direction + " NULLS LAST, id"
+	case models.SortPriority:
+		return "CASE priority WHEN 'URGENT' THEN 4 WHEN 'HIGH' THEN 3 WHEN 'MEDIUM' THEN 2 ELSE 1 END " + direction + ", id"
+	case models.SortTitle:
+		return "title " + direction + ", id"
+	case models.SortCustomField:
+		// Keys are validated against models.ValidFieldKey, so they are safe to
+		// inline. JSONB ordering compares numbers numerically and strings as text.
+		return "custom_fields->'" + sort.CustomField + "' " + direction + " NULLS LAST, id"
+	case models.SortPosition:
+		return "list_rank " + direction + ", id"
+	case models.SortCreatedAt:
+		return "created_at " + direction + ", id"
+	default:
+		return "created_at DESC, id"
+	}
+}
+
+// taskSource is the table or view a filter reads from.
+func taskSource(filter models.TaskFilter) string {
+	if filter.IncludeArchived {
+		return "all_tasks"
+	}
+	return "tasks"
+}
+
+// QueryUserTasks returns a page of the user's tasks matching filter, ordered
+// by sort, along with the total number of matches.
+func (r *PostgresRepository) QueryUserTasks(userID string, filter models.TaskFilter, sort models.TaskSort, loc *time.Location, page, pageSize int) ([]*models.Task, int, error) {
+	q := buildTaskFilter(userID, filter, loc, time.Now())
+
+	var total int
+	if err := r.db.QueryRow("SELECT COUNT(*) FROM "+taskSource(filter)+q.where(), q.args...).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	offset := (page - 1) * pageSize
+	args := append(q.args, pageSize, offset)
+	rows, err := r.db.Query(
+		fmt.Sprintf("SELECT %s FROM %s%s ORDER BY %s LIMIT $%d OFFSET $%d", taskColumns, taskSource(filter), q.where(), taskOrderBy(sort), len(args)-1, len(args)),
+		args...,
+	)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	tasks, err := scanTasks(rows)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return tasks, total, nil
+}
+
+func (r *PostgresRepository) CountUserTasks(userID string, filter models.TaskFilter, loc *time.Location) (int, error) {
+	q := buildTaskFilter(userID, filter, loc, time.Now())
+
+	var total int
+	err := r.db.QueryRow("SELECT COUNT(*) FROM "+taskSource(filter)+q.where(), q.args...).Scan(&total)
+	return total, err
+}
@@ -0,0 +1,205 @@
+package repository
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/todo/services/task-service/internal/models"
+)
+
+const taskTemplatesSchema = `
+	CREATE TABLE IF NOT EXISTS task_templates (
+		id VARCHAR(36) PRIMARY KEY,
+		user_id VARCHAR(36) NOT NULL,
+		name VARCHAR(255) NOT NULL,
+		description TEXT NOT NULL DEFAULT '',
+		default_priority VARCHAR(50) NOT NULL DEFAULT 'MEDIUM',
+		tasks JSONB NOT NULL DEFAULT '[]',
+		created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
+		updated_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
+		UNIQUE (user_id, name)
+	);
+`
+
+const templateColumns = "id, user_id, name, description, default_priority, tasks, created_at, updated_at"
+
+func scanTemplate(row rowScanner) (*models.TaskTemplate, error) {
+	template := &models.TaskTemplate{}
+	var tasks []byte
+
+	err := row.Scan(&template.ID, &template.UserID, &template.Name, &template.Description, &template.DefaultPriority, &tasks, &template.CreatedAt, &template.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal(tasks, &template.Tasks); err != nil {
+		return nil, err
+	}
+
+	return template, nil
+}
+
+func (r *PostgresRepository) CreateTemplate(input *models.TaskTemplate) (*models.TaskTemplate, error) {
+	template := *input
+	template.ID = uuid.New().String()
+	template.CreatedAt = time.Now()
+	template.UpdatedAt = time.Now()
+
+	tasksJSON, err := json.Marshal(template.Tasks)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = r.db.Exec(
+		"INSERT INTO task_templates (id, user_id, name, description, default_priority, tasks, created_at, updated_at) VALUES ($1, $2, $3, $4, $5, $6, $7, $8)",
+		template.ID, template.UserID, template.Name, template.Description, template.DefaultPriority, tasksJSON, template.CreatedAt, template.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &template, nil
+}
+
+func (r *PostgresRepository) GetTemplate(id string) (*models.TaskTemplate, error) {
+	template, err := scanTemplate(r.db.QueryRow("SELECT "+templateColumns+" FROM task_templates WHERE id = $1", id))
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("template not found")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return template, nil
+}
+
+// UpdateTemplate replaces the editable fields of the template identified by
+// input.ID.
+func (r *PostgresRepository) UpdateTemplate(input *models.TaskTemplate) (*models.TaskTemplate, error) {
+	tasksJSON, err := json.Marshal(input.Tasks)
+	if err != nil {
+		return nil, err
+	}
+
+	template, err := scanTemplate(r.db.QueryRow(
+		"UPDATE task_templates SET name = $2, description = $3, default_priority = $4, tasks = $5, updated_at = $6 WHERE id = $1 RETURNING "+templateColumns,
+		input.ID, input.Name, input.Description, input.DefaultPriority, tasksJSON, time.Now(),
+	))
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("template not found")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return template, nil
+}
+
+func (r *PostgresRepository) DeleteTemplate(id string) error {
+	result, err := r.db.Exec("DELETE FROM task_templates WHERE id = $1", id)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("template not found")
+	}
+
+	return nil
+}
+
+func (r *PostgresRepository) ListTemplates(userID string) ([]*models.TaskTemplate, error) {
+	rows, err := r.db.Query("SELECT "+templateColumns+" FROM task_templates WHERE user_id = $1 ORDER BY name", userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	templates := []*models.TaskTemplate{}
+	for rows.Next() {
+		template, err := scanTemplate(rows)
+		if err != nil {
+			return nil, err
+		}
+		templates = append(templates, template)
+	}
+
+	return templates, rows.Err()
+}
+
+// InstantiateTemplate creates every task in a template, with their checklists,
+// for userID in one transaction. Due dates are offset from start in its
+// timezone, and are all-day when allDay is set.
+func (r *PostgresRepository) InstantiateTemplate(id, userID, projectID string, start time.Time, allDay bool) ([]*models.Task, error) {
+	template, err := r.GetTemplate(id)
+	if err != nil {
+		return nil, err
+	}
+
+	tx, err := r.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	tasks := make([]*models.Task, 0, len(template.Tasks))
+	for _, item := range template.Tasks {
+		task := &models.Task{
+			ID:          uuid.New().String(),
+			Title:       item.Title,
+			Description: item.Description,
+			Status:      models.StatusPending,
+			Priority:    item.Priority,
+			UserID:      userID,
+			ProjectID:   projectID,
+			Tags:        models.NormalizeTags(item.Tags),
+			Timezone:    start.Location().String(),
+			CreatedAt:   time.Now(),
+			UpdatedAt:   time.Now(),
+		}
+		if task.Priority == "" {
+			task.Priority = template.DefaultPriority
+		}
+		if item.DueOffsetDays != nil {
+			dueDate := start.AddDate(0, 0, *item.DueOffsetDays)
+			task.DueDate = &dueDate
+			task.DueAllDay = allDay
+		}
+
+		if err := insertTask(tx, task); err != nil {
+			return nil, err
+		}
+
+		task.Checklist = []*models.ChecklistItem{}
+		for i, title := range item.Checklist {
+			checklistItem, err := insertChecklistItem(tx, task.ID, title, i+1)
+			if err != nil {
+				return nil, err
+			}
+			task.Checklist = append(task.Checklist, checklistItem)
+		}
+
+		// Automations refresh the task from its row, which has no checklist
+		checklist := task.Checklist
+		if err := newAutomationChain().taskCreated(tx, task); err != nil {
+			return nil, err
+		}
+		task.Checklist = checklist
+
+		tasks = append(tasks, task)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return tasks, nil
+}
@@ -0,0 +1,296 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"github.com/todo/services/task-service/internal/models"
+)
+
+const timeEntriesSchema = `
+	CREATE TABLE IF NOT EXISTS time_entries (
+		id VARCHAR(36) PRIMARY KEY,
+		task_id VARCHAR(36) NOT NULL,
+		user_id VARCHAR(36) NOT NULL,
+		started_at TIMESTAMPTZ NOT NULL,
+		ended_at TIMESTAMPTZ,
+		note TEXT NOT NULL DEFAULT '',
+		manual BOOLEAN NOT NULL DEFAULT FALSE,
+		created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP
+	);
+	CREATE INDEX IF NOT EXISTS idx_time_entries_task_id ON time_entries(task_id);
+	CREATE INDEX IF NOT EXISTS idx_time_entries_user_started ON time_entries(user_id, started_at);
+	CREATE UNIQUE INDEX IF NOT EXISTS idx_time_entries_running ON time_entries(user_id) WHERE ended_at IS NULL;
+`
+
+const timeEntryColumns = "te.id, te.task_id, te.user_id, te.started_at, te.ended_at, te.note, te.manual, te.created_at"
+
+// uniqueViolation is the Postgres error code for a unique constraint failure.
+const uniqueViolation = "23505"
+
+func scanTimeEntry(row rowScanner, extra ...interface{}) (*models.TimeEntry, error) {
+	entry := &models.TimeEntry{}
+	var endedAt sql.NullTime
+
+	dest := []interface{}{&entry.ID, &entry.TaskID, &entry.UserID, &entry.StartedAt, &endedAt, &entry.Note, &entry.Manual, &entry.CreatedAt}
+	if err := row.Scan(append(dest, extra...)...); err != nil {
+		return nil, err
+	}
+
+	end := time.Now()
+	if endedAt.Valid {
+		entry.EndedAt = &endedAt.Time
+		end = endedAt.Time
+	}
+	entry.DurationSeconds = int64(end.Sub(entry.StartedAt).Seconds())
+
+	return entry, nil
+}
+
+// StartTimer starts a running timer on a task. A user can only have one
+// running timer at a time.
+func (r *PostgresRepository) StartTimer(taskID, userID, note string) (*models.TimeEntry, error) {
+	if _, err := r.GetTaskByID(taskID); err != nil {
+		return nil, err
+	}
+
+	entry := &models.TimeEntry{
+		ID:        uuid.New().String(),
+		TaskID:    taskID,
+		UserID:    userID,
+		StartedAt: time.Now(),
+		Note:      note,
+		CreatedAt: time.Now(),
+	}
+
+	_, err := r.db.Exec(
+		"INSERT INTO time_entries (id, task_id, user_id, started_at, note, manual, created_at) VALUES ($1, $2, $3, $4, $5, FALSE, $6)",
+		entry.ID, entry.TaskID, entry.UserID, entry.StartedAt, entry.Note, entry.CreatedAt,
+	)
+	if pqErr, ok := err.(*pq.Error); ok && pqErr.Code == uniqueViolation {
+		return nil, fmt.Errorf("a timer is already running")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return entry, nil
+}
+
+// StopTimer stops the user's running timer and returns the finished entry.
+func (r *PostgresRepository) StopTimer(userID string) (*models.TimeEntry, error) {
+	entry, err := scanTimeEntry(r.db.QueryRow(
+		"UPDATE time_entries te SET ended_at = $2 WHERE te.user_id = $1 AND te.ended_at IS NULL RETURNING "+timeEntryColumns,
+		userID, time.Now(),
+	))
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("no running timer")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return entry, nil
+}
+
+func (r *PostgresRepository) GetRunningTimer(userID string) (*models.TimeEntry, error) {
+	entry, err := scanTimeEntry(r.db.QueryRow(
+		"SELECT "+timeEntryColumns+" FROM time_entries te WHERE te.user_id = $1 AND te.ended_at IS NULL",
+		userID,
+	))
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("no running timer")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return entry, nil
+}
+
+// CreateTimeEntry records time that was tracked outside a timer.
+func (r *PostgresRepository) CreateTimeEntry(taskID, userID string, startedAt, endedAt time.Time, note string) (*models.TimeEntry, error) {
+	if !endedAt.After(startedAt) {
+		return nil, fmt.Errorf("ended_at must be after started_at")
+	}
+
+	if _, err := r.GetTaskByID(taskID); err != nil {
+		return nil, err
+	}
+
+	entry := &models.TimeEntry{
+		ID:              uuid.New().String(),
+		TaskID:          taskID,
+		UserID:          userID,
+		StartedAt:       startedAt,
+		EndedAt:         &endedAt,
+		DurationSeconds: int64(endedAt.Sub(startedAt).Seconds()),
+		Note:            note,
+		Manual:          true,
+		CreatedAt:       time.Now(),
+	}
+
+	_, err := r.db.Exec(
+		"INSERT INTO time_entries (id, task_id, user_id, started_at, ended_at, note, manual, created_at) VALUES ($1, $2, $3, $4, $5, $6, TRUE, $7)",
+		entry.ID, entry.TaskID, entry.UserID, entry.StartedAt, entry.EndedAt, entry.Note, entry.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return entry, nil
+}
+
+func (r *PostgresRepository) DeleteTimeEntry(id string) error {
+	result, err := r.db.Exec("DELETE FROM time_entries WHERE id = $1", id)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("time entry not found")
+	}
+
+	return nil
+}
+
+// timeFilterClause builds the WHERE clause for a TimeFilter over time_entries
+// aliased te joined with tasks aliased t.
+func timeFilterClause(filter models.TimeFilter) (string, []interface{}) {
+	var conditions []string
+	var args []interface{}
+
+	add := func(condition string, arg interface{}) {
+		args = append(args, arg)
+		conditions = append(conditions, fmt.Sprintf(condition, len(args)))
+	}
+
+	if filter.UserID != "" {
+		add("te.user_id = $%d", filter.UserID)
+	}
+	if filter.TaskID != "" {
+		add("te.task_id = $%d", filter.TaskID)
+	}
+	if filter.ProjectID != "" {
+		add("t.project_id = $%d", filter.ProjectID)
+	}
+	if filter.From != nil {
+		add("te.started_at >= $%d", *filter.From)
+	}
+	if filter.To != nil {
+		add("te.started_at < $%d", *filter.To)
+	}
+
+	if len(conditions) == 0 {
+		return "", nil
+	}
+
+	return " WHERE " + strings.Join(conditions, " AND "), args
+}
+
+func (r *PostgresRepository) ListTimeEntries(filter models.TimeFilter) ([]*models.TimeEntry, error) {
+	where, args := timeFilterClause(filter)
+
+	rows, err := r.db.Query(
+		"SELECT "+timeEntryColumns+" FROM time_entries te JOIN all_tasks t ON t.id = te.task_id"+where+" ORDER BY te.started_at",
+		args...,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var entries []*models.TimeEntry
+	for rows.Next() {
+		entry, err := scanTimeEntry(rows)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, entry)
+	}
+
+	return entries, rows.Err()
+}
+
+// SummarizeTime rolls tracked time up per task, project or day. Running
+// timers count up to now. Days are computed in the given IANA timezone.
+func (r *PostgresRepository) SummarizeTime(filter models.TimeFilter, groupBy models.TimeGrouping, timezone string) ([]*models.TimeTotal, error) {
+	where, args := timeFilterClause(filter)
+
+	var key, label string
+	switch groupBy {
+	case models.GroupByTask:
+		key, label = "t.id", "MAX(t.title)"
+	case models.GroupByProject:
+		key, label = "COALESCE(t.project_id, '')", "''"
+	case models.GroupByDay:
+		if timezone == "" {
+			timezone = "UTC"
+		}
+		if _, err := time.LoadLocation(timezone); err != nil {
+			return nil, fmt.Errorf("invalid timezone: %s", timezone)
+		}
+		args = append(args, timezone)
+		key, label = fmt.Sprintf("TO_CHAR(te.started_at AT TIME ZONE $%d, 'YYYY-MM-DD')", len(args)), "''"
+	default:
+		return nil, fmt.Errorf("invalid group_by: %s", groupBy)
+	}
+
+	rows, err := r.db.Query(
+		"SELECT "+key+", "+label+", SUM(EXTRACT(EPOCH FROM COALESCE(te.ended_at, NOW()) - te.started_at))::BIGINT, COUNT(*)"+
+			" FROM time_entries te JOIN all_tasks t ON t.id = te.task_id"+where+
+			" GROUP BY "+key+" ORDER BY "+key,
+		args...,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var totals []*models.TimeTotal
+	for rows.Next() {
+		total := &models.TimeTotal{}
+		if err := rows.Scan(&total.Key, &total.Label, &total.DurationSeconds, &total.Entries); err != nil {
+			return nil, err
+		}
+		totals = append(totals, total)
+	}
+
+	return totals, rows.Err()
+}
+
+// Timesheet lists time entries with their task details for export.
+func (r *PostgresRepository) Timesheet(filter models.TimeFilter) ([]*models.TimesheetRow, error) {
+	where, args := timeFilterClause(filter)
+
+	rows, err := r.db.Query(
+		"SELECT "+timeEntryColumns+", t.title, COALESCE(t.project_id, '') FROM time_entries te JOIN all_tasks t ON t.id = te.task_id"+where+" ORDER BY te.started_at",
+		args...,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var sheet []*models.TimesheetRow
+	for rows.Next() {
+		row := &models.TimesheetRow{}
+		entry, err := scanTimeEntry(rows, &row.TaskTitle, &row.ProjectID)
+		if err != nil {
+			return nil, err
+		}
+		row.TimeEntry = *entry
+		sheet = append(sheet, row)
+	}
+
+	return sheet, rows.Err()
+}
@@ -0,0 +1,175 @@
+package repository
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/todo/services/task-service/internal/models"
+)
+
+const savedViewsSchema = `
+	CREATE TABLE IF NOT EXISTS saved_views (
+		id VARCHAR(36) PRIMARY KEY,
+		user_id VARCHAR(36) NOT NULL,
+		name VARCHAR(255) NOT NULL,
+		filter JSONB NOT NULL DEFAULT '{}',
+		sort JSONB NOT NULL DEFAULT '{}',
+		created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+		updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+		UNIQUE (user_id, name)
+	);
+`
+
+func scanSavedView(row rowScanner) (*models.SavedView, error) {
+	view := &models.SavedView{}
+	var filter, sort []byte
+
+	if err := row.Scan(&view.ID, &view.UserID, &view.Name, &filter, &sort, &view.CreatedAt, &view.UpdatedAt); err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal(filter, &view.Filter); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(sort, &view.Sort); err != nil {
+		return nil, err
+	}
+
+	return view, nil
+}
+
+func (r *PostgresRepository) CreateView(userID, name string, filter models.TaskFilter, sort models.TaskSort) (*models.SavedView, error) {
+	view := &models.SavedView{
+		ID:        uuid.New().String(),
+		UserID:    userID,
+		Name:      name,
+		Filter:    filter,
+		Sort:      sort,
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+	}
+
+	filterJSON, err := json.Marshal(view.Filter)
+	if err != nil {
+		return nil, err
+	}
+	sortJSON, err := json.Marshal(view.Sort)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = r.db.Exec(
+		"INSERT INTO saved_views (id, user_id, name, filter, sort, created_at, updated_at) VALUES ($1, $2, $3, $4, $5, $6, $7)",
+		view.ID, view.UserID, view.Name, filterJSON, sortJSON, view.CreatedAt, view.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return view, nil
+}
+
+func (r *PostgresRepository) GetView(id string) (*models.SavedView, error) {
+	view, err := scanSavedView(r.db.QueryRow(
+		"SELECT id, user_id, name, filter, sort, created_at, updated_at FROM saved_views WHERE id = $1",
+		id,
+	))
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("view not found")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return view, nil
+}
+
+func (r *PostgresRepository) UpdateView(id, name string, filter models.TaskFilter, sort models.TaskSort) (*models.SavedView, error) {
+	filterJSON, err := json.Marshal(filter)
+	if err != nil {
+		return nil, err
+	}
+	sortJSON, err := json.Marshal(sort)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := r.db.Exec(
+		"UPDATE saved_views SET name = $2, filter = $3, sort = $4, updated_at = $5 WHERE id = $1",
+		id, name, filterJSON, sortJSON, time.Now(),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return nil, err
+	}
+	if rowsAffected == 0 {
+		return nil, fmt.Errorf("view not found")
+	}
+
+	return r.GetView(id)
+}
+
+func (r *PostgresRepository) DeleteView(id string) error {
+	result, err := r.db.Exec("DELETE FROM saved_views WHERE id = $1", id)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("view not found")
+	}
+
+	return nil
+}
+
+// ListViews returns the built-in views followed by the user's saved views.
+func (r *PostgresRepository) ListViews(userID string) ([]*models.SavedView, error) {
+	rows, err := r.db.Query(
+		"SELECT id, user_id, name, filter, sort, created_at, updated_at FROM saved_views WHERE user_id = $1 ORDER BY name",
+		userID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	views := append([]*models.SavedView{}, models.BuiltInViews...)
+	for rows.Next() {
+		view, err := scanSavedView(rows)
+		if err != nil {
+			return nil, err
+		}
+		views = append(views, view)
+	}
+
+	return views, rows.Err()
+}
+
+// ResolveView returns a built-in view by ID or the user's saved view.
+func (r *PostgresRepository) ResolveView(userID, id string) (*models.SavedView, error) {
+	if view := models.BuiltInView(id); view != nil {
+		return view, nil
+	}
+
+	view, err := r.GetView(id)
+	if err != nil {
+		return nil, err
+	}
+	if view.UserID != userID {
+		return nil, fmt.Errorf("view not found")
+	}
+
+	return view, nil
+}
@@ -1,14 +1,21 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"log"
 	"net"
 	"net/http"
 	"os"
+	"time"
 
 	"github.com/gorilla/mux"
+	"github.com/todo/pkg/auth"
+	"github.com/todo/pkg/events"
 	pb "github.com/todo/proto/user"
+	"github.com/todo/services/user-service/internal/cleanup"
+	"github.com/todo/services/user-service/internal/clients"
+	"github.com/todo/services/user-service/internal/export"
 	grpcServer "github.com/todo/services/user-service/internal/grpc"
 	httpHandler "github.com/todo/services/user-service/internal/http"
 	"github.com/todo/services/user-service/internal/repository"
@@ -24,6 +31,15 @@ func main() {
 	dbName := getEnv("DB_NAME", "user_db")
 	grpcPort := getEnv("GRPC_PORT", "50051")
 	httpPort := getEnv("HTTP_PORT", "8081")
+	eventSubscribers := getEnv("EVENT_SUBSCRIBERS", "")
+	taskServiceAddr := getEnv("TASK_SERVICE_ADDR", "localhost:50053")
+	authServiceAddr := getEnv("AUTH_SERVICE_ADDR", "localhost:50052")
+	notificationServiceAddr := getEnv("NOTIFICATION_SERVICE_ADDR", "localhost:50054")
+	authJWKSURL := getEnv("AUTH_JWKS_URL", "")
+	serviceToken := getEnv("SERVICE_TOKEN", "")
+	if err := auth.CheckServiceToken(serviceToken); err != nil {
+		log.Fatalf("Refusing to start: %v", err)
+	}
 
 	// Connect to database
 	connStr := fmt.Sprintf("host=%s port=%s user=%s password=%s dbname=%s sslmode=disable",
@@ -35,6 +51,38 @@ func main() {
 	}
 	defer repo.Close()
 
+	// Authenticate callers with auth-service tokens or the shared service token
+	validator, err := auth.NewValidator(authJWKSURL, authServiceAddr, serviceToken)
+	if err != nil {
+		log.Fatalf("Failed to create token validator: %v", err)
+	}
+
+	// Publish outbox events to subscribers. Without any, events are kept in
+	// the outbox until some are configured.
+	subscribers := events.ParseEndpoints(eventSubscribers)
+	if len(subscribers) == 0 {
+		log.Printf("EVENT_SUBSCRIBERS is not set; events are kept in the outbox until it is")
+	} else {
+		publisher := events.NewHTTPPublisher(subscribers, serviceToken)
+		relay := events.NewRelay(repo.DB(), publisher, 2*time.Second)
+		go relay.Run(context.Background())
+	}
+
+	// Connect to the services that hold user-owned data
+	serviceClients, err := clients.NewClients(taskServiceAddr, authServiceAddr, notificationServiceAddr, serviceToken)
+	if err != nil {
+		log.Fatalf("Failed to create service clients: %v", err)
+	}
+	defer serviceClients.Close()
+
+	// Clean up deleted users' data in other services
+	orchestrator := cleanup.NewOrchestrator(repo, serviceClients, 5*time.Second)
+	go orchestrator.Run(context.Background())
+
+	// Build queued data exports
+	exporter := export.NewExporter(repo, serviceClients, 5*time.Second)
+	go exporter.Run(context.Background())
+
 	// Start gRPC server
 	go func() {
 		lis, err := net.Listen("tcp", ":"+grpcPort)
@@ -42,7 +90,11 @@ func main() {
 			log.Fatalf("Failed to listen on gRPC port: %v", err)
 		}
 
-		s := grpc.NewServer()
+		// Signing up needs no credentials
+		s := grpc.NewServer(
+			grpc.UnaryInterceptor(auth.UnaryServerInterceptor(validator, pb.UserService_CreateUser_FullMethodName)),
+			grpc.StreamInterceptor(auth.StreamServerInterceptor(validator, pb.UserService_CreateUser_FullMethodName)),
+		)
 		pb.RegisterUserServiceServer(s, grpcServer.NewUserServer(repo))
 
 		log.Printf("gRPC server listening on :%s", grpcPort)
@@ -53,6 +105,9 @@ func main() {
 
 	// Start HTTP server
 	router := mux.NewRouter()
+	router.Use(auth.Middleware(validator, func(r *http.Request) bool {
+		return r.Method == http.MethodPost && r.URL.Path == "/api/users"
+	}))
 	handler := httpHandler.NewHandler(repo)
 	handler.RegisterRoutes(router)
 
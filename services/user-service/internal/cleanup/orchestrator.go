@@ -0,0 +1,107 @@
+package cleanup
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	authpb "github.com/todo/proto/auth"
+	notificationpb "github.com/todo/proto/notification"
+	taskpb "github.com/todo/proto/task"
+	"github.com/todo/services/user-service/internal/clients"
+	"github.com/todo/services/user-service/internal/models"
+	"github.com/todo/services/user-service/internal/repository"
+)
+
+const maxAttempts = 10
+
+// Orchestrator drives user deletion jobs: for every pending step it asks the
+// owning service to remove the user's data, retrying failed steps on the next
+// tick until they succeed or run out of attempts.
+type Orchestrator struct {
+	repo     *repository.PostgresRepository
+	clients  *clients.Clients
+	interval time.Duration
+}
+
+func NewOrchestrator(repo *repository.PostgresRepository, clients *clients.Clients, interval time.Duration) *Orchestrator {
+	return &Orchestrator{
+		repo:     repo,
+		clients:  clients,
+		interval: interval,
+	}
+}
+
+func (o *Orchestrator) Run(ctx context.Context) {
+	ticker := time.NewTicker(o.interval)
+	defer ticker.Stop()
+
+	for {
+		o.processPending(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (o *Orchestrator) processPending(ctx context.Context) {
+	steps, err := o.repo.ListPendingDeletionSteps(maxAttempts, 50)
+	if err != nil {
+		log.Printf("Failed to list pending deletion steps: %v", err)
+		return
+	}
+
+	for _, step := range steps {
+		if err := o.runStep(ctx, step); err != nil {
+			log.Printf("Deletion job %s: %s cleanup for user %s failed: %v", step.JobID, step.Service, step.UserID, err)
+			if err := o.repo.FailDeletionStep(step.JobID, step.Service, err, maxAttempts); err != nil {
+				log.Printf("Failed to record deletion step failure: %v", err)
+			}
+			continue
+		}
+
+		if err := o.repo.CompleteDeletionStep(step.JobID, step.Service); err != nil {
+			log.Printf("Failed to complete deletion step: %v", err)
+		}
+	}
+}
+
+func (o *Orchestrator) runStep(ctx context.Context, step *models.DeletionStep) error {
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+
+	switch step.Service {
+	case models.ServiceTask:
+		resp, err := o.clients.Task.DeleteUserTasks(ctx, &taskpb.DeleteUserTasksRequest{UserId: step.UserID})
+		if err != nil {
+			return err
+		}
+		if resp.Error != "" {
+			return fmt.Errorf("%s", resp.Error)
+		}
+	case models.ServiceAuth:
+		resp, err := o.clients.Auth.RevokeUserTokens(ctx, &authpb.RevokeUserTokensRequest{UserId: step.UserID, Username: step.Username})
+		if err != nil {
+			return err
+		}
+		if resp.Error != "" {
+			return fmt.Errorf("%s", resp.Error)
+		}
+	case models.ServiceNotification:
+		resp, err := o.clients.Notification.PurgeUserNotifications(ctx, &notificationpb.PurgeUserNotificationsRequest{UserId: step.UserID})
+		if err != nil {
+			return err
+		}
+		if resp.Error != "" {
+			return fmt.Errorf("%s", resp.Error)
+		}
+	default:
+		return fmt.Errorf("unknown service %q", step.Service)
+	}
+
+	return nil
+}
@@ -0,0 +1,66 @@
+package clients
+
+import (
+	"github.com/todo/pkg/auth"
+	authpb "github.com/todo/proto/auth"
+	notificationpb "github.com/todo/proto/notification"
+	taskpb "github.com/todo/proto/task"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+)
+
+// Clients holds gRPC connections to the services user-service coordinates with.
+type Clients struct {
+	Task         taskpb.TaskServiceClient
+	Auth         authpb.AuthServiceClient
+	Notification notificationpb.NotificationServiceClient
+	conns        []*grpc.ClientConn
+	serviceToken string
+}
+
+// NewClients connects to the other services, authenticating every call with
+// the shared service token.
+func NewClients(taskAddr, authAddr, notificationAddr, serviceToken string) (*Clients, error) {
+	c := &Clients{serviceToken: serviceToken}
+
+	taskConn, err := c.dial(taskAddr)
+	if err != nil {
+		return nil, err
+	}
+	c.Task = taskpb.NewTaskServiceClient(taskConn)
+
+	authConn, err := c.dial(authAddr)
+	if err != nil {
+		c.Close()
+		return nil, err
+	}
+	c.Auth = authpb.NewAuthServiceClient(authConn)
+
+	notificationConn, err := c.dial(notificationAddr)
+	if err != nil {
+		c.Close()
+		return nil, err
+	}
+	c.Notification = notificationpb.NewNotificationServiceClient(notificationConn)
+
+	return c, nil
+}
+
+func (c *Clients) dial(addr string) (*grpc.ClientConn, error) {
+	conn, err := grpc.NewClient(addr,
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithPerRPCCredentials(auth.ServiceCredentials(c.serviceToken)),
+	)
+	if err != nil {
+		return nil, err
+	}
+	c.conns = append(c.conns, conn)
+	return conn, nil
+}
+
+func (c *Clients) Close() error {
+	for _, conn := range c.conns {
+		conn.Close()
+	}
+	return nil
+}
@@ -0,0 +1,178 @@
+package export
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"time"
+
+	authpb "github.com/todo/proto/auth"
+	notificationpb "github.com/todo/proto/notification"
+	taskpb "github.com/todo/proto/task"
+	"github.com/todo/services/user-service/internal/clients"
+	"github.com/todo/services/user-service/internal/models"
+	"github.com/todo/services/user-service/internal/repository"
+)
+
+// archiveRetention is how long a finished export can be downloaded.
+const archiveRetention = 7 * 24 * time.Hour
+
+// Exporter processes queued data exports: it gathers everything each service
+// stores about the user and packs it into a zip archive.
+type Exporter struct {
+	repo     *repository.PostgresRepository
+	clients  *clients.Clients
+	interval time.Duration
+}
+
+func NewExporter(repo *repository.PostgresRepository, clients *clients.Clients, interval time.Duration) *Exporter {
+	return &Exporter{
+		repo:     repo,
+		clients:  clients,
+		interval: interval,
+	}
+}
+
+func (e *Exporter) Run(ctx context.Context) {
+	ticker := time.NewTicker(e.interval)
+	defer ticker.Stop()
+
+	for {
+		e.processPending(ctx)
+
+		if err := e.repo.DeleteExpiredDataExports(); err != nil {
+			log.Printf("Failed to delete expired data exports: %v", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (e *Exporter) processPending(ctx context.Context) {
+	for {
+		export, err := e.repo.ClaimPendingDataExport()
+		if err != nil {
+			log.Printf("Failed to claim data export: %v", err)
+			return
+		}
+		if export == nil {
+			return
+		}
+
+		archive, err := e.buildArchive(ctx, export)
+		if err != nil {
+			log.Printf("Data export %s for user %s failed: %v", export.ID, export.UserID, err)
+			if err := e.repo.FailDataExport(export.ID, err); err != nil {
+				log.Printf("Failed to record data export failure: %v", err)
+			}
+			continue
+		}
+
+		if err := e.repo.CompleteDataExport(export.ID, archive, time.Now().Add(archiveRetention)); err != nil {
+			log.Printf("Failed to store data export %s: %v", export.ID, err)
+		}
+	}
+}
+
+func (e *Exporter) buildArchive(ctx context.Context, export *models.DataExport) ([]byte, error) {
+	ctx, cancel := context.WithTimeout(ctx, 2*time.Minute)
+	defer cancel()
+
+	user, err := e.repo.GetUserByID(export.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	profile, err := json.MarshalIndent(user, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+
+	taskResp, err := e.clients.Task.ExportUserData(ctx, &taskpb.ExportUserDataRequest{UserId: export.UserID})
+	if err != nil {
+		return nil, fmt.Errorf("task-service: %v", err)
+	}
+	if taskResp.Error != "" {
+		return nil, fmt.Errorf("task-service: %s", taskResp.Error)
+	}
+
+	authResp, err := e.clients.Auth.ExportUserData(ctx, &authpb.ExportUserDataRequest{UserId: export.UserID})
+	if err != nil {
+		return nil, fmt.Errorf("auth-service: %v", err)
+	}
+	if authResp.Error != "" {
+		return nil, fmt.Errorf("auth-service: %s", authResp.Error)
+	}
+
+	notificationResp, err := e.clients.Notification.ExportUserData(ctx, &notificationpb.ExportUserDataRequest{UserId: export.UserID})
+	if err != nil {
+		return nil, fmt.Errorf("notification-service: %v", err)
+	}
+	if notificationResp.Error != "" {
+		return nil, fmt.Errorf("notification-service: %s", notificationResp.Error)
+	}
+
+	files := []struct {
+		name string
+		data []byte
+	}{
+		{"profile.json", profile},
+		{"tasks.json", indent(taskResp.Data)},
+		{"sessions.json", indent(authResp.Data)},
+		{"notifications.json", indent(notificationResp.Data)},
+	}
+
+	manifest, err := json.MarshalIndent(map[string]interface{}{
+		"export_id":    export.ID,
+		"user_id":      export.UserID,
+		"requested_at": export.CreatedAt,
+		"generated_at": time.Now(),
+		"files":        []string{"profile.json", "tasks.json", "sessions.json", "notifications.json"},
+	}, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+
+	if err := writeFile(zw, "manifest.json", manifest); err != nil {
+		return nil, err
+	}
+	for _, f := range files {
+		if err := writeFile(zw, f.name, f.data); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+func writeFile(zw *zip.Writer, name string, data []byte) error {
+	w, err := zw.Create(name)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(data)
+	return err
+}
+
+// indent pretty-prints a JSON document, falling back to the raw bytes.
+func indent(data []byte) []byte {
+	var buf bytes.Buffer
+	if err := json.Indent(&buf, data, "", "  "); err != nil {
+		return data
+	}
+	return buf.Bytes()
+}
@@ -0,0 +1,99 @@
+package grpc
+
+import (
+	"context"
+
+	"github.com/todo/pkg/auth"
+	pb "github.com/todo/proto/user"
+	"github.com/todo/services/user-service/internal/models"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func (s *UserServer) ListRoles(ctx context.Context, req *pb.ListRolesRequest) (*pb.ListRolesResponse, error) {
+	roles, err := s.repo.ListRoles()
+	if err != nil {
+		return &pb.ListRolesResponse{
+			Error: err.Error(),
+		}, nil
+	}
+
+	pbRoles := make([]*pb.Role, len(roles))
+	for i, role := range roles {
+		pbRoles[i] = &pb.Role{
+			Name:        role.Name,
+			Description: role.Description,
+			Permissions: role.Permissions,
+		}
+	}
+
+	return &pb.ListRolesResponse{
+		Roles: pbRoles,
+	}, nil
+}
+
+func (s *UserServer) GetUserRoles(ctx context.Context, req *pb.GetUserRolesRequest) (*pb.UserRolesResponse, error) {
+	if err := auth.AuthorizeScope(ctx, req.UserId, auth.ScopeRolesManage); err != nil {
+		return &pb.UserRolesResponse{
+			Error: err.Error(),
+		}, nil
+	}
+
+	roles, err := s.repo.GetUserRoles(req.UserId)
+	if err != nil {
+		return &pb.UserRolesResponse{
+			Error: err.Error(),
+		}, nil
+	}
+
+	return convertUserRolesToProto(roles), nil
+}
+
+func (s *UserServer) AssignRole(ctx context.Context, req *pb.AssignRoleRequest) (*pb.UserRolesResponse, error) {
+	if err := auth.RequireScope(ctx, auth.ScopeRolesManage); err != nil {
+		return &pb.UserRolesResponse{
+			Error: err.Error(),
+		}, nil
+	}
+
+	roles, err := s.repo.AssignRole(req.UserId, req.Role)
+	if err != nil {
+		return &pb.UserRolesResponse{
+			Error: err.Error(),
+		}, nil
+	}
+
+	return convertUserRolesToProto(roles), nil
+}
+
+func (s *UserServer) RevokeRole(ctx context.Context, req *pb.RevokeRoleRequest) (*pb.UserRolesResponse, error) {
+	if err := auth.RequireScope(ctx, auth.ScopeRolesManage); err != nil {
+		return &pb.UserRolesResponse{
+			Error: err.Error(),
+		}, nil
+	}
+
+	roles, err := s.repo.RevokeRole(req.UserId, req.Role)
+	if err != nil {
+		return &pb.UserRolesResponse{
+			Error: err.Error(),
+		}, nil
+	}
+
+	return convertUserRolesToProto(roles), nil
+}
+
+func convertUserRolesToProto(roles *models.UserRoles) *pb.UserRolesResponse {
+	resp := &pb.UserRolesResponse{
+		UserId:      roles.UserID,
+		Permissions: roles.Permissions,
+	}
+
+	for _, role := range roles.Roles {
+		resp.Roles = append(resp.Roles, &pb.UserRole{
+			Role:      role.Role,
+			GrantedAt: timestamppb.New(role.GrantedAt),
+		})
+	}
+
+	return resp
+}
@@ -3,7 +3,9 @@ package grpc
 import (
 	"context"
 
+	"github.com/todo/pkg/auth"
 	pb "github.com/todo/proto/user"
+	"github.com/todo/services/user-service/internal/models"
 	"github.com/todo/services/user-service/internal/repository"
 	"google.golang.org/protobuf/types/known/timestamppb"
 )
@@ -38,6 +40,12 @@ func (s *UserServer) CreateUser(ctx context.Context, req *pb.CreateUserRequest)
 }
 
 func (s *UserServer) GetUser(ctx context.Context, req *pb.GetUserRequest) (*pb.GetUserResponse, error) {
+	if err := auth.AuthorizeScope(ctx, req.Id, auth.ScopeUsersRead); err != nil {
+		return &pb.GetUserResponse{
+			Error: err.Error(),
+		}, nil
+	}
+
 	user, err := s.repo.GetUserByID(req.Id)
 	if err != nil {
 		return &pb.GetUserResponse{
@@ -58,6 +66,12 @@ func (s *UserServer) GetUser(ctx context.Context, req *pb.GetUserRequest) (*pb.G
 }
 
 func (s *UserServer) UpdateUser(ctx context.Context, req *pb.UpdateUserRequest) (*pb.UpdateUserResponse, error) {
+	if err := auth.AuthorizeScope(ctx, req.Id, auth.ScopeUsersWrite); err != nil {
+		return &pb.UpdateUserResponse{
+			Error: err.Error(),
+		}, nil
+	}
+
 	user, err := s.repo.UpdateUser(req.Id, req.Username, req.Email, req.FullName)
 	if err != nil {
 		return &pb.UpdateUserResponse{
@@ -78,7 +92,14 @@ func (s *UserServer) UpdateUser(ctx context.Context, req *pb.UpdateUserRequest)
 }
 
 func (s *UserServer) DeleteUser(ctx context.Context, req *pb.DeleteUserRequest) (*pb.DeleteUserResponse, error) {
-	err := s.repo.DeleteUser(req.Id)
+	if err := auth.AuthorizeScope(ctx, req.Id, auth.ScopeUsersDelete); err != nil {
+		return &pb.DeleteUserResponse{
+			Success: false,
+			Error:   err.Error(),
+		}, nil
+	}
+
+	job, err := s.repo.DeleteUser(req.Id)
 	if err != nil {
 		return &pb.DeleteUserResponse{
 			Success: false,
@@ -87,11 +108,18 @@ func (s *UserServer) DeleteUser(ctx context.Context, req *pb.DeleteUserRequest)
 	}
 
 	return &pb.DeleteUserResponse{
-		Success: true,
+		Success:       true,
+		DeletionJobId: job.ID,
 	}, nil
 }
 
 func (s *UserServer) ListUsers(ctx context.Context, req *pb.ListUsersRequest) (*pb.ListUsersResponse, error) {
+	if err := auth.RequireScope(ctx, auth.ScopeUsersRead); err != nil {
+		return &pb.ListUsersResponse{
+			Error: err.Error(),
+		}, nil
+	}
+
 	users, total, err := s.repo.ListUsers(int(req.Page), int(req.PageSize))
 	if err != nil {
 		return &pb.ListUsersResponse{
@@ -116,3 +144,116 @@ func (s *UserServer) ListUsers(ctx context.Context, req *pb.ListUsersRequest) (*
 		Total: int32(total),
 	}, nil
 }
+
+func (s *UserServer) GetDeletionJob(ctx context.Context, req *pb.GetDeletionJobRequest) (*pb.GetDeletionJobResponse, error) {
+	// Authorize before loading the job: callers without users:read only
+	// find their own jobs, so others' job IDs can't be probed
+	userID := ""
+	if err := auth.RequireScope(ctx, auth.ScopeUsersRead); err != nil {
+		if userID, err = auth.ScopeUser(ctx, "", auth.ScopeUsersRead); err != nil {
+			return &pb.GetDeletionJobResponse{
+				Error: err.Error(),
+			}, nil
+		}
+	}
+
+	job, err := s.repo.GetDeletionJob(req.Id, userID)
+	if err != nil {
+		return &pb.GetDeletionJobResponse{
+			Error: err.Error(),
+		}, nil
+	}
+
+	return &pb.GetDeletionJobResponse{
+		Job: convertDeletionJobToProto(job),
+	}, nil
+}
+
+func convertDeletionJobToProto(job *models.DeletionJob) *pb.DeletionJob {
+	pbJob := &pb.DeletionJob{
+		Id:        job.ID,
+		UserId:    job.UserID,
+		Status:    string(job.Status),
+		CreatedAt: timestamppb.New(job.CreatedAt),
+		UpdatedAt: timestamppb.New(job.UpdatedAt),
+	}
+
+	for _, step := range job.Steps {
+		pbStep := &pb.DeletionStep{
+			Service:  step.Service,
+			Status:   string(step.Status),
+			Attempts: int32(step.Attempts),
+			Error:    step.Error,
+		}
+		if step.CompletedAt != nil {
+			pbStep.CompletedAt = timestamppb.New(*step.CompletedAt)
+		}
+		pbJob.Steps = append(pbJob.Steps, pbStep)
+	}
+
+	return pbJob
+}
+
+func (s *UserServer) RequestDataExport(ctx context.Context, req *pb.RequestDataExportRequest) (*pb.RequestDataExportResponse, error) {
+	if err := auth.Authorize(ctx, req.UserId, auth.ScopeUsersRead); err != nil {
+		return &pb.RequestDataExportResponse{
+			Error: err.Error(),
+		}, nil
+	}
+
+	if _, err := s.repo.GetUserByID(req.UserId); err != nil {
+		return &pb.RequestDataExportResponse{
+			Error: err.Error(),
+		}, nil
+	}
+
+	export, err := s.repo.CreateDataExport(req.UserId)
+	if err != nil {
+		return &pb.RequestDataExportResponse{
+			Error: err.Error(),
+		}, nil
+	}
+
+	return &pb.RequestDataExportResponse{
+		Export: convertDataExportToProto(export),
+	}, nil
+}
+
+func (s *UserServer) GetDataExport(ctx context.Context, req *pb.GetDataExportRequest) (*pb.GetDataExportResponse, error) {
+	export, err := s.repo.GetDataExport(req.Id)
+	if err != nil {
+		return &pb.GetDataExportResponse{
+			Error: err.Error(),
+		}, nil
+	}
+
+	if err := auth.Authorize(ctx, export.UserID, auth.ScopeUsersRead); err != nil {
+		return &pb.GetDataExportResponse{
+			Error: err.Error(),
+		}, nil
+	}
+
+	return &pb.GetDataExportResponse{
+		Export: convertDataExportToProto(export),
+	}, nil
+}
+
+func convertDataExportToProto(export *models.DataExport) *pb.DataExport {
+	pbExport := &pb.DataExport{
+		Id:        export.ID,
+		UserId:    export.UserID,
+		Status:    string(export.Status),
+		Error:     export.Error,
+		SizeBytes: export.SizeBytes,
+		CreatedAt: timestamppb.New(export.CreatedAt),
+	}
+
+	if export.CompletedAt != nil {
+		pbExport.CompletedAt = timestamppb.New(*export.CompletedAt)
+	}
+	if export.ExpiresAt != nil {
+		pbExport.ExpiresAt = timestamppb.New(*export.ExpiresAt)
+	}
+
+	return pbExport
+}
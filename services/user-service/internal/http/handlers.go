@@ -2,10 +2,12 @@ package http
 
 import (
 	"encoding/json"
+	"fmt"
 	"net/http"
 	"strconv"
 
 	"github.com/gorilla/mux"
+	"github.com/todo/pkg/auth"
 	"github.com/todo/services/user-service/internal/repository"
 )
 
@@ -51,6 +53,11 @@ func (h *Handler) GetUser(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	id := vars["id"]
 
+	if err := auth.AuthorizeScope(r.Context(), id, auth.ScopeUsersRead); err != nil {
+		auth.HTTPError(w, err, http.StatusForbidden)
+		return
+	}
+
 	user, err := h.repo.GetUserByID(id)
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusNotFound)
@@ -65,6 +72,11 @@ func (h *Handler) UpdateUser(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	id := vars["id"]
 
+	if err := auth.AuthorizeScope(r.Context(), id, auth.ScopeUsersWrite); err != nil {
+		auth.HTTPError(w, err, http.StatusForbidden)
+		return
+	}
+
 	var req UpdateUserRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		http.Error(w, err.Error(), http.StatusBadRequest)
@@ -85,16 +97,53 @@ func (h *Handler) DeleteUser(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	id := vars["id"]
 
-	err := h.repo.DeleteUser(id)
+	if err := auth.AuthorizeScope(r.Context(), id, auth.ScopeUsersDelete); err != nil {
+		auth.HTTPError(w, err, http.StatusForbidden)
+		return
+	}
+
+	job, err := h.repo.DeleteUser(id)
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
 
-	w.WriteHeader(http.StatusNoContent)
+	// The user is gone; their data in other services is cleaned up by the job
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusAccepted)
+	json.NewEncoder(w).Encode(job)
+}
+
+func (h *Handler) GetDeletionJob(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	// Authorize before loading the job: callers without users:read only
+	// find their own jobs, so others' job IDs can't be probed
+	userID := ""
+	if err := auth.RequireScope(r.Context(), auth.ScopeUsersRead); err != nil {
+		if userID, err = auth.ScopeUser(r.Context(), "", auth.ScopeUsersRead); err != nil {
+			auth.HTTPError(w, err, http.StatusForbidden)
+			return
+		}
+	}
+
+	job, err := h.repo.GetDeletionJob(id, userID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(job)
 }
 
 func (h *Handler) ListUsers(w http.ResponseWriter, r *http.Request) {
+	if err := auth.RequireScope(r.Context(), auth.ScopeUsersRead); err != nil {
+		auth.HTTPError(w, err, http.StatusForbidden)
+		return
+	}
+
 	pageStr := r.URL.Query().Get("page")
 	pageSizeStr := r.URL.Query().Get("page_size")
 
@@ -129,10 +178,88 @@ func (h *Handler) ListUsers(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(response)
 }
 
+func (h *Handler) RequestDataExport(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	userID := vars["id"]
+
+	if err := auth.Authorize(r.Context(), userID, auth.ScopeUsersRead); err != nil {
+		auth.HTTPError(w, err, http.StatusForbidden)
+		return
+	}
+
+	if _, err := h.repo.GetUserByID(userID); err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	export, err := h.repo.CreateDataExport(userID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusAccepted)
+	json.NewEncoder(w).Encode(export)
+}
+
+func (h *Handler) GetDataExport(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+
+	if err := auth.Authorize(r.Context(), vars["id"], auth.ScopeUsersRead); err != nil {
+		auth.HTTPError(w, err, http.StatusForbidden)
+		return
+	}
+
+	export, err := h.repo.GetDataExport(vars["export_id"])
+	if err != nil || export.UserID != vars["id"] {
+		http.Error(w, "data export not found", http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(export)
+}
+
+func (h *Handler) DownloadDataExport(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+
+	if err := auth.Authorize(r.Context(), vars["id"], auth.ScopeUsersRead); err != nil {
+		auth.HTTPError(w, err, http.StatusForbidden)
+		return
+	}
+
+	export, err := h.repo.GetDataExport(vars["export_id"])
+	if err != nil || export.UserID != vars["id"] {
+		http.Error(w, "data export not found", http.StatusNotFound)
+		return
+	}
+
+	archive, err := h.repo.GetDataExportArchive(export.ID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusConflict)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/zip")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=\"user-data-%s.zip\"", export.ID))
+	w.Write(archive)
+}
+
 func (h *Handler) RegisterRoutes(router *mux.Router) {
 	router.HandleFunc("/api/users", h.CreateUser).Methods("POST")
 	router.HandleFunc("/api/users", h.ListUsers).Methods("GET")
 	router.HandleFunc("/api/users/{id}", h.GetUser).Methods("GET")
 	router.HandleFunc("/api/users/{id}", h.UpdateUser).Methods("PUT")
 	router.HandleFunc("/api/users/{id}", h.DeleteUser).Methods("DELETE")
+	router.HandleFunc("/api/users/deletion-jobs/{id}", h.GetDeletionJob).Methods("GET")
+	router.HandleFunc("/api/users/{id}/exports", h.RequestDataExport).Methods("POST")
+	router.HandleFunc("/api/users/{id}/exports/{export_id}", h.GetDataExport).Methods("GET")
+	router.HandleFunc("/api/users/{id}/exports/{export_id}/download", h.DownloadDataExport).Methods("GET")
+
+	// Roles
+	router.HandleFunc("/api/roles", h.ListRoles).Methods("GET")
+	router.HandleFunc("/api/users/{id}/roles", h.GetUserRoles).Methods("GET")
+	router.HandleFunc("/api/users/{id}/roles/{role}", h.AssignRole).Methods("PUT")
+	router.HandleFunc("/api/users/{id}/roles/{role}", h.RevokeRole).Methods("DELETE")
 }
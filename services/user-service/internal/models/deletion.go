@@ -0,0 +1,41 @@
+package models
+
+import "time"
+
+type DeletionStatus string
+
+const (
+	DeletionPending    DeletionStatus = "PENDING"
+	DeletionInProgress DeletionStatus = "IN_PROGRESS"
+	DeletionCompleted  DeletionStatus = "COMPLETED"
+	DeletionFailed     DeletionStatus = "FAILED"
+)
+
+// Services whose data is cleaned up when a user is deleted.
+const (
+	ServiceTask         = "task-service"
+	ServiceAuth         = "auth-service"
+	ServiceNotification = "notification-service"
+)
+
+var DeletionServices = []string{ServiceTask, ServiceAuth, ServiceNotification}
+
+type DeletionStep struct {
+	JobID       string         `json:"-"`
+	UserID      string         `json:"-"`
+	Username    string         `json:"-"`
+	Service     string         `json:"service"`
+	Status      DeletionStatus `json:"status"`
+	Attempts    int            `json:"attempts"`
+	Error       string         `json:"error,omitempty"`
+	CompletedAt *time.Time     `json:"completed_at,omitempty"`
+}
+
+type DeletionJob struct {
+	ID        string          `json:"id"`
+	UserID    string          `json:"user_id"`
+	Status    DeletionStatus  `json:"status"`
+	Steps     []*DeletionStep `json:"steps"`
+	CreatedAt time.Time       `json:"created_at"`
+	UpdatedAt time.Time       `json:"updated_at"`
+}
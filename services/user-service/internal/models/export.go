@@ -0,0 +1,24 @@
+package models
+
+import "time"
+
+type ExportStatus string
+
+const (
+	ExportPending    ExportStatus = "PENDING"
+	ExportInProgress ExportStatus = "IN_PROGRESS"
+	ExportCompleted  ExportStatus = "COMPLETED"
+	ExportFailed     ExportStatus = "FAILED"
+)
+
+// DataExport tracks an asynchronous export of everything stored about a user.
+type DataExport struct {
+	ID          string       `json:"id"`
+	UserID      string       `json:"user_id"`
+	Status      ExportStatus `json:"status"`
+	Error       string       `json:"error,omitempty"`
+	SizeBytes   int64        `json:"size_bytes"`
+	CreatedAt   time.Time    `json:"created_at"`
+	CompletedAt *time.Time   `json:"completed_at,omitempty"`
+	ExpiresAt   *time.Time   `json:"expires_at,omitempty"`
+}
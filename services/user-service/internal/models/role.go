@@ -0,0 +1,41 @@
+package models
+
+import (
+	"time"
+
+	"github.com/todo/pkg/auth"
+)
+
+// Role is a named set of permissions, which are auth scopes.
+type Role struct {
+	Name        string   `json:"name"`
+	Description string   `json:"description"`
+	Permissions []string `json:"permissions"`
+}
+
+// BuiltInRoles are created, and kept up to date, at startup.
+var BuiltInRoles = []Role{
+	{
+		Name:        auth.RoleAdmin,
+		Description: "Full access to every user, task and role",
+		Permissions: auth.AllScopes,
+	},
+	{
+		Name:        auth.RoleSupport,
+		Description: "Read access to every user and task",
+		Permissions: []string{auth.ScopeUsersRead, auth.ScopeTasksRead},
+	},
+}
+
+// UserRole records a role assigned to a user.
+type UserRole struct {
+	Role      string    `json:"role"`
+	GrantedAt time.Time `json:"granted_at"`
+}
+
+// UserRoles are a user's roles together with the permissions they grant.
+type UserRoles struct {
+	UserID      string      `json:"user_id"`
+	Roles       []*UserRole `json:"roles"`
+	Permissions []string    `json:"permissions"`
+}
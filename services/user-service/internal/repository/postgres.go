@@ -7,6 +7,7 @@ import (
 
 	"github.com/google/uuid"
 	_ "github.com/lib/pq"
+	"github.com/todo/pkg/events"
 	"github.com/todo/services/user-service/internal/models"
 	"golang.org/x/crypto/bcrypt"
 )
@@ -35,15 +36,61 @@ func NewPostgresRepository(connStr string) (*PostgresRepository, error) {
 			full_name VARCHAR(255),
 			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
 			updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
-		)
+		);
+		CREATE TABLE IF NOT EXISTS user_deletion_jobs (
+			id VARCHAR(36) PRIMARY KEY,
+			user_id VARCHAR(36) NOT NULL,
+			status VARCHAR(50) NOT NULL DEFAULT 'PENDING',
+			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+			updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+		);
+		ALTER TABLE user_deletion_jobs ADD COLUMN IF NOT EXISTS username VARCHAR(255) NOT NULL DEFAULT '';
+		CREATE TABLE IF NOT EXISTS user_deletion_steps (
+			job_id VARCHAR(36) NOT NULL REFERENCES user_deletion_jobs(id) ON DELETE CASCADE,
+			service VARCHAR(100) NOT NULL,
+			status VARCHAR(50) NOT NULL DEFAULT 'PENDING',
+			attempts INT NOT NULL DEFAULT 0,
+			last_error TEXT,
+			completed_at TIMESTAMP,
+			PRIMARY KEY (job_id, service)
+		);
+		CREATE TABLE IF NOT EXISTS user_data_exports (
+			id VARCHAR(36) PRIMARY KEY,
+			user_id VARCHAR(36) NOT NULL,
+			status VARCHAR(50) NOT NULL DEFAULT 'PENDING',
+			error TEXT,
+			archive BYTEA,
+			size_bytes BIGINT NOT NULL DEFAULT 0,
+			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+			completed_at TIMESTAMP,
+			expires_at TIMESTAMP
+		);
+		CREATE INDEX IF NOT EXISTS idx_user_data_exports_user_id ON user_data_exports(user_id);
 	`)
 	if err != nil {
 		return nil, err
 	}
 
+	if _, err := db.Exec(rolesSchema); err != nil {
+		return nil, err
+	}
+
+	if err := seedRoles(db); err != nil {
+		return nil, err
+	}
+
+	if err := events.CreateOutboxTable(db); err != nil {
+		return nil, err
+	}
+
 	return &PostgresRepository{db: db}, nil
 }
 
+// DB exposes the connection pool for the outbox relay.
+func (r *PostgresRepository) DB() *sql.DB {
+	return r.db
+}
+
 func (r *PostgresRepository) CreateUser(username, email, password, fullName string) (*models.User, error) {
 	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
 	if err != nil {
@@ -60,7 +107,13 @@ func (r *PostgresRepository) CreateUser(username, email, password, fullName stri
 		UpdatedAt: time.Now(),
 	}
 
-	_, err = r.db.Exec(
+	tx, err := r.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	_, err = tx.Exec(
 		"INSERT INTO users (id, username, email, password, full_name, created_at, updated_at) VALUES ($1, $2, $3, $4, $5, $6, $7)",
 		user.ID, user.Username, user.Email, user.Password, user.FullName, user.CreatedAt, user.UpdatedAt,
 	)
@@ -68,14 +121,36 @@ func (r *PostgresRepository) CreateUser(username, email, password, fullName stri
 		return nil, err
 	}
 
+	if err := events.Append(tx, events.UserCreated, user.ID, userPayload(user)); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
 	return user, nil
 }
 
 func (r *PostgresRepository) GetUserByID(id string) (*models.User, error) {
+	return getUser(r.db, "id", id)
+}
+
+func (r *PostgresRepository) GetUserByUsername(username string) (*models.User, error) {
+	return getUser(r.db, "username", username)
+}
+
+// queryRower is satisfied by both *sql.DB and *sql.Tx.
+type queryRower interface {
+	QueryRow(query string, args ...interface{}) *sql.Row
+}
+
+// getUser looks a user up by a unique column; column is never user input.
+func getUser(q queryRower, column, value string) (*models.User, error) {
 	user := &models.User{}
-	err := r.db.QueryRow(
-		"SELECT id, username, email, password, full_name, created_at, updated_at FROM users WHERE id = $1",
-		id,
+	err := q.QueryRow(
+		"SELECT id, username, email, password, full_name, created_at, updated_at FROM users WHERE "+column+" = $1",
+		value,
 	).Scan(&user.ID, &user.Username, &user.Email, &user.Password, &user.FullName, &user.CreatedAt, &user.UpdatedAt)
 
 	if err == sql.ErrNoRows {
@@ -88,51 +163,229 @@ func (r *PostgresRepository) GetUserByID(id string) (*models.User, error) {
 	return user, nil
 }
 
-func (r *PostgresRepository) GetUserByUsername(username string) (*models.User, error) {
-	user := &models.User{}
+func (r *PostgresRepository) UpdateUser(id, username, email, fullName string) (*models.User, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	_, err = tx.Exec(
+		"UPDATE users SET username = $2, email = $3, full_name = $4, updated_at = $5 WHERE id = $1",
+		id, username, email, fullName, time.Now(),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := getUser(tx, "id", id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := events.Append(tx, events.UserUpdated, user.ID, userPayload(user)); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return user, nil
+}
+
+// DeleteUser removes the user and schedules a deletion job that cleans up
+// the user's data in the other services.
+func (r *PostgresRepository) DeleteUser(id string) (*models.DeletionJob, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	user, err := getUser(tx, "id", id)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := tx.Exec("DELETE FROM users WHERE id = $1", id); err != nil {
+		return nil, err
+	}
+
+	if _, err := tx.Exec("DELETE FROM user_data_exports WHERE user_id = $1", id); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	job := &models.DeletionJob{
+		ID:        uuid.New().String(),
+		UserID:    user.ID,
+		Status:    models.DeletionPending,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+
+	_, err = tx.Exec(
+		"INSERT INTO user_deletion_jobs (id, user_id, username, status, created_at, updated_at) VALUES ($1, $2, $3, $4, $5, $6)",
+		job.ID, job.UserID, user.Username, job.Status, job.CreatedAt, job.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, service := range models.DeletionServices {
+		_, err := tx.Exec(
+			"INSERT INTO user_deletion_steps (job_id, service, status) VALUES ($1, $2, $3)",
+			job.ID, service, models.DeletionPending,
+		)
+		if err != nil {
+			return nil, err
+		}
+		job.Steps = append(job.Steps, &models.DeletionStep{
+			JobID:    job.ID,
+			UserID:   job.UserID,
+			Username: user.Username,
+			Service:  service,
+			Status:   models.DeletionPending,
+		})
+	}
+
+	if err := events.Append(tx, events.UserDeleted, user.ID, userPayload(user)); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return job, nil
+}
+
+// GetDeletionJob loads a deletion job. A non-empty userID restricts the
+// lookup to that user's jobs, so other users' jobs read as not found.
+func (r *PostgresRepository) GetDeletionJob(id, userID string) (*models.DeletionJob, error) {
+	job := &models.DeletionJob{}
 	err := r.db.QueryRow(
-		"SELECT id, username, email, password, full_name, created_at, updated_at FROM users WHERE username = $1",
-		username,
-	).Scan(&user.ID, &user.Username, &user.Email, &user.Password, &user.FullName, &user.CreatedAt, &user.UpdatedAt)
+		"SELECT id, user_id, status, created_at, updated_at FROM user_deletion_jobs WHERE id = $1 AND ($2 = '' OR user_id = $2)",
+		id, userID,
+	).Scan(&job.ID, &job.UserID, &job.Status, &job.CreatedAt, &job.UpdatedAt)
 
 	if err == sql.ErrNoRows {
-		return nil, fmt.Errorf("user not found")
+		return nil, fmt.Errorf("deletion job not found")
 	}
 	if err != nil {
 		return nil, err
 	}
 
-	return user, nil
+	rows, err := r.db.Query(
+		"SELECT job_id, service, status, attempts, COALESCE(last_error, ''), completed_at FROM user_deletion_steps WHERE job_id = $1 ORDER BY service",
+		id,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		step := &models.DeletionStep{UserID: job.UserID}
+		var completedAt sql.NullTime
+
+		if err := rows.Scan(&step.JobID, &step.Service, &step.Status, &step.Attempts, &step.Error, &completedAt); err != nil {
+			return nil, err
+		}
+
+		if completedAt.Valid {
+			step.CompletedAt = &completedAt.Time
+		}
+
+		job.Steps = append(job.Steps, step)
+	}
+
+	return job, rows.Err()
 }
 
-func (r *PostgresRepository) UpdateUser(id, username, email, fullName string) (*models.User, error) {
-	_, err := r.db.Exec(
-		"UPDATE users SET username = $2, email = $3, full_name = $4, updated_at = $5 WHERE id = $1",
-		id, username, email, fullName, time.Now(),
+// ListPendingDeletionSteps returns steps that still have to run, oldest job first.
+func (r *PostgresRepository) ListPendingDeletionSteps(maxAttempts, limit int) ([]*models.DeletionStep, error) {
+	rows, err := r.db.Query(
+		`SELECT s.job_id, j.user_id, j.username, s.service, s.status, s.attempts, COALESCE(s.last_error, '')
+		FROM user_deletion_steps s JOIN user_deletion_jobs j ON j.id = s.job_id
+		WHERE s.status IN ($1, $2) AND s.attempts < $3
+		ORDER BY j.created_at LIMIT $4`,
+		models.DeletionPending, models.DeletionInProgress, maxAttempts, limit,
 	)
 	if err != nil {
 		return nil, err
 	}
+	defer rows.Close()
+
+	var steps []*models.DeletionStep
+	for rows.Next() {
+		step := &models.DeletionStep{}
+		if err := rows.Scan(&step.JobID, &step.UserID, &step.Username, &step.Service, &step.Status, &step.Attempts, &step.Error); err != nil {
+			return nil, err
+		}
+		steps = append(steps, step)
+	}
+
+	return steps, rows.Err()
+}
+
+// CompleteDeletionStep marks a step as done and completes the job once every
+// step has finished.
+func (r *PostgresRepository) CompleteDeletionStep(jobID, service string) error {
+	now := time.Now()
+	_, err := r.db.Exec(
+		"UPDATE user_deletion_steps SET status = $3, attempts = attempts + 1, last_error = NULL, completed_at = $4 WHERE job_id = $1 AND service = $2",
+		jobID, service, models.DeletionCompleted, now,
+	)
+	if err != nil {
+		return err
+	}
 
-	return r.GetUserByID(id)
+	return r.refreshDeletionJob(jobID)
 }
 
-func (r *PostgresRepository) DeleteUser(id string) error {
-	result, err := r.db.Exec("DELETE FROM users WHERE id = $1", id)
+// FailDeletionStep records a failed attempt. The step stays retryable until
+// it has used up maxAttempts, after which it and the job are marked failed.
+func (r *PostgresRepository) FailDeletionStep(jobID, service string, stepErr error, maxAttempts int) error {
+	_, err := r.db.Exec(
+		`UPDATE user_deletion_steps SET attempts = attempts + 1, last_error = $3,
+		status = CASE WHEN attempts + 1 >= $4 THEN $5 ELSE $6 END
+		WHERE job_id = $1 AND service = $2`,
+		jobID, service, stepErr.Error(), maxAttempts, models.DeletionFailed, models.DeletionInProgress,
+	)
 	if err != nil {
 		return err
 	}
 
-	rowsAffected, err := result.RowsAffected()
+	return r.refreshDeletionJob(jobID)
+}
+
+func (r *PostgresRepository) refreshDeletionJob(jobID string) error {
+	var total, completed, failed int
+	err := r.db.QueryRow(
+		"SELECT COUNT(*), COUNT(*) FILTER (WHERE status = $2), COUNT(*) FILTER (WHERE status = $3) FROM user_deletion_steps WHERE job_id = $1",
+		jobID, models.DeletionCompleted, models.DeletionFailed,
+	).Scan(&total, &completed, &failed)
 	if err != nil {
 		return err
 	}
 
-	if rowsAffected == 0 {
-		return fmt.Errorf("user not found")
+	status := models.DeletionInProgress
+	switch {
+	case failed > 0:
+		status = models.DeletionFailed
+	case completed == total:
+		status = models.DeletionCompleted
 	}
 
-	return nil
+	// The username is only kept for the cleanup steps that need it
+	_, err = r.db.Exec(
+		`UPDATE user_deletion_jobs SET status = $2, updated_at = $3,
+		username = CASE WHEN $4 THEN '' ELSE username END WHERE id = $1`,
+		jobID, status, time.Now(), status == models.DeletionCompleted,
+	)
+	return err
 }
 
 func (r *PostgresRepository) ListUsers(page, pageSize int) ([]*models.User, int, error) {
@@ -166,6 +419,131 @@ func (r *PostgresRepository) ListUsers(page, pageSize int) ([]*models.User, int,
 	return users, total, nil
 }
 
+func (r *PostgresRepository) CreateDataExport(userID string) (*models.DataExport, error) {
+	export := &models.DataExport{
+		ID:        uuid.New().String(),
+		UserID:    userID,
+		Status:    models.ExportPending,
+		CreatedAt: time.Now(),
+	}
+
+	_, err := r.db.Exec(
+		"INSERT INTO user_data_exports (id, user_id, status, created_at) VALUES ($1, $2, $3, $4)",
+		export.ID, export.UserID, export.Status, export.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return export, nil
+}
+
+func (r *PostgresRepository) GetDataExport(id string) (*models.DataExport, error) {
+	export := &models.DataExport{}
+	var completedAt, expiresAt sql.NullTime
+
+	err := r.db.QueryRow(
+		"SELECT id, user_id, status, COALESCE(error, ''), size_bytes, created_at, completed_at, expires_at FROM user_data_exports WHERE id = $1",
+		id,
+	).Scan(&export.ID, &export.UserID, &export.Status, &export.Error, &export.SizeBytes, &export.CreatedAt, &completedAt, &expiresAt)
+
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("data export not found")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if completedAt.Valid {
+		export.CompletedAt = &completedAt.Time
+	}
+	if expiresAt.Valid {
+		export.ExpiresAt = &expiresAt.Time
+	}
+
+	return export, nil
+}
+
+// GetDataExportArchive returns the archive of a completed, unexpired export.
+func (r *PostgresRepository) GetDataExportArchive(id string) ([]byte, error) {
+	var archive []byte
+	var expiresAt sql.NullTime
+
+	err := r.db.QueryRow(
+		"SELECT archive, expires_at FROM user_data_exports WHERE id = $1 AND status = $2",
+		id, models.ExportCompleted,
+	).Scan(&archive, &expiresAt)
+
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("data export not ready")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if expiresAt.Valid && time.Now().After(expiresAt.Time) {
+		return nil, fmt.Errorf("data export expired")
+	}
+
+	return archive, nil
+}
+
+// ClaimPendingDataExport marks the oldest pending export as in progress and
+// returns it, or nil if there is nothing to do.
+func (r *PostgresRepository) ClaimPendingDataExport() (*models.DataExport, error) {
+	export := &models.DataExport{}
+	err := r.db.QueryRow(
+		`UPDATE user_data_exports SET status = $1
+		WHERE id = (SELECT id FROM user_data_exports WHERE status = $2 ORDER BY created_at LIMIT 1 FOR UPDATE SKIP LOCKED)
+		RETURNING id, user_id, status, created_at`,
+		models.ExportInProgress, models.ExportPending,
+	).Scan(&export.ID, &export.UserID, &export.Status, &export.CreatedAt)
+
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return export, nil
+}
+
+func (r *PostgresRepository) CompleteDataExport(id string, archive []byte, expiresAt time.Time) error {
+	_, err := r.db.Exec(
+		"UPDATE user_data_exports SET status = $2, archive = $3, size_bytes = $4, completed_at = $5, expires_at = $6 WHERE id = $1",
+		id, models.ExportCompleted, archive, len(archive), time.Now(), expiresAt,
+	)
+	return err
+}
+
+func (r *PostgresRepository) FailDataExport(id string, exportErr error) error {
+	_, err := r.db.Exec(
+		"UPDATE user_data_exports SET status = $2, error = $3, completed_at = $4 WHERE id = $1",
+		id, models.ExportFailed, exportErr.Error(), time.Now(),
+	)
+	return err
+}
+
+// DeleteExpiredDataExports drops archives past their expiry, keeping the job
+// rows so their status stays queryable.
+func (r *PostgresRepository) DeleteExpiredDataExports() error {
+	_, err := r.db.Exec(
+		"UPDATE user_data_exports SET archive = NULL WHERE archive IS NOT NULL AND expires_at < $1",
+		time.Now(),
+	)
+	return err
+}
+
+func userPayload(user *models.User) events.UserPayload {
+	return events.UserPayload{
+		ID:       user.ID,
+		Username: user.Username,
+		Email:    user.Email,
+		FullName: user.FullName,
+	}
+}
+
 func (r *PostgresRepository) Close() error {
 	return r.db.Close()
 }
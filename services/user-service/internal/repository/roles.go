@@ -0,0 +1,188 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/todo/services/user-service/internal/models"
+)
+
+// Roles live next to users so auth-service can read them when issuing
+// tokens.
+const rolesSchema = `
+	CREATE TABLE IF NOT EXISTS roles (
+		name VARCHAR(50) PRIMARY KEY,
+		description TEXT NOT NULL DEFAULT ''
+	);
+
+	CREATE TABLE IF NOT EXISTS role_permissions (
+		role VARCHAR(50) NOT NULL REFERENCES roles(name) ON DELETE CASCADE,
+		permission VARCHAR(100) NOT NULL,
+		PRIMARY KEY (role, permission)
+	);
+
+	CREATE TABLE IF NOT EXISTS user_roles (
+		user_id VARCHAR(36) NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+		role VARCHAR(50) NOT NULL REFERENCES roles(name) ON DELETE CASCADE,
+		granted_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+		PRIMARY KEY (user_id, role)
+	);
+`
+
+// seedRoles creates the built-in roles and resets their permissions.
+func seedRoles(db *sql.DB) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	for _, role := range models.BuiltInRoles {
+		_, err := tx.Exec(
+			"INSERT INTO roles (name, description) VALUES ($1, $2) ON CONFLICT (name) DO UPDATE SET description = EXCLUDED.description",
+			role.Name, role.Description,
+		)
+		if err != nil {
+			return err
+		}
+
+		if _, err := tx.Exec("DELETE FROM role_permissions WHERE role = $1", role.Name); err != nil {
+			return err
+		}
+		for _, permission := range role.Permissions {
+			if _, err := tx.Exec("INSERT INTO role_permissions (role, permission) VALUES ($1, $2)", role.Name, permission); err != nil {
+				return err
+			}
+		}
+	}
+
+	return tx.Commit()
+}
+
+func (r *PostgresRepository) ListRoles() ([]*models.Role, error) {
+	rows, err := r.db.Query(
+		`SELECT r.name, r.description, COALESCE(p.permission, '')
+		FROM roles r LEFT JOIN role_permissions p ON p.role = r.name
+		ORDER BY r.name, p.permission`,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var roles []*models.Role
+	for rows.Next() {
+		var name, description, permission string
+		if err := rows.Scan(&name, &description, &permission); err != nil {
+			return nil, err
+		}
+
+		if len(roles) == 0 || roles[len(roles)-1].Name != name {
+			roles = append(roles, &models.Role{Name: name, Description: description, Permissions: []string{}})
+		}
+		if permission != "" {
+			role := roles[len(roles)-1]
+			role.Permissions = append(role.Permissions, permission)
+		}
+	}
+
+	return roles, rows.Err()
+}
+
+// GetUserRoles returns the user's roles and the permissions they grant.
+func (r *PostgresRepository) GetUserRoles(userID string) (*models.UserRoles, error) {
+	if _, err := getUser(r.db, "id", userID); err != nil {
+		return nil, err
+	}
+
+	userRoles := &models.UserRoles{
+		UserID:      userID,
+		Roles:       []*models.UserRole{},
+		Permissions: []string{},
+	}
+
+	rows, err := r.db.Query(
+		"SELECT role, granted_at FROM user_roles WHERE user_id = $1 ORDER BY role",
+		userID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		role := &models.UserRole{}
+		if err := rows.Scan(&role.Role, &role.GrantedAt); err != nil {
+			return nil, err
+		}
+		userRoles.Roles = append(userRoles.Roles, role)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	permissions, err := r.db.Query(
+		`SELECT DISTINCT p.permission FROM user_roles u
+		JOIN role_permissions p ON p.role = u.role
+		WHERE u.user_id = $1 ORDER BY p.permission`,
+		userID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer permissions.Close()
+
+	for permissions.Next() {
+		var permission string
+		if err := permissions.Scan(&permission); err != nil {
+			return nil, err
+		}
+		userRoles.Permissions = append(userRoles.Permissions, permission)
+	}
+
+	return userRoles, permissions.Err()
+}
+
+// AssignRole gives the user a role. Assigning a role the user already has
+// is a no-op.
+func (r *PostgresRepository) AssignRole(userID, role string) (*models.UserRoles, error) {
+	if _, err := getUser(r.db, "id", userID); err != nil {
+		return nil, err
+	}
+
+	var exists bool
+	if err := r.db.QueryRow("SELECT EXISTS (SELECT 1 FROM roles WHERE name = $1)", role).Scan(&exists); err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, fmt.Errorf("role not found")
+	}
+
+	_, err := r.db.Exec(
+		"INSERT INTO user_roles (user_id, role, granted_at) VALUES ($1, $2, $3) ON CONFLICT (user_id, role) DO NOTHING",
+		userID, role, time.Now(),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return r.GetUserRoles(userID)
+}
+
+func (r *PostgresRepository) RevokeRole(userID, role string) (*models.UserRoles, error) {
+	result, err := r.db.Exec("DELETE FROM user_roles WHERE user_id = $1 AND role = $2", userID, role)
+	if err != nil {
+		return nil, err
+	}
+
+	n, err := result.RowsAffected()
+	if err != nil {
+		return nil, err
+	}
+	if n == 0 {
+		return nil, fmt.Errorf("user does not have role %s", role)
+	}
+
+	return r.GetUserRoles(userID)
+}
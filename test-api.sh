@@ -12,6 +12,9 @@ AUTH_SERVICE="http://localhost:8082"
 TASK_SERVICE="http://localhost:8083"
 NOTIFICATION_SERVICE="http://localhost:8084"
 
+# Token other services use to call user-service and task-service
+SERVICE_TOKEN="${SERVICE_TOKEN:-your-service-token-change-in-production}"
+
 echo -e "${BLUE}========================================${NC}"
 echo -e "${BLUE}Testing Task Management Microservices${NC}"
 echo -e "${BLUE}========================================${NC}\n"
@@ -74,7 +77,8 @@ fi
 
 # Test 4: Get user details
 echo -e "${BLUE}4. Getting user details...${NC}"
-GET_USER_RESPONSE=$(curl -s -X GET $USER_SERVICE/api/users/$USER_ID)
+GET_USER_RESPONSE=$(curl -s -X GET $USER_SERVICE/api/users/$USER_ID \
+  -H "Authorization: Bearer $ACCESS_TOKEN")
 
 echo "$GET_USER_RESPONSE" | jq .
 echo -e "${GREEN}✓ User details retrieved${NC}\n"
@@ -82,6 +86,7 @@ echo -e "${GREEN}✓ User details retrieved${NC}\n"
 # Test 5: Create a task
 echo -e "${BLUE}5. Creating a task...${NC}"
 TASK_RESPONSE=$(curl -s -X POST $TASK_SERVICE/api/tasks \
+  -H "Authorization: Bearer $ACCESS_TOKEN" \
   -H "Content-Type: application/json" \
   -d "{
     \"title\": \"Complete API testing\",
@@ -102,7 +107,8 @@ fi
 
 # Test 6: Get task details
 echo -e "${BLUE}6. Getting task details...${NC}"
-GET_TASK_RESPONSE=$(curl -s -X GET $TASK_SERVICE/api/tasks/$TASK_ID)
+GET_TASK_RESPONSE=$(curl -s -X GET $TASK_SERVICE/api/tasks/$TASK_ID \
+  -H "Authorization: Bearer $ACCESS_TOKEN")
 
 echo "$GET_TASK_RESPONSE" | jq .
 echo -e "${GREEN}✓ Task details retrieved${NC}\n"
@@ -110,6 +116,7 @@ echo -e "${GREEN}✓ Task details retrieved${NC}\n"
 # Test 7: Update task
 echo -e "${BLUE}7. Updating task status...${NC}"
 UPDATE_TASK_RESPONSE=$(curl -s -X PUT $TASK_SERVICE/api/tasks/$TASK_ID \
+  -H "Authorization: Bearer $ACCESS_TOKEN" \
   -H "Content-Type: application/json" \
   -d '{
     "title": "Complete API testing",
@@ -124,7 +131,8 @@ echo -e "${GREEN}✓ Task updated successfully${NC}\n"
 
 # Test 8: List user tasks
 echo -e "${BLUE}8. Listing user tasks...${NC}"
-LIST_TASKS_RESPONSE=$(curl -s -X GET "$TASK_SERVICE/api/users/$USER_ID/tasks?page=1&page_size=10")
+LIST_TASKS_RESPONSE=$(curl -s -X GET "$TASK_SERVICE/api/users/$USER_ID/tasks?page=1&page_size=10" \
+  -H "Authorization: Bearer $ACCESS_TOKEN")
 
 echo "$LIST_TASKS_RESPONSE" | jq .
 echo -e "${GREEN}✓ User tasks listed${NC}\n"
@@ -149,14 +157,16 @@ fi
 
 # Test 10: List all users
 echo -e "${BLUE}10. Listing all users...${NC}"
-LIST_USERS_RESPONSE=$(curl -s -X GET "$USER_SERVICE/api/users?page=1&page_size=10")
+LIST_USERS_RESPONSE=$(curl -s -X GET "$USER_SERVICE/api/users?page=1&page_size=10" \
+  -H "Authorization: Bearer $SERVICE_TOKEN")
 
 echo "$LIST_USERS_RESPONSE" | jq .
 echo -e "${GREEN}✓ Users listed${NC}\n"
 
 # Test 11: List all tasks
 echo -e "${BLUE}11. Listing all tasks...${NC}"
-ALL_TASKS_RESPONSE=$(curl -s -X GET "$TASK_SERVICE/api/tasks?page=1&page_size=10")
+ALL_TASKS_RESPONSE=$(curl -s -X GET "$TASK_SERVICE/api/tasks?page=1&page_size=10" \
+  -H "Authorization: Bearer $SERVICE_TOKEN")
 
 echo "$ALL_TASKS_RESPONSE" | jq .
 echo -e "${GREEN}✓ All tasks listed${NC}\n"
@@ -171,6 +181,6 @@ echo -e "Task ID: ${GREEN}$TASK_ID${NC}"
 echo -e "Access Token: ${GREEN}${ACCESS_TOKEN:0:50}...${NC}\n"
 
 echo -e "${BLUE}Cleanup (optional):${NC}"
-echo -e "To delete the task: curl -X DELETE $TASK_SERVICE/api/tasks/$TASK_ID"
-echo -e "To delete the user: curl -X DELETE $USER_SERVICE/api/users/$USER_ID"
+echo -e "To delete the task: curl -X DELETE -H \"Authorization: Bearer \$ACCESS_TOKEN\" $TASK_SERVICE/api/tasks/$TASK_ID"
+echo -e "To delete the user: curl -X DELETE -H \"Authorization: Bearer \$ACCESS_TOKEN\" $USER_SERVICE/api/users/$USER_ID"
 